@@ -0,0 +1,117 @@
+//go:build cgo
+
+package main
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"sync"
+
+	"github.com/Apricot-S/mjai-manue-go/configs"
+	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+const defaultName = "Manue030"
+
+// botConfig is the JSON configuration of manue_new. The members match the
+// flags of mjai-manue, and every member is optional.
+type botConfig struct {
+	Name            string `json:"name"`
+	ID              int    `json:"id"`
+	Profiles        string `json:"profiles"`
+	TenpaiModel     string `json:"tenpai_model"`
+	DefenseTurns    int    `json:"defense_turns"`
+	PossibleActions string `json:"possible_actions"`
+	Meta            bool   `json:"meta"`
+	Resilient       bool   `json:"resilient"`
+	ServerProfile   string `json:"server_profile"`
+}
+
+func parseBotConfig(config string) (botConfig, error) {
+	cfg := botConfig{Name: defaultName, PossibleActions: "off"}
+	if config == "" {
+		return cfg, nil
+	}
+	if err := json.Unmarshal([]byte(config), &cfg, json.RejectUnknownMembers(true)); err != nil {
+		return botConfig{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if _, err := seat.NewSeat(cfg.ID); err != nil {
+		return botConfig{}, fmt.Errorf("invalid config: %w", err)
+	}
+	if cfg.DefenseTurns < 0 {
+		return botConfig{}, fmt.Errorf("invalid config: defense turns must not be negative")
+	}
+	return cfg, nil
+}
+
+// embeddedDeps loads the embedded configuration once, since it is read-only
+// and can be shared between bots.
+var embeddedDeps = sync.OnceValues(func() (ai.ManueAgentDeps, error) {
+	stats, err := configs.LoadGameStats()
+	if err != nil {
+		return ai.ManueAgentDeps{}, fmt.Errorf("failed to load game stats: %w", err)
+	}
+	dangerTree, err := configs.LoadDangerTree()
+	if err != nil {
+		return ai.ManueAgentDeps{}, fmt.Errorf("failed to load danger tree: %w", err)
+	}
+	return ai.ManueAgentDeps{
+		Stats:  stats,
+		Danger: ai.NewDangerEstimator(dangerTree),
+	}, nil
+})
+
+// newBot returns a stdio session of a Manue agent. The session is silent:
+// the traces and decision logs that mjai-manue writes to stderr are dropped.
+func newBot(seed uint64, config string) (*mjairuntime.Session, error) {
+	cfg, err := parseBotConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	possibleActions, err := mjairuntime.ParsePossibleActionsMode(cfg.PossibleActions)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	var profile *mjairuntime.ServerProfile
+	if cfg.ServerProfile != "" {
+		profile, err = mjairuntime.LookupServerProfile(cfg.ServerProfile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	deps, err := embeddedDeps()
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Profiles != "" {
+		profiles, err := configs.LoadOpponentProfiles(cfg.Profiles)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load opponent profiles: %w", err)
+		}
+		deps.Profiles = profiles
+	}
+	if cfg.TenpaiModel != "" {
+		model, err := configs.LoadTenpaiModel(cfg.TenpaiModel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tenpai model: %w", err)
+		}
+		deps.Tenpai = model
+	}
+	deps.DefenseTurns = cfg.DefenseTurns
+	agent, err := ai.NewManueAgent(seed, deps)
+	if err != nil {
+		return nil, err
+	}
+	return mjairuntime.NewSession(mjairuntime.SessionConfig{
+		Name:            cfg.Name,
+		Room:            "default",
+		FallbackID:      cfg.ID,
+		Agent:           agent,
+		PossibleActions: possibleActions,
+		Meta:            cfg.Meta,
+		Resilient:       cfg.Resilient,
+		ServerProfile:   profile,
+	})
+}
@@ -0,0 +1,119 @@
+// Command libmanue is a C shared library that runs Manue in the process of
+// its host, such as a Python program through ctypes. Build it with
+//
+//	go build -buildmode=c-shared -o libmanue.so ./cmd/libmanue
+//
+// which also writes the header libmanue.h. The library needs cgo, so the
+// package is empty when cgo is disabled.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime/cgo"
+	"unsafe"
+
+	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+)
+
+func main() {}
+
+// setString stores a copy of s in *dst, which the caller frees with
+// manue_free, unless dst is NULL.
+func setString(dst **C.char, s string) {
+	if dst != nil {
+		*dst = C.CString(s)
+	}
+}
+
+// clearString sets *dst to NULL unless dst is NULL.
+func clearString(dst **C.char) {
+	if dst != nil {
+		*dst = nil
+	}
+}
+
+// recoverFailure is deferred by the exported functions that can fail, so that
+// a panic fails the call instead of crashing the host: it sets *result to
+// failed and *err to the panic.
+func recoverFailure[T any](result *T, failed T, err **C.char) {
+	if r := recover(); r != nil {
+		*result = failed
+		setString(err, fmt.Sprintf("panic: %v", r))
+	}
+}
+
+// ignorePanic is deferred by the exported functions that cannot report a
+// failure, so that a panic does not crash the host.
+func ignorePanic() {
+	_ = recover()
+}
+
+// manue_new creates a bot. config is a JSON object of options, or NULL or
+// empty for the defaults. It returns 0 and sets *err on failure.
+//
+//export manue_new
+func manue_new(seed C.uint64_t, config *C.char, err **C.char) (handle C.uintptr_t) {
+	clearString(err)
+	defer recoverFailure(&handle, 0, err)
+	var cfg string
+	if config != nil {
+		cfg = C.GoString(config)
+	}
+	bot, e := newBot(uint64(seed), cfg)
+	if e != nil {
+		setString(err, e.Error())
+		return 0
+	}
+	return C.uintptr_t(cgo.NewHandle(bot))
+}
+
+// manue_push hands a line from the server to a bot. On success it returns 0
+// and sets *reaction to the reply line, or to NULL when the bot has nothing
+// to say. On failure it returns -1 and sets *err, which is also how it
+// reports a bot already released. A bot must not be pushed from two threads
+// at once.
+//
+//export manue_push
+func manue_push(bot C.uintptr_t, line *C.char, reaction **C.char, err **C.char) (status C.int) {
+	clearString(reaction)
+	clearString(err)
+	defer recoverFailure(&status, -1, err)
+	if bot == 0 || line == nil {
+		setString(err, "bot and line must not be NULL")
+		return -1
+	}
+	session := cgo.Handle(bot).Value().(*mjairuntime.Session)
+	reply, e := session.HandleLine([]byte(C.GoString(line)))
+	if e != nil {
+		setString(err, e.Error())
+		return -1
+	}
+	if reply != nil {
+		setString(reaction, string(reply))
+	}
+	return 0
+}
+
+// manue_destroy releases a bot. It ignores 0 and a bot already released.
+//
+//export manue_destroy
+func manue_destroy(bot C.uintptr_t) {
+	defer ignorePanic()
+	if bot != 0 {
+		cgo.Handle(bot).Delete()
+	}
+}
+
+// manue_free frees a string returned by the other functions. It ignores NULL.
+//
+//export manue_free
+func manue_free(s *C.char) {
+	defer ignorePanic()
+	C.free(unsafe.Pointer(s))
+}
@@ -0,0 +1,125 @@
+//go:build cgo
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParseBotConfig(t *testing.T) {
+	cfg, err := parseBotConfig(`{"name":"ManueGo","id":2,"defense_turns":3,"meta":true}`)
+	if err != nil {
+		t.Fatalf("parseBotConfig() failed: %v", err)
+	}
+	want := botConfig{Name: "ManueGo", ID: 2, DefenseTurns: 3, PossibleActions: "off", Meta: true}
+	if cfg != want {
+		t.Errorf("parseBotConfig() = %+v, want %+v", cfg, want)
+	}
+
+	if cfg, err := parseBotConfig(""); err != nil || cfg.Name != defaultName {
+		t.Errorf("parseBotConfig(\"\") = %+v, %v, want the defaults", cfg, err)
+	}
+}
+
+func TestParseBotConfigRejectsInvalidConfig(t *testing.T) {
+	for _, config := range []string{
+		`{`,
+		`{"seed":1}`,
+		`{"id":4}`,
+		`{"defense_turns":-1}`,
+	} {
+		if _, err := parseBotConfig(config); err == nil {
+			t.Errorf("parseBotConfig(%s) succeeded unexpectedly", config)
+		}
+	}
+}
+
+func TestNewBotRejectsInvalidPossibleActions(t *testing.T) {
+	if _, err := newBot(0, `{"possible_actions":"never"}`); err == nil {
+		t.Fatal("newBot() succeeded unexpectedly")
+	}
+}
+
+// TestCABI builds the shared library and drives it from a C program.
+func TestCABI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the shared library")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("the C program links with -rpath")
+	}
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("no C compiler")
+	}
+	dir := t.TempDir()
+	build := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-buildmode=c-shared", "-o", filepath.Join(dir, "libmanue.so"), ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build the library: %v\n%s", err, out)
+	}
+	program := filepath.Join(dir, "push_lines")
+	compile := exec.Command(cc, "-o", program, "-I", dir, filepath.Join("testdata", "push_lines.c"), "-L", dir, "-lmanue", "-Wl,-rpath,"+dir)
+	if out, err := compile.CombinedOutput(); err != nil {
+		t.Fatalf("failed to compile the program: %v\n%s", err, out)
+	}
+
+	run := func(t *testing.T, args ...string) string {
+		t.Helper()
+		in, err := os.Open(filepath.Join("testdata", "session.mjson"))
+		if err != nil {
+			t.Fatalf("failed to open the session: %v", err)
+		}
+		defer in.Close()
+		cmd := exec.Command(program, args...)
+		cmd.Stdin = in
+		out, _ := cmd.Output()
+		return string(out)
+	}
+
+	t.Run("session", func(t *testing.T) {
+		got := run(t, "0", `{"name":"ManueGo"}`)
+		lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+		want := []string{
+			`{"type":"join","name":"ManueGo","room":"default"}`,
+			"-",
+			"-",
+			`{"type":"dahai","actor":0,"pai":"N","tsumogiri":true,"log":`,
+			"error: ",
+			"-",
+		}
+		if len(lines) != len(want) {
+			t.Fatalf("output = %q, want %d lines", got, len(want))
+		}
+		for i := range want {
+			if !strings.HasPrefix(lines[i], want[i]) {
+				t.Errorf("line %d = %q, want %q", i+1, lines[i], want[i])
+			}
+		}
+	})
+
+	t.Run("released bot", func(t *testing.T) {
+		// Pushing to a released bot panics in cgo.Handle, which fails each
+		// push, and releasing it again is ignored.
+		got := run(t, "0", `{}`, "released")
+		lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+		if len(lines) != 6 {
+			t.Fatalf("output = %q, want 6 lines", got)
+		}
+		for i, line := range lines {
+			if want := "error: panic: runtime/cgo: misuse of an invalid Handle"; line != want {
+				t.Errorf("line %d = %q, want %q", i+1, line, want)
+			}
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		if got := run(t, "0", `{"id":4}`); !strings.HasPrefix(got, "error: invalid config") {
+			t.Errorf("output = %q, want an error", got)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+"""Drive Manue in-process through libmanue with ctypes.
+
+Build the library first:
+
+    go build -buildmode=c-shared -o libmanue.so ./cmd/libmanue
+
+Then run this file to play the lines of an mjai log from stdin:
+
+    python cmd/libmanue/manue.py ./libmanue.so < game.mjson
+"""
+
+from __future__ import annotations
+
+import ctypes
+import json
+import sys
+from typing import TYPE_CHECKING, Self
+
+if TYPE_CHECKING:
+    from types import TracebackType
+
+
+class ManueError(Exception):
+    """An error reported by libmanue."""
+
+
+class Library:
+    """The functions of a loaded libmanue."""
+
+    def __init__(self, path: str) -> None:
+        lib = ctypes.CDLL(path)
+        out_string = ctypes.POINTER(ctypes.c_void_p)
+        lib.manue_new.argtypes = [
+            ctypes.c_uint64,
+            ctypes.c_char_p,
+            out_string,
+        ]
+        lib.manue_new.restype = ctypes.c_size_t
+        lib.manue_push.argtypes = [
+            ctypes.c_size_t,
+            ctypes.c_char_p,
+            out_string,
+            out_string,
+        ]
+        lib.manue_push.restype = ctypes.c_int
+        lib.manue_destroy.argtypes = [ctypes.c_size_t]
+        lib.manue_destroy.restype = None
+        lib.manue_free.argtypes = [ctypes.c_void_p]
+        lib.manue_free.restype = None
+        self._lib = lib
+
+    def new(self, seed: int, config: dict[str, object] | None) -> int:
+        """Create a bot and return its handle."""
+        err = ctypes.c_void_p()
+        encoded = json.dumps(config).encode() if config else None
+        handle = self._lib.manue_new(seed, encoded, ctypes.byref(err))
+        if not handle:
+            raise ManueError(self._take(err))
+        return handle
+
+    def push(self, handle: int, line: str) -> str | None:
+        """Push a line to a bot and return the reply, if any."""
+        reaction = ctypes.c_void_p()
+        err = ctypes.c_void_p()
+        status = self._lib.manue_push(
+            handle,
+            line.encode(),
+            ctypes.byref(reaction),
+            ctypes.byref(err),
+        )
+        if status != 0:
+            raise ManueError(self._take(err))
+        return self._take(reaction)
+
+    def destroy(self, handle: int) -> None:
+        """Destroy a bot."""
+        self._lib.manue_destroy(handle)
+
+    def _take(self, p: ctypes.c_void_p) -> str | None:
+        """Return the string p points to and free it."""
+        if not p.value:
+            return None
+        try:
+            return ctypes.string_at(p.value).decode()
+        finally:
+            self._lib.manue_free(p)
+
+
+class Bot:
+    """A Manue bot that reacts to mjai messages.
+
+    A bot must not be pushed from two threads at once, but separate bots
+    can run in parallel.
+    """
+
+    def __init__(
+        self,
+        lib: Library,
+        seed: int = 0,
+        config: dict[str, object] | None = None,
+    ) -> None:
+        self._lib = lib
+        self._handle = lib.new(seed, config)
+
+    def push(self, line: str) -> str | None:
+        """Push a line from the server and return the reply, if any."""
+        return self._lib.push(self._handle, line)
+
+    def close(self) -> None:
+        """Destroy the bot. It is safe to call twice."""
+        if self._handle:
+            self._lib.destroy(self._handle)
+            self._handle = 0
+
+    def __enter__(self) -> Self:
+        return self
+
+    def __exit__(
+        self,
+        exc_type: type[BaseException] | None,
+        exc: BaseException | None,
+        tb: TracebackType | None,
+    ) -> None:
+        self.close()
+
+
+def main() -> None:
+    lib = Library(sys.argv[1] if len(sys.argv) > 1 else "./libmanue.so")
+    with Bot(lib, seed=0, config={"name": "Manue030"}) as bot:
+        for line in sys.stdin:
+            reply = bot.push(line.rstrip("\n"))
+            if reply is not None:
+                print(reply)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,43 @@
+/*
+ * push_lines creates a bot with the seed and the config in the arguments,
+ * pushes each line of stdin to it and prints its reactions, "-" for no
+ * reaction, and "error: " and the message for an error. With "released" as
+ * the third argument, the bot is released before the lines are pushed.
+ */
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#include "libmanue.h"
+
+int main(int argc, char **argv) {
+    char line[65536];
+    char *reaction;
+    char *err;
+    uintptr_t bot;
+
+    bot = manue_new(argc > 1 ? strtoull(argv[1], NULL, 10) : 0,
+                    argc > 2 ? argv[2] : NULL, &err);
+    if (bot == 0) {
+        printf("error: %s\n", err);
+        manue_free(err);
+        return 1;
+    }
+    if (argc > 3 && strcmp(argv[3], "released") == 0) {
+        manue_destroy(bot);
+    }
+    while (fgets(line, sizeof line, stdin) != NULL) {
+        line[strcspn(line, "\n")] = '\0';
+        if (manue_push(bot, line, &reaction, &err) != 0) {
+            printf("error: %s\n", err);
+            manue_free(err);
+        } else if (reaction == NULL) {
+            printf("-\n");
+        } else {
+            printf("%s\n", reaction);
+            manue_free(reaction);
+        }
+    }
+    manue_destroy(bot);
+    return 0;
+}
@@ -0,0 +1,616 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/application"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+const debugKeys = "n/→ next  p/← previous  ]/↓ next round  [/↑ previous round  a next decision\n" +
+	"g go to line  e evaluate  s seed  t trials  q quit"
+
+const debugCommands = "n [K] next  p [K] previous  ] next round  [ previous round  a next decision\n" +
+	"g LINE go to line  e evaluate  s SEED seed  t N trials  q quit"
+
+// runDebug steps through a game log from a seat and evaluates the positions
+// of the seat with the agent. It runs full screen when stdin and stdout are a
+// terminal and reads a command per line otherwise.
+func runDebug(args []string, in io.Reader, out io.Writer, errOut io.Writer) int {
+	flags := flag.NewFlagSet("mjai-manue debug", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	seatID := flags.Int("seat", 0, "view the game from player `N`")
+	seed := flags.Uint64("seed", defaultSeed, "evaluate with the random seed")
+	trials := flags.Int("trials", ai.DefaultWinTrials, "evaluate with `N` trials of the win estimate of each candidate")
+	profiles := flags.String("profiles", "", "evaluate with the opponent profiles in `FILE`")
+	tenpaiModel := flags.String("tenpai-model", "", "evaluate with the tenpai model in `FILE`")
+	defenseTurns := flags.Int("defense-turns", 0, "evaluate planning the deal-in of each discard over `N` turns")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(errOut, "usage: mjai-manue debug [--seat N] [--seed N] [--trials N] [--profiles FILE] [--tenpai-model FILE] [--defense-turns N] LOG")
+		return exitUsageError
+	}
+	self, err := seat.NewSeat(*seatID)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitUsageError
+	}
+	if *trials <= 0 {
+		fmt.Fprintln(errOut, "trials must be positive")
+		return exitUsageError
+	}
+	if *defenseTurns < 0 {
+		fmt.Fprintln(errOut, "defense turns must not be negative")
+		return exitUsageError
+	}
+
+	lines, err := readDebugLog(flags.Arg(0), self)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	deps, err := loadManueAgentDeps(agentConfigFiles{profiles: *profiles, tenpaiModel: *tenpaiModel})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	deps.DefenseTurns = *defenseTurns
+
+	d := &debugger{
+		title:  filepath.Base(flags.Arg(0)),
+		lines:  lines,
+		self:   self,
+		deps:   deps,
+		seed:   *seed,
+		trials: *trials,
+	}
+	d.goTo(0)
+	if f, ok := in.(*os.File); ok && isTerminal(f) && isTerminal(out) {
+		err = d.runTerminal(f, out)
+	} else {
+		err = d.runLines(in, out)
+	}
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	return exitOK
+}
+
+func isTerminal(w any) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// debugLine is a line of the log as the debugged seat sees it.
+type debugLine struct {
+	number int
+	text   string
+	msg    inbound.Message
+}
+
+// readDebugLog reads the log at path, decompressing it when the name ends in
+// .gz. The hands and draws of the other players are hidden from self.
+func readDebugLog(path string, self seat.Seat) ([]debugLine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var lines []debugLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := string(bytes.TrimSpace(scanner.Bytes()))
+		if text == "" {
+			return nil, fmt.Errorf("%s:%d: empty line", path, number)
+		}
+		msg, err := inbound.ParseMessage([]byte(text))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: failed to parse message: %w", path, number, err)
+		}
+		if _, ok := msg.(*inbound.Hello); ok {
+			continue
+		}
+		hideOthers(msg, self)
+		lines = append(lines, debugLine{number: number, text: text, msg: msg})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%s: no games", path)
+	}
+	return lines, nil
+}
+
+// hideOthers replaces the tiles of msg that self cannot see with "?", as a
+// server sends them to self.
+func hideOthers(msg inbound.Message, self seat.Seat) {
+	switch msg := msg.(type) {
+	case *inbound.StartKyoku:
+		for i, tehai := range msg.Tehais {
+			if i == self.Index() {
+				continue
+			}
+			for j := range tehai {
+				tehai[j] = "?"
+			}
+		}
+	case *inbound.Tsumo:
+		if msg.Actor != self.Index() {
+			msg.Pai = "?"
+		}
+	}
+}
+
+// debugPlayer feeds lines of the log to the bot of the game they belong to.
+type debugPlayer struct {
+	self  seat.Seat
+	agent ai.Agent
+	bot   *application.Bot
+}
+
+// apply applies line. The bot is nil outside a game.
+func (p *debugPlayer) apply(line debugLine) error {
+	switch msg := line.msg.(type) {
+	case *inbound.StartGame:
+		if p.agent != nil {
+			p.agent.Reset()
+			if r, ok := p.agent.(ai.PlayerNamesReceiver); ok && len(msg.Names) > 0 {
+				r.SetPlayerNames(msg.Names)
+			}
+		}
+		p.bot = application.NewBot(p.self, p.agent, nil)
+		return nil
+	case *inbound.EndGame:
+		p.bot = nil
+		return nil
+	}
+	if p.bot == nil {
+		return nil
+	}
+	ev, err := inbound.ParseEvent(line.msg)
+	if err != nil {
+		return nil
+	}
+	if err := p.bot.Observe(ev); err != nil {
+		return fmt.Errorf("line %d: %w", line.number, err)
+	}
+	return nil
+}
+
+// legalActions returns the legal actions of the seat, or none outside a
+// round.
+func (p *debugPlayer) legalActions() []action.Action {
+	if p.bot == nil {
+		return nil
+	}
+	legalActions, err := p.bot.LegalActions()
+	if err != nil {
+		return nil
+	}
+	return legalActions
+}
+
+// debugger is the state of a debug session. The bot is rebuilt from the
+// start of the game on every move, so that any line can be reached in both
+// directions.
+type debugger struct {
+	title  string
+	lines  []debugLine
+	self   seat.Seat
+	deps   ai.ManueAgentDeps
+	seed   uint64
+	trials int
+
+	pos int
+	// player holds the state after lines[pos].
+	player *debugPlayer
+	// replayErr is the error that stopped the replay before pos.
+	replayErr error
+	// evaluation is the result of the agent at pos, if evaluated.
+	evaluation string
+	status     string
+}
+
+// gameStart returns the index of the start_game of the game that lines[pos]
+// belongs to, or -1 when no game has started.
+func (d *debugger) gameStart(pos int) int {
+	for i := pos; i >= 0; i-- {
+		switch d.lines[i].msg.(type) {
+		case *inbound.StartGame:
+			return i
+		case *inbound.EndGame:
+			if i < pos {
+				return -1
+			}
+		}
+	}
+	return -1
+}
+
+// replay returns a player fed with the lines of the game up to pos.
+func (d *debugger) replay(pos int, agent ai.Agent) (*debugPlayer, error) {
+	p := &debugPlayer{self: d.self, agent: agent}
+	start := d.gameStart(pos)
+	if start < 0 {
+		return p, nil
+	}
+	for _, line := range d.lines[start : pos+1] {
+		if err := p.apply(line); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+func (d *debugger) goTo(pos int) {
+	d.pos = max(0, min(len(d.lines)-1, pos))
+	d.player, d.replayErr = d.replay(d.pos, nil)
+	d.evaluation = ""
+}
+
+// goToLine moves to the line with the given number in the file, or the
+// first line after it.
+func (d *debugger) goToLine(number int) {
+	for i, line := range d.lines {
+		if line.number >= number {
+			d.goTo(i)
+			return
+		}
+	}
+	d.goTo(len(d.lines) - 1)
+}
+
+// jumpRound moves to the next or previous start of a round or a game.
+func (d *debugger) jumpRound(step int) {
+	i := d.pos
+	for {
+		i += step
+		if i <= 0 || i >= len(d.lines)-1 {
+			break
+		}
+		switch d.lines[i].msg.(type) {
+		case *inbound.StartKyoku, *inbound.StartGame:
+			d.goTo(i)
+			return
+		}
+	}
+	d.goTo(i)
+}
+
+// nextDecision moves to the next line after which the seat has a legal
+// action.
+func (d *debugger) nextDecision() {
+	p, err := d.replay(d.pos, nil)
+	if err != nil {
+		d.status = "cannot follow the game: " + err.Error()
+		return
+	}
+	for i := d.pos + 1; i < len(d.lines); i++ {
+		if err := p.apply(d.lines[i]); err != nil {
+			d.goTo(i)
+			return
+		}
+		if len(p.legalActions()) > 0 {
+			d.goTo(i)
+			return
+		}
+	}
+	d.status = "no decision of the seat after this line"
+}
+
+// evaluate runs a new agent with the seed and the trials over the game up to
+// the position and asks it for a decision.
+func (d *debugger) evaluate() {
+	if d.replayErr != nil {
+		d.status = "cannot evaluate: " + d.replayErr.Error()
+		return
+	}
+	if len(d.player.legalActions()) == 0 {
+		d.status = "no legal action to evaluate"
+		return
+	}
+	deps := d.deps
+	deps.WinTrials = d.trials
+	agent, err := ai.NewManueAgent(d.seed, deps)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	p, err := d.replay(d.pos, agent)
+	if err != nil {
+		d.status = err.Error()
+		return
+	}
+	decision, ok, err := p.bot.Decide()
+	if err != nil {
+		d.status = "cannot evaluate: " + err.Error()
+		return
+	}
+	if !ok {
+		d.status = "no legal action to evaluate"
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "evaluation with seed %d and %d trials:\n", d.seed, d.trials)
+	fmt.Fprintf(&b, "  decided %s\n", formatAction(decision.Action))
+	if decision.Log != "" {
+		b.WriteString(decision.Log)
+		if !strings.HasSuffix(decision.Log, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	d.evaluation = b.String()
+}
+
+func formatAction(a action.Action) string {
+	msg, err := outbound.ToMessage(a, "")
+	if err != nil {
+		return fmt.Sprint(a)
+	}
+	b, err := outbound.MarshalMessage(msg)
+	if err != nil {
+		return fmt.Sprint(a)
+	}
+	return string(b)
+}
+
+// execute runs a command and reports whether it quits the session.
+func (d *debugger) execute(command string) bool {
+	d.status = ""
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return false
+	}
+	arg := func() (int64, bool) {
+		if len(fields) != 2 {
+			d.status = fmt.Sprintf("usage: %s N", fields[0])
+			return 0, false
+		}
+		n, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			d.status = fmt.Sprintf("invalid number: %s", fields[1])
+			return 0, false
+		}
+		return n, true
+	}
+	count := func() (int, bool) {
+		if len(fields) == 1 {
+			return 1, true
+		}
+		n, ok := arg()
+		return int(n), ok
+	}
+
+	switch fields[0] {
+	case "n":
+		if n, ok := count(); ok {
+			d.goTo(d.pos + n)
+		}
+	case "p":
+		if n, ok := count(); ok {
+			d.goTo(d.pos - n)
+		}
+	case "]":
+		d.jumpRound(1)
+	case "[":
+		d.jumpRound(-1)
+	case "a":
+		d.nextDecision()
+	case "g":
+		if n, ok := arg(); ok {
+			d.goToLine(int(n))
+		}
+	case "e":
+		d.evaluate()
+	case "s":
+		if n, ok := arg(); ok {
+			if n < 0 {
+				d.status = "seed must not be negative"
+				break
+			}
+			d.seed = uint64(n)
+			d.reevaluate()
+		}
+	case "t":
+		if n, ok := arg(); ok {
+			if n <= 0 {
+				d.status = "trials must be positive"
+				break
+			}
+			d.trials = int(n)
+			d.reevaluate()
+		}
+	case "q":
+		return true
+	default:
+		d.status = fmt.Sprintf("unknown command: %s", fields[0])
+	}
+	return false
+}
+
+// reevaluate evaluates again when the position has been evaluated.
+func (d *debugger) reevaluate() {
+	if d.evaluation != "" {
+		d.evaluate()
+	}
+}
+
+// render returns the screen of the position, ending with help.
+func (d *debugger) render(help string) string {
+	var b strings.Builder
+	line := d.lines[d.pos]
+	fmt.Fprintf(&b, "%s  seat %d  seed %d  trials %d\n", d.title, d.self.Index(), d.seed, d.trials)
+	fmt.Fprintf(&b, "line %d (%d/%d): %s\n", line.number, d.pos+1, len(d.lines), line.text)
+	if d.player.bot != nil {
+		b.WriteString(d.player.bot.RenderBoard())
+		b.WriteString(d.player.bot.RenderUkeire())
+	}
+	if d.replayErr != nil {
+		fmt.Fprintf(&b, "cannot follow the game: %v\n", d.replayErr)
+	} else if legalActions := d.player.legalActions(); len(legalActions) > 0 {
+		b.WriteString("legal actions:\n")
+		for _, a := range legalActions {
+			fmt.Fprintf(&b, "  %s\n", formatAction(a))
+		}
+	}
+	b.WriteString(d.evaluation)
+	if d.status != "" {
+		b.WriteString(d.status + "\n")
+	}
+	b.WriteString(help + "\n")
+	return b.String()
+}
+
+// runLines reads a command per line and writes the screen after each.
+func (d *debugger) runLines(in io.Reader, out io.Writer) error {
+	fmt.Fprint(out, d.render(debugCommands))
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+		if d.execute(scanner.Text()) {
+			return nil
+		}
+		fmt.Fprint(out, d.render(debugCommands))
+	}
+}
+
+// runTerminal runs the session full screen with a key per command.
+func (d *debugger) runTerminal(in *os.File, out io.Writer) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), state)
+	fmt.Fprint(out, "\x1b[?1049h")
+	defer fmt.Fprint(out, "\x1b[?1049l")
+
+	r := bufio.NewReader(in)
+	for {
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.ReplaceAll(d.render(debugKeys), "\n", "\r\n"))
+		key, err := readKey(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		command := key
+		switch key {
+		case "right":
+			command = "n"
+		case "left":
+			command = "p"
+		case "down":
+			command = "]"
+		case "up":
+			command = "["
+		case "\x03":
+			command = "q"
+		case "g", "s", "t":
+			prompts := map[string]string{"g": "go to line: ", "s": "seed: ", "t": "trials: "}
+			value, ok, err := readField(r, out, prompts[key])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			command = key + " " + value
+		}
+		if d.execute(command) {
+			return nil
+		}
+	}
+}
+
+// readKey reads a key from a terminal in raw mode. Arrow keys are returned
+// by name and other keys as they are.
+func readKey(r *bufio.Reader) (string, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if b != '\x1b' || r.Buffered() < 2 {
+		return string(b), nil
+	}
+	seq, err := r.Peek(2)
+	if err != nil || seq[0] != '[' {
+		return string(b), nil
+	}
+	r.Discard(2)
+	switch seq[1] {
+	case 'A':
+		return "up", nil
+	case 'B':
+		return "down", nil
+	case 'C':
+		return "right", nil
+	case 'D':
+		return "left", nil
+	}
+	return "", nil
+}
+
+// readField reads a line typed after prompt at the bottom of the screen. It
+// returns false when Escape cancels it.
+func readField(r *bufio.Reader, out io.Writer, prompt string) (string, bool, error) {
+	fmt.Fprint(out, prompt)
+	var value []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", false, err
+		}
+		switch {
+		case b == '\r' || b == '\n':
+			return string(value), true, nil
+		case b == '\x1b' || b == '\x03':
+			return "", false, nil
+		case b == '\x7f' || b == '\b':
+			if len(value) > 0 {
+				value = value[:len(value)-1]
+				fmt.Fprint(out, "\b \b")
+			}
+		case b >= ' ' && b < '\x7f':
+			value = append(value, b)
+			fmt.Fprintf(out, "%c", b)
+		}
+	}
+}
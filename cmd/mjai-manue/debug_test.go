@@ -0,0 +1,96 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const debugTestLog = `{"type":"hello","protocol":"mjsonp","protocol_version":3}
+{"type":"start_game","names":["A","B","C","D"]}
+{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[["1m","1m","1m","2p","3p","4p","3s","4s","5s","6s","6s","7s","N"],["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","E","S","S"],["1p","1p","2p","2p","3p","3p","4p","4p","6p","6p","7p","7p","W"],["9m","9m","9m","9p","9p","9p","1s","1s","P","P","F","F","C"]]}
+{"type":"tsumo","actor":0,"pai":"9s"}
+{"type":"dahai","actor":0,"pai":"N","tsumogiri":false}
+{"type":"tsumo","actor":1,"pai":"W"}
+{"type":"dahai","actor":1,"pai":"W","tsumogiri":true}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+
+func debugForTest(t *testing.T, args []string, commands string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "game.mjson")
+	if err := os.WriteFile(path, []byte(debugTestLog), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	var out strings.Builder
+	var errOut strings.Builder
+	got := run(append(append([]string{"debug"}, args...), path), strings.NewReader(commands), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run(debug) = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	return out.String()
+}
+
+func TestRun_DebugEvaluatesDecision(t *testing.T) {
+	out := debugForTest(t, []string{"--seat", "0", "--trials", "10"}, "a\ne\nt 20\nq\n")
+
+	// Each command is followed by a screen and a prompt.
+	screens := strings.Split(out, "> ")
+	if len(screens) != 5 {
+		t.Fatalf("got %d screens, want 5; stdout = %q", len(screens), out)
+	}
+	decision := screens[1]
+	for _, want := range []string{
+		"line 4 (3/8): ",
+		"ukeire: dahai N  shanten 0  4 tiles: 8s\n",
+		"legal actions:\n",
+		`{"type":"dahai","actor":0,"pai":"N","tsumogiri":false}`,
+	} {
+		if !strings.Contains(decision, want) {
+			t.Errorf("screen = %q, want containing %q", decision, want)
+		}
+	}
+	if !strings.Contains(decision, " [1] tehai: ?  ?") {
+		t.Errorf("screen = %q, want the hand of player 1 hidden", decision)
+	}
+	if !strings.Contains(screens[2], "evaluation with seed 0 and 10 trials:\n  decided {\"type\":\"dahai\"") {
+		t.Errorf("screen = %q, want the evaluation", screens[2])
+	}
+	if !strings.Contains(screens[3], "evaluation with seed 0 and 20 trials:") {
+		t.Errorf("screen = %q, want the evaluation with the new trials", screens[3])
+	}
+}
+
+func TestRun_DebugSteps(t *testing.T) {
+	out := debugForTest(t, []string{"--seat", "1"}, "g 6\ne\np 2\n]\nx\n")
+
+	screens := strings.Split(out, "> ")
+	if len(screens) != 7 {
+		t.Fatalf("got %d screens, want 7; stdout = %q", len(screens), out)
+	}
+	if !strings.Contains(screens[1], "line 6 (5/8): ") || !strings.Contains(screens[1], `"pai":"W","tsumogiri":true}`) {
+		t.Errorf("screen = %q, want the draw of player 1", screens[1])
+	}
+	if !strings.Contains(screens[3], "line 4 (3/8): ") || strings.Contains(screens[3], "legal actions:") {
+		t.Errorf("screen = %q, want the draw of player 0 without actions", screens[3])
+	}
+	if !strings.Contains(screens[4], "line 9 (8/8): ") {
+		t.Errorf("screen = %q, want the end of the log", screens[4])
+	}
+	if !strings.Contains(screens[5], "unknown command: x") {
+		t.Errorf("screen = %q, want an unknown command", screens[5])
+	}
+}
+
+func TestRun_DebugRejectsInvalidSeat(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"debug", "--seat", "4", "game.mjson"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Errorf("run(debug) = %d, want %d", got, exitUsageError)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+// runLobby plays on several mjsonp tables at once. The first interrupt lets
+// the games in progress finish; a second one terminates the process.
+func runLobby(args []string, errOut io.Writer) int {
+	flags := flag.NewFlagSet("mjai-manue lobby", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	name := flags.String("name", defaultName, "player name")
+	id := flags.Int("id", 0, "fallback player id used when start_game omits id")
+	seed := flags.Uint64("seed", defaultSeed, "base random seed of the games")
+	games := flags.Int("games", 0, "number of games per table; 0 plays until interrupted")
+	logDir := flags.String("log-dir", "", "write the log of each table to `DIR`/table-N.log")
+	profiles := flags.String("profiles", "", "adjust to opponents with the profiles in `FILE`")
+	tenpaiModel := flags.String("tenpai-model", "", "estimate tenpai with the model in `FILE`")
+	defenseTurns := flags.Int("defense-turns", 0, "plan the deal-in of each discard over `N` turns; 0 considers the discard alone")
+	possibleActions := flags.String("possible-actions", "off", "cross-check the possible_actions of the server: `MODE` is off, warn, restrict, or strict; the server profile may change the default")
+	serverProfile := flags.String("server-profile", "", "expect the mjai dialect of the built-in profile `NAME` ("+strings.Join(mjairuntime.ServerProfileNames(), ", ")+") or of the profile in a .json file")
+	meta := flags.Bool("meta", false, "attach the evaluation of each decision to its action as meta")
+	resilient := flags.Bool("resilient", false, "play through errors with a safe fallback instead of exiting")
+	logging := addLogFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintln(errOut, "usage: mjai-manue lobby [flags] URL...")
+		return exitUsageError
+	}
+	if _, err := seat.NewSeat(*id); err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitUsageError
+	}
+	if *defenseTurns < 0 {
+		fmt.Fprintln(errOut, "defense turns must not be negative")
+		return exitUsageError
+	}
+	profile, err := loadServerProfile(*serverProfile)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		if _, ok := errors.AsType[*mjairuntime.UsageError](err); ok {
+			return exitUsageError
+		}
+		return exitRuntimeError
+	}
+	possibleActionsMode, err := possibleActionsModeOf(flags, *possibleActions, profile)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitUsageError
+	}
+	logConfig, err := logging.config()
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		if _, ok := errors.AsType[*mjairuntime.UsageError](err); ok {
+			return exitUsageError
+		}
+		return exitRuntimeError
+	}
+
+	deps, err := loadManueAgentDeps(agentConfigFiles{profiles: *profiles, tenpaiModel: *tenpaiModel})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	deps.DefenseTurns = *defenseTurns
+	if *logDir != "" {
+		if err := os.MkdirAll(*logDir, 0o755); err != nil {
+			fmt.Fprintln(errOut, err)
+			return exitRuntimeError
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		// Restore the default behavior so that a second signal terminates.
+		signal.Stop(signals)
+		fmt.Fprintln(errOut, "shutting down after the games in progress")
+		cancel()
+	}()
+
+	err = mjairuntime.RunLobby(ctx, mjairuntime.LobbyConfig{
+		Name:       *name,
+		URLs:       flags.Args(),
+		FallbackID: *id,
+		Seed:       *seed,
+		Games:      *games,
+		NewAgent: func(seed uint64) (ai.Agent, error) {
+			return ai.NewManueAgent(seed, deps)
+		},
+		LogDir:          *logDir,
+		Log:             errOut,
+		Logging:         logConfig,
+		PossibleActions: possibleActionsMode,
+		Meta:            *meta,
+		Resilient:       *resilient,
+		ServerProfile:   profile,
+	})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		if _, ok := errors.AsType[*mjairuntime.UsageError](err); ok {
+			return exitUsageError
+		}
+		return exitRuntimeError
+	}
+	return exitOK
+}
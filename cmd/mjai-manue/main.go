@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/Apricot-S/mjai-manue-go/configs"
 	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
@@ -16,6 +17,7 @@ import (
 const (
 	defaultName = "Manue030"
 	defaultSeed = uint64(0)
+	agentName   = "manue"
 
 	exitOK           = 0
 	exitRuntimeError = 1
@@ -27,11 +29,33 @@ func main() {
 }
 
 func run(args []string, in io.Reader, out io.Writer, errOut io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "replay":
+			return runReplay(args[1:], out, errOut)
+		case "lobby":
+			return runLobby(args[1:], errOut)
+		case "serve":
+			return runServe(args[1:], errOut)
+		case "debug":
+			return runDebug(args[1:], in, out, errOut)
+		}
+	}
+
 	flags := flag.NewFlagSet("mjai-manue", flag.ContinueOnError)
 	flags.SetOutput(errOut)
 	name := flags.String("name", defaultName, "player name")
 	id := flags.Int("id", 0, "fallback player id used when start_game omits id")
 	seed := flags.Uint64("seed", defaultSeed, "random seed")
+	record := flags.String("record", "", "record the session to `FILE` for replay")
+	profiles := flags.String("profiles", "", "adjust to opponents with the profiles in `FILE`")
+	tenpaiModel := flags.String("tenpai-model", "", "estimate tenpai with the model in `FILE`")
+	defenseTurns := flags.Int("defense-turns", 0, "plan the deal-in of each discard over `N` turns; 0 considers the discard alone")
+	possibleActions := flags.String("possible-actions", "off", "cross-check the possible_actions of the server: `MODE` is off, warn, restrict, or strict; the server profile may change the default")
+	serverProfile := flags.String("server-profile", "", "expect the mjai dialect of the built-in profile `NAME` ("+strings.Join(mjairuntime.ServerProfileNames(), ", ")+") or of the profile in a .json file")
+	meta := flags.Bool("meta", false, "attach the evaluation of each decision to its action as meta")
+	resilient := flags.Bool("resilient", false, "play through errors with a safe fallback instead of exiting")
+	logging := addLogFlags(flags)
 	if err := flags.Parse(args); err != nil {
 		return exitUsageError
 	}
@@ -43,43 +67,89 @@ func run(args []string, in io.Reader, out io.Writer, errOut io.Writer) int {
 		fmt.Fprintln(errOut, err)
 		return exitUsageError
 	}
-
-	stats, err := configs.LoadGameStats()
+	if *defenseTurns < 0 {
+		fmt.Fprintln(errOut, "defense turns must not be negative")
+		return exitUsageError
+	}
+	profile, err := loadServerProfile(*serverProfile)
 	if err != nil {
-		fmt.Fprintf(errOut, "failed to load game stats: %v\n", err)
+		fmt.Fprintln(errOut, err)
+		if _, ok := errors.AsType[*mjairuntime.UsageError](err); ok {
+			return exitUsageError
+		}
 		return exitRuntimeError
 	}
-	dangerTree, err := configs.LoadDangerTree()
+	possibleActionsMode, err := possibleActionsModeOf(flags, *possibleActions, profile)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitUsageError
+	}
+	logConfig, err := logging.config()
 	if err != nil {
-		fmt.Fprintf(errOut, "failed to load danger tree: %v\n", err)
+		fmt.Fprintln(errOut, err)
+		if _, ok := errors.AsType[*mjairuntime.UsageError](err); ok {
+			return exitUsageError
+		}
 		return exitRuntimeError
 	}
-	agent, err := ai.NewManueAgent(*seed, ai.ManueAgentDeps{
-		Stats:  stats,
-		Danger: ai.NewDangerEstimator(dangerTree),
-	})
+
+	deps, err := loadManueAgentDeps(agentConfigFiles{profiles: *profiles, tenpaiModel: *tenpaiModel})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	deps.DefenseTurns = *defenseTurns
+	agent, err := ai.NewManueAgent(*seed, deps)
 	if err != nil {
 		fmt.Fprintln(errOut, err)
 		return exitRuntimeError
 	}
 
+	var recorder *mjairuntime.Recorder
+	if *record != "" {
+		f, err := os.Create(*record)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return exitRuntimeError
+		}
+		defer f.Close()
+		recorder = mjairuntime.NewRecorder(f, mjairuntime.RecordingHeader{
+			Seed:         *seed,
+			Agent:        agentName,
+			Configs:      configFingerprints(deps),
+			DefenseTurns: *defenseTurns,
+		})
+	}
+
 	if flags.NArg() == 1 {
 		err = mjairuntime.RunTCP(mjairuntime.TCPConfig{
-			Name:       *name,
-			URL:        flags.Arg(0),
-			FallbackID: *id,
-			Agent:      agent,
-			Log:        errOut,
+			Name:            *name,
+			URL:             flags.Arg(0),
+			FallbackID:      *id,
+			Agent:           agent,
+			Log:             errOut,
+			Logging:         logConfig,
+			Recorder:        recorder,
+			PossibleActions: possibleActionsMode,
+			Meta:            *meta,
+			Resilient:       *resilient,
+			ServerProfile:   profile,
 		})
 	} else {
 		err = mjairuntime.RunStdio(mjairuntime.StdioConfig{
-			Name:       *name,
-			Room:       "default",
-			FallbackID: *id,
-			Agent:      agent,
-			In:         in,
-			Out:        out,
-			Log:        errOut,
+			Name:            *name,
+			Room:            "default",
+			FallbackID:      *id,
+			Agent:           agent,
+			In:              in,
+			Out:             out,
+			Log:             errOut,
+			Logging:         logConfig,
+			Recorder:        recorder,
+			PossibleActions: possibleActionsMode,
+			Meta:            *meta,
+			Resilient:       *resilient,
+			ServerProfile:   profile,
 		})
 	}
 	if err != nil {
@@ -91,3 +161,124 @@ func run(args []string, in io.Reader, out io.Writer, errOut io.Writer) int {
 	}
 	return exitOK
 }
+
+// loadServerProfile returns the built-in server profile with the name, or the
+// profile in the file when the name ends in .json. An empty name selects no
+// profile.
+func loadServerProfile(name string) (*mjairuntime.ServerProfile, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if !strings.HasSuffix(name, ".json") {
+		return mjairuntime.LookupServerProfile(name)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server profile: %w", err)
+	}
+	defer f.Close()
+	profile, err := mjairuntime.ReadServerProfile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return profile, nil
+}
+
+// possibleActionsModeOf returns the mode of the --possible-actions flag, or
+// the mode the server profile suggests when the flag is not given.
+func possibleActionsModeOf(flags *flag.FlagSet, value string, profile *mjairuntime.ServerProfile) (mjairuntime.PossibleActionsMode, error) {
+	given := false
+	flags.Visit(func(f *flag.Flag) {
+		given = given || f.Name == "possible-actions"
+	})
+	if !given && profile != nil {
+		return profile.PossibleActionsMode()
+	}
+	return mjairuntime.ParsePossibleActionsMode(value)
+}
+
+// logFlags are the flags that select how the log is written.
+type logFlags struct {
+	format  *string
+	levels  *string
+	gameDir *string
+}
+
+func addLogFlags(flags *flag.FlagSet) logFlags {
+	return logFlags{
+		format:  flags.String("log-format", "plain", "write the log as `FORMAT`: plain, text, or json"),
+		levels:  flags.String("log-level", "", "set the lowest level of log channels as comma-separated `[CHANNEL=]LEVEL`; CHANNEL is protocol, board, decision, or error, and LEVEL is debug, info, warn, error, or off"),
+		gameDir: flags.String("log-game-dir", "", "write the log of each game to a file in `DIR` named by the room and start time"),
+	}
+}
+
+// config returns the log configuration of the flags, creating the directory
+// of the per-game files.
+func (f logFlags) config() (mjairuntime.LogConfig, error) {
+	format, err := mjairuntime.ParseLogFormat(*f.format)
+	if err != nil {
+		return mjairuntime.LogConfig{}, err
+	}
+	levels, err := mjairuntime.ParseLogLevels(*f.levels)
+	if err != nil {
+		return mjairuntime.LogConfig{}, err
+	}
+	if *f.gameDir != "" {
+		if err := os.MkdirAll(*f.gameDir, 0o755); err != nil {
+			return mjairuntime.LogConfig{}, err
+		}
+	}
+	return mjairuntime.LogConfig{Format: format, Levels: levels, GameDir: *f.gameDir}, nil
+}
+
+// agentConfigFiles are the paths of the optional configuration files. An
+// empty path leaves the configuration out.
+type agentConfigFiles struct {
+	profiles    string
+	tenpaiModel string
+}
+
+// loadManueAgentDeps loads the embedded configuration and the optional files.
+// The dependencies are read-only and can be shared between agents.
+func loadManueAgentDeps(files agentConfigFiles) (ai.ManueAgentDeps, error) {
+	stats, err := configs.LoadGameStats()
+	if err != nil {
+		return ai.ManueAgentDeps{}, fmt.Errorf("failed to load game stats: %w", err)
+	}
+	dangerTree, err := configs.LoadDangerTree()
+	if err != nil {
+		return ai.ManueAgentDeps{}, fmt.Errorf("failed to load danger tree: %w", err)
+	}
+	deps := ai.ManueAgentDeps{
+		Stats:  stats,
+		Danger: ai.NewDangerEstimator(dangerTree),
+	}
+	if files.profiles != "" {
+		profiles, err := configs.LoadOpponentProfiles(files.profiles)
+		if err != nil {
+			return ai.ManueAgentDeps{}, fmt.Errorf("failed to load opponent profiles: %w", err)
+		}
+		deps.Profiles = profiles
+	}
+	if files.tenpaiModel != "" {
+		model, err := configs.LoadTenpaiModel(files.tenpaiModel)
+		if err != nil {
+			return ai.ManueAgentDeps{}, fmt.Errorf("failed to load tenpai model: %w", err)
+		}
+		deps.Tenpai = model
+	}
+	return deps, nil
+}
+
+// configFingerprints returns the fingerprints of the configuration in deps,
+// which a session recording stores.
+func configFingerprints(deps ai.ManueAgentDeps) map[string]string {
+	fingerprints := configs.Fingerprints()
+	if profiles, ok := deps.Profiles.(*configs.OpponentProfiles); ok {
+		fingerprints[configs.OpponentProfilesName] = profiles.Fingerprint()
+	}
+	if model, ok := deps.Tenpai.(*configs.TenpaiModel); ok {
+		fingerprints[configs.TenpaiModelName] = model.Fingerprint()
+	}
+	return fingerprints
+}
@@ -67,6 +67,19 @@ func TestRun_InvalidIDFlagReturnsUsageError(t *testing.T) {
 	}
 }
 
+func TestRun_NegativeDefenseTurnsReturnsUsageError(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"--defense-turns", "-1"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitUsageError, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "defense turns must not be negative") {
+		t.Errorf("stderr = %q, want defense turns error", errOut.String())
+	}
+}
+
 func TestRun_TooManyArguments(t *testing.T) {
 	var out strings.Builder
 	var errOut strings.Builder
@@ -79,3 +92,93 @@ func TestRun_TooManyArguments(t *testing.T) {
 		t.Errorf("stdout = %q, want empty", out.String())
 	}
 }
+
+func TestRun_LobbyRequiresURL(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"lobby", "--games", "1"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Fatalf("run(lobby) = %d, want %d; stderr = %q", got, exitUsageError, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "usage: mjai-manue lobby") {
+		t.Errorf("stderr = %q, want usage", errOut.String())
+	}
+}
+
+func TestRun_LobbyInvalidURLReturnsUsageError(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"lobby", "mjsonp://localhost:11600/room", "tcp://localhost:11600/room"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Fatalf("run(lobby) = %d, want %d; stderr = %q", got, exitUsageError, errOut.String())
+	}
+}
+
+func TestRun_ServeRejectsInvalidConcurrency(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"serve", "--concurrency", "0"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Fatalf("run(serve) = %d, want %d; stderr = %q", got, exitUsageError, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "concurrency must be positive") {
+		t.Errorf("stderr = %q, want concurrency error", errOut.String())
+	}
+}
+
+func TestRun_ServerProfileRequiresStartGameID(t *testing.T) {
+	in := strings.NewReader(`{"type":"start_game"}` + "\n")
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"--server-profile", "mjai"}, in, &out, &errOut)
+	if got != exitRuntimeError {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitRuntimeError, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "server profile mjai requires start_game.id") {
+		t.Errorf("stderr = %q, want the missing id", errOut.String())
+	}
+}
+
+func TestRun_UnknownServerProfileReturnsUsageError(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"--server-profile", "tenhou"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Errorf("run() = %d, want %d; stderr = %q", got, exitUsageError, errOut.String())
+	}
+}
+
+func TestRun_LogFormatJSON(t *testing.T) {
+	in := strings.NewReader(`{"type":"hello","protocol":"mjsonp","protocol_version":3}` + "\n")
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"--log-format", "json", "--log-level", "protocol=info,error=warn"}, in, &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	lines := strings.Split(strings.TrimSuffix(errOut.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("stderr = %q, want the hello and join records", errOut.String())
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, `{"time":`) || !strings.Contains(line, `"level":"INFO"`) || !strings.Contains(line, `"channel":"protocol"`) {
+			t.Errorf("record = %q, want a JSON record of the protocol channel", line)
+		}
+	}
+}
+
+func TestRun_UnknownLogChannelReturnsUsageError(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"--log-level", "network=info"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Errorf("run() = %d, want %d; stderr = %q", got, exitUsageError, errOut.String())
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/configs"
+	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+// runReplay feeds a session recorded with --record back through the runtime
+// and reports every step whose output differs from the recording.
+func runReplay(args []string, out io.Writer, errOut io.Writer) int {
+	flags := flag.NewFlagSet("mjai-manue replay", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	verbose := flags.Bool("verbose", false, "write the replay trace to stderr")
+	profiles := flags.String("profiles", "", "replay with the opponent profiles in `FILE`")
+	tenpaiModel := flags.String("tenpai-model", "", "replay with the tenpai model in `FILE`")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(errOut, "usage: mjai-manue replay [--verbose] [--profiles FILE] [--tenpai-model FILE] FILE")
+		return exitUsageError
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	defer f.Close()
+	rec, err := mjairuntime.ReadRecording(f)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	if rec.Header.Agent != agentName {
+		fmt.Fprintf(errOut, "cannot replay a recording of agent %q\n", rec.Header.Agent)
+		return exitRuntimeError
+	}
+
+	deps, err := loadManueAgentDeps(agentConfigFiles{profiles: *profiles, tenpaiModel: *tenpaiModel})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	deps.DefenseTurns = rec.Header.DefenseTurns
+	current := configFingerprints(deps)
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		if recorded, ok := rec.Header.Configs[name]; ok && recorded != current[name] {
+			fmt.Fprintf(errOut, "warning: %s differs from the recording (recorded %s, current %s)\n",
+				name, recorded, current[name])
+		}
+	}
+	if _, ok := rec.Header.Configs[configs.OpponentProfilesName]; ok && *profiles == "" {
+		fmt.Fprintln(errOut, "warning: the recording used opponent profiles; pass --profiles to replay with them")
+	}
+	if _, ok := rec.Header.Configs[configs.TenpaiModelName]; ok && *tenpaiModel == "" {
+		fmt.Fprintln(errOut, "warning: the recording used a tenpai model; pass --tenpai-model to replay with it")
+	}
+
+	agent, err := ai.NewManueAgent(rec.Header.Seed, deps)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	var log io.Writer
+	if *verbose {
+		log = errOut
+	}
+	divergences, err := rec.Replay(agent, log)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+
+	for _, d := range divergences {
+		fmt.Fprint(out, d.String())
+	}
+	if len(divergences) > 0 {
+		fmt.Fprintf(errOut, "%d of %d steps diverged\n", len(divergences), rec.NumInbound())
+		return exitRuntimeError
+	}
+	fmt.Fprintf(errOut, "replayed %d inbound lines without divergence\n", rec.NumInbound())
+	return exitOK
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const replayTestInput = `{"type":"start_game","id":0}
+{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[["1m","1m","1m","2p","3p","4p","3s","4s","5s","6s","6s","7s","N"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]],"scores":[25000,25000,25000,25000]}
+{"type":"tsumo","actor":0,"pai":"9s"}
+`
+
+func recordForTest(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var out strings.Builder
+	var errOut strings.Builder
+	got := run([]string{"--record", path, "--seed", "7"}, strings.NewReader(replayTestInput), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	return path
+}
+
+func TestRun_RecordWritesSession(t *testing.T) {
+	path := recordForTest(t)
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	for _, want := range []string{`"type":"session"`, `"seed":7`, `"agent":"manue"`, `"game_stats.json":"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("header = %s, want containing %s", lines[0], want)
+		}
+	}
+	if got := strings.Count(string(b), `"type":"in"`); got != 3 {
+		t.Errorf("inbound entries = %d, want 3", got)
+	}
+	if !strings.Contains(string(b), `"type":"out"`) {
+		t.Errorf("recording = %s, want an outbound entry", b)
+	}
+}
+
+func TestRun_ReplayWithoutDivergence(t *testing.T) {
+	path := recordForTest(t)
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"replay", path}, strings.NewReader(""), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run(replay) = %d, want %d; stdout = %q, stderr = %q", got, exitOK, out.String(), errOut.String())
+	}
+	if out.String() != "" {
+		t.Errorf("stdout = %q, want empty", out.String())
+	}
+	if !strings.Contains(errOut.String(), "replayed 3 inbound lines without divergence") {
+		t.Errorf("stderr = %q, want a summary", errOut.String())
+	}
+}
+
+func TestRun_ReplayReportsDivergence(t *testing.T) {
+	path := recordForTest(t)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	tampered := strings.Replace(string(b), `"seed":7`, `"seed":8`, 1)
+	tampered = strings.Replace(tampered, `{"type":"out","line":"`, `{"type":"out","line":"{\"type\":\"none\"}","x":"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"replay", path}, strings.NewReader(""), &out, &errOut)
+	if got != exitRuntimeError {
+		t.Fatalf("run(replay) = %d, want %d; stderr = %q", got, exitRuntimeError, errOut.String())
+	}
+	if !strings.Contains(out.String(), "step 3: <- ") || !strings.Contains(out.String(), `-	-> {"type":"none"}`) {
+		t.Errorf("stdout = %q, want the diverged step", out.String())
+	}
+}
+
+func TestRun_ReplayRequiresFile(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"replay"}, strings.NewReader(""), &out, &errOut)
+	if got != exitUsageError {
+		t.Errorf("run(replay) = %d, want %d", got, exitUsageError)
+	}
+}
+
+func TestRun_ReplayWarnsAboutMissingProfiles(t *testing.T) {
+	dir := t.TempDir()
+	profiles := filepath.Join(dir, "profiles.json")
+	if err := os.WriteFile(profiles, []byte(`{"p1":{"numGames":1,"closedTenpaiScale":1.5}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	path := filepath.Join(dir, "session.jsonl")
+	var out strings.Builder
+	var errOut strings.Builder
+	got := run([]string{"--record", path, "--profiles", profiles}, strings.NewReader(replayTestInput), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+
+	errOut.Reset()
+	if got := run([]string{"replay", path}, strings.NewReader(""), &out, &errOut); got != exitOK {
+		t.Fatalf("run(replay) = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "pass --profiles") {
+		t.Errorf("stderr = %q, want a warning about the profiles", errOut.String())
+	}
+
+	errOut.Reset()
+	if got := run([]string{"replay", "--profiles", profiles, path}, strings.NewReader(""), &out, &errOut); got != exitOK {
+		t.Fatalf("run(replay --profiles) = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	if strings.Contains(errOut.String(), "warning") {
+		t.Errorf("stderr = %q, want no warning", errOut.String())
+	}
+}
+
+func TestRun_MissingProfilesFile(t *testing.T) {
+	var out strings.Builder
+	var errOut strings.Builder
+
+	got := run([]string{"--profiles", filepath.Join(t.TempDir(), "missing.json")}, strings.NewReader(""), &out, &errOut)
+	if got != exitRuntimeError {
+		t.Errorf("run() = %d, want %d", got, exitRuntimeError)
+	}
+}
+
+func TestRun_ReplayWithTenpaiModel(t *testing.T) {
+	dir := t.TempDir()
+	model := filepath.Join(dir, "tenpai_model.json")
+	if err := os.WriteFile(model, []byte(`{"numSamples":1,"bias":-1,"weights":{"late_honor_discards":0.5}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() failed: %v", err)
+	}
+	path := filepath.Join(dir, "session.jsonl")
+	var out strings.Builder
+	var errOut strings.Builder
+	got := run([]string{"--record", path, "--tenpai-model", model}, strings.NewReader(replayTestInput), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+
+	// Without the model the decisions may diverge, so only the warning is
+	// checked.
+	errOut.Reset()
+	run([]string{"replay", path}, strings.NewReader(""), &out, &errOut)
+	if !strings.Contains(errOut.String(), "pass --tenpai-model") {
+		t.Errorf("stderr = %q, want a warning about the tenpai model", errOut.String())
+	}
+
+	errOut.Reset()
+	if got := run([]string{"replay", "--tenpai-model", model, path}, strings.NewReader(""), &out, &errOut); got != exitOK {
+		t.Fatalf("run(replay --tenpai-model) = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	if strings.Contains(errOut.String(), "warning") {
+		t.Errorf("stderr = %q, want no warning", errOut.String())
+	}
+}
+
+func TestRun_ReplayKeepsDefenseTurns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var out strings.Builder
+	var errOut strings.Builder
+	got := run([]string{"--record", path, "--defense-turns", "4"}, strings.NewReader(replayTestInput), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	if !strings.Contains(string(b), `"defense_turns":4`) {
+		t.Errorf("recording = %s, want defense turns in the header", b)
+	}
+
+	out.Reset()
+	errOut.Reset()
+	if got := run([]string{"replay", path}, strings.NewReader(""), &out, &errOut); got != exitOK {
+		t.Fatalf("run(replay) = %d, want %d; stdout = %q, stderr = %q", got, exitOK, out.String(), errOut.String())
+	}
+}
+
+func TestRun_RecordKeepsServerProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	var out strings.Builder
+	var errOut strings.Builder
+	got := run([]string{"--record", path, "--server-profile", "mjai"}, strings.NewReader(replayTestInput), &out, &errOut)
+	if got != exitOK {
+		t.Fatalf("run() = %d, want %d; stderr = %q", got, exitOK, errOut.String())
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	header, _, _ := strings.Cut(string(b), "\n")
+	// The profile chooses the possible_actions mode when the flag is not given.
+	for _, want := range []string{`"possible_actions":"warn"`, `"server_profile":{"name":"mjai","ack":"all"`} {
+		if !strings.Contains(header, want) {
+			t.Errorf("header = %s, want containing %s", header, want)
+		}
+	}
+	if strings.Count(out.String(), `{"type":"none"}`) != 2 {
+		t.Errorf("stdout = %q, want start_game and start_kyoku acked", out.String())
+	}
+}
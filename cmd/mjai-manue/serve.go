@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/httpapi"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+const (
+	defaultListen      = "127.0.0.1:8080"
+	defaultMaxBatch    = 1024
+	defaultMaxSessions = 1024
+	defaultSessionTTL  = 30 * time.Minute
+)
+
+// runServe serves decisions over HTTP until interrupted.
+func runServe(args []string, errOut io.Writer) int {
+	flags := flag.NewFlagSet("mjai-manue serve", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	listen := flags.String("listen", defaultListen, "listen on TCP `ADDR`, or on a unix socket with unix:PATH")
+	seed := flags.Uint64("seed", defaultSeed, "random seed")
+	concurrency := flags.Int("concurrency", runtime.GOMAXPROCS(0), "maximum number of decisions evaluated at once")
+	maxBatch := flags.Int("max-batch", defaultMaxBatch, "maximum number of requests in a batch; 0 means no limit")
+	maxSessions := flags.Int("max-sessions", defaultMaxSessions, "maximum number of live sessions; 0 means no limit")
+	sessionTTL := flags.Duration("session-ttl", defaultSessionTTL, "delete sessions idle for `DURATION`; 0 keeps them until deleted")
+	profiles := flags.String("profiles", "", "adjust to opponents with the profiles in `FILE`")
+	tenpaiModel := flags.String("tenpai-model", "", "estimate tenpai with the model in `FILE`")
+	defenseTurns := flags.Int("defense-turns", 0, "plan the deal-in of each discard over `N` turns; 0 considers the discard alone")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintln(errOut, "too many arguments")
+		return exitUsageError
+	}
+	if *defenseTurns < 0 {
+		fmt.Fprintln(errOut, "defense turns must not be negative")
+		return exitUsageError
+	}
+
+	deps, err := loadManueAgentDeps(agentConfigFiles{profiles: *profiles, tenpaiModel: *tenpaiModel})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	deps.DefenseTurns = *defenseTurns
+	handler, err := httpapi.NewServer(httpapi.Config{
+		NewAgent: func(seed uint64) (ai.Agent, error) {
+			return ai.NewManueAgent(seed, deps)
+		},
+		Seed:        *seed,
+		Concurrency: *concurrency,
+		MaxBatch:    *maxBatch,
+		MaxSessions: *maxSessions,
+		SessionTTL:  *sessionTTL,
+	})
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitUsageError
+	}
+
+	network, address := "tcp", *listen
+	if path, ok := strings.CutPrefix(*listen, "unix:"); ok {
+		network, address = "unix", path
+	}
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	fmt.Fprintf(errOut, "listening on %s\n", ln.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Handler: handler}
+	go func() {
+		<-ctx.Done()
+		_ = server.Shutdown(context.Background())
+	}()
+	if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(errOut, err)
+		return exitRuntimeError
+	}
+	return exitOK
+}
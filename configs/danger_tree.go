@@ -3,8 +3,10 @@ package configs
 import (
 	_ "embed"
 	"encoding/json/v2"
+	"fmt"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai/dangerfeature"
 )
 
 // DecisionNode represents a node of a decision tree for danger estimation.
@@ -23,14 +25,39 @@ type DecisionNode struct {
 //go:embed danger_tree.all.json
 var rawDangerTree []byte
 
+// LoadDangerTree loads the embedded danger tree. It fails when the tree uses
+// a feature that the bot cannot evaluate, rather than failing mid-game.
 func LoadDangerTree() (*DecisionNode, error) {
+	return parseDangerTree(rawDangerTree)
+}
+
+func parseDangerTree(raw []byte) (*DecisionNode, error) {
 	var root DecisionNode
-	if err := json.Unmarshal(rawDangerTree, &root); err != nil {
+	if err := json.Unmarshal(raw, &root); err != nil {
 		return nil, err
 	}
+	if err := validateDangerTree(&root); err != nil {
+		return nil, fmt.Errorf("invalid danger tree: %w", err)
+	}
 	return &root, nil
 }
 
+func validateDangerTree(n *DecisionNode) error {
+	if n.FeatureName == nil {
+		return nil
+	}
+	if _, err := dangerfeature.Parse(*n.FeatureName); err != nil {
+		return err
+	}
+	if n.Negative == nil || n.Positive == nil {
+		return fmt.Errorf("node of feature %q lacks a child", *n.FeatureName)
+	}
+	if err := validateDangerTree(n.Negative); err != nil {
+		return err
+	}
+	return validateDangerTree(n.Positive)
+}
+
 func (n *DecisionNode) LeafProb() (float64, bool) {
 	if n == nil || n.FeatureName != nil {
 		return 0, false
@@ -2,6 +2,7 @@ package configs
 
 import (
 	"math"
+	"strings"
 	"testing"
 )
 
@@ -61,3 +62,40 @@ func TestLoadDangerTree(t *testing.T) {
 		t.Errorf("LoadDangerTree().Positive.Positive = %v, want %v", got.Positive, nil)
 	}
 }
+
+func TestParseDangerTree_RejectsInvalidTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{
+			name:    "unsupported feature",
+			raw:     `{"feature_name":"tsupai","negative":{"average_prob":0.1},"positive":{"feature_name":"no_such_feature","negative":{},"positive":{}}}`,
+			wantErr: `unsupported danger feature "no_such_feature"`,
+		},
+		{
+			name:    "missing child",
+			raw:     `{"feature_name":"tsupai","negative":{"average_prob":0.1}}`,
+			wantErr: `node of feature "tsupai" lacks a child`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseDangerTree([]byte(tt.raw))
+			if err == nil {
+				t.Fatal("parseDangerTree() succeeded unexpectedly")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseDangerTree() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseDangerTree_AcceptsTrainingOnlyFeature(t *testing.T) {
+	raw := `{"feature_name":"urasuji_of_5","negative":{"average_prob":0.1},"positive":{"average_prob":0.05}}`
+	if _, err := parseDangerTree([]byte(raw)); err != nil {
+		t.Errorf("parseDangerTree() failed: %v", err)
+	}
+}
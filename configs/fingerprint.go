@@ -0,0 +1,22 @@
+package configs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Fingerprints returns SHA-256 digests of the embedded configuration files
+// keyed by file name. Session recordings store them so that a replay can tell
+// whether it runs with the same configuration.
+func Fingerprints() map[string]string {
+	return map[string]string{
+		"danger_tree.all.json":  fingerprint(rawDangerTree),
+		"game_stats.json":       fingerprint(rawGameStats),
+		"light_game_stats.json": fingerprint(rawLightGameStats),
+	}
+}
+
+func fingerprint(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package configs
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"os"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+// OpponentProfilesName is the key of the opponent profiles in the
+// fingerprints of a session recording.
+const OpponentProfilesName = "opponent_profiles"
+
+// OpponentProfile is an entry of an opponent profile file, as written by
+// tools/dump_opponent_profiles. An omitted scale means the average player.
+type OpponentProfile struct {
+	// NumGames is the number of games the profile was built from.
+	NumGames          int     `json:"numGames"`
+	ClosedTenpaiScale float64 `json:"closedTenpaiScale,omitzero"`
+	OpenTenpaiScale   float64 `json:"openTenpaiScale,omitzero"`
+	WinPointsScale    float64 `json:"winPointsScale,omitzero"`
+}
+
+// OpponentProfiles are the profiles of an opponent profile file keyed by
+// player name. Unlike the other configuration, the file is not embedded and
+// is loaded only when given.
+type OpponentProfiles struct {
+	profiles    map[string]OpponentProfile
+	fingerprint string
+}
+
+func LoadOpponentProfiles(path string) (*OpponentProfiles, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseOpponentProfiles(raw)
+}
+
+func parseOpponentProfiles(raw []byte) (*OpponentProfiles, error) {
+	var profiles map[string]OpponentProfile
+	if err := json.Unmarshal(raw, &profiles); err != nil {
+		return nil, err
+	}
+	for name, p := range profiles {
+		for _, scale := range []float64{p.ClosedTenpaiScale, p.OpenTenpaiScale, p.WinPointsScale} {
+			if scale < 0 {
+				return nil, fmt.Errorf("invalid opponent profile of %q: scale must not be negative", name)
+			}
+		}
+	}
+	return &OpponentProfiles{profiles: profiles, fingerprint: fingerprint(raw)}, nil
+}
+
+func (p *OpponentProfiles) OpponentProfile(name string) (ai.OpponentProfile, bool) {
+	profile, ok := p.profiles[name]
+	if !ok {
+		return ai.OpponentProfile{}, false
+	}
+	return ai.OpponentProfile{
+		ClosedTenpaiScale: profile.ClosedTenpaiScale,
+		OpenTenpaiScale:   profile.OpenTenpaiScale,
+		WinPointsScale:    profile.WinPointsScale,
+	}, true
+}
+
+// Fingerprint returns the SHA-256 digest of the file.
+func (p *OpponentProfiles) Fingerprint() string {
+	return p.fingerprint
+}
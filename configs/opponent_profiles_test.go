@@ -0,0 +1,46 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+func TestLoadOpponentProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	raw := `{"Mortal":{"numGames":120,"closedTenpaiScale":0.8,"openTenpaiScale":1.25,"winPointsScale":1.1},"akochan":{"numGames":3}}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadOpponentProfiles(path)
+	if err != nil {
+		t.Fatalf("LoadOpponentProfiles() error = %v", err)
+	}
+	if p, ok := got.OpponentProfile("Mortal"); !ok || p != (ai.OpponentProfile{ClosedTenpaiScale: 0.8, OpenTenpaiScale: 1.25, WinPointsScale: 1.1}) {
+		t.Errorf("OpponentProfile(Mortal) = %+v, %v", p, ok)
+	}
+	if p, ok := got.OpponentProfile("akochan"); !ok || p != (ai.OpponentProfile{}) {
+		t.Errorf("OpponentProfile(akochan) = %+v, %v, want the average player", p, ok)
+	}
+	if _, ok := got.OpponentProfile("unknown"); ok {
+		t.Error("OpponentProfile(unknown) found a profile")
+	}
+	if got.Fingerprint() != fingerprint([]byte(raw)) {
+		t.Errorf("Fingerprint() = %s, want the digest of the file", got.Fingerprint())
+	}
+}
+
+func TestParseOpponentProfilesRejectsNegativeScale(t *testing.T) {
+	if _, err := parseOpponentProfiles([]byte(`{"Mortal":{"winPointsScale":-1}}`)); err == nil {
+		t.Fatal("parseOpponentProfiles() succeeded unexpectedly")
+	}
+}
+
+func TestLoadOpponentProfilesMissingFile(t *testing.T) {
+	if _, err := LoadOpponentProfiles(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("LoadOpponentProfiles() succeeded unexpectedly")
+	}
+}
@@ -0,0 +1,57 @@
+package configs
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"os"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+// TenpaiModelName is the key of the tenpai model in the fingerprints of a
+// session recording.
+const TenpaiModelName = "tenpai_model"
+
+// TenpaiModelFile is a tenpai model file as written by tools/estimate_tenpai:
+// the weights of a logistic regression on the features of
+// internal/domain/ai/tenpaifeature.
+type TenpaiModelFile struct {
+	// NumSamples is the number of discards the model was trained on.
+	NumSamples int                `json:"numSamples"`
+	Bias       float64            `json:"bias"`
+	Weights    map[string]float64 `json:"weights"`
+}
+
+// TenpaiModel is the estimator of a tenpai model file. Like the opponent
+// profiles, the file is not embedded and is loaded only when given.
+type TenpaiModel struct {
+	*ai.FeatureTenpaiEstimator
+	fingerprint string
+}
+
+// LoadTenpaiModel loads a tenpai model file. It fails when the model uses a
+// feature that the bot cannot evaluate.
+func LoadTenpaiModel(path string) (*TenpaiModel, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTenpaiModel(raw)
+}
+
+func parseTenpaiModel(raw []byte) (*TenpaiModel, error) {
+	var file TenpaiModelFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, err
+	}
+	estimator, err := ai.NewFeatureTenpaiEstimator(file.Bias, file.Weights)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tenpai model: %w", err)
+	}
+	return &TenpaiModel{FeatureTenpaiEstimator: estimator, fingerprint: fingerprint(raw)}, nil
+}
+
+// Fingerprint returns the SHA-256 digest of the file.
+func (m *TenpaiModel) Fingerprint() string {
+	return m.fingerprint
+}
@@ -0,0 +1,37 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTenpaiModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenpai_model.json")
+	raw := `{"numSamples":1000,"bias":-2.5,"weights":{"num_melds":0.4,"recent_tedashi":0.2}}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadTenpaiModel(path)
+	if err != nil {
+		t.Fatalf("LoadTenpaiModel() error = %v", err)
+	}
+	if got.Fingerprint() != fingerprint([]byte(raw)) {
+		t.Errorf("Fingerprint() = %s, want the digest of the file", got.Fingerprint())
+	}
+}
+
+func TestParseTenpaiModelRejectsUnknownFeature(t *testing.T) {
+	_, err := parseTenpaiModel([]byte(`{"bias":0,"weights":{"unknown":1}}`))
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Fatalf("parseTenpaiModel() error = %v, want the unknown feature", err)
+	}
+}
+
+func TestLoadTenpaiModelMissingFile(t *testing.T) {
+	if _, err := LoadTenpaiModel(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("LoadTenpaiModel() succeeded unexpectedly")
+	}
+}
@@ -0,0 +1,96 @@
+package httpapi
+
+import (
+	"encoding/json/jsontext"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+// DecisionResponse is a decision with the mjai message of its action. Action
+// is null when the player has no legal action.
+type DecisionResponse struct {
+	Action     jsontext.Value      `json:"action"`
+	Candidates []CandidateResponse `json:"candidates,omitzero"`
+	Log        string              `json:"log,omitzero"`
+}
+
+// CandidateResponse is a row of the decision log. The field names follow the
+// columns of the log.
+type CandidateResponse struct {
+	Key           string         `json:"key"`
+	Action        jsontext.Value `json:"action"`
+	AvgRank       float64        `json:"avg_rank"`
+	ExpPt         float64        `json:"exp_pt"`
+	HojuProb      float64        `json:"hoju_prob"`
+	MyHoraProb    float64        `json:"my_hora_prob"`
+	RyukyokuProb  float64        `json:"ryukyoku_prob"`
+	OtherHoraProb float64        `json:"other_hora_prob"`
+	AvgHoraPt     float64        `json:"avg_hora_pt"`
+	RyukyokuAvgPt float64        `json:"ryukyoku_avg_pt"`
+	// Shanten is null when the candidate gives up winning.
+	Shanten *int `json:"shanten"`
+}
+
+func newDecisionResponse(decision *ai.Decision) (*DecisionResponse, error) {
+	msg, err := marshalAction(decision.Action)
+	if err != nil {
+		return nil, err
+	}
+	resp := &DecisionResponse{
+		Action: msg,
+		Log:    decision.Log,
+	}
+	for _, c := range decision.Candidates {
+		msg, err := marshalAction(c.Action)
+		if err != nil {
+			return nil, err
+		}
+		var shanten *int
+		if c.Shanten != service.InfinityShanten {
+			shanten = new(c.Shanten)
+		}
+		resp.Candidates = append(resp.Candidates, CandidateResponse{
+			Key:           c.Key,
+			Action:        msg,
+			AvgRank:       c.AverageRank,
+			ExpPt:         c.ExpectedPoints,
+			HojuProb:      c.DealInProb,
+			MyHoraProb:    c.WinProb,
+			RyukyokuProb:  c.ExhaustiveDrawProb,
+			OtherHoraProb: c.OtherWinProb,
+			AvgHoraPt:     c.AverageWinPoints,
+			RyukyokuAvgPt: c.ExhaustiveDrawAveragePoints,
+			Shanten:       shanten,
+		})
+	}
+	return resp, nil
+}
+
+func marshalAction(a action.Action) (jsontext.Value, error) {
+	msg, err := outbound.ToMessage(a, "")
+	if err != nil {
+		return nil, err
+	}
+	return outbound.MarshalMessage(msg)
+}
+
+// decideState decides on a restored state, which has no game to replay.
+func decideState(agent ai.Agent, state *round.State, self seat.Seat) (*DecisionResponse, error) {
+	legalActions, err := state.LegalActions(self)
+	if err != nil {
+		return nil, badRequest("%w", err)
+	}
+	if len(legalActions) == 0 {
+		return &DecisionResponse{}, nil
+	}
+	decision, err := agent.Decide(ai.Request{Self: self, Round: state})
+	if err != nil {
+		return nil, err
+	}
+	return newDecisionResponse(&decision)
+}
@@ -0,0 +1,361 @@
+// Package httpapi serves agent decisions over HTTP with mjai messages and
+// snapshots as JSON, so that external pipelines can query an agent without
+// running a game through stdio.
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/snapshot"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+const maxRequestBytes = 64 << 20
+
+type Config struct {
+	// NewAgent builds the agent of a request or a session. Agents are never
+	// shared between goroutines.
+	NewAgent func(seed uint64) (ai.Agent, error)
+	Seed     uint64
+	// Concurrency is the maximum number of decisions evaluated at once.
+	Concurrency int
+	// MaxBatch is the maximum number of requests in a batch. Zero means no
+	// limit.
+	MaxBatch int
+	// MaxSessions is the maximum number of live sessions. Creating a session
+	// beyond it fails with 429 Too Many Requests. Zero means no limit.
+	MaxSessions int
+	// SessionTTL is how long a session lives without requests. Expired
+	// sessions are deleted when the next session request arrives. Zero means
+	// sessions live until deleted.
+	SessionTTL time.Duration
+}
+
+// Server is an http.Handler with these endpoints:
+//
+//	POST   /v1/decide               decide from an event history or a snapshot
+//	POST   /v1/decide/batch         decide for several requests concurrently
+//	POST   /v1/sessions             create a session for incremental events
+//	POST   /v1/sessions/{id}/events apply events and decide
+//	DELETE /v1/sessions/{id}        delete a session
+type Server struct {
+	cfg   Config
+	mux   *http.ServeMux
+	slots chan struct{}
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func NewServer(cfg Config) (*Server, error) {
+	if cfg.NewAgent == nil {
+		return nil, fmt.Errorf("cannot create server: agent factory is required")
+	}
+	if cfg.Concurrency <= 0 {
+		return nil, fmt.Errorf("cannot create server: concurrency must be positive: %d", cfg.Concurrency)
+	}
+	if cfg.MaxBatch < 0 {
+		return nil, fmt.Errorf("cannot create server: max batch must not be negative: %d", cfg.MaxBatch)
+	}
+	if cfg.MaxSessions < 0 {
+		return nil, fmt.Errorf("cannot create server: max sessions must not be negative: %d", cfg.MaxSessions)
+	}
+	if cfg.SessionTTL < 0 {
+		return nil, fmt.Errorf("cannot create server: session TTL must not be negative: %v", cfg.SessionTTL)
+	}
+	s := &Server{
+		cfg:      cfg,
+		mux:      http.NewServeMux(),
+		slots:    make(chan struct{}, cfg.Concurrency),
+		sessions: make(map[string]*session),
+	}
+	s.mux.HandleFunc("POST /v1/decide", s.handleDecide)
+	s.mux.HandleFunc("POST /v1/decide/batch", s.handleDecideBatch)
+	s.mux.HandleFunc("POST /v1/sessions", s.handleCreateSession)
+	s.mux.HandleFunc("POST /v1/sessions/{id}/events", s.handleSessionEvents)
+	s.mux.HandleFunc("DELETE /v1/sessions/{id}", s.handleDeleteSession)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// DecideRequest asks for a decision of the player at Seat. Exactly one of
+// Events and Snapshot must be given. Events is an mjai event history that
+// starts with start_game or start_kyoku.
+type DecideRequest struct {
+	Seat     int              `json:"seat"`
+	Events   []jsontext.Value `json:"events,omitzero"`
+	Snapshot jsontext.Value   `json:"snapshot,omitzero"`
+}
+
+type batchRequest struct {
+	Requests []DecideRequest `json:"requests"`
+}
+
+// BatchResult is the result of a request of a batch. Error is set instead of
+// the decision when the request failed.
+type BatchResult struct {
+	*DecisionResponse
+	Error string `json:"error,omitzero"`
+}
+
+type batchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+type createSessionRequest struct {
+	Seat int `json:"seat"`
+}
+
+type createSessionResponse struct {
+	ID string `json:"id"`
+}
+
+type sessionEventsRequest struct {
+	Events []jsontext.Value `json:"events"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// requestError is an error caused by the client.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+func badRequest(format string, args ...any) error {
+	return &requestError{status: http.StatusBadRequest, err: fmt.Errorf(format, args...)}
+}
+
+func (s *Server) handleDecide(w http.ResponseWriter, r *http.Request) {
+	var req DecideRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.decide(r.Context(), &req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleDecideBatch(w http.ResponseWriter, r *http.Request) {
+	var req batchRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	if s.cfg.MaxBatch > 0 && len(req.Requests) > s.cfg.MaxBatch {
+		writeError(w, badRequest("too many requests in batch: %d > %d", len(req.Requests), s.cfg.MaxBatch))
+		return
+	}
+
+	results := make([]BatchResult, len(req.Requests))
+	var wg sync.WaitGroup
+	for i := range req.Requests {
+		wg.Go(func() {
+			resp, err := s.decide(r.Context(), &req.Requests[i])
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].DecisionResponse = resp
+		})
+	}
+	wg.Wait()
+	writeJSON(w, http.StatusOK, &batchResponse{Results: results})
+}
+
+func (s *Server) decide(ctx context.Context, req *DecideRequest) (*DecisionResponse, error) {
+	self, err := seat.NewSeat(req.Seat)
+	if err != nil {
+		return nil, badRequest("invalid seat: %w", err)
+	}
+	if (len(req.Events) == 0) == (len(req.Snapshot) == 0) {
+		return nil, badRequest("exactly one of events and snapshot is required")
+	}
+
+	// Building the agent and replaying the events take as long as an
+	// evaluation, so they run within the concurrency limit too.
+	return s.withSlot(ctx, func() (*DecisionResponse, error) {
+		agent, err := s.cfg.NewAgent(s.cfg.Seed)
+		if err != nil {
+			return nil, err
+		}
+		if len(req.Snapshot) > 0 {
+			state, err := snapshot.Unmarshal(req.Snapshot)
+			if err != nil {
+				return nil, badRequest("%w", err)
+			}
+			return decideState(agent, state, self)
+		}
+
+		sess := newSession(self, agent)
+		if err := sess.observe(req.Events); err != nil {
+			return nil, err
+		}
+		return sess.decide()
+	})
+}
+
+// withSlot runs an evaluation within the concurrency limit.
+func (s *Server) withSlot(ctx context.Context, f func() (*DecisionResponse, error)) (*DecisionResponse, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-s.slots }()
+	return f()
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req createSessionRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+	self, err := seat.NewSeat(req.Seat)
+	if err != nil {
+		writeError(w, badRequest("invalid seat: %w", err))
+		return
+	}
+	agent, err := s.cfg.NewAgent(s.cfg.Seed)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	sess := newSession(self, agent)
+	s.mu.Lock()
+	s.evictIdleLocked()
+	if s.cfg.MaxSessions > 0 && len(s.sessions) >= s.cfg.MaxSessions {
+		s.mu.Unlock()
+		writeError(w, &requestError{
+			status: http.StatusTooManyRequests,
+			err:    fmt.Errorf("too many sessions: %d", s.cfg.MaxSessions),
+		})
+		return
+	}
+	id := rand.Text()
+	sess.lastUsed = time.Now()
+	s.sessions[id] = sess
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, &createSessionResponse{ID: id})
+}
+
+func (s *Server) handleSessionEvents(w http.ResponseWriter, r *http.Request) {
+	sess, err := s.session(r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var req sessionEventsRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Events of a session are applied in the order the requests arrive.
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if err := sess.observe(req.Events); err != nil {
+		writeError(w, err)
+		return
+	}
+	resp, err := s.withSlot(r.Context(), sess.decide)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictIdleLocked()
+	if _, ok := s.sessions[id]; !ok {
+		writeError(w, errSessionNotFound(id))
+		return
+	}
+	delete(s.sessions, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) session(id string) (*session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.evictIdleLocked()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return nil, errSessionNotFound(id)
+	}
+	sess.lastUsed = time.Now()
+	return sess, nil
+}
+
+// evictIdleLocked deletes the sessions that have had no requests for longer
+// than the TTL. s.mu must be held.
+func (s *Server) evictIdleLocked() {
+	if s.cfg.SessionTTL == 0 {
+		return
+	}
+	now := time.Now()
+	for id, sess := range s.sessions {
+		if now.Sub(sess.lastUsed) > s.cfg.SessionTTL {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func errSessionNotFound(id string) error {
+	return &requestError{status: http.StatusNotFound, err: fmt.Errorf("session not found: %q", id)}
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	if err := json.UnmarshalRead(http.MaxBytesReader(w, r.Body, maxRequestBytes), v); err != nil {
+		return badRequest("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.MarshalWrite(w, v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if reqErr, ok := errors.AsType[*requestError](err); ok {
+		status = reqErr.status
+	} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, &errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,378 @@
+package httpapi_test
+
+import (
+	"encoding/json/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/httpapi"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+)
+
+const (
+	startKyoku = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","4p"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]],"scores":[25000,25000,25000,25000]}`
+	tsumo      = `{"type":"tsumo","actor":0,"pai":"C"}`
+	dahai      = `{"type":"dahai","actor":0,"pai":"C","tsumogiri":true}`
+)
+
+// riichiBoard is a snapshot of the dealer's first turn.
+const riichiBoard = `{
+	"version": 1, "bakaze": "E", "kyoku": 1, "honba": 0, "kyotaku": 0, "oya": 0, "chicha": 0,
+	"scores": [25000, 25000, 25000, 25000], "dora_markers": ["3s"], "num_left_tiles": 69,
+	"players": [
+		{"tehai": ["1m","2m","3m","4p","5p","6p","7s","8s","9s","E","E","S","W"], "tsumo": "S"},
+		{"tehai": ["?","?","?","?","?","?","?","?","?","?","?","?","?"]},
+		{"tehai": ["?","?","?","?","?","?","?","?","?","?","?","?","?"]},
+		{"tehai": ["?","?","?","?","?","?","?","?","?","?","?","?","?"]}
+	],
+	"next_tsumo": 0, "pending_dahai": 0, "last_actor": 0
+}`
+
+func newServerForTest(t *testing.T, newAgent func(uint64) (ai.Agent, error), concurrency int) *httptest.Server {
+	t.Helper()
+
+	return newServerWithConfig(t, httpapi.Config{NewAgent: newAgent, Concurrency: concurrency})
+}
+
+func newServerWithConfig(t *testing.T, cfg httpapi.Config) *httptest.Server {
+	t.Helper()
+
+	s, err := httpapi.NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer() failed: %v", err)
+	}
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func newTsumogiriAgent(uint64) (ai.Agent, error) {
+	return ai.NewTsumogiriAgent(), nil
+}
+
+func post(t *testing.T, url string, body string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Post(%s) failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	return resp.StatusCode, string(b)
+}
+
+func TestServer_Decide(t *testing.T) {
+	ts := newServerForTest(t, newTsumogiriAgent, 1)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "event history",
+			body: `{"seat":0,"events":[` + startKyoku + `,` + tsumo + `]}`,
+			want: `{"action":{"type":"dahai","actor":0,"pai":"C","tsumogiri":true}}`,
+		},
+		{
+			name: "event history with start_game",
+			body: `{"seat":1,"events":[{"type":"start_game","id":0},` + startKyoku + `,` + tsumo + `]}`,
+			want: `{"action":{"type":"dahai","actor":0,"pai":"C","tsumogiri":true}}`,
+		},
+		{
+			name: "no legal action",
+			body: `{"seat":0,"events":[` + startKyoku + `,` + tsumo + `,` + dahai + `]}`,
+			want: `{"action":null}`,
+		},
+		{
+			name: "snapshot",
+			body: `{"seat":0,"snapshot":` + riichiBoard + `}`,
+			want: `{"action":{"type":"dahai","actor":0,"pai":"S","tsumogiri":true}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, ts.URL+"/v1/decide", tt.body)
+			if status != http.StatusOK {
+				t.Fatalf("status = %d, want %d; body = %s", status, http.StatusOK, body)
+			}
+			if body != tt.want {
+				t.Errorf("body = %s, want %s", body, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_DecideReturnsBadRequest(t *testing.T) {
+	ts := newServerForTest(t, newTsumogiriAgent, 1)
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "malformed JSON", body: `{`, wantErr: "invalid request body"},
+		{name: "invalid seat", body: `{"seat":4,"events":[` + startKyoku + `]}`, wantErr: "invalid seat"},
+		{name: "no input", body: `{"seat":0}`, wantErr: "exactly one of events and snapshot is required"},
+		{
+			name:    "both inputs",
+			body:    `{"seat":0,"events":[` + startKyoku + `],"snapshot":` + riichiBoard + `}`,
+			wantErr: "exactly one of events and snapshot is required",
+		},
+		{name: "event before start_kyoku", body: `{"seat":0,"events":[` + tsumo + `]}`, wantErr: "event 0: "},
+		{
+			name:    "invalid snapshot",
+			body:    `{"seat":0,"snapshot":` + strings.Replace(riichiBoard, `"num_left_tiles": 69`, `"num_left_tiles": 70`, 1) + `}`,
+			wantErr: "number of left tiles",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, ts.URL+"/v1/decide", tt.body)
+			if status != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d; body = %s", status, http.StatusBadRequest, body)
+			}
+			if !strings.Contains(body, tt.wantErr) {
+				t.Errorf("body = %s, want containing %q", body, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestServer_DecideBatch(t *testing.T) {
+	ts := newServerForTest(t, newTsumogiriAgent, 2)
+
+	status, body := post(t, ts.URL+"/v1/decide/batch", `{"requests":[
+		{"seat":0,"events":[`+startKyoku+`,`+tsumo+`]},
+		{"seat":9,"events":[`+startKyoku+`]},
+		{"seat":0,"snapshot":`+riichiBoard+`}
+	]}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", status, http.StatusOK, body)
+	}
+
+	var got struct {
+		Results []struct {
+			Action map[string]any `json:"action"`
+			Error  string         `json:"error"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(body), &got); err != nil {
+		t.Fatalf("Unmarshal(%s) failed: %v", body, err)
+	}
+	if len(got.Results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(got.Results))
+	}
+	if got.Results[0].Action["pai"] != "C" || got.Results[2].Action["pai"] != "S" {
+		t.Errorf("results = %+v, want decisions in request order", got.Results)
+	}
+	if !strings.Contains(got.Results[1].Error, "invalid seat") {
+		t.Errorf("results[1].error = %q, want invalid seat", got.Results[1].Error)
+	}
+}
+
+func TestServer_Session(t *testing.T) {
+	ts := newServerForTest(t, newTsumogiriAgent, 1)
+
+	status, body := post(t, ts.URL+"/v1/sessions", `{"seat":0}`)
+	if status != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d; body = %s", status, http.StatusCreated, body)
+	}
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(body), &created); err != nil {
+		t.Fatalf("Unmarshal(%s) failed: %v", body, err)
+	}
+	eventsURL := ts.URL + "/v1/sessions/" + created.ID + "/events"
+
+	for _, step := range []struct {
+		events string
+		want   string
+	}{
+		{events: startKyoku, want: `{"action":null}`},
+		{events: tsumo, want: `{"action":{"type":"dahai","actor":0,"pai":"C","tsumogiri":true}}`},
+		{events: dahai, want: `{"action":null}`},
+	} {
+		status, body := post(t, eventsURL, `{"events":[`+step.events+`]}`)
+		if status != http.StatusOK {
+			t.Fatalf("events status = %d, want %d; body = %s", status, http.StatusOK, body)
+		}
+		if body != step.want {
+			t.Errorf("events %s = %s, want %s", step.events, body, step.want)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/v1/sessions/"+created.ID, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() failed: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("delete status = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+
+	if status, body := post(t, eventsURL, `{"events":[`+tsumo+`]}`); status != http.StatusNotFound {
+		t.Errorf("events after delete status = %d, want %d; body = %s", status, http.StatusNotFound, body)
+	}
+}
+
+func TestServer_DecideBatchReturnsBadRequestOverLimit(t *testing.T) {
+	ts := newServerWithConfig(t, httpapi.Config{NewAgent: newTsumogiriAgent, Concurrency: 1, MaxBatch: 2})
+
+	request := `{"seat":0,"snapshot":` + riichiBoard + `}`
+	if status, body := post(t, ts.URL+"/v1/decide/batch", `{"requests":[`+request+`,`+request+`]}`); status != http.StatusOK {
+		t.Errorf("status of 2 requests = %d, want %d; body = %s", status, http.StatusOK, body)
+	}
+	status, body := post(t, ts.URL+"/v1/decide/batch", `{"requests":[`+request+`,`+request+`,`+request+`]}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("status of 3 requests = %d, want %d; body = %s", status, http.StatusBadRequest, body)
+	}
+	if !strings.Contains(body, "too many requests in batch") {
+		t.Errorf("body = %s, want too many requests in batch", body)
+	}
+}
+
+func createSession(t *testing.T, url string) (int, string) {
+	t.Helper()
+
+	status, body := post(t, url+"/v1/sessions", `{"seat":0}`)
+	var created struct {
+		ID string `json:"id"`
+	}
+	if status == http.StatusCreated {
+		if err := json.Unmarshal([]byte(body), &created); err != nil {
+			t.Fatalf("Unmarshal(%s) failed: %v", body, err)
+		}
+	}
+	return status, created.ID
+}
+
+func TestServer_CreateSessionReturnsTooManyRequests(t *testing.T) {
+	ts := newServerWithConfig(t, httpapi.Config{NewAgent: newTsumogiriAgent, Concurrency: 1, MaxSessions: 1})
+
+	status, id := createSession(t, ts.URL)
+	if status != http.StatusCreated {
+		t.Fatalf("first create status = %d, want %d", status, http.StatusCreated)
+	}
+	if status, _ := createSession(t, ts.URL); status != http.StatusTooManyRequests {
+		t.Errorf("second create status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL+"/v1/sessions/"+id, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() failed: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	resp.Body.Close()
+	if status, _ := createSession(t, ts.URL); status != http.StatusCreated {
+		t.Errorf("create after delete status = %d, want %d", status, http.StatusCreated)
+	}
+}
+
+func TestServer_SessionExpiresWhenIdle(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+	ts := newServerWithConfig(t, httpapi.Config{
+		NewAgent:    newTsumogiriAgent,
+		Concurrency: 1,
+		MaxSessions: 1,
+		SessionTTL:  ttl,
+	})
+
+	status, id := createSession(t, ts.URL)
+	if status != http.StatusCreated {
+		t.Fatalf("create status = %d, want %d", status, http.StatusCreated)
+	}
+	eventsURL := ts.URL + "/v1/sessions/" + id + "/events"
+	// Requests within the TTL keep the session alive.
+	for range 3 {
+		time.Sleep(ttl / 2)
+		if status, body := post(t, eventsURL, `{"events":[`+startKyoku+`]}`); status != http.StatusOK {
+			t.Fatalf("events status = %d, want %d; body = %s", status, http.StatusOK, body)
+		}
+	}
+
+	time.Sleep(2 * ttl)
+	if status, _ := createSession(t, ts.URL); status != http.StatusCreated {
+		t.Errorf("create after TTL status = %d, want %d", status, http.StatusCreated)
+	}
+	if status, body := post(t, eventsURL, `{"events":[`+startKyoku+`]}`); status != http.StatusNotFound {
+		t.Errorf("events after TTL status = %d, want %d; body = %s", status, http.StatusNotFound, body)
+	}
+}
+
+// candidateAgent returns a fixed evaluation and tracks how many decisions run
+// at once.
+type candidateAgent struct {
+	mu      *sync.Mutex
+	running *int
+	maxSeen *int
+}
+
+func (candidateAgent) Reset() {}
+
+func (a candidateAgent) Decide(request ai.Request) (ai.Decision, error) {
+	a.mu.Lock()
+	*a.running++
+	*a.maxSeen = max(*a.maxSeen, *a.running)
+	a.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	a.mu.Lock()
+	*a.running--
+	a.mu.Unlock()
+
+	decision, err := ai.NewTsumogiriAgent().Decide(request)
+	if err != nil {
+		return ai.Decision{}, err
+	}
+	decision.Candidates = []ai.CandidateEvaluation{
+		{Key: "-1.C", Action: decision.Action, AverageRank: 2.5, ExpectedPoints: -100, Shanten: 1},
+		{Key: "-1.1m", Action: decision.Action, AverageRank: 2.6, Shanten: service.InfinityShanten},
+	}
+	return decision, nil
+}
+
+func TestServer_DecideReturnsCandidatesWithinConcurrencyLimit(t *testing.T) {
+	var mu sync.Mutex
+	running, maxSeen := 0, 0
+	ts := newServerForTest(t, func(uint64) (ai.Agent, error) {
+		return candidateAgent{mu: &mu, running: &running, maxSeen: &maxSeen}, nil
+	}, 2)
+
+	request := `{"seat":0,"events":[` + startKyoku + `,` + tsumo + `]}`
+	status, body := post(t, ts.URL+"/v1/decide/batch", `{"requests":[`+strings.Repeat(request+",", 7)+request+`]}`)
+	if status != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", status, http.StatusOK, body)
+	}
+	if maxSeen != 2 {
+		t.Errorf("max concurrent decisions = %d, want 2", maxSeen)
+	}
+	for _, want := range []string{
+		`"key":"-1.C","action":{"type":"dahai","actor":0,"pai":"C","tsumogiri":true},"avg_rank":2.5,"exp_pt":-100`,
+		`"ryukyoku_avg_pt":0,"shanten":1}`,
+		`"ryukyoku_avg_pt":0,"shanten":null}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body = %s, want containing %s", body, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"encoding/json/jsontext"
+	"sync"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/application"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+// session holds a live Bot so that a client can stream the events of a game.
+// A session starts as if start_game has been received for its seat; a later
+// start_game starts a new game.
+type session struct {
+	mu    sync.Mutex
+	self  seat.Seat
+	agent ai.Agent
+	bot   *application.Bot
+
+	// lastUsed is when the session was last requested, guarded by Server.mu.
+	lastUsed time.Time
+}
+
+func newSession(self seat.Seat, agent ai.Agent) *session {
+	sess := &session{agent: agent}
+	sess.startGame(self, nil)
+	return sess
+}
+
+func (s *session) startGame(self seat.Seat, names []string) {
+	s.self = self
+	s.agent.Reset()
+	if r, ok := s.agent.(ai.PlayerNamesReceiver); ok {
+		r.SetPlayerNames(names)
+	}
+	s.bot = application.NewBot(self, s.agent, nil)
+}
+
+// observe applies mjai messages without deciding. When a message fails, the
+// messages before it stay applied.
+func (s *session) observe(messages []jsontext.Value) error {
+	for i, raw := range messages {
+		msg, err := inbound.ParseMessage(raw)
+		if err != nil {
+			return badRequest("event %d: %w", i, err)
+		}
+		switch msg := msg.(type) {
+		case *inbound.Hello, *inbound.EndGame:
+			continue
+		case *inbound.StartGame:
+			self := s.self
+			if msg.ID != nil {
+				self, err = seat.NewSeat(*msg.ID)
+				if err != nil {
+					return badRequest("event %d: %w", i, err)
+				}
+			}
+			s.startGame(self, msg.Names)
+			continue
+		case *inbound.Error:
+			return badRequest("event %d: server error: %s", i, msg.Message)
+		}
+
+		ev, err := inbound.ParseEvent(msg)
+		if err != nil {
+			return badRequest("event %d: %w", i, err)
+		}
+		if err := s.bot.Observe(ev); err != nil {
+			return badRequest("event %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (s *session) decide() (*DecisionResponse, error) {
+	decision, ok, err := s.bot.Decide()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &DecisionResponse{}, nil
+	}
+	return newDecisionResponse(&decision)
+}
@@ -7,15 +7,20 @@ import (
 )
 
 type Chi struct {
-	Type     string   `json:"type"`
-	Actor    int      `json:"actor"`
-	Target   int      `json:"target"`
-	Pai      string   `json:"pai"`
-	Consumed []string `json:"consumed"`
+	Type            string           `json:"type"`
+	Actor           int              `json:"actor"`
+	Target          int              `json:"target"`
+	Pai             string           `json:"pai"`
+	Consumed        []string         `json:"consumed"`
+	PossibleActions []PossibleAction `json:"possible_actions,omitzero"`
 }
 
 func (*Chi) inboundMessage() {}
 
+func (m *Chi) possibleActions() []PossibleAction {
+	return m.PossibleActions
+}
+
 func (m *Chi) ToEvent() (*event.Chii, error) {
 	if m == nil {
 		return nil, fmt.Errorf("chi message is nil")
@@ -7,14 +7,19 @@ import (
 )
 
 type Dahai struct {
-	Type      string `json:"type"`
-	Actor     int    `json:"actor"`
-	Pai       string `json:"pai"`
-	Tsumogiri bool   `json:"tsumogiri"`
+	Type            string           `json:"type"`
+	Actor           int              `json:"actor"`
+	Pai             string           `json:"pai"`
+	Tsumogiri       bool             `json:"tsumogiri"`
+	PossibleActions []PossibleAction `json:"possible_actions,omitzero"`
 }
 
 func (*Dahai) inboundMessage() {}
 
+func (m *Dahai) possibleActions() []PossibleAction {
+	return m.PossibleActions
+}
+
 func (m *Dahai) ToEvent() (*event.Discard, error) {
 	if m == nil {
 		return nil, fmt.Errorf("dahai message is nil")
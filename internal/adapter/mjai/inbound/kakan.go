@@ -7,14 +7,19 @@ import (
 )
 
 type Kakan struct {
-	Type     string   `json:"type"`
-	Actor    int      `json:"actor"`
-	Pai      string   `json:"pai"`
-	Consumed []string `json:"consumed"`
+	Type            string           `json:"type"`
+	Actor           int              `json:"actor"`
+	Pai             string           `json:"pai"`
+	Consumed        []string         `json:"consumed"`
+	PossibleActions []PossibleAction `json:"possible_actions,omitzero"`
 }
 
 func (*Kakan) inboundMessage() {}
 
+func (m *Kakan) possibleActions() []PossibleAction {
+	return m.PossibleActions
+}
+
 func (m *Kakan) ToEvent() (*event.PromotedKan, error) {
 	if m == nil {
 		return nil, fmt.Errorf("kakan message is nil")
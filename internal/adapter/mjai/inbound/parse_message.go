@@ -35,6 +35,16 @@ var parseMessageByType = map[string]func([]byte) (Message, error){
 	"end_kyoku":      parseAs[*EndKyoku],
 }
 
+// UnsupportedTypeError is returned by ParseMessage for a message whose type
+// is not an mjai inbound message type.
+type UnsupportedTypeError struct {
+	Type string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported message type: %q", e.Type)
+}
+
 // ParseMessage decodes a single mjai inbound JSON message into an inbound.Message.
 //
 // The returned message may or may not be convertible into a domain event.
@@ -48,7 +58,7 @@ func ParseMessage(b []byte) (Message, error) {
 
 	parser, ok := parseMessageByType[header.Type]
 	if !ok {
-		return nil, fmt.Errorf("unsupported message type: %q", header.Type)
+		return nil, &UnsupportedTypeError{Type: header.Type}
 	}
 	return parser(b)
 }
@@ -1,13 +1,18 @@
 package inbound_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
 )
 
 func TestParseMessage_JoinUnsupported(t *testing.T) {
-	if _, err := inbound.ParseMessage([]byte(`{"type":"join","name":"bot","room":"default"}`)); err == nil {
+	_, err := inbound.ParseMessage([]byte(`{"type":"join","name":"bot","room":"default"}`))
+	if err == nil {
 		t.Fatal("ParseMessage() succeeded unexpectedly")
 	}
+	if unsupported, ok := errors.AsType[*inbound.UnsupportedTypeError](err); !ok || unsupported.Type != "join" {
+		t.Errorf("ParseMessage() error = %v, want UnsupportedTypeError for join", err)
+	}
 }
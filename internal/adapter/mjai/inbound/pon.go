@@ -7,15 +7,20 @@ import (
 )
 
 type Pon struct {
-	Type     string   `json:"type"`
-	Actor    int      `json:"actor"`
-	Target   int      `json:"target"`
-	Pai      string   `json:"pai"`
-	Consumed []string `json:"consumed"`
+	Type            string           `json:"type"`
+	Actor           int              `json:"actor"`
+	Target          int              `json:"target"`
+	Pai             string           `json:"pai"`
+	Consumed        []string         `json:"consumed"`
+	PossibleActions []PossibleAction `json:"possible_actions,omitzero"`
 }
 
 func (*Pon) inboundMessage() {}
 
+func (m *Pon) possibleActions() []PossibleAction {
+	return m.PossibleActions
+}
+
 func (m *Pon) ToEvent() (*event.Pon, error) {
 	if m == nil {
 		return nil, fmt.Errorf("pon message is nil")
@@ -0,0 +1,28 @@
+package inbound
+
+// PossibleAction is an entry of the possible_actions a server attaches to a
+// message that the receiving player may respond to. Fields that do not apply
+// to the action type are left zero.
+type PossibleAction struct {
+	Type     string   `json:"type"`
+	Actor    int      `json:"actor"`
+	Target   *int     `json:"target,omitzero"`
+	Pai      string   `json:"pai,omitzero"`
+	Consumed []string `json:"consumed,omitzero"`
+	Reason   string   `json:"reason,omitzero"`
+}
+
+type possibleActionsCarrier interface {
+	possibleActions() []PossibleAction
+}
+
+// PossibleActionsOf returns the possible_actions of msg. It returns false when
+// the message does not carry them, which differs from an empty list.
+func PossibleActionsOf(msg Message) ([]PossibleAction, bool) {
+	c, ok := msg.(possibleActionsCarrier)
+	if !ok {
+		return nil, false
+	}
+	actions := c.possibleActions()
+	return actions, actions != nil
+}
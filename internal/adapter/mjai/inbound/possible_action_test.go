@@ -0,0 +1,62 @@
+package inbound_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+)
+
+func TestPossibleActionsOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		b      []byte
+		want   []inbound.PossibleAction
+		wantOK bool
+	}{
+		{
+			name:   "absent",
+			b:      []byte(`{"type":"tsumo","actor":0,"pai":"E"}`),
+			want:   nil,
+			wantOK: false,
+		},
+		{
+			name:   "empty",
+			b:      []byte(`{"type":"tsumo","actor":0,"pai":"E","possible_actions":[]}`),
+			want:   []inbound.PossibleAction{},
+			wantOK: true,
+		},
+		{
+			name: "calls",
+			b: []byte(`{"type":"dahai","actor":3,"pai":"E","tsumogiri":false,"possible_actions":[` +
+				`{"type":"pon","actor":0,"target":3,"pai":"E","consumed":["E","E"]},` +
+				`{"type":"hora","actor":0,"target":3,"pai":"E"}]}`),
+			want: []inbound.PossibleAction{
+				{Type: "pon", Actor: 0, Target: new(3), Pai: "E", Consumed: []string{"E", "E"}},
+				{Type: "hora", Actor: 0, Target: new(3), Pai: "E"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "message without possible_actions",
+			b:      []byte(`{"type":"dora","dora_marker":"E"}`),
+			want:   nil,
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := inbound.ParseMessage(tt.b)
+			if err != nil {
+				t.Fatalf("ParseMessage() failed: %v", err)
+			}
+			got, ok := inbound.PossibleActionsOf(msg)
+			if ok != tt.wantOK {
+				t.Errorf("PossibleActionsOf() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PossibleActionsOf() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
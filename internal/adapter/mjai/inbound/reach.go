@@ -7,12 +7,17 @@ import (
 )
 
 type Reach struct {
-	Type  string `json:"type"`
-	Actor int    `json:"actor"`
+	Type            string           `json:"type"`
+	Actor           int              `json:"actor"`
+	PossibleActions []PossibleAction `json:"possible_actions,omitzero"`
 }
 
 func (*Reach) inboundMessage() {}
 
+func (m *Reach) possibleActions() []PossibleAction {
+	return m.PossibleActions
+}
+
 func (m *Reach) ToEvent() (*event.Riichi, error) {
 	if m == nil {
 		return nil, fmt.Errorf("reach message is nil")
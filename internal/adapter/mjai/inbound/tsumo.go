@@ -7,13 +7,18 @@ import (
 )
 
 type Tsumo struct {
-	Type  string `json:"type"`
-	Actor int    `json:"actor"`
-	Pai   string `json:"pai"`
+	Type            string           `json:"type"`
+	Actor           int              `json:"actor"`
+	Pai             string           `json:"pai"`
+	PossibleActions []PossibleAction `json:"possible_actions,omitzero"`
 }
 
 func (*Tsumo) inboundMessage() {}
 
+func (m *Tsumo) possibleActions() []PossibleAction {
+	return m.PossibleActions
+}
+
 func (m *Tsumo) ToEvent() (*event.Draw, error) {
 	if m == nil {
 		return nil, fmt.Errorf("tsumo message is nil")
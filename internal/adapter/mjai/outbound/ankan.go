@@ -7,6 +7,7 @@ type Ankan struct {
 	Actor    int      `json:"actor"`
 	Consumed []string `json:"consumed"`
 	Log      string   `json:"log,omitempty"`
+	Meta     *Meta    `json:"meta,omitzero"`
 }
 
 func NewAnkan(a *action.ConcealedKan, log string) *Ankan {
@@ -19,3 +20,7 @@ func NewAnkan(a *action.ConcealedKan, log string) *Ankan {
 }
 
 func (*Ankan) outboundMessage() {}
+
+func (m *Ankan) setMeta(meta *Meta) {
+	m.Meta = meta
+}
@@ -9,6 +9,7 @@ type Chi struct {
 	Pai      string   `json:"pai"`
 	Consumed []string `json:"consumed"`
 	Log      string   `json:"log,omitempty"`
+	Meta     *Meta    `json:"meta,omitzero"`
 }
 
 func NewChi(a *action.Chii, log string) *Chi {
@@ -23,3 +24,7 @@ func NewChi(a *action.Chii, log string) *Chi {
 }
 
 func (*Chi) outboundMessage() {}
+
+func (m *Chi) setMeta(meta *Meta) {
+	m.Meta = meta
+}
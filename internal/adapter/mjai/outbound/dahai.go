@@ -10,6 +10,7 @@ type Dahai struct {
 	Pai       string `json:"pai"`
 	Tsumogiri bool   `json:"tsumogiri"`
 	Log       string `json:"log,omitempty"`
+	Meta      *Meta  `json:"meta,omitzero"`
 }
 
 func NewDahai(a *action.Discard, log string) *Dahai {
@@ -23,3 +24,7 @@ func NewDahai(a *action.Discard, log string) *Dahai {
 }
 
 func (*Dahai) outboundMessage() {}
+
+func (m *Dahai) setMeta(meta *Meta) {
+	m.Meta = meta
+}
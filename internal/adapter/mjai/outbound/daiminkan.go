@@ -9,6 +9,7 @@ type Daiminkan struct {
 	Pai      string   `json:"pai"`
 	Consumed []string `json:"consumed"`
 	Log      string   `json:"log,omitempty"`
+	Meta     *Meta    `json:"meta,omitzero"`
 }
 
 func NewDaiminkan(a *action.CalledKan, log string) *Daiminkan {
@@ -23,3 +24,7 @@ func NewDaiminkan(a *action.CalledKan, log string) *Daiminkan {
 }
 
 func (*Daiminkan) outboundMessage() {}
+
+func (m *Daiminkan) setMeta(meta *Meta) {
+	m.Meta = meta
+}
@@ -8,6 +8,7 @@ type Hora struct {
 	Target int    `json:"target"`
 	Pai    string `json:"pai"`
 	Log    string `json:"log,omitempty"`
+	Meta   *Meta  `json:"meta,omitzero"`
 }
 
 func NewHora(a *action.Win, log string) *Hora {
@@ -21,3 +22,7 @@ func NewHora(a *action.Win, log string) *Hora {
 }
 
 func (*Hora) outboundMessage() {}
+
+func (m *Hora) setMeta(meta *Meta) {
+	m.Meta = meta
+}
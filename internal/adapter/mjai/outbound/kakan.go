@@ -8,6 +8,7 @@ type Kakan struct {
 	Pai      string   `json:"pai"`
 	Consumed []string `json:"consumed"`
 	Log      string   `json:"log,omitempty"`
+	Meta     *Meta    `json:"meta,omitzero"`
 }
 
 func NewKakan(a *action.PromotedKan, log string) *Kakan {
@@ -21,3 +22,7 @@ func NewKakan(a *action.PromotedKan, log string) *Kakan {
 }
 
 func (*Kakan) outboundMessage() {}
+
+func (m *Kakan) setMeta(meta *Meta) {
+	m.Meta = meta
+}
@@ -7,6 +7,7 @@ type Kyushukyuhai struct {
 	Reason string `json:"reason"`
 	Actor  int    `json:"actor"`
 	Log    string `json:"log,omitempty"`
+	Meta   *Meta  `json:"meta,omitzero"`
 }
 
 func NewKyushukyuhai(a *action.Kyushukyuhai, log string) *Kyushukyuhai {
@@ -19,3 +20,7 @@ func NewKyushukyuhai(a *action.Kyushukyuhai, log string) *Kyushukyuhai {
 }
 
 func (*Kyushukyuhai) outboundMessage() {}
+
+func (m *Kyushukyuhai) setMeta(meta *Meta) {
+	m.Meta = meta
+}
@@ -0,0 +1,155 @@
+package outbound
+
+import (
+	"encoding/json/jsontext"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+)
+
+// Meta is the evaluation attached to an action message. It has the fields of
+// the meta object of Mortal that viewers such as mjai-reviewer and mjai.app
+// read, and the evaluated candidates.
+type Meta struct {
+	// QValues holds a value for each action in MaskBits, in the order of
+	// their indices. Manue has no Q-values, so it gives the negated average
+	// rank of the best candidate of each action, which is highest for the
+	// action Manue prefers.
+	QValues []float64 `json:"q_values,omitzero"`
+	// MaskBits has the bit of each evaluated action set, indexed as in
+	// Mortal: 0 to 36 discard a tile in the order of mjai tile IDs with the
+	// red fives last, then 37 riichi, 38 to 40 chi with the taken tile the
+	// lowest, middle, or highest, 41 pon, 42 any kan, 43 hora, 44 ryukyoku,
+	// and 45 none.
+	MaskBits uint64 `json:"mask_bits,omitzero"`
+	// IsGreedy reports that the action was chosen by the values, which always
+	// holds for Manue.
+	IsGreedy bool `json:"is_greedy"`
+	// Shanten is the shanten number after the chosen action. It is omitted
+	// when the action gives up winning or no candidate was evaluated.
+	Shanten    *int            `json:"shanten,omitzero"`
+	EvalTimeNs int64           `json:"eval_time_ns"`
+	EvalTimeMs float64         `json:"eval_time_ms"`
+	Candidates []MetaCandidate `json:"candidates,omitzero"`
+}
+
+// MetaCandidate is an evaluated candidate, from best to worst. The field
+// names follow the columns of the decision log.
+type MetaCandidate struct {
+	Key        string         `json:"key"`
+	Action     jsontext.Value `json:"action"`
+	ExpPt      float64        `json:"exp_pt"`
+	MyHoraProb float64        `json:"my_hora_prob"`
+	HojuProb   float64        `json:"hoju_prob"`
+	// Shanten is null when the candidate gives up winning.
+	Shanten *int `json:"shanten"`
+}
+
+// numMortalActions is the size of the action space of Mortal.
+const numMortalActions = 46
+
+// NewMeta builds the meta of a decision from its candidates, sorted from best
+// to worst, and the time it took.
+func NewMeta(candidates []ai.CandidateEvaluation, elapsed time.Duration) (*Meta, error) {
+	meta := &Meta{
+		IsGreedy:   true,
+		EvalTimeNs: elapsed.Nanoseconds(),
+		EvalTimeMs: float64(elapsed.Microseconds()) / 1000,
+	}
+	var values [numMortalActions]float64
+	for i, c := range candidates {
+		msg, err := ToMessage(c.Action, "")
+		if err != nil {
+			return nil, err
+		}
+		b, err := MarshalMessage(msg)
+		if err != nil {
+			return nil, err
+		}
+		shanten := finiteShanten(c.Shanten)
+		if i == 0 {
+			// The best candidate is the chosen one.
+			meta.Shanten = shanten
+		}
+		meta.Candidates = append(meta.Candidates, MetaCandidate{
+			Key:        c.Key,
+			Action:     b,
+			ExpPt:      c.ExpectedPoints,
+			MyHoraProb: c.WinProb,
+			HojuProb:   c.DealInProb,
+			Shanten:    shanten,
+		})
+		// Candidates of the same index, such as riichi with different
+		// discards, take the value of the best one. 0 - x keeps a zero rank
+		// from becoming -0.
+		if index, ok := mortalActionIndex(c.Action); ok && meta.MaskBits&(1<<index) == 0 {
+			meta.MaskBits |= 1 << index
+			values[index] = 0 - c.AverageRank
+		}
+	}
+	for index, value := range values {
+		if meta.MaskBits&(1<<index) != 0 {
+			meta.QValues = append(meta.QValues, value)
+		}
+	}
+	return meta, nil
+}
+
+// mortalActionIndex returns the index of a in the action space of Mortal.
+func mortalActionIndex(a action.Action) (int, bool) {
+	switch a := a.(type) {
+	case *action.Discard:
+		return a.Tile().ID(), true
+	case *action.Riichi:
+		return 37, true
+	case *action.Chii:
+		taken, consumed := a.Taken().Number(), a.Consumed()
+		switch {
+		case taken < consumed[0].Number() && taken < consumed[1].Number():
+			return 38, true
+		case taken > consumed[0].Number() && taken > consumed[1].Number():
+			return 40, true
+		default:
+			return 39, true
+		}
+	case *action.Pon:
+		return 41, true
+	case *action.CalledKan, *action.ConcealedKan, *action.PromotedKan:
+		return 42, true
+	case *action.Win:
+		return 43, true
+	case *action.Kyushukyuhai:
+		return 44, true
+	case *action.Pass:
+		return 45, true
+	default:
+		return 0, false
+	}
+}
+
+func finiteShanten(shanten int) *int {
+	if shanten == service.InfinityShanten {
+		return nil
+	}
+	return new(shanten)
+}
+
+// metaCarrier is implemented by the messages of actions.
+type metaCarrier interface {
+	setMeta(meta *Meta)
+}
+
+// ToMessageWithMeta is ToMessage with meta attached. A nil meta leaves the
+// message as ToMessage makes it.
+func ToMessageWithMeta(a action.Action, log string, meta *Meta) (Message, error) {
+	msg, err := ToMessage(a, log)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		msg.(metaCarrier).setMeta(meta)
+	}
+	return msg, nil
+}
@@ -0,0 +1,125 @@
+package outbound_test
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+func TestMarshalMessage_DahaiWithMeta(t *testing.T) {
+	keep, err := action.NewDiscard(seat.MustSeat(1), tile.MustTileFromCode("9s"), true)
+	if err != nil {
+		t.Fatalf("NewDiscard() failed: %v", err)
+	}
+	fold, err := action.NewDiscard(seat.MustSeat(1), tile.MustTileFromCode("E"), false)
+	if err != nil {
+		t.Fatalf("NewDiscard() failed: %v", err)
+	}
+	meta, err := outbound.NewMeta([]ai.CandidateEvaluation{
+		{Key: "9s", Action: keep, AverageRank: 2.25, ExpectedPoints: 1200.5, WinProb: 0.25, DealInProb: 0.125, Shanten: 1},
+		{Key: "E", Action: fold, AverageRank: 2.5, ExpectedPoints: -300, WinProb: 0, DealInProb: 0, Shanten: service.InfinityShanten},
+	}, 1500*time.Microsecond)
+	if err != nil {
+		t.Fatalf("NewMeta() failed: %v", err)
+	}
+	msg, err := outbound.ToMessageWithMeta(keep, "log", meta)
+	if err != nil {
+		t.Fatalf("ToMessageWithMeta() failed: %v", err)
+	}
+
+	got, err := outbound.MarshalMessage(msg)
+	if err != nil {
+		t.Fatalf("MarshalMessage() failed: %v", err)
+	}
+	// 9s is index 26 and E is index 27. Values follow the indices.
+	want := `{"type":"dahai","actor":1,"pai":"9s","tsumogiri":true,"log":"log","meta":{"q_values":[-2.25,-2.5],"mask_bits":201326592,"is_greedy":true,` +
+		`"shanten":1,"eval_time_ns":1500000,"eval_time_ms":1.5,"candidates":[` +
+		`{"key":"9s","action":{"type":"dahai","actor":1,"pai":"9s","tsumogiri":true},"exp_pt":1200.5,"my_hora_prob":0.25,"hoju_prob":0.125,"shanten":1},` +
+		`{"key":"E","action":{"type":"dahai","actor":1,"pai":"E","tsumogiri":false},"exp_pt":-300,"my_hora_prob":0,"hoju_prob":0,"shanten":null}]}}`
+	if string(got) != want {
+		t.Errorf("MarshalMessage() = %s, want %s", got, want)
+	}
+}
+
+func TestMarshalMessage_PassWithMetaWithoutCandidates(t *testing.T) {
+	pass := action.NewPass(seat.MustSeat(2))
+	meta, err := outbound.NewMeta(nil, 2*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewMeta() failed: %v", err)
+	}
+	msg, err := outbound.ToMessageWithMeta(pass, "", meta)
+	if err != nil {
+		t.Fatalf("ToMessageWithMeta() failed: %v", err)
+	}
+
+	got, err := outbound.MarshalMessage(msg)
+	if err != nil {
+		t.Fatalf("MarshalMessage() failed: %v", err)
+	}
+	if want := `{"type":"none","actor":2,"meta":{"is_greedy":true,"eval_time_ns":2000000,"eval_time_ms":2}}`; string(got) != want {
+		t.Errorf("MarshalMessage() = %s, want %s", got, want)
+	}
+}
+
+func TestToMessageWithMeta_NilMeta(t *testing.T) {
+	riichi := action.NewRiichi(seat.MustSeat(0))
+	msg, err := outbound.ToMessageWithMeta(riichi, "", nil)
+	if err != nil {
+		t.Fatalf("ToMessageWithMeta() failed: %v", err)
+	}
+
+	got, err := outbound.MarshalMessage(msg)
+	if err != nil {
+		t.Fatalf("MarshalMessage() failed: %v", err)
+	}
+	if want := `{"type":"reach","actor":0}`; string(got) != want {
+		t.Errorf("MarshalMessage() = %s, want %s", got, want)
+	}
+}
+
+func TestNewMeta_MortalActionSpace(t *testing.T) {
+	self, kamicha := seat.MustSeat(0), seat.MustSeat(3)
+	mustChii := func(taken string, consumed [2]string) action.Action {
+		c, err := action.NewChii(self, kamicha, tile.MustTileFromCode(taken),
+			[2]tile.Tile{tile.MustTileFromCode(consumed[0]), tile.MustTileFromCode(consumed[1])})
+		if err != nil {
+			t.Fatalf("NewChii() failed: %v", err)
+		}
+		return c
+	}
+	red, err := action.NewDiscard(self, tile.MustTileFromCode("5mr"), false)
+	if err != nil {
+		t.Fatalf("NewDiscard() failed: %v", err)
+	}
+
+	meta, err := outbound.NewMeta([]ai.CandidateEvaluation{
+		{Key: "pass", Action: action.NewPass(self), AverageRank: 0},
+		{Key: "reach", Action: action.NewRiichi(self), AverageRank: 2},
+		{Key: "reach 2", Action: action.NewRiichi(self), AverageRank: 3},
+		{Key: "chi low", Action: mustChii("3m", [2]string{"4m", "5m"}), AverageRank: 2.5},
+		{Key: "chi middle", Action: mustChii("4m", [2]string{"3m", "5m"}), AverageRank: 2.75},
+		{Key: "5mr", Action: red, AverageRank: 3.5},
+	}, 0)
+	if err != nil {
+		t.Fatalf("NewMeta() failed: %v", err)
+	}
+
+	wantMask := uint64(1)<<34 | 1<<37 | 1<<38 | 1<<39 | 1<<45
+	if meta.MaskBits != wantMask {
+		t.Errorf("MaskBits = %b, want %b", meta.MaskBits, wantMask)
+	}
+	if want := []float64{-3.5, -2, -2.5, -2.75, 0}; !slices.Equal(meta.QValues, want) {
+		t.Errorf("QValues = %v, want %v", meta.QValues, want)
+	}
+	if math.Signbit(meta.QValues[4]) {
+		t.Errorf("QValues[4] = -0, want 0")
+	}
+}
@@ -6,6 +6,7 @@ type Pass struct {
 	Type  string `json:"type"`
 	Actor int    `json:"actor"`
 	Log   string `json:"log,omitempty"`
+	Meta  *Meta  `json:"meta,omitzero"`
 }
 
 func NewPass(a *action.Pass, log string) *Pass {
@@ -17,3 +18,7 @@ func NewPass(a *action.Pass, log string) *Pass {
 }
 
 func (*Pass) outboundMessage() {}
+
+func (m *Pass) setMeta(meta *Meta) {
+	m.Meta = meta
+}
@@ -9,6 +9,7 @@ type Pon struct {
 	Pai      string   `json:"pai"`
 	Consumed []string `json:"consumed"`
 	Log      string   `json:"log,omitempty"`
+	Meta     *Meta    `json:"meta,omitzero"`
 }
 
 func NewPon(a *action.Pon, log string) *Pon {
@@ -23,3 +24,7 @@ func NewPon(a *action.Pon, log string) *Pon {
 }
 
 func (*Pon) outboundMessage() {}
+
+func (m *Pon) setMeta(meta *Meta) {
+	m.Meta = meta
+}
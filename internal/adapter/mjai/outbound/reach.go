@@ -6,6 +6,7 @@ type Reach struct {
 	Type  string `json:"type"`
 	Actor int    `json:"actor"`
 	Log   string `json:"log,omitempty"`
+	Meta  *Meta  `json:"meta,omitzero"`
 }
 
 func NewReach(a *action.Riichi, log string) *Reach {
@@ -17,3 +18,7 @@ func NewReach(a *action.Riichi, log string) *Reach {
 }
 
 func (*Reach) outboundMessage() {}
+
+func (m *Reach) setMeta(meta *Meta) {
+	m.Meta = meta
+}
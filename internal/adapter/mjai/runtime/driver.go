@@ -3,6 +3,7 @@ package mjairuntime
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
 	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
@@ -18,7 +19,27 @@ type Driver struct {
 	agent      ai.Agent
 	bot        *application.Bot
 	ended      bool
-	log        io.Writer
+	log        *logger
+	recorder   *Recorder
+	reporter   *reporter
+	options    driverOptions
+	// onStartGame is called when start_game has been handled.
+	onStartGame func()
+	// kanDora checks the dora timing of open kans against the profile.
+	kanDora kanDoraTracker
+}
+
+// driverOptions are the optional behaviors of a driver. A session recording
+// keeps them so that its replay behaves the same.
+type driverOptions struct {
+	// possibleActions selects how possible_actions are cross-checked.
+	possibleActions PossibleActionsMode
+	// meta attaches the evaluation of the decision to action messages.
+	meta bool
+	// resilient plays through errors of the state tracking and the agent.
+	resilient bool
+	// profile is the dialect of the server, nil to accept any.
+	profile *ServerProfile
 }
 
 func NewDriver(name string, room string, fallbackID int, agent ai.Agent, log io.Writer) *Driver {
@@ -27,11 +48,21 @@ func NewDriver(name string, room string, fallbackID int, agent ai.Agent, log io.
 		room:       room,
 		fallbackID: fallbackID,
 		agent:      agent,
-		log:        log,
+		log:        newLogger(log, LogConfig{}),
 	}
 }
 
 func (d *Driver) Handle(msg inbound.Message) (outbound.Message, error) {
+	if err := d.options.profile.checkRequired(msg); err != nil {
+		if err := d.diverge(err); err != nil {
+			return nil, err
+		}
+	}
+	if err := d.kanDora.check(d.options.profile, msg); err != nil {
+		if err := d.diverge(err); err != nil {
+			return nil, err
+		}
+	}
 	switch msg := msg.(type) {
 	case *inbound.Hello:
 		return outbound.NewJoin(d.name, d.room), nil
@@ -45,8 +76,18 @@ func (d *Driver) Handle(msg inbound.Message) (outbound.Message, error) {
 			return nil, err
 		}
 		d.agent.Reset()
-		d.bot = application.NewBot(self, d.agent, newReporter(d.log))
+		if r, ok := d.agent.(ai.PlayerNamesReceiver); ok {
+			r.SetPlayerNames(msg.Names)
+		}
+		d.reporter = newReporter(d.log, d.recorder)
+		d.bot = application.NewBot(self, d.agent, d.reporter)
+		if d.options.resilient {
+			d.bot.SetFallback(fallbackOf(d.agent))
+		}
 		d.ended = false
+		if d.onStartGame != nil {
+			d.onStartGame()
+		}
 		return nil, nil
 	case *inbound.EndGame:
 		d.bot = nil
@@ -60,17 +101,57 @@ func (d *Driver) Handle(msg inbound.Message) (outbound.Message, error) {
 		}
 		ev, err := inbound.ParseEvent(msg)
 		if err != nil {
-			return nil, err
+			return nil, d.bot.Diverge(err)
+		}
+		start := time.Now()
+		var reaction application.Reaction
+		// A diverged bot acts on possible_actions whatever the mode, since
+		// they are all it knows of the round.
+		if possible, ok := inbound.PossibleActionsOf(msg); ok && (d.options.possibleActions != PossibleActionsOff || d.bot.Diverged()) {
+			reaction, err = d.processWithPossibleActions(ev, possible)
+		} else {
+			reaction, err = d.bot.Process(ev)
 		}
-		reaction, err := d.bot.Process(ev)
 		if err != nil {
 			return nil, err
 		}
 		if reaction.Kind() != application.ReactionAction {
 			return nil, nil
 		}
-		return outbound.ToMessage(reaction.Action(), reaction.Log())
+		if !d.options.meta {
+			return outbound.ToMessage(reaction.Action(), reaction.Log())
+		}
+		meta, err := outbound.NewMeta(reaction.Candidates(), time.Since(start))
+		if err != nil {
+			return nil, err
+		}
+		return outbound.ToMessageWithMeta(reaction.Action(), reaction.Log(), meta)
+	}
+}
+
+// diverge plays through err, found while handling a message, when the driver
+// is resilient and a game has started: the bot stops tracking the round and
+// reports err as an incident. It returns err otherwise.
+func (d *Driver) diverge(err error) error {
+	if d.bot == nil {
+		return err
+	}
+	return d.bot.Diverge(err)
+}
+
+// fallbackOf returns the agent a resilient driver falls back on when agent
+// fails.
+func fallbackOf(agent ai.Agent) ai.Agent {
+	if p, ok := agent.(ai.FallbackProvider); ok {
+		return p.Fallback()
 	}
+	return ai.NewSafeAgent(nil)
+}
+
+// setInbound tells the driver the line it is about to handle, which
+// incidents quote.
+func (d *Driver) setInbound(line []byte) {
+	d.reporter.setInbound(line)
 }
 
 func (d *Driver) Ended() bool {
@@ -1,6 +1,7 @@
 package mjairuntime_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
@@ -59,6 +60,19 @@ func TestDriver_HandleStartGameResetsAgent(t *testing.T) {
 	}
 }
 
+func TestDriver_HandleStartGamePassesPlayerNames(t *testing.T) {
+	agent := &recordingAgent{}
+	driver := mjairuntime.NewDriver("manue", "default", 0, agent, nil)
+
+	names := []string{"manue", "Mortal", "akochan", "tsumogiri"}
+	if _, err := driver.Handle(&inbound.StartGame{Type: "start_game", ID: new(0), Names: names}); err != nil {
+		t.Fatalf("Handle(start_game) failed: %v", err)
+	}
+	if !slices.Equal(agent.names, names) {
+		t.Errorf("player names = %v, want %v", agent.names, names)
+	}
+}
+
 func TestDriver_HandleEndGameMarksEnded(t *testing.T) {
 	driver := mjairuntime.NewDriver("tsumogiri", "default", 0, ai.NewTsumogiriAgent(), nil)
 
@@ -164,10 +178,16 @@ func TestDriver_HandleStartGameRejectsInvalidFallbackID(t *testing.T) {
 
 type recordingAgent struct {
 	resets int
+	names  []string
 }
 
 func (a *recordingAgent) Reset() {
 	a.resets++
+	a.names = nil
+}
+
+func (a *recordingAgent) SetPlayerNames(names []string) {
+	a.names = names
 }
 
 func (*recordingAgent) Decide(ai.Request) (ai.Decision, error) {
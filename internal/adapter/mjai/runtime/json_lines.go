@@ -11,14 +11,26 @@ import (
 )
 
 type jsonLinesPolicy struct {
+	// transport names the policy in session recordings.
+	transport               string
 	respondNoneOnNoReaction bool
 	stopOnEndGame           bool
 }
 
+var (
+	stdioPolicy  = jsonLinesPolicy{transport: transportStdio}
+	mjsonpPolicy = jsonLinesPolicy{
+		transport:               transportMjsonp,
+		respondNoneOnNoReaction: true,
+		stopOnEndGame:           true,
+	}
+)
+
 // runJSONLines hosts the common mjai JSON Lines loop. The policy captures the
 // transport-level differences: stdio is sparse, while mjsonp TCP must ack every
-// non-terminal server message and stops immediately after end_game. EOF is a
-// normal transport shutdown for both stdio and mjsonp TCP.
+// non-terminal server message and stops immediately after end_game. A server
+// profile in options overrides the acks. EOF is a normal transport shutdown for
+// both stdio and mjsonp TCP.
 func runJSONLines(
 	name string,
 	room string,
@@ -26,17 +38,39 @@ func runJSONLines(
 	agent ai.Agent,
 	in io.Reader,
 	out io.Writer,
-	log io.Writer,
+	log *logger,
 	policy jsonLinesPolicy,
+	rec *Recorder,
+	options driverOptions,
+) error {
+	if err := rec.start(policy.transport, name, room, fallbackID, options); err != nil {
+		return err
+	}
+	driver := NewDriver(name, room, fallbackID, agent, nil)
+	driver.log = log
+	driver.recorder = rec
+	driver.options = options
+	return runDriver(driver, in, out, policy, rec)
+}
+
+// runDriver feeds the lines of in to a driver that the caller has prepared.
+func runDriver(
+	driver *Driver,
+	in io.Reader,
+	out io.Writer,
+	policy jsonLinesPolicy,
+	rec *Recorder,
 ) error {
 	r := bufio.NewScanner(in)
 	w := bufio.NewWriter(out)
 	defer w.Flush()
 
-	driver := NewDriver(name, room, fallbackID, agent, log)
 	for r.Scan() {
-		stop, err := handleJSONLine(r.Bytes(), w, driver, log, policy)
+		stop, err := handleJSONLine(r.Bytes(), w, driver, policy, rec)
 		if err != nil {
+			if recErr := rec.recordError(err); recErr != nil {
+				return recErr
+			}
 			return err
 		}
 		if stop {
@@ -49,30 +83,66 @@ func runJSONLines(
 	return nil
 }
 
-func handleJSONLine(line []byte, w *bufio.Writer, driver *Driver, log io.Writer, policy jsonLinesPolicy) (bool, error) {
-	if err := traceLine(log, "<-", line); err != nil {
+// handleJSONLine handles a line from the server. A start_game moves the log
+// to the file of the new game before the line is logged, and an end_game
+// closes the file after the reply is logged.
+func handleJSONLine(
+	line []byte,
+	w *bufio.Writer,
+	driver *Driver,
+	policy jsonLinesPolicy,
+	rec *Recorder,
+) (stop bool, err error) {
+	if err := rec.recordInbound(line); err != nil {
 		return false, err
 	}
-	if len(line) == 0 {
-		return false, fmt.Errorf("empty input line")
+	profile := driver.options.profile
+	msg, parseErr := parseLine(line, profile)
+	if _, ok := msg.(*inbound.StartGame); ok {
+		if err := driver.log.startGame(driver.room); err != nil {
+			return false, err
+		}
 	}
-
-	msg, err := inbound.ParseMessage(line)
-	if err != nil {
+	if err := driver.log.message("<-", line); err != nil {
 		return false, err
 	}
-	outMsg, err := driver.Handle(msg)
-	if err != nil {
+	if _, ok := msg.(*inbound.EndGame); ok {
+		defer func() {
+			if closeErr := driver.log.close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+	driver.setInbound(line)
+	var outMsg outbound.Message
+	if parseErr != nil {
+		// A resilient driver treats a line it cannot read as an event it
+		// cannot apply, and answers it as it answers no reaction.
+		if err := driver.diverge(parseErr); err != nil {
+			return false, err
+		}
+	} else if outMsg, err = driver.Handle(msg); err != nil {
 		return false, err
 	}
 	if driver.Ended() && policy.stopOnEndGame {
 		return true, nil
 	}
 	if outMsg == nil {
-		if !policy.respondNoneOnNoReaction {
+		if !profile.acksAll(policy.respondNoneOnNoReaction) {
 			return false, nil
 		}
 		outMsg = outbound.NewNone()
 	}
-	return false, writeMessageWithTrace(w, outMsg, log)
+	return false, writeMessageWithTrace(w, outMsg, driver.log, rec, profile)
+}
+
+func parseLine(line []byte, profile *ServerProfile) (inbound.Message, error) {
+	if len(line) == 0 {
+		return nil, fmt.Errorf("empty input line")
+	}
+	line, err := profile.decodeLine(line)
+	if err != nil {
+		return nil, err
+	}
+	return inbound.ParseMessage(line)
 }
@@ -0,0 +1,240 @@
+package mjairuntime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+// AgentFactory builds the agent of one game. The lobby calls it once per game,
+// so an agent is never shared between goroutines, but anything the factory
+// shares between agents, such as stats, must be read-only.
+type AgentFactory func(seed uint64) (ai.Agent, error)
+
+type LobbyConfig struct {
+	Name string
+	// URLs lists the mjsonp URL of each table. The same URL may appear more
+	// than once to take several seats in a room.
+	URLs       []string
+	FallbackID int
+	// Seed is the base seed from which GameSeed derives the seed of each game.
+	Seed uint64
+	// Games is the number of games each table plays. Zero plays until the
+	// context is done.
+	Games    int
+	NewAgent AgentFactory
+	// LogDir receives the log of table N in table-N.log when it is not empty.
+	// Otherwise the tables write to Log with a "table N: " prefix on each
+	// line, or a table attribute on each structured record.
+	LogDir string
+	Log    io.Writer
+	// Logging selects the format, the channel levels, and the per-game files
+	// of the log of each table.
+	Logging LogConfig
+	// PossibleActions selects how possible_actions are cross-checked.
+	PossibleActions PossibleActionsMode
+	// Meta attaches the evaluation of each decision to its action message.
+	Meta bool
+	// Resilient plays through errors of the state tracking and the agent
+	// instead of ending the session.
+	Resilient bool
+	// ServerProfile is the dialect of the server. A nil profile accepts any.
+	ServerProfile *ServerProfile
+}
+
+// GameSeed derives the seed of a game from the base seed, the table index, and
+// the game index, so that every game of a lobby is reproducible on its own
+// with mjai-manue --seed.
+func GameSeed(base uint64, table int, game int) uint64 {
+	return splitMix64(splitMix64(base^uint64(table)) ^ uint64(game))
+}
+
+func splitMix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// RunLobby plays on every table concurrently, reconnecting after each game.
+// When ctx is done, tables that are waiting for a game disconnect at once and
+// tables in a game finish it before they stop. RunLobby returns after every
+// table has stopped, with the errors of the tables that failed.
+func RunLobby(ctx context.Context, cfg LobbyConfig) error {
+	if len(cfg.URLs) == 0 {
+		return &UsageError{err: fmt.Errorf("lobby requires at least one mjsonp URL")}
+	}
+	if cfg.Games < 0 {
+		return &UsageError{err: fmt.Errorf("number of games must be non-negative: %d", cfg.Games)}
+	}
+	if cfg.NewAgent == nil {
+		return fmt.Errorf("lobby requires an agent factory")
+	}
+	endpoints := make([]*mjsonpEndpoint, len(cfg.URLs))
+	for i, rawURL := range cfg.URLs {
+		endpoint, err := parseMjsonpURL(rawURL)
+		if err != nil {
+			return err
+		}
+		endpoints[i] = endpoint
+	}
+
+	logs := make([]*logger, len(endpoints))
+	var sharedLogMu sync.Mutex
+	structured := cfg.Logging.Format != "" && cfg.Logging.Format != LogFormatPlain
+	for i := range endpoints {
+		var w io.Writer
+		switch {
+		case cfg.LogDir != "":
+			f, err := os.OpenFile(filepath.Join(cfg.LogDir, fmt.Sprintf("table-%d.log", i)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		case cfg.Log != nil && structured:
+			w = &prefixedLineWriter{mu: &sharedLogMu, w: cfg.Log}
+		case cfg.Log != nil:
+			w = &prefixedLineWriter{mu: &sharedLogMu, w: cfg.Log, prefix: fmt.Sprintf("table %d: ", i)}
+		}
+		logs[i] = newLogger(w, cfg.Logging)
+		if logs[i] != nil && structured {
+			logs[i].attrs = []slog.Attr{slog.Int("table", i)}
+		}
+	}
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Go(func() {
+			t := &table{index: i, endpoint: endpoint, cfg: &cfg, log: logs[i]}
+			defer t.log.close()
+			if err := t.run(ctx); err != nil {
+				_ = t.log.write(LogError, slog.LevelError, "table error: "+err.Error())
+				errs[i] = fmt.Errorf("table %d: %w", i, err)
+			}
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+type table struct {
+	index    int
+	endpoint *mjsonpEndpoint
+	cfg      *LobbyConfig
+	log      *logger
+}
+
+func (t *table) run(ctx context.Context) error {
+	for game := 0; t.cfg.Games == 0 || game < t.cfg.Games; game++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := t.playGame(ctx, game); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *table) playGame(ctx context.Context, game int) error {
+	seed := GameSeed(t.cfg.Seed, t.index, game)
+	agent, err := t.cfg.NewAgent(seed)
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", t.endpoint.address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if logErr := t.log.write(LogError, slog.LevelError, "tcp error: "+err.Error()); logErr != nil {
+			return logErr
+		}
+		return err
+	}
+	if err := t.log.write(LogProtocol, slog.LevelInfo, fmt.Sprintf("connected: game %d, seed %d", game, seed)); err != nil {
+		conn.Close()
+		return err
+	}
+	defer func() {
+		conn.Close()
+		_ = t.log.write(LogProtocol, slog.LevelInfo, "closed")
+	}()
+
+	// A shutdown only closes the connection while the table is still waiting
+	// for start_game; a game in progress is played to the end.
+	var mu sync.Mutex
+	inGame := false
+	disconnected := false
+	stop := context.AfterFunc(ctx, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !inGame {
+			disconnected = true
+			conn.Close()
+		}
+	})
+	defer stop()
+
+	driver := NewDriver(t.cfg.Name, t.endpoint.room, t.cfg.FallbackID, agent, nil)
+	driver.log = t.log
+	driver.options = driverOptions{possibleActions: t.cfg.PossibleActions, meta: t.cfg.Meta, resilient: t.cfg.Resilient, profile: t.cfg.ServerProfile}
+	driver.onStartGame = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		inGame = true
+	}
+	err = runDriver(driver, conn, conn, mjsonpPolicy, nil)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if disconnected {
+		return nil
+	}
+	return err
+}
+
+// prefixedLineWriter writes whole lines to a writer shared between tables, so
+// that lines of different tables do not interleave. The prefix may be empty.
+type prefixedLineWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixedLineWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	i := bytes.LastIndexByte(p.buf, '\n')
+	if i < 0 {
+		return len(b), nil
+	}
+
+	var out []byte
+	for line := range bytes.Lines(p.buf[:i+1]) {
+		out = append(out, p.prefix...)
+		out = append(out, line...)
+	}
+	p.buf = slices.Delete(p.buf, 0, i+1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
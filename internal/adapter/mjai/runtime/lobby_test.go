@@ -0,0 +1,276 @@
+package mjairuntime_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+// serveGame plays a minimal game with a client: hello, start_game, and
+// end_game. beforeEndGame, if not nil, runs after start_game is acknowledged.
+func serveGame(conn net.Conn, beforeEndGame func()) error {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	for _, line := range []string{
+		`{"type":"hello","protocol":"mjsonp","protocol_version":3}`,
+		`{"type":"start_game","id":0,"names":["A","B","C","D"]}`,
+	} {
+		if _, err := fmt.Fprintln(conn, line); err != nil {
+			return err
+		}
+		if _, err := r.ReadString('\n'); err != nil {
+			return err
+		}
+	}
+	if beforeEndGame != nil {
+		beforeEndGame()
+	}
+	_, err := fmt.Fprintln(conn, `{"type":"end_game","scores":[25000,25000,25000,25000]}`)
+	return err
+}
+
+type seedRecorder struct {
+	mu    sync.Mutex
+	seeds []uint64
+}
+
+func (s *seedRecorder) newAgent(seed uint64) (ai.Agent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seeds = append(s.seeds, seed)
+	return ai.NewTsumogiriAgent(), nil
+}
+
+func TestRunLobby_PlaysGamesOnEveryTable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	defer ln.Close()
+
+	const numTables = 3
+	const numGames = 2
+	serverErrs := make(chan error, numTables*numGames)
+	go func() {
+		for range numTables * numGames {
+			conn, err := ln.Accept()
+			if err != nil {
+				serverErrs <- err
+				return
+			}
+			go func() { serverErrs <- serveGame(conn, nil) }()
+		}
+	}()
+
+	url := "mjsonp://" + ln.Addr().String() + "/room"
+	logDir := t.TempDir()
+	seeds := &seedRecorder{}
+	err = mjairuntime.RunLobby(context.Background(), mjairuntime.LobbyConfig{
+		Name:     "tsumogiri",
+		URLs:     []string{url, url, url},
+		Seed:     7,
+		Games:    numGames,
+		NewAgent: seeds.newAgent,
+		LogDir:   logDir,
+	})
+	if err != nil {
+		t.Fatalf("RunLobby() failed: %v", err)
+	}
+	for range numTables * numGames {
+		if err := <-serverErrs; err != nil {
+			t.Fatalf("server failed: %v", err)
+		}
+	}
+
+	if got := len(seeds.seeds); got != numTables*numGames {
+		t.Errorf("NewAgent was called %d times, want %d", got, numTables*numGames)
+	}
+	for table := range numTables {
+		b, err := os.ReadFile(filepath.Join(logDir, fmt.Sprintf("table-%d.log", table)))
+		if err != nil {
+			t.Fatalf("ReadFile() failed: %v", err)
+		}
+		for game := range numGames {
+			want := fmt.Sprintf("connected: game %d, seed %d\n", game, mjairuntime.GameSeed(7, table, game))
+			if !strings.Contains(string(b), want) {
+				t.Errorf("table %d log = %q, want containing %q", table, b, want)
+			}
+		}
+	}
+}
+
+func TestGameSeed_IsDistinctPerGame(t *testing.T) {
+	seen := make(map[uint64]bool)
+	for table := range 8 {
+		for game := range 8 {
+			seed := mjairuntime.GameSeed(0, table, game)
+			if seen[seed] {
+				t.Fatalf("GameSeed(0, %d, %d) = %d is not unique", table, game, seed)
+			}
+			seen[seed] = true
+		}
+	}
+	if mjairuntime.GameSeed(1, 0, 0) == mjairuntime.GameSeed(0, 0, 0) {
+		t.Errorf("GameSeed() does not depend on the base seed")
+	}
+}
+
+func TestRunLobby_ShutdownFinishesGameInProgress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		serverErr <- serveGame(conn, func() {
+			cancel()
+			// Give the lobby time to disconnect if it wrongly ignores the game.
+			time.Sleep(50 * time.Millisecond)
+		})
+
+		// The table must not reconnect after the game.
+		if err := ln.(*net.TCPListener).SetDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+			serverErr <- err
+			return
+		}
+		if conn, err := ln.Accept(); err == nil {
+			conn.Close()
+			serverErr <- fmt.Errorf("table reconnected after shutdown")
+			return
+		}
+		serverErr <- nil
+	}()
+
+	var log strings.Builder
+	err = mjairuntime.RunLobby(ctx, mjairuntime.LobbyConfig{
+		Name:     "tsumogiri",
+		URLs:     []string{"mjsonp://" + ln.Addr().String() + "/room"},
+		NewAgent: func(uint64) (ai.Agent, error) { return ai.NewTsumogiriAgent(), nil },
+		Log:      &log,
+	})
+	if err != nil {
+		t.Fatalf("RunLobby() failed: %v", err)
+	}
+	for range 2 {
+		if err := <-serverErr; err != nil {
+			t.Fatalf("server failed: %v", err)
+		}
+	}
+	if !strings.Contains(log.String(), "table 0: <-\t{\"type\":\"end_game\"") {
+		t.Errorf("log = %q, want the game to reach end_game", log.String())
+	}
+}
+
+func TestRunLobby_ShutdownDisconnectsWaitingTables(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		accepted <- conn
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- mjairuntime.RunLobby(ctx, mjairuntime.LobbyConfig{
+			Name:     "tsumogiri",
+			URLs:     []string{"mjsonp://" + ln.Addr().String() + "/room"},
+			NewAgent: func(uint64) (ai.Agent, error) { return ai.NewTsumogiriAgent(), nil },
+		})
+	}()
+
+	if conn, ok := <-accepted; ok {
+		defer conn.Close()
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("RunLobby() failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunLobby() did not return after shutdown")
+	}
+}
+
+func TestRunLobby_ReturnsTableErrors(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() failed: %v", err)
+	}
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintln(conn, `{"type":"error","message":"room is full"}`)
+	}()
+
+	err = mjairuntime.RunLobby(context.Background(), mjairuntime.LobbyConfig{
+		Name:     "tsumogiri",
+		URLs:     []string{"mjsonp://" + ln.Addr().String() + "/room"},
+		Games:    1,
+		NewAgent: func(uint64) (ai.Agent, error) { return ai.NewTsumogiriAgent(), nil },
+	})
+	if err == nil {
+		t.Fatal("RunLobby() succeeded unexpectedly")
+	}
+	if !strings.Contains(err.Error(), "table 0: server error: room is full") {
+		t.Errorf("error = %q, want the table error", err)
+	}
+}
+
+func TestRunLobby_InvalidConfigIsUsageError(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  mjairuntime.LobbyConfig
+	}{
+		{name: "no URL", cfg: mjairuntime.LobbyConfig{}},
+		{name: "invalid URL", cfg: mjairuntime.LobbyConfig{URLs: []string{"tcp://localhost:11600/room"}}},
+		{name: "negative games", cfg: mjairuntime.LobbyConfig{URLs: []string{"mjsonp://localhost:11600/room"}, Games: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.NewAgent = func(uint64) (ai.Agent, error) { return ai.NewTsumogiriAgent(), nil }
+			err := mjairuntime.RunLobby(context.Background(), tt.cfg)
+			if _, ok := errors.AsType[*mjairuntime.UsageError](err); !ok {
+				t.Errorf("RunLobby() error = %v, want UsageError", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package mjairuntime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogChannel is a stream of the session log with a level of its own.
+type LogChannel string
+
+const (
+	// LogProtocol carries the lines to and from the server and the
+	// connection events.
+	LogProtocol LogChannel = "protocol"
+	// LogBoard carries the board as the bot tracks it.
+	LogBoard LogChannel = "board"
+	// LogDecision carries the decision traces of the agent.
+	LogDecision LogChannel = "decision"
+	// LogError carries incidents, possible_actions mismatches, and
+	// connection errors.
+	LogError LogChannel = "error"
+)
+
+// LogChannels lists every channel.
+var LogChannels = []LogChannel{LogProtocol, LogBoard, LogDecision, LogError}
+
+// LogLevelOff is a level above every record, which turns a channel off.
+const LogLevelOff = slog.Level(math.MaxInt)
+
+// LogFormat is how log records are written.
+type LogFormat string
+
+const (
+	// LogFormatPlain writes the bare lines without levels or timestamps.
+	LogFormatPlain LogFormat = "plain"
+	// LogFormatText writes records with slog.TextHandler.
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes records with slog.JSONHandler.
+	LogFormatJSON LogFormat = "json"
+)
+
+func ParseLogFormat(s string) (LogFormat, error) {
+	switch f := LogFormat(s); f {
+	case LogFormatPlain, LogFormatText, LogFormatJSON:
+		return f, nil
+	default:
+		return "", &UsageError{err: fmt.Errorf("unknown log format %q (want plain, text, or json)", s)}
+	}
+}
+
+// ParseLogLevels parses a comma-separated list of CHANNEL=LEVEL. A LEVEL
+// without a channel applies to every channel, and a later entry overrides an
+// earlier one. LEVEL is debug, info, warn, error, or off.
+func ParseLogLevels(s string) (map[LogChannel]slog.Level, error) {
+	levels := make(map[LogChannel]slog.Level)
+	if s == "" {
+		return levels, nil
+	}
+	for entry := range strings.SplitSeq(s, ",") {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			name, value = "", entry
+		}
+		level := LogLevelOff
+		if value != "off" {
+			if err := level.UnmarshalText([]byte(value)); err != nil {
+				return nil, &UsageError{err: fmt.Errorf("invalid log level %q", entry)}
+			}
+		}
+		if name == "" {
+			for _, channel := range LogChannels {
+				levels[channel] = level
+			}
+			continue
+		}
+		channel := LogChannel(name)
+		if !slices.Contains(LogChannels, channel) {
+			return nil, &UsageError{err: fmt.Errorf("unknown log channel %q (want protocol, board, decision, or error)", name)}
+		}
+		levels[channel] = level
+	}
+	return levels, nil
+}
+
+// LogConfig selects how a session writes its log.
+type LogConfig struct {
+	// Format is how records are written. Empty is LogFormatPlain.
+	Format LogFormat
+	// Levels holds the lowest level each channel writes. A missing channel
+	// writes from info, which is every record but debug ones.
+	Levels map[LogChannel]slog.Level
+	// GameDir receives the log of each game in a file of its own when it is
+	// not empty. The file is named by the room and the time start_game
+	// arrived, and the lines outside games go to the log writer.
+	GameDir string
+}
+
+// logger writes the log of a session to its channels. A nil logger discards
+// everything.
+type logger struct {
+	// w receives the lines outside per-game files.
+	w      io.Writer
+	config LogConfig
+	// attrs are added to every structured record.
+	attrs []slog.Attr
+	now   func() time.Time
+
+	out     io.Writer
+	handler slog.Handler
+	file    *os.File
+}
+
+func newLogger(w io.Writer, config LogConfig) *logger {
+	if w == nil && config.GameDir == "" {
+		return nil
+	}
+	l := &logger{w: w, config: config, now: time.Now}
+	l.setOutput(w)
+	return l
+}
+
+func (l *logger) setOutput(w io.Writer) {
+	l.out = w
+	l.handler = nil
+	if w == nil {
+		return
+	}
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	switch l.config.Format {
+	case LogFormatText:
+		l.handler = slog.NewTextHandler(w, options)
+	case LogFormatJSON:
+		l.handler = slog.NewJSONHandler(w, options)
+	}
+}
+
+func (l *logger) enabled(channel LogChannel, level slog.Level) bool {
+	if l == nil || l.out == nil {
+		return false
+	}
+	lowest, ok := l.config.Levels[channel]
+	if !ok {
+		lowest = slog.LevelInfo
+	}
+	return level >= lowest
+}
+
+// write logs text to channel. Plain logs keep text as is; structured records
+// take its first line as the message and the rest as detail.
+func (l *logger) write(channel LogChannel, level slog.Level, text string) error {
+	if text == "" || !l.enabled(channel, level) {
+		return nil
+	}
+	if l.handler == nil {
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		_, err := io.WriteString(l.out, text)
+		return err
+	}
+	msg, detail, _ := strings.Cut(strings.TrimSuffix(text, "\n"), "\n")
+	var attrs []slog.Attr
+	if detail != "" {
+		attrs = append(attrs, slog.String("detail", detail))
+	}
+	return l.handle(channel, level, msg, attrs...)
+}
+
+// message logs a line to or from the server. direction is "<-" or "->".
+func (l *logger) message(direction string, line []byte) error {
+	if !l.enabled(LogProtocol, slog.LevelInfo) {
+		return nil
+	}
+	if l.handler == nil {
+		_, err := fmt.Fprintf(l.out, "%s\t%s\n", direction, line)
+		return err
+	}
+	return l.handle(LogProtocol, slog.LevelInfo, direction, slog.String("line", string(line)))
+}
+
+func (l *logger) handle(channel LogChannel, level slog.Level, msg string, attrs ...slog.Attr) error {
+	r := slog.NewRecord(l.now(), level, msg, 0)
+	r.AddAttrs(slog.String("channel", string(channel)))
+	r.AddAttrs(l.attrs...)
+	r.AddAttrs(attrs...)
+	return l.handler.Handle(context.Background(), r)
+}
+
+// startGame moves the log to a new file in GameDir, if any.
+func (l *logger) startGame(room string) error {
+	if l == nil || l.config.GameDir == "" {
+		return nil
+	}
+	if err := l.close(); err != nil {
+		return err
+	}
+	f, err := createGameLog(l.config.GameDir, room, l.now())
+	if err != nil {
+		return err
+	}
+	l.file = f
+	l.setOutput(f)
+	return nil
+}
+
+// close closes the file of the current game and moves the log back to the
+// log writer.
+func (l *logger) close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	l.setOutput(l.w)
+	return err
+}
+
+// createGameLog creates ROOM-YYYYMMDD-HHMMSS.log in dir, adding -2, -3 and
+// so on when games of the room start in the same second. ROOM is the room as
+// gameLogRoom makes it safe for a file name.
+func createGameLog(dir string, room string, start time.Time) (*os.File, error) {
+	base := fmt.Sprintf("%s-%s", gameLogRoom(room), start.Format("20060102-150405"))
+	for n := 1; ; n++ {
+		name := base + ".log"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.log", base, n)
+		}
+		f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return f, err
+	}
+}
+
+// gameLogRoom returns the last element of room with every character but
+// ASCII letters, digits, '-', '_', and '.' replaced by '_' and the leading
+// dots removed, or "game" when nothing is left, so that a room cannot place
+// its game logs outside the directory.
+func gameLogRoom(room string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, filepath.Base(room))
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "game"
+	}
+	return name
+}
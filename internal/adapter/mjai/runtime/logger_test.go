@@ -0,0 +1,209 @@
+package mjairuntime
+
+import (
+	"encoding/json/v2"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+func TestLogger_ChannelLevels(t *testing.T) {
+	var out strings.Builder
+	levels, err := ParseLogLevels("warn,board=info,decision=off")
+	if err != nil {
+		t.Fatalf("ParseLogLevels() failed: %v", err)
+	}
+	log := newLogger(&out, LogConfig{Levels: levels})
+
+	for _, err := range []error{
+		log.message("<-", []byte(`{"type":"end_game"}`)),
+		log.write(LogBoard, slog.LevelInfo, "board\n"),
+		log.write(LogDecision, slog.LevelError, "trace\n"),
+		log.write(LogError, slog.LevelWarn, "incident"),
+	} {
+		if err != nil {
+			t.Fatalf("logger failed: %v", err)
+		}
+	}
+
+	if want := "board\nincident\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLogger_JSONRecords(t *testing.T) {
+	var out strings.Builder
+	log := newLogger(&out, LogConfig{Format: LogFormatJSON})
+	log.attrs = []slog.Attr{slog.Int("table", 1)}
+
+	if err := log.message("->", []byte(`{"type":"none"}`)); err != nil {
+		t.Fatalf("message() failed: %v", err)
+	}
+	if err := log.write(LogError, slog.LevelWarn, "incident: agent\n  inbound: line\n"); err != nil {
+		t.Fatalf("write() failed: %v", err)
+	}
+
+	var records []map[string]any
+	for line := range strings.Lines(out.String()) {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Unmarshal(%q) failed: %v", line, err)
+		}
+		if _, ok := record["time"]; !ok {
+			t.Errorf("record = %v, want a time", record)
+		}
+		delete(record, "time")
+		records = append(records, record)
+	}
+	want := []map[string]any{
+		{"level": "INFO", "msg": "->", "channel": "protocol", "table": float64(1), "line": `{"type":"none"}`},
+		{"level": "WARN", "msg": "incident: agent", "channel": "error", "table": float64(1), "detail": "  inbound: line"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records = %v, want %v", records, want)
+	}
+	for i := range want {
+		if len(records[i]) != len(want[i]) {
+			t.Errorf("record %d = %v, want %v", i, records[i], want[i])
+			continue
+		}
+		for k, v := range want[i] {
+			if records[i][k] != v {
+				t.Errorf("record %d = %v, want %v", i, records[i], want[i])
+				break
+			}
+		}
+	}
+}
+
+func TestLogger_GameFiles(t *testing.T) {
+	dir := t.TempDir()
+	game := `{"type":"start_game","id":0}
+{"type":"end_game"}
+`
+	var out strings.Builder
+	var log strings.Builder
+	l := newLogger(&log, LogConfig{GameDir: dir})
+	l.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+
+	in := strings.NewReader(`{"type":"hello","protocol":"mjsonp","protocol_version":3}` + "\n" + game + game)
+	err := runJSONLines("tsumogiri", "room", 0, ai.NewTsumogiriAgent(), in, &out, l, stdioPolicy, nil, driverOptions{})
+	if err != nil {
+		t.Fatalf("runJSONLines() failed: %v", err)
+	}
+	if err := l.close(); err != nil {
+		t.Fatalf("close() failed: %v", err)
+	}
+
+	if !strings.Contains(log.String(), `"type":"hello"`) || strings.Contains(log.String(), "start_game") {
+		t.Errorf("log = %q, want only the lines before the first game", log.String())
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"room-20261019-120000-2.log", "room-20261019-120000.log"}; !slices.Equal(names, want) {
+		t.Fatalf("game logs = %v, want %v", names, want)
+	}
+	for _, name := range names {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile() failed: %v", err)
+		}
+		if want := "<-\t{\"type\":\"start_game\",\"id\":0}\n<-\t{\"type\":\"end_game\"}\n"; string(b) != want {
+			t.Errorf("%s = %q, want %q", name, b, want)
+		}
+	}
+}
+
+func TestLogger_EndGameClosesGameFile(t *testing.T) {
+	dir := t.TempDir()
+	hello := `{"type":"hello","protocol":"mjsonp","protocol_version":3}` + "\n"
+	var out, log strings.Builder
+	l := newLogger(&log, LogConfig{GameDir: dir})
+	l.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+
+	in := strings.NewReader(`{"type":"start_game","id":0}` + "\n" + `{"type":"end_game"}` + "\n" + hello)
+	err := runJSONLines("tsumogiri", "room", 0, ai.NewTsumogiriAgent(), in, &out, l, stdioPolicy, nil, driverOptions{})
+	if err != nil {
+		t.Fatalf("runJSONLines() failed: %v", err)
+	}
+
+	if l.file != nil {
+		t.Errorf("game log %s is still open after end_game", l.file.Name())
+	}
+	if !strings.Contains(log.String(), `"type":"hello"`) {
+		t.Errorf("log = %q, want the line after end_game", log.String())
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "room-20261019-120000.log"))
+	if err != nil {
+		t.Fatalf("ReadFile() failed: %v", err)
+	}
+	if strings.Contains(string(b), "hello") {
+		t.Errorf("game log = %q, want only the game", b)
+	}
+}
+
+func TestCreateGameLog_SanitizesRoom(t *testing.T) {
+	start := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		room string
+		want string
+	}{
+		{"default", "default"},
+		{"../../etc/passwd", "passwd"},
+		{"a/b", "b"},
+		{`a\b:c`, "a_b_c"},
+		{"room one", "room_one"},
+		{"部屋", "__"},
+		{".hidden", "hidden"},
+		{"..", "game"},
+		{"", "game"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		f, err := createGameLog(dir, tt.room, start)
+		if err != nil {
+			t.Errorf("createGameLog(%q) failed: %v", tt.room, err)
+			continue
+		}
+		f.Close()
+		if want := filepath.Join(dir, tt.want+"-20261019-120000.log"); f.Name() != want {
+			t.Errorf("createGameLog(%q) = %s, want %s", tt.room, f.Name(), want)
+		}
+	}
+}
+
+func TestParseLogLevels(t *testing.T) {
+	levels, err := ParseLogLevels("error,protocol=debug,board=off")
+	if err != nil {
+		t.Fatalf("ParseLogLevels() failed: %v", err)
+	}
+	want := map[LogChannel]slog.Level{
+		LogProtocol: slog.LevelDebug,
+		LogBoard:    LogLevelOff,
+		LogDecision: slog.LevelError,
+		LogError:    slog.LevelError,
+	}
+	for channel, level := range want {
+		if levels[channel] != level {
+			t.Errorf("level of %s = %v, want %v", channel, levels[channel], level)
+		}
+	}
+
+	for _, s := range []string{"loud", "table=info", "board=", ","} {
+		if _, err := ParseLogLevels(s); err == nil {
+			t.Errorf("ParseLogLevels(%q) succeeded unexpectedly", s)
+		}
+	}
+}
@@ -2,18 +2,23 @@ package mjairuntime
 
 import (
 	"bufio"
-	"fmt"
-	"io"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
 )
 
-func writeMessageWithTrace(w *bufio.Writer, msg outbound.Message, log io.Writer) error {
+func writeMessageWithTrace(w *bufio.Writer, msg outbound.Message, log *logger, rec *Recorder, profile *ServerProfile) error {
 	b, err := outbound.MarshalMessage(msg)
 	if err != nil {
 		return err
 	}
-	if err := traceLine(log, "->", b); err != nil {
+	b, err = profile.encodeLine(b)
+	if err != nil {
+		return err
+	}
+	if err := log.message("->", b); err != nil {
+		return err
+	}
+	if err := rec.recordOutbound(b); err != nil {
 		return err
 	}
 	if _, err := w.Write(b); err != nil {
@@ -24,19 +29,3 @@ func writeMessageWithTrace(w *bufio.Writer, msg outbound.Message, log io.Writer)
 	}
 	return w.Flush()
 }
-
-func traceLine(log io.Writer, direction string, line []byte) error {
-	if log == nil {
-		return nil
-	}
-	_, err := fmt.Fprintf(log, "%s\t%s\n", direction, string(line))
-	return err
-}
-
-func logLine(log io.Writer, line string) error {
-	if log == nil {
-		return nil
-	}
-	_, err := fmt.Fprintln(log, line)
-	return err
-}
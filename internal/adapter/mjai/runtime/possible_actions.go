@@ -0,0 +1,297 @@
+package mjairuntime
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/application"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// PossibleActionsMode selects what the driver does with the possible_actions
+// a server attaches to messages. The bot always derives its legal actions
+// from the game state; possible_actions are only cross-checked against them.
+type PossibleActionsMode int
+
+const (
+	// PossibleActionsOff ignores possible_actions.
+	PossibleActionsOff PossibleActionsMode = iota
+	// PossibleActionsWarn reports every discrepancy and plays on.
+	PossibleActionsWarn
+	// PossibleActionsRestrict reports every discrepancy and lets the agent
+	// choose only among the actions both sides agree on.
+	PossibleActionsRestrict
+	// PossibleActionsStrict reports a discrepancy and stops the session.
+	PossibleActionsStrict
+)
+
+var possibleActionsModeNames = [...]string{"off", "warn", "restrict", "strict"}
+
+func (m PossibleActionsMode) String() string {
+	if m < 0 || int(m) >= len(possibleActionsModeNames) {
+		return fmt.Sprintf("PossibleActionsMode(%d)", int(m))
+	}
+	return possibleActionsModeNames[m]
+}
+
+// ParsePossibleActionsMode parses the name of a mode as String returns it.
+func ParsePossibleActionsMode(s string) (PossibleActionsMode, error) {
+	i := slices.Index(possibleActionsModeNames[:], s)
+	if i < 0 {
+		return 0, &UsageError{err: fmt.Errorf("unknown possible_actions mode %q (want one of %s)", s, strings.Join(possibleActionsModeNames[:], ", "))}
+	}
+	return PossibleActionsMode(i), nil
+}
+
+// isCheckedAction reports whether an action of the mjai type typ is
+// compared. Servers differ in whether they list discards and passes, so only
+// calls, riichi, wins, and abortive draws are compared.
+func isCheckedAction(typ string) bool {
+	return typ != "dahai" && typ != "none"
+}
+
+// possibleActionKey returns the mjai JSON of a with the consumed tiles sorted
+// and only the fields every server sends for its type, so that the same
+// action has the same key on both sides. Servers differ in whether a hora has
+// target and pai and whether a ryukyoku has a reason, and the type and actor
+// alone tell those apart among the actions offered at once.
+func possibleActionKey(a inbound.PossibleAction) (string, error) {
+	switch a.Type {
+	case "hora", "ryukyoku":
+		a = inbound.PossibleAction{Type: a.Type, Actor: a.Actor}
+	default:
+		a.Consumed = slices.Sorted(slices.Values(a.Consumed))
+	}
+	return marshalPossibleAction(a)
+}
+
+func marshalPossibleAction(a inbound.PossibleAction) (string, error) {
+	b, err := json.Marshal(a, json.Deterministic(true))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// derivedAction returns a domain action as the bot would send it, or false
+// when actions of its type are not compared.
+func derivedAction(a action.Action) (inbound.PossibleAction, bool, error) {
+	msg, err := outbound.ToMessage(a, "")
+	if err != nil {
+		return inbound.PossibleAction{}, false, err
+	}
+	b, err := outbound.MarshalMessage(msg)
+	if err != nil {
+		return inbound.PossibleAction{}, false, err
+	}
+	var pa inbound.PossibleAction
+	if err := json.Unmarshal(b, &pa); err != nil {
+		return inbound.PossibleAction{}, false, err
+	}
+	return pa, isCheckedAction(pa.Type), nil
+}
+
+// derivedActionKey returns the key of a domain action, or an empty string
+// when actions of its type are not compared.
+func derivedActionKey(a action.Action) (string, error) {
+	pa, checked, err := derivedAction(a)
+	if err != nil || !checked {
+		return "", err
+	}
+	return possibleActionKey(pa)
+}
+
+// possibleActionsCheck is the result of comparing the legal actions the bot
+// derived with the possible_actions of the server.
+type possibleActionsCheck struct {
+	// agreed holds the keys both sides list.
+	agreed map[string]bool
+	// onlyServer and onlyDerived hold the actions only one side lists, as
+	// that side sent them, sorted.
+	onlyServer  []string
+	onlyDerived []string
+}
+
+func (c *possibleActionsCheck) ok() bool {
+	return len(c.onlyServer) == 0 && len(c.onlyDerived) == 0
+}
+
+func checkPossibleActions(possible []inbound.PossibleAction, legalActions []action.Action) (*possibleActionsCheck, error) {
+	// server and derived map the keys to the actions as each side sent them,
+	// which the report shows.
+	server := map[string]string{}
+	for _, a := range possible {
+		if !isCheckedAction(a.Type) {
+			continue
+		}
+		if err := addPossibleAction(server, a); err != nil {
+			return nil, err
+		}
+	}
+	derived := map[string]string{}
+	for _, a := range legalActions {
+		pa, checked, err := derivedAction(a)
+		if err != nil {
+			return nil, err
+		}
+		if !checked {
+			continue
+		}
+		if err := addPossibleAction(derived, pa); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &possibleActionsCheck{agreed: map[string]bool{}}
+	for key, sent := range server {
+		if _, ok := derived[key]; ok {
+			c.agreed[key] = true
+		} else {
+			c.onlyServer = append(c.onlyServer, sent)
+		}
+	}
+	for key, sent := range derived {
+		if _, ok := server[key]; !ok {
+			c.onlyDerived = append(c.onlyDerived, sent)
+		}
+	}
+	slices.Sort(c.onlyServer)
+	slices.Sort(c.onlyDerived)
+	return c, nil
+}
+
+func addPossibleAction(actions map[string]string, a inbound.PossibleAction) error {
+	key, err := possibleActionKey(a)
+	if err != nil {
+		return err
+	}
+	sent, err := marshalPossibleAction(a)
+	if err != nil {
+		return err
+	}
+	actions[key] = sent
+	return nil
+}
+
+// allows reports whether the agent may choose a when it is restricted to the
+// actions both sides agree on. Actions that are not compared are allowed.
+func (c *possibleActionsCheck) allows(a action.Action) bool {
+	key, err := derivedActionKey(a)
+	if err != nil || key == "" {
+		return err == nil
+	}
+	return c.agreed[key]
+}
+
+// report returns the discrepancies with the board.
+func (c *possibleActionsCheck) report(board string) string {
+	var sb strings.Builder
+	sb.WriteString("possible_actions mismatch\n")
+	for _, key := range c.onlyServer {
+		fmt.Fprintf(&sb, "  only in possible_actions: %s\n", key)
+	}
+	for _, key := range c.onlyDerived {
+		fmt.Fprintf(&sb, "  only in derived actions: %s\n", key)
+	}
+	sb.WriteString(board)
+	return sb.String()
+}
+
+// processWithPossibleActions is Bot.Process for an event whose message
+// carries possible_actions.
+func (d *Driver) processWithPossibleActions(ev event.Event, possible []inbound.PossibleAction) (application.Reaction, error) {
+	if err := d.bot.Observe(ev); err != nil {
+		return application.Reaction{}, err
+	}
+	if d.bot.Diverged() {
+		return d.processDiverged(possible)
+	}
+	allowed, err := d.crossCheckPossibleActions(possible)
+	if err != nil {
+		return application.Reaction{}, err
+	}
+	decision, ok, err := d.bot.DecideAmong(allowed)
+	if err != nil {
+		return application.Reaction{}, err
+	}
+	if !ok {
+		return application.NewNoReaction(), nil
+	}
+	return application.NewDecisionReaction(decision), nil
+}
+
+// crossCheckPossibleActions compares possible with the legal actions of the
+// bot as the mode says, and returns the actions the agent may choose among,
+// nil for every legal action.
+func (d *Driver) crossCheckPossibleActions(possible []inbound.PossibleAction) (func(action.Action) bool, error) {
+	if d.options.possibleActions == PossibleActionsOff {
+		return nil, nil
+	}
+	legalActions, err := d.bot.LegalActions()
+	if err != nil {
+		return nil, err
+	}
+	c, err := checkPossibleActions(possible, legalActions)
+	if err != nil {
+		return nil, err
+	}
+	if c.ok() {
+		return nil, nil
+	}
+	if err := d.log.write(LogError, slog.LevelWarn, c.report(d.bot.RenderBoard())); err != nil {
+		return nil, err
+	}
+	switch d.options.possibleActions {
+	case PossibleActionsStrict:
+		return nil, fmt.Errorf("possible_actions mismatch: %d only in possible_actions, %d only in derived actions", len(c.onlyServer), len(c.onlyDerived))
+	case PossibleActionsRestrict:
+		return c.allows, nil
+	default:
+		return nil, nil
+	}
+}
+
+// processDiverged acts for a resilient bot that has stopped tracking the
+// round. When the bot does not know what to do, a discard the server lists
+// keeps the game going, such as the discard after the bot declared riichi.
+func (d *Driver) processDiverged(possible []inbound.PossibleAction) (application.Reaction, error) {
+	decision, ok, err := d.bot.Decide()
+	if err != nil {
+		return application.Reaction{}, err
+	}
+	if ok {
+		return application.NewDecisionReaction(decision), nil
+	}
+	for _, a := range possible {
+		if a.Type != "dahai" || a.Actor != d.bot.Self().Index() {
+			continue
+		}
+		discard, err := possibleDiscard(a)
+		if err != nil {
+			continue
+		}
+		return application.NewActionReaction(discard, ""), nil
+	}
+	return application.NewNoReaction(), nil
+}
+
+// possibleDiscard returns the discard of a dahai the server lists.
+func possibleDiscard(a inbound.PossibleAction) (*action.Discard, error) {
+	actor, err := seat.NewSeat(a.Actor)
+	if err != nil {
+		return nil, err
+	}
+	t, err := tile.NewTileFromCode(a.Pai)
+	if err != nil {
+		return nil, err
+	}
+	return action.NewDiscard(actor, t, false)
+}
@@ -0,0 +1,229 @@
+package mjairuntime_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+)
+
+// eagerAgent takes the first legal action other than a discard or a pass.
+type eagerAgent struct{}
+
+func (eagerAgent) Reset() {}
+
+func (eagerAgent) Decide(request ai.Request) (ai.Decision, error) {
+	legalActions, err := request.Round.LegalActions(request.Self)
+	if err != nil {
+		return ai.Decision{}, err
+	}
+	for _, a := range legalActions {
+		switch a.(type) {
+		case *action.Discard, *action.Pass:
+			continue
+		}
+		return ai.Decision{Action: a}, nil
+	}
+	return ai.Decision{Action: legalActions[0]}, nil
+}
+
+// possibleActionsSession deals seat 3 a hand that wins on 4p and draws it.
+func possibleActionsSession(possibleActions string) string {
+	return possibleActionsSessionWith(`["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","4p"]`, "4p", possibleActions)
+}
+
+// possibleActionsSessionWith deals seat 3 hand and draws tile on its first
+// turn.
+func possibleActionsSessionWith(hand string, tile string, possibleActions string) string {
+	return `{"type":"start_game","id":3,"names":["A","B","C","D"]}` + "\n" +
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[` +
+		`["?","?","?","?","?","?","?","?","?","?","?","?","?"],` +
+		`["?","?","?","?","?","?","?","?","?","?","?","?","?"],` +
+		`["?","?","?","?","?","?","?","?","?","?","?","?","?"],` +
+		hand + `],"scores":[25000,25000,25000,25000]}` + "\n" +
+		`{"type":"tsumo","actor":0,"pai":"?"}` + "\n" +
+		`{"type":"dahai","actor":0,"pai":"1s","tsumogiri":true}` + "\n" +
+		`{"type":"tsumo","actor":1,"pai":"?"}` + "\n" +
+		`{"type":"dahai","actor":1,"pai":"2s","tsumogiri":true}` + "\n" +
+		`{"type":"tsumo","actor":2,"pai":"?"}` + "\n" +
+		`{"type":"dahai","actor":2,"pai":"3s","tsumogiri":true}` + "\n" +
+		`{"type":"tsumo","actor":3,"pai":"` + tile + `","possible_actions":` + possibleActions + `}` + "\n"
+}
+
+const (
+	possibleHora  = `{"type":"hora","actor":3,"target":3,"pai":"4p"}`
+	possibleReach = `{"type":"reach","actor":3}`
+)
+
+func TestRunStdio_PossibleActions(t *testing.T) {
+	tests := []struct {
+		name            string
+		mode            mjairuntime.PossibleActionsMode
+		possibleActions string
+		wantOut         string
+		wantReport      []string
+		wantErr         bool
+	}{
+		{
+			name:            "off ignores a mismatch",
+			mode:            mjairuntime.PossibleActionsOff,
+			possibleActions: `[]`,
+			wantOut:         `{"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+		},
+		{
+			name:            "warn is silent when both sides agree",
+			mode:            mjairuntime.PossibleActionsWarn,
+			possibleActions: `[` + possibleReach + `,` + possibleHora + `]`,
+			wantOut:         `{"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+		},
+		{
+			name:            "warn reports a mismatch and plays on",
+			mode:            mjairuntime.PossibleActionsWarn,
+			possibleActions: `[` + possibleReach + `,{"type":"ankan","actor":3,"consumed":["1m","1m","1m","1m"]}]`,
+			wantOut:         `{"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+			wantReport: []string{
+				`only in possible_actions: {"type":"ankan","actor":3,"consumed":["1m","1m","1m","1m"]}`,
+				`only in derived actions: {"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+			},
+		},
+		{
+			name:            "restrict leaves out what the server does not list",
+			mode:            mjairuntime.PossibleActionsRestrict,
+			possibleActions: `[` + possibleReach + `]`,
+			wantOut:         `{"type":"reach","actor":3}`,
+			wantReport: []string{
+				`only in derived actions: {"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+			},
+		},
+		{
+			name:            "discards are not compared",
+			mode:            mjairuntime.PossibleActionsStrict,
+			possibleActions: `[` + possibleHora + `,{"type":"dahai","actor":3,"pai":"9s","tsumogiri":false},` + possibleReach + `]`,
+			wantOut:         `{"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+		},
+		{
+			name:            "a hora without target and pai matches",
+			mode:            mjairuntime.PossibleActionsStrict,
+			possibleActions: `[{"type":"hora","actor":3},` + possibleReach + `]`,
+			wantOut:         `{"type":"hora","actor":3,"target":3,"pai":"4p"}`,
+		},
+		{
+			name:            "strict stops on a mismatch",
+			mode:            mjairuntime.PossibleActionsStrict,
+			possibleActions: `[` + possibleHora + `]`,
+			wantReport: []string{
+				`only in derived actions: {"type":"reach","actor":3}`,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, log strings.Builder
+			err := mjairuntime.RunStdio(mjairuntime.StdioConfig{
+				Name:            "eager",
+				Room:            "default",
+				Agent:           eagerAgent{},
+				In:              strings.NewReader(possibleActionsSession(tt.possibleActions)),
+				Out:             &out,
+				Log:             &log,
+				PossibleActions: tt.mode,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunStdio() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && strings.TrimSpace(out.String()) != tt.wantOut {
+				t.Errorf("output = %q, want %q", out.String(), tt.wantOut)
+			}
+
+			got := log.String()
+			if len(tt.wantReport) == 0 {
+				if strings.Contains(got, "possible_actions mismatch") {
+					t.Errorf("log reports a mismatch:\n%s", got)
+				}
+				return
+			}
+			_, report, ok := strings.Cut(got, "possible_actions mismatch\n")
+			if !ok {
+				t.Fatalf("log does not report a mismatch:\n%s", got)
+			}
+			for _, line := range tt.wantReport {
+				if !strings.Contains(report, line) {
+					t.Errorf("report does not contain %q:\n%s", line, report)
+				}
+			}
+			if !strings.Contains(report, "E-1 kyoku 0 honba") {
+				t.Errorf("report does not show the board:\n%s", report)
+			}
+		})
+	}
+}
+
+func TestRunStdio_PossibleActionsKyushukyuhaiWithoutReason(t *testing.T) {
+	var out, log strings.Builder
+	err := mjairuntime.RunStdio(mjairuntime.StdioConfig{
+		Name:  "eager",
+		Room:  "default",
+		Agent: eagerAgent{},
+		In: strings.NewReader(possibleActionsSessionWith(
+			`["1m","9m","1p","9p","1s","9s","E","S","W","N","2m","3m","4m"]`, "P",
+			`[{"type":"ryukyoku","actor":3}]`,
+		)),
+		Out:             &out,
+		Log:             &log,
+		PossibleActions: mjairuntime.PossibleActionsStrict,
+	})
+	if err != nil {
+		t.Fatalf("RunStdio() failed: %v\n%s", err, log.String())
+	}
+	if want := `{"type":"ryukyoku","reason":"kyushukyuhai","actor":3}`; strings.TrimSpace(out.String()) != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestParsePossibleActionsMode(t *testing.T) {
+	for _, mode := range []mjairuntime.PossibleActionsMode{
+		mjairuntime.PossibleActionsOff,
+		mjairuntime.PossibleActionsWarn,
+		mjairuntime.PossibleActionsRestrict,
+		mjairuntime.PossibleActionsStrict,
+	} {
+		got, err := mjairuntime.ParsePossibleActionsMode(mode.String())
+		if err != nil {
+			t.Fatalf("ParsePossibleActionsMode(%q) failed: %v", mode, err)
+		}
+		if got != mode {
+			t.Errorf("ParsePossibleActionsMode(%q) = %v, want %v", mode, got, mode)
+		}
+	}
+
+	_, err := mjairuntime.ParsePossibleActionsMode("loose")
+	if _, ok := errors.AsType[*mjairuntime.UsageError](err); !ok {
+		t.Errorf("ParsePossibleActionsMode(loose) error = %v, want a usage error", err)
+	}
+}
+
+func TestRunStdio_DivergedBotActsOnPossibleActionsWhenOff(t *testing.T) {
+	var out, log strings.Builder
+	err := mjairuntime.RunStdio(mjairuntime.StdioConfig{
+		Name:  "tsumogiri",
+		Room:  "default",
+		Agent: ai.NewTsumogiriAgent(),
+		In: strings.NewReader(resilientGame(
+			`{"type":"dahai","actor":0,`,
+			`{"type":"pon","actor":3,"target":0,"pai":"1s","consumed":["1s","1s"],"possible_actions":[{"type":"dahai","actor":3,"pai":"4p","tsumogiri":false}]}`,
+		)),
+		Out:       &out,
+		Log:       &log,
+		Resilient: true,
+	})
+	if err != nil {
+		t.Fatalf("RunStdio() failed: %v\n%s", err, log.String())
+	}
+	if want := `{"type":"dahai","actor":3,"pai":"4p","tsumogiri":false}`; !strings.Contains(out.String(), want) {
+		t.Errorf("output = %q, want %s", out.String(), want)
+	}
+}
@@ -0,0 +1,375 @@
+package mjairuntime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+const recordingVersion = 1
+
+const (
+	transportStdio  = "stdio"
+	transportMjsonp = "mjsonp"
+)
+
+// RecordingHeader is the first line of a session recording. The caller fills
+// the agent settings; the runtime fills the connection settings when the
+// session starts.
+type RecordingHeader struct {
+	Type       string `json:"type"`
+	Version    int    `json:"version"`
+	Transport  string `json:"transport"`
+	Name       string `json:"name"`
+	Room       string `json:"room"`
+	FallbackID int    `json:"fallback_id"`
+	Seed       uint64 `json:"seed"`
+	Agent      string `json:"agent"`
+	// Configs maps embedded configuration file names to their fingerprints.
+	Configs map[string]string `json:"configs,omitzero"`
+	// DefenseTurns is the number of turns discards are planned over for
+	// defense, 0 when it is off.
+	DefenseTurns int `json:"defense_turns,omitzero"`
+	// PossibleActions is the possible_actions mode, empty when it is off.
+	PossibleActions string `json:"possible_actions,omitzero"`
+	// Meta tells whether action messages carry meta.
+	Meta bool `json:"meta,omitzero"`
+	// Resilient tells whether the session played through errors.
+	Resilient bool `json:"resilient,omitzero"`
+	// ServerProfile is the dialect of the server, absent when any was
+	// accepted.
+	ServerProfile *ServerProfile `json:"server_profile,omitzero"`
+}
+
+// recordEntry is a line of a session recording after the header. Type is
+// "in" and "out" for protocol lines, "trace" for decision traces,
+// "incident" for errors a resilient session played through, and "error" for
+// the error that ended the session.
+type recordEntry struct {
+	Type string `json:"type"`
+	Line string `json:"line,omitzero"`
+	Text string `json:"text,omitzero"`
+}
+
+// Recorder writes a session recording in JSON Lines.
+type Recorder struct {
+	w      *bufio.Writer
+	header RecordingHeader
+}
+
+func NewRecorder(w io.Writer, header RecordingHeader) *Recorder {
+	return &Recorder{w: bufio.NewWriter(w), header: header}
+}
+
+func (r *Recorder) start(transport string, name string, room string, fallbackID int, options driverOptions) error {
+	if r == nil {
+		return nil
+	}
+	r.header.Type = "session"
+	r.header.Version = recordingVersion
+	r.header.Transport = transport
+	r.header.Name = name
+	r.header.Room = room
+	r.header.FallbackID = fallbackID
+	r.header.PossibleActions = ""
+	if options.possibleActions != PossibleActionsOff {
+		r.header.PossibleActions = options.possibleActions.String()
+	}
+	r.header.Meta = options.meta
+	r.header.Resilient = options.resilient
+	r.header.ServerProfile = options.profile
+	return r.write(&r.header)
+}
+
+func (r *Recorder) recordInbound(line []byte) error {
+	if r == nil {
+		return nil
+	}
+	return r.write(&recordEntry{Type: "in", Line: string(line)})
+}
+
+func (r *Recorder) recordOutbound(line []byte) error {
+	if r == nil {
+		return nil
+	}
+	return r.write(&recordEntry{Type: "out", Line: string(line)})
+}
+
+func (r *Recorder) recordTrace(trace string) error {
+	if r == nil || trace == "" {
+		return nil
+	}
+	return r.write(&recordEntry{Type: "trace", Text: trace})
+}
+
+func (r *Recorder) recordIncident(text string) error {
+	if r == nil {
+		return nil
+	}
+	return r.write(&recordEntry{Type: "incident", Text: text})
+}
+
+func (r *Recorder) recordError(err error) error {
+	if r == nil {
+		return nil
+	}
+	return r.write(&recordEntry{Type: "error", Text: err.Error()})
+}
+
+// write flushes every line so that the recording survives a crash.
+func (r *Recorder) write(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := r.w.Write(b); err != nil {
+		return err
+	}
+	if err := r.w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return r.w.Flush()
+}
+
+// Recording is a parsed session recording.
+type Recording struct {
+	Header  RecordingHeader
+	entries []recordEntry
+}
+
+func ReadRecording(r io.Reader) (*Recording, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("recording is empty")
+	}
+	rec := &Recording{}
+	if err := json.Unmarshal(scanner.Bytes(), &rec.Header); err != nil {
+		return nil, fmt.Errorf("invalid recording header: %w", err)
+	}
+	if rec.Header.Type != "session" {
+		return nil, fmt.Errorf("invalid recording header type: %q", rec.Header.Type)
+	}
+	if rec.Header.Version != recordingVersion {
+		return nil, fmt.Errorf("unsupported recording version: %d", rec.Header.Version)
+	}
+	if p := rec.Header.ServerProfile; p != nil {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid recording header: %w", err)
+		}
+	}
+	if _, err := policyOfTransport(rec.Header.Transport); err != nil {
+		return nil, err
+	}
+
+	for lineNumber := 2; scanner.Scan(); lineNumber++ {
+		var entry recordEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid recording line %d: %w", lineNumber, err)
+		}
+		switch entry.Type {
+		case "in", "out", "trace", "incident", "error":
+		default:
+			return nil, fmt.Errorf("invalid recording line %d: unknown type %q", lineNumber, entry.Type)
+		}
+		rec.entries = append(rec.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func policyOfTransport(transport string) (jsonLinesPolicy, error) {
+	switch transport {
+	case transportStdio:
+		return jsonLinesPolicy{}, nil
+	case transportMjsonp:
+		return jsonLinesPolicy{respondNoneOnNoReaction: true, stopOnEndGame: true}, nil
+	default:
+		return jsonLinesPolicy{}, fmt.Errorf("unknown recording transport: %q", transport)
+	}
+}
+
+// Divergence is a difference between a recording and its replay.
+type Divergence struct {
+	// Step is the 1-based index of the inbound line after which the outputs
+	// differ. It is 0 for outputs before the first inbound line.
+	Step    int
+	Inbound string
+	Want    []string
+	Got     []string
+}
+
+func (d *Divergence) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "step %d: <- %s\n", d.Step, d.Inbound)
+	for _, line := range d.Want {
+		fmt.Fprintf(&sb, "-\t%s\n", strings.TrimSuffix(line, "\n"))
+	}
+	for _, line := range d.Got {
+		fmt.Fprintf(&sb, "+\t%s\n", strings.TrimSuffix(line, "\n"))
+	}
+	return sb.String()
+}
+
+// NumInbound returns the number of recorded inbound lines.
+func (r *Recording) NumInbound() int {
+	n := 0
+	for _, entry := range r.entries {
+		if entry.Type == "in" {
+			n++
+		}
+	}
+	return n
+}
+
+// Step is a recorded inbound line with what the session wrote in response.
+type Step struct {
+	Inbound string
+	// Outbound holds the lines sent in response.
+	Outbound []string
+	// Traces holds the decision traces.
+	Traces []string
+}
+
+// Steps returns the recorded inbound lines in order with their responses.
+// Outputs before the first inbound line are left out.
+func (r *Recording) Steps() []Step {
+	var steps []Step
+	for _, entry := range r.entries {
+		if entry.Type == "in" {
+			steps = append(steps, Step{Inbound: entry.Line})
+			continue
+		}
+		if len(steps) == 0 {
+			continue
+		}
+		step := &steps[len(steps)-1]
+		switch entry.Type {
+		case "out":
+			step.Outbound = append(step.Outbound, entry.Line)
+		case "trace":
+			step.Traces = append(step.Traces, entry.Text)
+		}
+	}
+	return steps
+}
+
+// Replay feeds the recorded inbound lines to a new Driver with agent, records
+// the replay the same way, and returns the steps whose outputs, traces, or
+// errors differ. The agent must be built with the recorded seed and
+// configuration for the replay to be deterministic.
+func (r *Recording) Replay(agent ai.Agent, log io.Writer) ([]Divergence, error) {
+	policy, err := policyOfTransport(r.Header.Transport)
+	if err != nil {
+		return nil, err
+	}
+	options := driverOptions{meta: r.Header.Meta, resilient: r.Header.Resilient, profile: r.Header.ServerProfile}
+	if r.Header.PossibleActions != "" {
+		options.possibleActions, err = ParsePossibleActionsMode(r.Header.PossibleActions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	rec := NewRecorder(&buf, r.Header)
+	driver := NewDriver(r.Header.Name, r.Header.Room, r.Header.FallbackID, agent, log)
+	driver.recorder = rec
+	driver.options = options
+	if err := rec.start(r.Header.Transport, r.Header.Name, r.Header.Room, r.Header.FallbackID, options); err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(io.Discard)
+	for _, entry := range r.entries {
+		if entry.Type != "in" {
+			continue
+		}
+		stop, err := handleJSONLine([]byte(entry.Line), w, driver, policy, rec)
+		if err != nil {
+			if err := rec.recordError(err); err != nil {
+				return nil, err
+			}
+			break
+		}
+		if stop {
+			break
+		}
+	}
+
+	replayed, err := ReadRecording(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return diffSteps(splitSteps(r.entries), splitSteps(replayed.entries)), nil
+}
+
+type recordStep struct {
+	inbound string
+	outputs []string
+}
+
+// splitSteps groups the outputs of a recording by the inbound line that
+// caused them.
+func splitSteps(entries []recordEntry) []recordStep {
+	steps := []recordStep{{}}
+	for _, entry := range entries {
+		switch entry.Type {
+		case "in":
+			steps = append(steps, recordStep{inbound: entry.Line})
+		case "out":
+			steps[len(steps)-1].outputs = append(steps[len(steps)-1].outputs, "-> "+entry.Line)
+		default:
+			steps[len(steps)-1].outputs = append(steps[len(steps)-1].outputs, entry.Type+": "+entry.Text)
+		}
+	}
+	return steps
+}
+
+// evalTimePattern matches the evaluation times in meta, which differ between
+// runs.
+var evalTimePattern = regexp.MustCompile(`"eval_time_(ms|ns)":[-+.0-9eE]+`)
+
+func equalOutputs(want string, got string) bool {
+	return evalTimePattern.ReplaceAllLiteralString(want, "") == evalTimePattern.ReplaceAllLiteralString(got, "")
+}
+
+func diffSteps(want []recordStep, got []recordStep) []Divergence {
+	var divergences []Divergence
+	for i := range max(len(want), len(got)) {
+		var w, g recordStep
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w.inbound == g.inbound && slices.EqualFunc(w.outputs, g.outputs, equalOutputs) {
+			continue
+		}
+		inbound := w.inbound
+		if inbound == "" {
+			inbound = g.inbound
+		}
+		divergences = append(divergences, Divergence{
+			Step:    i,
+			Inbound: inbound,
+			Want:    w.outputs,
+			Got:     g.outputs,
+		})
+	}
+	return divergences
+}
@@ -0,0 +1,346 @@
+package mjairuntime
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+func recordStdioSessionForTest(t *testing.T, input string, agent ai.Agent) *Recording {
+	t.Helper()
+
+	var recording bytes.Buffer
+	var out bytes.Buffer
+	// The session error, if any, is part of the recording.
+	_ = RunStdio(StdioConfig{
+		Name:       "Manue",
+		Room:       "default",
+		FallbackID: 0,
+		Agent:      agent,
+		In:         strings.NewReader(input),
+		Out:        &out,
+		Recorder:   NewRecorder(&recording, RecordingHeader{Seed: 42, Agent: "test"}),
+	})
+
+	rec, err := ReadRecording(&recording)
+	if err != nil {
+		t.Fatalf("ReadRecording() failed: %v\n%s", err, recording.String())
+	}
+	return rec
+}
+
+func TestRecording_ReplayWithoutDivergence(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		newAgent func(t *testing.T) ai.Agent
+	}{
+		{
+			name:     "tsumogiri",
+			input:    "testdata/tsumogiri/self_draw.input.mjson",
+			newAgent: func(*testing.T) ai.Agent { return ai.NewTsumogiriAgent() },
+		},
+		{
+			name:     "manue",
+			input:    "testdata/manue/chiihou.input.mjson",
+			newAgent: newManueAgentForGoldenTest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := recordStdioSessionForTest(t, readGoldenFile(t, tt.input), tt.newAgent(t))
+
+			if rec.Header.Transport != "stdio" || rec.Header.Seed != 42 || rec.Header.Agent != "test" {
+				t.Errorf("Header = %+v, want stdio transport, seed 42 and agent test", rec.Header)
+			}
+			if got := rec.NumInbound(); got != 10 {
+				t.Errorf("NumInbound() = %d, want 10", got)
+			}
+
+			divergences, err := rec.Replay(tt.newAgent(t), nil)
+			if err != nil {
+				t.Fatalf("Replay() failed: %v", err)
+			}
+			for _, d := range divergences {
+				t.Errorf("unexpected divergence:\n%s", d.String())
+			}
+		})
+	}
+}
+
+func TestRecording_RecordsTraces(t *testing.T) {
+	rec := recordStdioSessionForTest(t, readGoldenFile(t, "testdata/manue/double_riichi.input.mjson"), newManueAgentForGoldenTest(t))
+
+	for _, entry := range rec.entries {
+		if entry.Type == "trace" && entry.Text != "" {
+			return
+		}
+	}
+	t.Errorf("recording has no trace entries: %+v", rec.entries)
+}
+
+func TestRecording_StepsGroupOutputsByInbound(t *testing.T) {
+	rec := recordStdioSessionForTest(t, readGoldenFile(t, "testdata/manue/double_riichi.input.mjson"), newManueAgentForGoldenTest(t))
+
+	steps := rec.Steps()
+	if len(steps) != rec.NumInbound() {
+		t.Fatalf("len(Steps()) = %d, want %d", len(steps), rec.NumInbound())
+	}
+	numOutbound := 0
+	for _, step := range steps {
+		numOutbound += len(step.Outbound)
+		if len(step.Traces) > 0 && len(step.Outbound) == 0 {
+			t.Errorf("step %s has traces without an action", step.Inbound)
+		}
+	}
+	if numOutbound == 0 {
+		t.Errorf("Steps() = %+v, want outbound lines", steps)
+	}
+}
+
+func TestRecording_ReplayReportsDivergence(t *testing.T) {
+	rec := recordStdioSessionForTest(t, readGoldenFile(t, "testdata/tsumogiri/self_draw.input.mjson"), ai.NewTsumogiriAgent())
+
+	step := 0
+	for i, entry := range rec.entries {
+		if entry.Type == "in" {
+			step++
+		}
+		if entry.Type == "out" {
+			rec.entries[i].Line = `{"type":"none"}`
+			break
+		}
+	}
+
+	divergences, err := rec.Replay(ai.NewTsumogiriAgent(), nil)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if len(divergences) != 1 {
+		t.Fatalf("len(Replay()) = %d, want 1: %+v", len(divergences), divergences)
+	}
+	d := divergences[0]
+	if d.Step != step {
+		t.Errorf("Step = %d, want %d", d.Step, step)
+	}
+	if len(d.Want) != 1 || d.Want[0] != `-> {"type":"none"}` {
+		t.Errorf("Want = %q, want the tampered line", d.Want)
+	}
+	if len(d.Got) != 1 || !strings.Contains(d.Got[0], `"type":"dahai"`) {
+		t.Errorf("Got = %q, want a dahai", d.Got)
+	}
+}
+
+func TestRecording_ReplayReproducesError(t *testing.T) {
+	input := `{"type":"start_game","id":0}` + "\n" + `{"type":"tsumo","actor":0,"pai":"1m"}` + "\n"
+	rec := recordStdioSessionForTest(t, input, ai.NewTsumogiriAgent())
+
+	last := rec.entries[len(rec.entries)-1]
+	if last.Type != "error" {
+		t.Fatalf("last entry = %+v, want an error", last)
+	}
+
+	divergences, err := rec.Replay(ai.NewTsumogiriAgent(), nil)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if len(divergences) != 0 {
+		t.Errorf("Replay() = %+v, want no divergences", divergences)
+	}
+}
+
+func TestReadRecording_ReturnsErrorOnInvalidHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "empty", input: "", wantErr: "recording is empty"},
+		{name: "not a header", input: `{"type":"in","line":"{}"}`, wantErr: "invalid recording header type"},
+		{
+			name:    "unsupported version",
+			input:   `{"type":"session","version":2,"transport":"stdio"}`,
+			wantErr: "unsupported recording version: 2",
+		},
+		{
+			name:    "unknown transport",
+			input:   `{"type":"session","version":1,"transport":"udp"}`,
+			wantErr: `unknown recording transport: "udp"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadRecording(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("ReadRecording() succeeded unexpectedly")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ReadRecording() error = %q, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRecording_ReplayKeepsPossibleActionsMode(t *testing.T) {
+	input := strings.Replace(readGoldenFile(t, "testdata/tsumogiri/self_draw.input.mjson"),
+		`{"type":"tsumo","actor":3,"pai":"5p"}`,
+		`{"type":"tsumo","actor":3,"pai":"5p","possible_actions":[{"type":"hora","actor":3,"target":3,"pai":"5p"}]}`, 1)
+
+	var recording bytes.Buffer
+	err := RunStdio(StdioConfig{
+		Name:            "Manue",
+		Room:            "default",
+		Agent:           ai.NewTsumogiriAgent(),
+		In:              strings.NewReader(input),
+		Out:             io.Discard,
+		Recorder:        NewRecorder(&recording, RecordingHeader{Seed: 42, Agent: "test"}),
+		PossibleActions: PossibleActionsStrict,
+	})
+	if err == nil {
+		t.Fatal("RunStdio() succeeded despite the mismatch")
+	}
+	rec, err := ReadRecording(&recording)
+	if err != nil {
+		t.Fatalf("ReadRecording() failed: %v", err)
+	}
+	if rec.Header.PossibleActions != "strict" {
+		t.Errorf("Header.PossibleActions = %q, want strict", rec.Header.PossibleActions)
+	}
+
+	// Replaying in the recorded mode stops at the same mismatch.
+	divergences, err := rec.Replay(ai.NewTsumogiriAgent(), nil)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if len(divergences) != 0 {
+		t.Errorf("Replay() = %+v, want no divergences", divergences)
+	}
+}
+
+func TestRecording_ReplayWithMetaIgnoresEvalTime(t *testing.T) {
+	var recording, out bytes.Buffer
+	err := RunStdio(StdioConfig{
+		Name:     "Manue",
+		Room:     "default",
+		Agent:    newManueAgentForGoldenTest(t),
+		In:       strings.NewReader(readGoldenFile(t, "testdata/manue/chiihou.input.mjson")),
+		Out:      &out,
+		Recorder: NewRecorder(&recording, RecordingHeader{Seed: 42, Agent: "test"}),
+		Meta:     true,
+	})
+	if err != nil {
+		t.Fatalf("RunStdio() failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `"meta":{`) || !strings.Contains(out.String(), `"eval_time_ms":`) {
+		t.Fatalf("output has no meta:\n%s", out.String())
+	}
+	rec, err := ReadRecording(&recording)
+	if err != nil {
+		t.Fatalf("ReadRecording() failed: %v", err)
+	}
+	if !rec.Header.Meta {
+		t.Error("Header.Meta = false, want true")
+	}
+
+	divergences, err := rec.Replay(newManueAgentForGoldenTest(t), nil)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if len(divergences) != 0 {
+		t.Errorf("Replay() = %+v, want no divergences", divergences)
+	}
+}
+
+func TestRecording_ReplayKeepsResilientMode(t *testing.T) {
+	// The draw before start_kyoku cannot be applied.
+	input := strings.Replace(readGoldenFile(t, "testdata/tsumogiri/self_draw.input.mjson"),
+		`{"type":"start_kyoku"`,
+		`{"type":"tsumo","actor":3,"pai":"1m"}`+"\n"+`{"type":"start_kyoku"`, 1)
+
+	var recording, out bytes.Buffer
+	err := RunStdio(StdioConfig{
+		Name:      "Manue",
+		Room:      "default",
+		Agent:     ai.NewTsumogiriAgent(),
+		In:        strings.NewReader(input),
+		Out:       &out,
+		Recorder:  NewRecorder(&recording, RecordingHeader{Seed: 42, Agent: "test"}),
+		Resilient: true,
+	})
+	if err != nil {
+		t.Fatalf("RunStdio() failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `{"type":"dahai","actor":3,"pai":"1m","tsumogiri":true}`) {
+		t.Errorf("output does not discard the drawn tile:\n%s", out.String())
+	}
+	rec, err := ReadRecording(&recording)
+	if err != nil {
+		t.Fatalf("ReadRecording() failed: %v", err)
+	}
+	if !rec.Header.Resilient {
+		t.Error("Header.Resilient = false, want true")
+	}
+	var incidents []string
+	for _, entry := range rec.entries {
+		if entry.Type == "incident" {
+			incidents = append(incidents, entry.Text)
+		}
+	}
+	if len(incidents) != 2 ||
+		!strings.HasPrefix(incidents[0], "incident: diverged: ") ||
+		!strings.Contains(incidents[0], `inbound: {"type":"tsumo","actor":3,"pai":"1m"}`) ||
+		!strings.HasPrefix(incidents[1], "incident: resynced") {
+		t.Errorf("incidents = %q, want diverged and resynced", incidents)
+	}
+
+	divergences, err := rec.Replay(ai.NewTsumogiriAgent(), nil)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if len(divergences) != 0 {
+		t.Errorf("Replay() = %+v, want no divergences", divergences)
+	}
+}
+
+func TestRecording_ReplayKeepsServerProfile(t *testing.T) {
+	profile, err := ReadServerProfile(strings.NewReader(`{"name":"acks","ack":"all"}`))
+	if err != nil {
+		t.Fatalf("ReadServerProfile() failed: %v", err)
+	}
+	var recording, out bytes.Buffer
+	err = RunStdio(StdioConfig{
+		Name:          "Manue",
+		Room:          "default",
+		Agent:         ai.NewTsumogiriAgent(),
+		In:            strings.NewReader(readGoldenFile(t, "testdata/tsumogiri/self_draw.input.mjson")),
+		Out:           &out,
+		Recorder:      NewRecorder(&recording, RecordingHeader{Seed: 42, Agent: "test"}),
+		ServerProfile: profile,
+	})
+	if err != nil {
+		t.Fatalf("RunStdio() failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `{"type":"none"}`) {
+		t.Errorf("output does not ack:\n%s", out.String())
+	}
+	rec, err := ReadRecording(&recording)
+	if err != nil {
+		t.Fatalf("ReadRecording() failed: %v", err)
+	}
+	if p := rec.Header.ServerProfile; p == nil || p.Name != "acks" || p.Ack != AckAll {
+		t.Errorf("Header.ServerProfile = %+v, want the profile", p)
+	}
+
+	divergences, err := rec.Replay(ai.NewTsumogiriAgent(), nil)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	if len(divergences) != 0 {
+		t.Errorf("Replay() = %+v, want no divergences", divergences)
+	}
+}
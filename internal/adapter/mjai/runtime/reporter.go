@@ -2,34 +2,84 @@ package mjairuntime
 
 import (
 	"fmt"
-	"io"
+	"log/slog"
+	"strings"
 
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/application"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
 )
 
 type reporter struct {
-	w io.Writer
+	log *logger
+	rec *Recorder
+	// inbound is the line being handled, which incidents quote.
+	inbound []byte
 }
 
-func newReporter(w io.Writer) *reporter {
-	if w == nil {
+func newReporter(log *logger, rec *Recorder) *reporter {
+	if log == nil && rec == nil {
 		return nil
 	}
-	return &reporter{w: w}
+	return &reporter{log: log, rec: rec}
 }
 
 func (r *reporter) ReportRoundState(state round.BoardRenderer) error {
-	if r == nil || r.w == nil {
+	if r == nil {
 		return nil
 	}
-	_, err := fmt.Fprint(r.w, state.RenderBoard())
-	return err
+	return r.log.write(LogBoard, slog.LevelInfo, state.RenderBoard())
 }
 
 func (r *reporter) ReportDecisionTrace(trace string) error {
-	if r == nil || r.w == nil || trace == "" {
+	if r == nil || trace == "" {
 		return nil
 	}
-	_, err := fmt.Fprint(r.w, trace)
-	return err
+	if err := r.rec.recordTrace(trace); err != nil {
+		return err
+	}
+	return r.log.write(LogDecision, slog.LevelInfo, trace)
+}
+
+// setInbound sets the line being handled.
+func (r *reporter) setInbound(line []byte) {
+	if r != nil {
+		r.inbound = line
+	}
+}
+
+// ReportIncident records the incident and writes it to the log with the
+// inbound line, the fallback action, and the board.
+func (r *reporter) ReportIncident(incident application.Incident) error {
+	if r == nil {
+		return nil
+	}
+	text := r.formatIncident(incident)
+	if err := r.rec.recordIncident(text); err != nil {
+		return err
+	}
+	return r.log.write(LogError, slog.LevelWarn, text)
+}
+
+func (r *reporter) formatIncident(incident application.Incident) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "incident: %s", incident.Kind)
+	if incident.Err != nil {
+		fmt.Fprintf(&sb, ": %s", strings.ReplaceAll(incident.Err.Error(), "\n", "; "))
+	}
+	sb.WriteByte('\n')
+	if len(r.inbound) > 0 {
+		fmt.Fprintf(&sb, "  inbound: %s\n", r.inbound)
+	}
+	if incident.Fallback != nil {
+		fallback := "<unknown>"
+		if msg, err := outbound.ToMessage(incident.Fallback, ""); err == nil {
+			if b, err := outbound.MarshalMessage(msg); err == nil {
+				fallback = string(b)
+			}
+		}
+		fmt.Fprintf(&sb, "  fallback: %s\n", fallback)
+	}
+	sb.WriteString(incident.Board)
+	return sb.String()
 }
@@ -7,7 +7,7 @@ import (
 
 func TestReporter_ReportDecisionTrace(t *testing.T) {
 	var out strings.Builder
-	reporter := newReporter(&out)
+	reporter := newReporter(newLogger(&out, LogConfig{}), nil)
 
 	if err := reporter.ReportDecisionTrace("evaluation trace\n"); err != nil {
 		t.Fatalf("ReportDecisionTrace() failed: %v", err)
@@ -20,7 +20,7 @@ func TestReporter_ReportDecisionTrace(t *testing.T) {
 
 func TestReporter_ReportDecisionTrace_IgnoresEmptyTrace(t *testing.T) {
 	var out strings.Builder
-	reporter := newReporter(&out)
+	reporter := newReporter(newLogger(&out, LogConfig{}), nil)
 
 	if err := reporter.ReportDecisionTrace(""); err != nil {
 		t.Fatalf("ReportDecisionTrace() failed: %v", err)
@@ -0,0 +1,304 @@
+package mjairuntime
+
+import (
+	"bytes"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+)
+
+// ServerProfile describes the mjai dialect of a server: how it expects
+// messages to be answered, which optional fields it always sends, when it
+// reveals the dora of an open kan, how it writes red fives, and how far its
+// possible_actions are trusted. A nil profile accepts every dialect the
+// codec understands and answers as the transport does.
+type ServerProfile struct {
+	Name string `json:"name"`
+	// Ack selects the messages answered with none. Empty leaves it to the
+	// transport: mjsonp answers every message, stdio only actions.
+	Ack AckPolicy `json:"ack,omitzero"`
+	// Required lists the optional fields the server always sends, named as
+	// in RequirableFields. A message without one of them is an error.
+	Required []string `json:"required,omitzero"`
+	// OpenKanDora is when the dora indicator of a daiminkan or kakan is
+	// revealed. Empty accepts both orders.
+	OpenKanDora KanDoraTiming `json:"open_kan_dora,omitzero"`
+	// RedFive is how the server writes red fives. Empty is RedFiveMjai.
+	RedFive RedFiveEncoding `json:"red_five,omitzero"`
+	// PossibleActions is the possible_actions mode the profile suggests,
+	// empty for off. Callers use it unless the user chooses a mode.
+	PossibleActions string `json:"possible_actions,omitzero"`
+}
+
+// AckPolicy selects the messages a player answers with none.
+type AckPolicy string
+
+const (
+	// AckAll answers none to every message the player does not act on.
+	AckAll AckPolicy = "all"
+	// AckActions answers only the messages the player acts on.
+	AckActions AckPolicy = "actions"
+)
+
+// KanDoraTiming is when the dora indicator of an open kan is revealed
+// relative to the replacement draw.
+type KanDoraTiming string
+
+const (
+	// KanDoraAfterReplacement reveals it between the replacement draw and
+	// the discard, as the original mjai server does.
+	KanDoraAfterReplacement KanDoraTiming = "after_replacement"
+	// KanDoraBeforeReplacement reveals it right after the kan, as for a
+	// concealed kan.
+	KanDoraBeforeReplacement KanDoraTiming = "before_replacement"
+)
+
+// RedFiveEncoding is how red fives are written in tiles.
+type RedFiveEncoding string
+
+const (
+	// RedFiveMjai writes red fives as 5mr, 5pr and 5sr.
+	RedFiveMjai RedFiveEncoding = "5mr"
+	// RedFiveZero writes red fives as 0m, 0p and 0s.
+	RedFiveZero RedFiveEncoding = "0m"
+)
+
+// RequirableFields are the optional fields a profile can require.
+var RequirableFields = []string{"start_game.id", "hora.pai", "reach_accepted.scores"}
+
+var serverProfiles = []ServerProfile{
+	{
+		Name:            "mjai",
+		Ack:             AckAll,
+		Required:        []string{"start_game.id", "hora.pai", "reach_accepted.scores"},
+		OpenKanDora:     KanDoraAfterReplacement,
+		RedFive:         RedFiveMjai,
+		PossibleActions: "warn",
+	},
+	{
+		Name:     "mortal",
+		Ack:      AckAll,
+		Required: []string{"start_game.id"},
+		RedFive:  RedFiveMjai,
+	},
+	{
+		Name:    "riichienv",
+		Ack:     AckActions,
+		RedFive: RedFiveMjai,
+	},
+	{
+		Name:    "mjx",
+		Ack:     AckAll,
+		RedFive: RedFiveMjai,
+	},
+	{
+		Name:        "akochan",
+		Ack:         AckAll,
+		Required:    []string{"start_game.id", "hora.pai"},
+		OpenKanDora: KanDoraAfterReplacement,
+		RedFive:     RedFiveMjai,
+	},
+}
+
+// ServerProfileNames returns the names of the built-in profiles.
+func ServerProfileNames() []string {
+	names := make([]string, len(serverProfiles))
+	for i, p := range serverProfiles {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// LookupServerProfile returns a copy of the built-in profile with the name.
+func LookupServerProfile(name string) (*ServerProfile, error) {
+	i := slices.IndexFunc(serverProfiles, func(p ServerProfile) bool { return p.Name == name })
+	if i < 0 {
+		return nil, &UsageError{err: fmt.Errorf("unknown server profile %q (want one of %s)", name, strings.Join(ServerProfileNames(), ", "))}
+	}
+	p := serverProfiles[i]
+	p.Required = slices.Clone(p.Required)
+	return &p, nil
+}
+
+// ReadServerProfile reads a profile written in JSON, for servers the
+// built-in profiles do not cover.
+func ReadServerProfile(r io.Reader) (*ServerProfile, error) {
+	var p ServerProfile
+	if err := json.UnmarshalRead(r, &p, json.RejectUnknownMembers(true)); err != nil {
+		return nil, fmt.Errorf("invalid server profile: %w", err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (p *ServerProfile) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("invalid server profile: missing name")
+	}
+	switch p.Ack {
+	case "", AckAll, AckActions:
+	default:
+		return fmt.Errorf("invalid server profile %s: unknown ack %q", p.Name, p.Ack)
+	}
+	for _, field := range p.Required {
+		if !slices.Contains(RequirableFields, field) {
+			return fmt.Errorf("invalid server profile %s: cannot require %q (want one of %s)", p.Name, field, strings.Join(RequirableFields, ", "))
+		}
+	}
+	switch p.OpenKanDora {
+	case "", KanDoraAfterReplacement, KanDoraBeforeReplacement:
+	default:
+		return fmt.Errorf("invalid server profile %s: unknown open_kan_dora %q", p.Name, p.OpenKanDora)
+	}
+	switch p.RedFive {
+	case "", RedFiveMjai, RedFiveZero:
+	default:
+		return fmt.Errorf("invalid server profile %s: unknown red_five %q", p.Name, p.RedFive)
+	}
+	if _, err := p.PossibleActionsMode(); err != nil {
+		return fmt.Errorf("invalid server profile %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// PossibleActionsMode returns the possible_actions mode of the profile.
+func (p *ServerProfile) PossibleActionsMode() (PossibleActionsMode, error) {
+	if p.PossibleActions == "" {
+		return PossibleActionsOff, nil
+	}
+	return ParsePossibleActionsMode(p.PossibleActions)
+}
+
+// acksAll reports whether every message is answered, given what the
+// transport does without a profile.
+func (p *ServerProfile) acksAll(transportDefault bool) bool {
+	if p == nil || p.Ack == "" {
+		return transportDefault
+	}
+	return p.Ack == AckAll
+}
+
+// checkRequired returns an error when msg lacks a field the profile
+// requires.
+func (p *ServerProfile) checkRequired(msg inbound.Message) error {
+	if p == nil {
+		return nil
+	}
+	var missing string
+	switch msg := msg.(type) {
+	case *inbound.StartGame:
+		if msg.ID == nil {
+			missing = "start_game.id"
+		}
+	case *inbound.Hora:
+		if msg.Pai == "" {
+			missing = "hora.pai"
+		}
+	case *inbound.ReachAccepted:
+		if msg.Scores == nil {
+			missing = "reach_accepted.scores"
+		}
+	}
+	if missing != "" && slices.Contains(p.Required, missing) {
+		return fmt.Errorf("server profile %s requires %s", p.Name, missing)
+	}
+	return nil
+}
+
+var (
+	zeroRedFives = map[string]string{"0m": "5mr", "0p": "5pr", "0s": "5sr"}
+	mjaiRedFives = map[string]string{"5mr": "0m", "5pr": "0p", "5sr": "0s"}
+)
+
+// decodeLine rewrites the red fives of a line from the server as mjai
+// writes them.
+func (p *ServerProfile) decodeLine(line []byte) ([]byte, error) {
+	if p == nil || p.RedFive != RedFiveZero {
+		return line, nil
+	}
+	return replaceStrings(line, zeroRedFives)
+}
+
+// encodeLine rewrites the red fives of a line to the server as the server
+// writes them.
+func (p *ServerProfile) encodeLine(line []byte) ([]byte, error) {
+	if p == nil || p.RedFive != RedFiveZero {
+		return line, nil
+	}
+	return replaceStrings(line, mjaiRedFives)
+}
+
+// replaceStrings replaces the string values of a JSON value that are keys of
+// replacements. Object member names are kept.
+func replaceStrings(line []byte, replacements map[string]string) ([]byte, error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(line))
+	var buf bytes.Buffer
+	enc := jsontext.NewEncoder(&buf)
+	for {
+		kind, length := dec.StackIndex(dec.StackDepth())
+		isName := kind == '{' && length%2 == 0
+		tok, err := dec.ReadToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if tok.Kind() == '"' && !isName {
+			if s, ok := replacements[tok.String()]; ok {
+				tok = jsontext.String(s)
+			}
+		}
+		if err := enc.WriteToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// kanDoraTracker follows the open kans of a round to check when their dora
+// indicators are revealed.
+type kanDoraTracker struct {
+	// drawn holds an entry for each open kan whose dora has not been
+	// revealed, oldest first, which is true once its replacement tile has
+	// been drawn. A second kan can be called before the dora of the first is
+	// revealed, and the reveals then come in the order of the kans.
+	drawn []bool
+}
+
+// check returns an error when msg reveals the dora of an open kan at another
+// time than the profile says.
+func (t *kanDoraTracker) check(p *ServerProfile, msg inbound.Message) error {
+	switch msg.(type) {
+	case *inbound.StartKyoku:
+		t.drawn = nil
+	case *inbound.Daiminkan, *inbound.Kakan:
+		t.drawn = append(t.drawn, false)
+	case *inbound.Tsumo:
+		// A draw after a kan is its replacement tile or comes after it.
+		for i := range t.drawn {
+			t.drawn[i] = true
+		}
+	case *inbound.Dora:
+		if len(t.drawn) == 0 {
+			return nil
+		}
+		timing := KanDoraBeforeReplacement
+		if t.drawn[0] {
+			timing = KanDoraAfterReplacement
+		}
+		t.drawn = t.drawn[1:]
+		if p != nil && p.OpenKanDora != "" && p.OpenKanDora != timing {
+			return fmt.Errorf("server profile %s reveals the dora of an open kan %s, but it was revealed %s",
+				p.Name, strings.ReplaceAll(string(p.OpenKanDora), "_", " "), strings.ReplaceAll(string(timing), "_", " "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+package mjairuntime_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+// profileGameOptions shape a game in the dialect of a server.
+type profileGameOptions struct {
+	// omit is the requirable field left out.
+	omit string
+	// doraBeforeReplacement reveals the dora of the open kan before the
+	// replacement draw.
+	doraBeforeReplacement bool
+}
+
+// profileGame returns a game that player 1 watches: player 2 calls a
+// daiminkan, player 3 declares riichi and wins on a discard of player 0.
+func profileGame(opts profileGameOptions) string {
+	startGame := `{"type":"start_game","id":1,"names":["A","B","C","D"]}`
+	if opts.omit == "start_game.id" {
+		startGame = `{"type":"start_game","names":["A","B","C","D"]}`
+	}
+	kan := []string{`{"type":"tsumo","actor":2,"pai":"?"}`, `{"type":"dora","dora_marker":"1s"}`}
+	if opts.doraBeforeReplacement {
+		kan[0], kan[1] = kan[1], kan[0]
+	}
+	reachAccepted := `{"type":"reach_accepted","actor":3,"deltas":[0,0,0,-1000],"scores":[25000,25000,25000,24000]}`
+	if opts.omit == "reach_accepted.scores" {
+		reachAccepted = `{"type":"reach_accepted","actor":3}`
+	}
+	hora := `{"type":"hora","actor":3,"target":0,"pai":"P","deltas":[-2000,0,0,3000],"scores":[23000,25000,25000,27000]}`
+	if opts.omit == "hora.pai" {
+		hora = `{"type":"hora","actor":3,"target":0,"deltas":[-2000,0,0,3000],"scores":[23000,25000,25000,27000]}`
+	}
+	hidden := `["?","?","?","?","?","?","?","?","?","?","?","?","?"]`
+	lines := []string{
+		`{"type":"hello","protocol":"mjsonp","protocol_version":3}`,
+		startGame,
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[` + hidden +
+			`,["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","9s"],` + hidden + `,` + hidden + `]}`,
+		`{"type":"tsumo","actor":0,"pai":"?"}`,
+		`{"type":"dahai","actor":0,"pai":"E","tsumogiri":true}`,
+		`{"type":"daiminkan","actor":2,"target":0,"pai":"E","consumed":["E","E","E"]}`,
+		kan[0],
+		kan[1],
+		`{"type":"dahai","actor":2,"pai":"W","tsumogiri":true}`,
+		`{"type":"tsumo","actor":3,"pai":"?"}`,
+		`{"type":"reach","actor":3}`,
+		`{"type":"dahai","actor":3,"pai":"N","tsumogiri":true}`,
+		reachAccepted,
+		`{"type":"tsumo","actor":0,"pai":"?"}`,
+		`{"type":"dahai","actor":0,"pai":"P","tsumogiri":true}`,
+		hora,
+		`{"type":"end_kyoku"}`,
+		`{"type":"end_game"}`,
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func runWithProfile(t *testing.T, profile *mjairuntime.ServerProfile, input string) (string, error) {
+	t.Helper()
+	var out strings.Builder
+	err := mjairuntime.RunStdio(mjairuntime.StdioConfig{
+		Name:          "tsumogiri",
+		Room:          "default",
+		FallbackID:    1,
+		Agent:         ai.NewTsumogiriAgent(),
+		In:            strings.NewReader(input),
+		Out:           &out,
+		ServerProfile: profile,
+	})
+	return out.String(), err
+}
+
+func TestServerProfile_Conformance(t *testing.T) {
+	for _, name := range mjairuntime.ServerProfileNames() {
+		t.Run(name, func(t *testing.T) {
+			profile, err := mjairuntime.LookupServerProfile(name)
+			if err != nil {
+				t.Fatalf("LookupServerProfile() failed: %v", err)
+			}
+			opts := profileGameOptions{doraBeforeReplacement: profile.OpenKanDora == mjairuntime.KanDoraBeforeReplacement}
+
+			out, err := runWithProfile(t, profile, profileGame(opts))
+			if err != nil {
+				t.Fatalf("RunStdio() failed on a conforming game: %v", err)
+			}
+			// Every line but hello is answered when the profile acks all
+			// messages.
+			acks := strings.Count(out, `{"type":"none"}`)
+			if profile.Ack == mjairuntime.AckAll && acks != 17 {
+				t.Errorf("acks = %d, want 17; output = %q", acks, out)
+			}
+			if profile.Ack == mjairuntime.AckActions && acks != 0 {
+				t.Errorf("acks = %d, want 0; output = %q", acks, out)
+			}
+
+			for _, field := range mjairuntime.RequirableFields {
+				_, err := runWithProfile(t, profile, profileGame(profileGameOptions{omit: field, doraBeforeReplacement: opts.doraBeforeReplacement}))
+				required := slices.Contains(profile.Required, field)
+				if required && (err == nil || !strings.Contains(err.Error(), "requires "+field)) {
+					t.Errorf("RunStdio() without %s error = %v, want a missing field", field, err)
+				}
+				if !required && err != nil {
+					t.Errorf("RunStdio() without %s failed: %v", field, err)
+				}
+			}
+
+			if profile.OpenKanDora != "" {
+				opts.doraBeforeReplacement = !opts.doraBeforeReplacement
+				if _, err := runWithProfile(t, profile, profileGame(opts)); err == nil || !strings.Contains(err.Error(), "dora of an open kan") {
+					t.Errorf("RunStdio() with the other dora timing error = %v, want a timing error", err)
+				}
+			}
+		})
+	}
+}
+
+// twoOpenKansGame returns a game in which player 2 calls a daiminkan and
+// then a kakan, with the lines of kans after the kakan.
+func twoOpenKansGame(kans ...string) string {
+	hidden := `["?","?","?","?","?","?","?","?","?","?","?","?","?"]`
+	lines := []string{
+		`{"type":"start_game","id":1,"names":["A","B","C","D"]}`,
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[` + hidden +
+			`,["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","9s"],` + hidden + `,` + hidden + `]}`,
+		`{"type":"tsumo","actor":0,"pai":"?"}`,
+		`{"type":"dahai","actor":0,"pai":"S","tsumogiri":true}`,
+		`{"type":"pon","actor":2,"target":0,"pai":"S","consumed":["S","S"]}`,
+		`{"type":"dahai","actor":2,"pai":"W","tsumogiri":false}`,
+		`{"type":"tsumo","actor":3,"pai":"?"}`,
+		`{"type":"dahai","actor":3,"pai":"E","tsumogiri":true}`,
+		`{"type":"daiminkan","actor":2,"target":3,"pai":"E","consumed":["E","E","E"]}`,
+		`{"type":"tsumo","actor":2,"pai":"?"}`,
+		`{"type":"kakan","actor":2,"pai":"S","consumed":["S","S","S"]}`,
+	}
+	lines = append(lines, kans...)
+	lines = append(lines, `{"type":"dahai","actor":2,"pai":"W","tsumogiri":true}`, `{"type":"end_game"}`)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestServerProfile_TwoOpenKansInARow(t *testing.T) {
+	profile, err := mjairuntime.LookupServerProfile("mjai")
+	if err != nil {
+		t.Fatalf("LookupServerProfile() failed: %v", err)
+	}
+	if profile.OpenKanDora != mjairuntime.KanDoraAfterReplacement {
+		t.Fatalf("OpenKanDora = %q, want %q", profile.OpenKanDora, mjairuntime.KanDoraAfterReplacement)
+	}
+
+	// The dora of the daiminkan comes after its replacement draw even though
+	// the kakan was called in between.
+	if _, err := runWithProfile(t, profile, twoOpenKansGame(
+		`{"type":"dora","dora_marker":"1s"}`,
+		`{"type":"tsumo","actor":2,"pai":"?"}`,
+		`{"type":"dora","dora_marker":"2s"}`,
+	)); err != nil {
+		t.Errorf("RunStdio() with both doras after replacement failed: %v", err)
+	}
+	if _, err := runWithProfile(t, profile, twoOpenKansGame(
+		`{"type":"dora","dora_marker":"1s"}`,
+		`{"type":"dora","dora_marker":"2s"}`,
+		`{"type":"tsumo","actor":2,"pai":"?"}`,
+	)); err == nil || !strings.Contains(err.Error(), "dora of an open kan") {
+		t.Errorf("RunStdio() with the dora of the kakan before replacement error = %v, want a timing error", err)
+	}
+}
+
+func TestServerProfile_NilAcceptsEveryDialect(t *testing.T) {
+	for _, opts := range []profileGameOptions{
+		{omit: "start_game.id"},
+		{omit: "hora.pai", doraBeforeReplacement: true},
+		{omit: "reach_accepted.scores"},
+	} {
+		if _, err := runWithProfile(t, nil, profileGame(opts)); err != nil {
+			t.Errorf("RunStdio(%+v) failed: %v", opts, err)
+		}
+	}
+}
+
+func TestServerProfile_ZeroRedFives(t *testing.T) {
+	profile, err := mjairuntime.ReadServerProfile(strings.NewReader(`{"name":"zero","red_five":"0m"}`))
+	if err != nil {
+		t.Fatalf("ReadServerProfile() failed: %v", err)
+	}
+	input := `{"type":"start_game","id":0}
+{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"0p","tehais":[["1m","2m","3m","4m","6m","7m","8m","9m","1p","2p","3p","9s","9s"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]]}
+{"type":"tsumo","actor":0,"pai":"0m"}
+`
+	out, err := runWithProfile(t, profile, input)
+	if err != nil {
+		t.Fatalf("RunStdio() failed: %v", err)
+	}
+	if want := `{"type":"dahai","actor":0,"pai":"0m","tsumogiri":true}` + "\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestReadServerProfile_RejectsInvalidProfile(t *testing.T) {
+	for _, input := range []string{
+		`{"ack":"all"}`,
+		`{"name":"x","ack":"some"}`,
+		`{"name":"x","required":["dahai.pai"]}`,
+		`{"name":"x","open_kan_dora":"later"}`,
+		`{"name":"x","red_five":"r5m"}`,
+		`{"name":"x","possible_actions":"trust"}`,
+		`{"name":"x","unknown":true}`,
+	} {
+		if _, err := mjairuntime.ReadServerProfile(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadServerProfile(%s) succeeded unexpectedly", input)
+		}
+	}
+}
+
+func TestLookupServerProfile_UnknownName(t *testing.T) {
+	if _, err := mjairuntime.LookupServerProfile("tenhou"); err == nil || !strings.Contains(err.Error(), "mjai, mortal") {
+		t.Errorf("LookupServerProfile() error = %v, want the known names", err)
+	}
+}
@@ -0,0 +1,75 @@
+package mjairuntime
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+type SessionConfig struct {
+	Name       string
+	Room       string
+	FallbackID int
+	Agent      ai.Agent
+	// Log receives the traces, boards and decision logs. A nil Log discards
+	// them, which is the fastest.
+	Log io.Writer
+	// Recorder records the session when it is not nil.
+	Recorder *Recorder
+	// PossibleActions selects how possible_actions are cross-checked.
+	PossibleActions PossibleActionsMode
+	// Meta attaches the evaluation of each decision to its action message.
+	Meta bool
+	// Resilient plays through errors of the state tracking and the agent
+	// instead of failing the line.
+	Resilient bool
+	// ServerProfile is the dialect of the server. A nil profile accepts any.
+	ServerProfile *ServerProfile
+}
+
+// Session is a stdio session driven one line at a time by its caller instead
+// of by a reader, for hosts that run the agent in-process.
+type Session struct {
+	driver *Driver
+	rec    *Recorder
+	buf    bytes.Buffer
+	w      *bufio.Writer
+}
+
+func NewSession(cfg SessionConfig) (*Session, error) {
+	options := driverOptions{possibleActions: cfg.PossibleActions, meta: cfg.Meta, resilient: cfg.Resilient, profile: cfg.ServerProfile}
+	if err := cfg.Recorder.start(stdioPolicy.transport, cfg.Name, cfg.Room, cfg.FallbackID, options); err != nil {
+		return nil, err
+	}
+	driver := NewDriver(cfg.Name, cfg.Room, cfg.FallbackID, cfg.Agent, cfg.Log)
+	driver.recorder = cfg.Recorder
+	driver.options = options
+	s := &Session{driver: driver, rec: cfg.Recorder}
+	s.w = bufio.NewWriter(&s.buf)
+	return s, nil
+}
+
+// HandleLine handles a line from the server and returns the reply without
+// the newline, or nil when the player has nothing to say, as stdio mode does.
+// The reply is valid until the next call.
+func (s *Session) HandleLine(line []byte) ([]byte, error) {
+	s.buf.Reset()
+	if _, err := handleJSONLine(line, s.w, s.driver, stdioPolicy, s.rec); err != nil {
+		if recErr := s.rec.recordError(err); recErr != nil {
+			return nil, recErr
+		}
+		return nil, err
+	}
+	if s.buf.Len() == 0 {
+		return nil, nil
+	}
+	return bytes.TrimSuffix(s.buf.Bytes(), []byte{'\n'}), nil
+}
+
+// Ended reports whether the session has received end_game since the last
+// start_game.
+func (s *Session) Ended() bool {
+	return s.driver.Ended()
+}
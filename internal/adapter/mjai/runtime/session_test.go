@@ -0,0 +1,78 @@
+package mjairuntime_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+func TestSession_HandleLineReturnsReplies(t *testing.T) {
+	session, err := mjairuntime.NewSession(mjairuntime.SessionConfig{
+		Name:  "tsumogiri",
+		Room:  "default",
+		Agent: ai.NewTsumogiriAgent(),
+	})
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+
+	lines := []string{
+		`{"type":"hello","protocol":"mjsonp","protocol_version":3}`,
+		`{"type":"start_game","id":0,"names":["tsumogiri","b","c","d"]}`,
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","4m","5m","6m","7m","8m","1p","2p","3p","5p","5p"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]]}`,
+		`{"type":"tsumo","actor":0,"pai":"N"}`,
+	}
+	wants := []string{
+		`{"type":"join","name":"tsumogiri","room":"default"}`,
+		"",
+		"",
+		`{"type":"dahai","actor":0,"pai":"N","tsumogiri":true}`,
+	}
+	for i, line := range lines {
+		got, err := session.HandleLine([]byte(line))
+		if err != nil {
+			t.Fatalf("HandleLine(%s) failed: %v", line, err)
+		}
+		if string(got) != wants[i] {
+			t.Errorf("HandleLine(%s) = %q, want %q", line, got, wants[i])
+		}
+		if wants[i] == "" && got != nil {
+			t.Errorf("HandleLine(%s) = %q, want nil", line, got)
+		}
+	}
+
+	if _, err := session.HandleLine([]byte(`{"type":"end_game"}`)); err != nil {
+		t.Fatalf("HandleLine(end_game) failed: %v", err)
+	}
+	if !session.Ended() {
+		t.Error("Ended() = false after end_game")
+	}
+}
+
+func TestSession_HandleLineRejectsInvalidJSON(t *testing.T) {
+	var log strings.Builder
+	var rec bytes.Buffer
+	session, err := mjairuntime.NewSession(mjairuntime.SessionConfig{
+		Name:     "tsumogiri",
+		Room:     "default",
+		Agent:    ai.NewTsumogiriAgent(),
+		Log:      &log,
+		Recorder: mjairuntime.NewRecorder(&rec, mjairuntime.RecordingHeader{Agent: "tsumogiri"}),
+	})
+	if err != nil {
+		t.Fatalf("NewSession() failed: %v", err)
+	}
+
+	if _, err := session.HandleLine([]byte("{")); err == nil {
+		t.Fatal("HandleLine() succeeded unexpectedly")
+	}
+	if !strings.Contains(log.String(), "<-\t{") {
+		t.Errorf("log = %q, want the trace of the line", log.String())
+	}
+	if !strings.Contains(rec.String(), `"error"`) {
+		t.Errorf("recording = %q, want the error", rec.String())
+	}
+}
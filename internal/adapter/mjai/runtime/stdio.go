@@ -14,8 +14,24 @@ type StdioConfig struct {
 	In         io.Reader
 	Out        io.Writer
 	Log        io.Writer
+	// Logging selects the format, the channel levels, and the per-game files
+	// of the log.
+	Logging LogConfig
+	// Recorder records the session when it is not nil.
+	Recorder *Recorder
+	// PossibleActions selects how possible_actions are cross-checked.
+	PossibleActions PossibleActionsMode
+	// Meta attaches the evaluation of each decision to its action message.
+	Meta bool
+	// Resilient plays through errors of the state tracking and the agent
+	// instead of ending the session.
+	Resilient bool
+	// ServerProfile is the dialect of the server. A nil profile accepts any.
+	ServerProfile *ServerProfile
 }
 
 func RunStdio(cfg StdioConfig) error {
-	return runJSONLines(cfg.Name, cfg.Room, cfg.FallbackID, cfg.Agent, cfg.In, cfg.Out, cfg.Log, jsonLinesPolicy{})
+	log := newLogger(cfg.Log, cfg.Logging)
+	defer log.close()
+	return runJSONLines(cfg.Name, cfg.Room, cfg.FallbackID, cfg.Agent, cfg.In, cfg.Out, log, stdioPolicy, cfg.Recorder, driverOptions{possibleActions: cfg.PossibleActions, meta: cfg.Meta, resilient: cfg.Resilient, profile: cfg.ServerProfile})
 }
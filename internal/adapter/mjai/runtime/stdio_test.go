@@ -90,3 +90,95 @@ func TestRunStdio_EndGameContinuesReadingUntilEOF(t *testing.T) {
 		t.Errorf("output = %q, want empty", out.String())
 	}
 }
+
+// resilientGame returns a game in which the lines of round are played
+// before the draw of seat 3.
+func resilientGame(round ...string) string {
+	lines := []string{
+		`{"type":"start_game","id":3,"names":["A","B","C","D"]}`,
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"4m","tehais":[["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","4p"]],"scores":[25000,25000,25000,25000]}`,
+		`{"type":"tsumo","actor":0,"pai":"?"}`,
+	}
+	lines = append(lines, round...)
+	lines = append(lines,
+		`{"type":"tsumo","actor":3,"pai":"5p"}`,
+		`{"type":"end_game","scores":[25000,25000,25000,25000]}`,
+	)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestRunStdio_ResilientPlaysThroughInvalidMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		round   []string
+		want    string
+	}{
+		{
+			name:  "malformed line",
+			round: []string{`{"type":"dahai","actor":0,`},
+			want:  "incident: diverged: ",
+		},
+		{
+			name:    "missing required field",
+			profile: `{"name":"scores","required":["reach_accepted.scores"]}`,
+			round: []string{
+				`{"type":"reach","actor":0}`,
+				`{"type":"dahai","actor":0,"pai":"1s","tsumogiri":true}`,
+				`{"type":"reach_accepted","actor":0}`,
+			},
+			want: "incident: diverged: server profile scores requires reach_accepted.scores",
+		},
+		{
+			name:    "dora of an open kan revealed early",
+			profile: `{"name":"late","open_kan_dora":"after_replacement"}`,
+			round: []string{
+				`{"type":"dahai","actor":0,"pai":"1s","tsumogiri":true}`,
+				`{"type":"daiminkan","actor":1,"target":0,"pai":"1s","consumed":["1s","1s","1s"]}`,
+				`{"type":"dora","dora_marker":"9s"}`,
+				`{"type":"tsumo","actor":1,"pai":"?"}`,
+				`{"type":"dahai","actor":1,"pai":"2s","tsumogiri":true}`,
+				`{"type":"tsumo","actor":2,"pai":"?"}`,
+				`{"type":"dahai","actor":2,"pai":"3s","tsumogiri":true}`,
+			},
+			want: "incident: diverged: server profile late reveals the dora of an open kan after replacement",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var profile *mjairuntime.ServerProfile
+			if tt.profile != "" {
+				var err error
+				if profile, err = mjairuntime.ReadServerProfile(strings.NewReader(tt.profile)); err != nil {
+					t.Fatalf("ReadServerProfile() failed: %v", err)
+				}
+			}
+			config := mjairuntime.StdioConfig{
+				Name:          "tsumogiri",
+				Room:          "default",
+				Agent:         ai.NewTsumogiriAgent(),
+				In:            strings.NewReader(resilientGame(tt.round...)),
+				Out:           &strings.Builder{},
+				ServerProfile: profile,
+			}
+			if err := mjairuntime.RunStdio(config); err == nil {
+				t.Fatal("RunStdio() without resilient mode succeeded unexpectedly")
+			}
+
+			var out, log strings.Builder
+			config.In = strings.NewReader(resilientGame(tt.round...))
+			config.Out = &out
+			config.Log = &log
+			config.Resilient = true
+			if err := mjairuntime.RunStdio(config); err != nil {
+				t.Fatalf("RunStdio() failed: %v", err)
+			}
+			if !strings.Contains(log.String(), tt.want) {
+				t.Errorf("log = %q, want an incident starting with %q", log.String(), tt.want)
+			}
+			if want := `{"type":"dahai","actor":3,"pai":"5p","tsumogiri":true}`; !strings.Contains(out.String(), want) {
+				t.Errorf("output = %q, want %s", out.String(), want)
+			}
+		})
+	}
+}
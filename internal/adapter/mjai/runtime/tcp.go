@@ -3,6 +3,7 @@ package mjairuntime
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"net"
 	"net/url"
 	"strings"
@@ -16,6 +17,20 @@ type TCPConfig struct {
 	FallbackID int
 	Agent      ai.Agent
 	Log        io.Writer
+	// Logging selects the format, the channel levels, and the per-game files
+	// of the log.
+	Logging LogConfig
+	// Recorder records the session when it is not nil.
+	Recorder *Recorder
+	// PossibleActions selects how possible_actions are cross-checked.
+	PossibleActions PossibleActionsMode
+	// Meta attaches the evaluation of each decision to its action message.
+	Meta bool
+	// Resilient plays through errors of the state tracking and the agent
+	// instead of ending the session.
+	Resilient bool
+	// ServerProfile is the dialect of the server. A nil profile accepts any.
+	ServerProfile *ServerProfile
 }
 
 type UsageError struct {
@@ -36,26 +51,25 @@ func RunTCP(cfg TCPConfig) error {
 		return err
 	}
 
+	log := newLogger(cfg.Log, cfg.Logging)
+	defer log.close()
 	conn, err := net.Dial("tcp", endpoint.address)
 	if err != nil {
-		if logErr := logLine(cfg.Log, "tcp error: "+err.Error()); logErr != nil {
+		if logErr := log.write(LogError, slog.LevelError, "tcp error: "+err.Error()); logErr != nil {
 			return logErr
 		}
 		return err
 	}
-	if err := logLine(cfg.Log, "connected"); err != nil {
+	if err := log.write(LogProtocol, slog.LevelInfo, "connected"); err != nil {
 		conn.Close()
 		return err
 	}
 	defer func() {
 		conn.Close()
-		_ = logLine(cfg.Log, "closed")
+		_ = log.write(LogProtocol, slog.LevelInfo, "closed")
 	}()
 
-	return runJSONLines(cfg.Name, endpoint.room, cfg.FallbackID, cfg.Agent, conn, conn, cfg.Log, jsonLinesPolicy{
-		respondNoneOnNoReaction: true,
-		stopOnEndGame:           true,
-	})
+	return runJSONLines(cfg.Name, endpoint.room, cfg.FallbackID, cfg.Agent, conn, conn, log, mjsonpPolicy, cfg.Recorder, driverOptions{possibleActions: cfg.PossibleActions, meta: cfg.Meta, resilient: cfg.Resilient, profile: cfg.ServerProfile})
 }
 
 type mjsonpEndpoint struct {
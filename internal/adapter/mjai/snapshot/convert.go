@@ -0,0 +1,439 @@
+package snapshot
+
+import (
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+// FromState converts a state to its snapshot.
+func FromState(s *round.State) *Snapshot {
+	rs := s.Snapshot()
+	snap := &Snapshot{
+		Version:              Version,
+		Bakaze:               rs.RoundWind.String(),
+		Kyoku:                rs.RoundNumber,
+		Honba:                rs.Honba,
+		Kyotaku:              rs.RiichiDeposit,
+		Oya:                  rs.Dealer.Index(),
+		Chicha:               rs.StartingDealer.Index(),
+		Scores:               rs.Scores[:],
+		DoraMarkers:          tileCodes(rs.DoraIndicators),
+		NumLeftTiles:         rs.NumLeftTiles,
+		Players:              make([]Player, common.NumPlayers),
+		NextTsumo:            rs.NextDraw.Index(),
+		PendingDahai:         seatIndex(rs.PendingDiscard),
+		PendingReachAccepted: seatIndex(rs.PendingRiichiAcceptance),
+		Rinshan:              rs.LastDrawWasReplacement,
+		CanKyushukyuhai:      rs.CanKyushukyuhai[:],
+		LastActor:            seatIndex(rs.LastActor),
+		SuppressActions:      rs.LegalActionsSuppressed,
+		Ended:                rs.RoundEnded,
+	}
+	for i := range rs.Players {
+		snap.Players[i] = fromPlayerSnapshot(&rs.Players[i])
+	}
+
+	if rs.KanActor != nil || rs.PendingDoraReveals > 0 || rs.RobbedKanTile != nil {
+		kan := &Kan{
+			Actor:       seatIndex(rs.KanActor),
+			Replacement: kanReplacementNames[rs.KanReplacement],
+			PendingDora: rs.PendingDoraReveals,
+		}
+		if rs.RobbedKanTile != nil {
+			kan.Chankan = rs.RobbedKanTile.String()
+		}
+		snap.Kan = kan
+	}
+	if rs.ExtraSafeDiscard != nil {
+		snap.PendingAnpai = &PendingAnpai{
+			Actor: rs.ExtraSafeDiscard.Actor.Index(),
+			Pai:   rs.ExtraSafeDiscard.Tile.String(),
+		}
+	}
+	if rs.WinTarget != nil {
+		snap.Hora = &Hora{Target: rs.WinTarget.Index(), Actors: rs.WinActors[:]}
+	}
+	return snap
+}
+
+var kanReplacementNames = map[round.KanReplacement]string{
+	round.NoPendingReplacement:  "",
+	round.ReplacementBeforeDora: "before_dora",
+	round.ReplacementAfterDora:  "after_dora",
+}
+
+var riichiStateNames = map[player.RiichiState]string{
+	player.NotRiichi:      "",
+	player.RiichiDeclared: "declared",
+	player.RiichiAccepted: "accepted",
+}
+
+func fromPlayerSnapshot(ps *player.Snapshot) Player {
+	p := Player{
+		Tehai:     tileCodes(ps.Hand),
+		Kawa:      tileCodes(ps.River),
+		Sutehai:   tileCodes(ps.DiscardedTiles),
+		Tsumogiri: ps.DiscardTsumogiris,
+		Anpai:     tileCodes(ps.ExtraSafeTiles),
+		Reach:     riichiStateNames[ps.RiichiState],
+		Rinshan:   ps.NeedsDeadWallDraw,
+		Furiten:   ps.IsFuriten,
+	}
+	if ps.DrawnTile != nil {
+		p.Tsumo = ps.DrawnTile.String()
+	}
+	for _, m := range ps.Melds {
+		p.Furos = append(p.Furos, fromMeld(m))
+	}
+	if ps.RiichiState == player.RiichiAccepted {
+		p.ReachKawaIndex = &ps.RiichiRiverIndex
+		p.ReachSutehaiIndex = &ps.RiichiDiscardedTilesIndex
+	}
+	if ps.SwapCallTiles != nil {
+		p.Kuikae = make([]string, 0, len(ps.SwapCallTiles))
+		p.Kuikae = append(p.Kuikae, tileCodes(ps.SwapCallTiles)...)
+	}
+	return p
+}
+
+func fromMeld(m meld.Meld) Furo {
+	f := Furo{Consumed: tileCodes(m.Consumed())}
+	switch m := m.(type) {
+	case *meld.Chii:
+		f.Type = "chi"
+	case *meld.Pon:
+		f.Type = "pon"
+	case *meld.CalledKan:
+		f.Type = "daiminkan"
+	case *meld.ConcealedKan:
+		f.Type = "ankan"
+	case *meld.PromotedKan:
+		f.Type = "kakan"
+		f.Added = m.Added().String()
+	default:
+		panic(fmt.Sprintf("unsupported meld type: %T", m))
+	}
+	if m, ok := m.(meld.OpenMeld); ok {
+		f.Target = new(m.Target().Index())
+		f.Pai = m.Taken().String()
+	}
+	return f
+}
+
+// ToState restores the state described by the snapshot.
+func (s *Snapshot) ToState() (*round.State, error) {
+	if s.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", s.Version)
+	}
+	rs, err := s.toRoundSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return round.NewStateFromSnapshot(rs)
+}
+
+func (s *Snapshot) toRoundSnapshot() (*round.Snapshot, error) {
+	var err error
+	rs := &round.Snapshot{
+		RoundNumber:            s.Kyoku,
+		Honba:                  s.Honba,
+		RiichiDeposit:          s.Kyotaku,
+		NumLeftTiles:           s.NumLeftTiles,
+		LastDrawWasReplacement: s.Rinshan,
+		LegalActionsSuppressed: s.SuppressActions,
+		RoundEnded:             s.Ended,
+	}
+
+	if rs.RoundWind, err = wind.NewWind(s.Bakaze); err != nil {
+		return nil, fmt.Errorf("invalid bakaze: %w", err)
+	}
+	if rs.Dealer, err = parseSeatField("oya", s.Oya); err != nil {
+		return nil, err
+	}
+	if rs.StartingDealer, err = parseSeatField("chicha", s.Chicha); err != nil {
+		return nil, err
+	}
+	if rs.NextDraw, err = parseSeatField("next_tsumo", s.NextTsumo); err != nil {
+		return nil, err
+	}
+	if len(s.Scores) != common.NumPlayers {
+		return nil, fmt.Errorf("invalid scores length: %d", len(s.Scores))
+	}
+	rs.Scores = [common.NumPlayers]int(s.Scores)
+	if rs.DoraIndicators, err = parseTilesField("dora_markers", s.DoraMarkers); err != nil {
+		return nil, err
+	}
+
+	if len(s.Players) != common.NumPlayers {
+		return nil, fmt.Errorf("invalid players length: %d", len(s.Players))
+	}
+	for i := range s.Players {
+		if rs.Players[i], err = s.Players[i].toPlayerSnapshot(); err != nil {
+			return nil, fmt.Errorf("invalid player %d: %w", i, err)
+		}
+	}
+
+	if rs.PendingDiscard, err = parseOptionalSeatField("pending_dahai", s.PendingDahai); err != nil {
+		return nil, err
+	}
+	if rs.PendingRiichiAcceptance, err = parseOptionalSeatField("pending_reach_accepted", s.PendingReachAccepted); err != nil {
+		return nil, err
+	}
+	if rs.LastActor, err = parseOptionalSeatField("last_actor", s.LastActor); err != nil {
+		return nil, err
+	}
+	if s.Kan != nil {
+		if err := s.Kan.toRoundSnapshot(rs); err != nil {
+			return nil, err
+		}
+	}
+	if s.PendingAnpai != nil {
+		actor, err := parseSeatField("pending_anpai.actor", s.PendingAnpai.Actor)
+		if err != nil {
+			return nil, err
+		}
+		t, err := parseTileField("pending_anpai.pai", s.PendingAnpai.Pai)
+		if err != nil {
+			return nil, err
+		}
+		rs.ExtraSafeDiscard = &round.ExtraSafeDiscard{Actor: actor, Tile: t}
+	}
+
+	switch len(s.CanKyushukyuhai) {
+	case 0:
+	case common.NumPlayers:
+		rs.CanKyushukyuhai = [common.NumPlayers]bool(s.CanKyushukyuhai)
+	default:
+		return nil, fmt.Errorf("invalid can_kyushukyuhai length: %d", len(s.CanKyushukyuhai))
+	}
+
+	if s.Hora != nil {
+		target, err := parseSeatField("hora.target", s.Hora.Target)
+		if err != nil {
+			return nil, err
+		}
+		if len(s.Hora.Actors) != common.NumPlayers {
+			return nil, fmt.Errorf("invalid hora.actors length: %d", len(s.Hora.Actors))
+		}
+		rs.WinTarget = &target
+		rs.WinActors = [common.NumPlayers]bool(s.Hora.Actors)
+	}
+	return rs, nil
+}
+
+func (k *Kan) toRoundSnapshot(rs *round.Snapshot) error {
+	var err error
+	if rs.KanActor, err = parseOptionalSeatField("kan.actor", k.Actor); err != nil {
+		return err
+	}
+	switch k.Replacement {
+	case "":
+		rs.KanReplacement = round.NoPendingReplacement
+	case "before_dora":
+		rs.KanReplacement = round.ReplacementBeforeDora
+	case "after_dora":
+		rs.KanReplacement = round.ReplacementAfterDora
+	default:
+		return fmt.Errorf("invalid kan.replacement: %q", k.Replacement)
+	}
+	rs.PendingDoraReveals = k.PendingDora
+	if k.Chankan != "" {
+		t, err := parseTileField("kan.chankan", k.Chankan)
+		if err != nil {
+			return err
+		}
+		rs.RobbedKanTile = &t
+	}
+	return nil
+}
+
+func (p *Player) toPlayerSnapshot() (player.Snapshot, error) {
+	var err error
+	ps := player.Snapshot{
+		RiichiRiverIndex:          -1,
+		RiichiDiscardedTilesIndex: -1,
+		NeedsDeadWallDraw:         p.Rinshan,
+		IsFuriten:                 p.Furiten,
+	}
+
+	if ps.Hand, err = parseTilesField("tehai", p.Tehai); err != nil {
+		return player.Snapshot{}, err
+	}
+	if p.Tsumo != "" {
+		t, err := parseTileField("tsumo", p.Tsumo)
+		if err != nil {
+			return player.Snapshot{}, err
+		}
+		ps.DrawnTile = &t
+	}
+	for i := range p.Furos {
+		m, err := p.Furos[i].toMeld()
+		if err != nil {
+			return player.Snapshot{}, fmt.Errorf("invalid furos[%d]: %w", i, err)
+		}
+		ps.Melds = append(ps.Melds, m)
+	}
+	if ps.River, err = parseTilesField("kawa", p.Kawa); err != nil {
+		return player.Snapshot{}, err
+	}
+	if ps.DiscardedTiles, err = parseTilesField("sutehai", p.Sutehai); err != nil {
+		return player.Snapshot{}, err
+	}
+	ps.DiscardTsumogiris = p.Tsumogiri
+	if ps.ExtraSafeTiles, err = parseTilesField("anpai", p.Anpai); err != nil {
+		return player.Snapshot{}, err
+	}
+	if p.Kuikae != nil {
+		if ps.SwapCallTiles, err = parseTilesField("kuikae", p.Kuikae); err != nil {
+			return player.Snapshot{}, err
+		}
+		if ps.SwapCallTiles == nil {
+			ps.SwapCallTiles = tile.Tiles{}
+		}
+	}
+
+	switch p.Reach {
+	case "":
+		ps.RiichiState = player.NotRiichi
+	case "declared":
+		ps.RiichiState = player.RiichiDeclared
+	case "accepted":
+		ps.RiichiState = player.RiichiAccepted
+		// A hand-written board may omit the indices when the riichi tile is
+		// the last discard.
+		ps.RiichiRiverIndex = len(ps.River) - 1
+		ps.RiichiDiscardedTilesIndex = len(ps.DiscardedTiles) - 1
+		if p.ReachKawaIndex != nil {
+			ps.RiichiRiverIndex = *p.ReachKawaIndex
+		}
+		if p.ReachSutehaiIndex != nil {
+			ps.RiichiDiscardedTilesIndex = *p.ReachSutehaiIndex
+		}
+	default:
+		return player.Snapshot{}, fmt.Errorf("invalid reach: %q", p.Reach)
+	}
+	return ps, nil
+}
+
+func (f *Furo) toMeld() (meld.Meld, error) {
+	consumed, err := parseTilesField("consumed", f.Consumed)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Type == "ankan" {
+		if len(consumed) != 4 {
+			return nil, fmt.Errorf("consumed must contain 4 tiles, got %d", len(consumed))
+		}
+		return meld.NewConcealedKan([4]tile.Tile(consumed))
+	}
+
+	if f.Target == nil {
+		return nil, fmt.Errorf("missing target")
+	}
+	target, err := parseSeatField("target", *f.Target)
+	if err != nil {
+		return nil, err
+	}
+	taken, err := parseTileField("pai", f.Pai)
+	if err != nil {
+		return nil, err
+	}
+
+	switch f.Type {
+	case "chi", "pon":
+		if len(consumed) != 2 {
+			return nil, fmt.Errorf("consumed must contain 2 tiles, got %d", len(consumed))
+		}
+		if f.Type == "chi" {
+			return meld.NewChii(taken, [2]tile.Tile(consumed), target)
+		}
+		return meld.NewPon(taken, [2]tile.Tile(consumed), target)
+	case "daiminkan":
+		if len(consumed) != 3 {
+			return nil, fmt.Errorf("consumed must contain 3 tiles, got %d", len(consumed))
+		}
+		return meld.NewCalledKan(taken, [3]tile.Tile(consumed), target)
+	case "kakan":
+		if len(consumed) != 2 {
+			return nil, fmt.Errorf("consumed must contain 2 tiles, got %d", len(consumed))
+		}
+		added, err := parseTileField("added", f.Added)
+		if err != nil {
+			return nil, err
+		}
+		return meld.NewPromotedKan(taken, [2]tile.Tile(consumed), added, target)
+	default:
+		return nil, fmt.Errorf("invalid type: %q", f.Type)
+	}
+}
+
+func parseSeatField(name string, value int) (seat.Seat, error) {
+	s, err := seat.NewSeat(value)
+	if err != nil {
+		return seat.Seat{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return s, nil
+}
+
+func parseOptionalSeatField(name string, value *int) (*seat.Seat, error) {
+	if value == nil {
+		return nil, nil
+	}
+	s, err := parseSeatField(name, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func parseTileField(name string, value string) (tile.Tile, error) {
+	if value == "" {
+		return tile.Tile{}, fmt.Errorf("missing %s", name)
+	}
+	t, err := tile.NewTileFromCode(value)
+	if err != nil {
+		return tile.Tile{}, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return t, nil
+}
+
+func parseTilesField(name string, values []string) (tile.Tiles, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	tiles := make(tile.Tiles, len(values))
+	for i, value := range values {
+		t, err := parseTileField(fmt.Sprintf("%s[%d]", name, i), value)
+		if err != nil {
+			return nil, err
+		}
+		tiles[i] = t
+	}
+	return tiles, nil
+}
+
+func tileCodes(tiles []tile.Tile) []string {
+	if len(tiles) == 0 {
+		return nil
+	}
+	codes := make([]string, len(tiles))
+	for i, t := range tiles {
+		codes[i] = t.String()
+	}
+	return codes
+}
+
+func seatIndex(s *seat.Seat) *int {
+	if s == nil {
+		return nil
+	}
+	return new(s.Index())
+}
@@ -0,0 +1,109 @@
+// Package snapshot encodes round states as JSON using mjai tile codes and
+// mjai-style field names, so that a board can be saved in the middle of a
+// round, written by hand, and restored as a round.State.
+package snapshot
+
+import (
+	"encoding/json/v2"
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+)
+
+// Version is the current snapshot format version.
+const Version = 1
+
+type Snapshot struct {
+	Version      int      `json:"version"`
+	Bakaze       string   `json:"bakaze"`
+	Kyoku        int      `json:"kyoku"`
+	Honba        int      `json:"honba"`
+	Kyotaku      int      `json:"kyotaku"`
+	Oya          int      `json:"oya"`
+	Chicha       int      `json:"chicha"`
+	Scores       []int    `json:"scores"`
+	DoraMarkers  []string `json:"dora_markers"`
+	NumLeftTiles int      `json:"num_left_tiles"`
+	Players      []Player `json:"players"`
+
+	NextTsumo            int           `json:"next_tsumo"`
+	PendingDahai         *int          `json:"pending_dahai,omitzero"`
+	PendingReachAccepted *int          `json:"pending_reach_accepted,omitzero"`
+	Kan                  *Kan          `json:"kan,omitzero"`
+	PendingAnpai         *PendingAnpai `json:"pending_anpai,omitzero"`
+	Rinshan              bool          `json:"rinshan,omitzero"`
+	CanKyushukyuhai      []bool        `json:"can_kyushukyuhai,omitzero"`
+	LastActor            *int          `json:"last_actor,omitzero"`
+	SuppressActions      bool          `json:"suppress_actions,omitzero"`
+	Ended                bool          `json:"ended,omitzero"`
+	Hora                 *Hora         `json:"hora,omitzero"`
+}
+
+// Player is a player state. A tehai of "?" tiles describes a player whose
+// hand is hidden.
+type Player struct {
+	Tehai   []string `json:"tehai"`
+	Tsumo   string   `json:"tsumo,omitzero"`
+	Furos   []Furo   `json:"furos,omitzero"`
+	Kawa    []string `json:"kawa,omitzero"`
+	Sutehai []string `json:"sutehai,omitzero"`
+	// Tsumogiri parallels Sutehai. A hand-written board may omit it, which
+	// treats every discard as a tile from the hand.
+	Tsumogiri []bool   `json:"tsumogiri,omitzero"`
+	Anpai     []string `json:"anpai,omitzero"`
+	// Reach is "declared" or "accepted", or empty before riichi.
+	Reach             string `json:"reach,omitzero"`
+	ReachKawaIndex    *int   `json:"reach_kawa_index,omitzero"`
+	ReachSutehaiIndex *int   `json:"reach_sutehai_index,omitzero"`
+	// Kuikae is present, possibly empty, while the player must discard after
+	// a chi or pon.
+	Kuikae  []string `json:"kuikae,omitzero"`
+	Rinshan bool     `json:"rinshan,omitzero"`
+	Furiten bool     `json:"furiten,omitzero"`
+}
+
+// Furo is a meld in the shape of the mjai call message, except that Pai of a
+// kakan is the tile taken by the pon and Added is the promoting tile.
+type Furo struct {
+	Type     string   `json:"type"`
+	Target   *int     `json:"target,omitzero"`
+	Pai      string   `json:"pai,omitzero"`
+	Consumed []string `json:"consumed"`
+	Added    string   `json:"added,omitzero"`
+}
+
+// Kan is the progress of the last kan.
+type Kan struct {
+	Actor *int `json:"actor,omitzero"`
+	// Replacement is "before_dora" or "after_dora" while the replacement
+	// tile draw is pending.
+	Replacement string `json:"replacement,omitzero"`
+	PendingDora int    `json:"pending_dora,omitzero"`
+	Chankan     string `json:"chankan,omitzero"`
+}
+
+// PendingAnpai is the last discard that becomes a safe tile for the other
+// players unless it is called.
+type PendingAnpai struct {
+	Actor int    `json:"actor"`
+	Pai   string `json:"pai"`
+}
+
+type Hora struct {
+	Target int    `json:"target"`
+	Actors []bool `json:"actors"`
+}
+
+// Marshal encodes a state as a snapshot.
+func Marshal(s *round.State) ([]byte, error) {
+	return json.Marshal(FromState(s))
+}
+
+// Unmarshal decodes a snapshot and restores the state it describes.
+func Unmarshal(b []byte) (*round.State, error) {
+	var snap Snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return snap.ToState()
+}
@@ -0,0 +1,222 @@
+package snapshot_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/snapshot"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+func codesToHand(codes ...string) [common.InitHandSize]tile.Tile {
+	var hand [common.InitHandSize]tile.Tile
+	for i, code := range codes {
+		hand[i] = tile.MustTileFromCode(code)
+	}
+	return hand
+}
+
+func unknownHand() [common.InitHandSize]tile.Tile {
+	var hand [common.InitHandSize]tile.Tile
+	for i := range hand {
+		hand[i] = tile.MustTileFromCode("?")
+	}
+	return hand
+}
+
+// newStateAfterChi plays a round until player 1 calls chi and still has to
+// discard.
+func newStateAfterChi(t *testing.T) *round.State {
+	t.Helper()
+
+	scores := &[common.NumPlayers]int{25000, 25000, 25000, 25000}
+	s, err := round.NewState(event.NewStartRound(
+		wind.East,
+		2,
+		1,
+		1,
+		seat.MustSeat(1),
+		tile.MustTileFromCode("9p"),
+		scores,
+		[common.NumPlayers][common.InitHandSize]tile.Tile{
+			unknownHand(),
+			codesToHand("3m", "4m", "5mr", "6m", "2p", "3p", "4p", "6s", "7s", "8s", "S", "S", "N"),
+			unknownHand(),
+			unknownHand(),
+		},
+	), *scores)
+	if err != nil {
+		t.Fatalf("NewState() failed: %v", err)
+	}
+
+	events := []event.Event{
+		event.NewDraw(seat.MustSeat(1), tile.MustTileFromCode("C")),
+		event.NewDiscard(seat.MustSeat(1), tile.MustTileFromCode("C"), true),
+		event.NewDraw(seat.MustSeat(2), tile.MustTileFromCode("?")),
+		event.NewDiscard(seat.MustSeat(2), tile.MustTileFromCode("1s"), false),
+		event.NewDraw(seat.MustSeat(3), tile.MustTileFromCode("?")),
+		event.NewDiscard(seat.MustSeat(3), tile.MustTileFromCode("9s"), true),
+		event.NewDraw(seat.MustSeat(0), tile.MustTileFromCode("?")),
+		event.NewDiscard(seat.MustSeat(0), tile.MustTileFromCode("7m"), false),
+		event.NewChii(seat.MustSeat(1), seat.MustSeat(0), tile.MustTileFromCode("7m"),
+			[2]tile.Tile{tile.MustTileFromCode("5mr"), tile.MustTileFromCode("6m")}),
+	}
+	for _, ev := range events {
+		if err := s.Apply(ev); err != nil {
+			t.Fatalf("Apply(%T) failed: %v", ev, err)
+		}
+	}
+	return s
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	s := newStateAfterChi(t)
+
+	b, err := snapshot.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() failed: %v", err)
+	}
+	restored, err := snapshot.Unmarshal(b)
+	if err != nil {
+		t.Fatalf("Unmarshal(%s) failed: %v", b, err)
+	}
+	got, err := snapshot.Marshal(restored)
+	if err != nil {
+		t.Fatalf("Marshal(restored) failed: %v", err)
+	}
+
+	if string(got) != string(b) {
+		t.Errorf("Marshal(Unmarshal()) =\n%s\nwant\n%s", got, b)
+	}
+	if got, want := restored.RenderBoard(), s.RenderBoard(); got != want {
+		t.Errorf("restored RenderBoard() =\n%s\nwant\n%s", got, want)
+	}
+	for _, want := range []string{
+		`"furos":[{"type":"chi","target":0,"pai":"7m","consumed":["5mr","6m"]}]`,
+		`"kuikae":["4m","7m"]`,
+		`"pending_dahai":1`,
+		`"tehai":["?","?","?","?","?","?","?","?","?","?","?","?","?"]`,
+	} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("Marshal() = %s, want containing %s", b, want)
+		}
+	}
+}
+
+// handWrittenBoard is the dealer's first turn: the dealer has drawn a tile
+// and is ready to declare riichi.
+const handWrittenBoard = `{
+	"version": 1,
+	"bakaze": "S",
+	"kyoku": 3,
+	"honba": 2,
+	"kyotaku": 1,
+	"oya": 2,
+	"chicha": 0,
+	"scores": [24000, 26000, 25000, 24000],
+	"dora_markers": ["3s"],
+	"num_left_tiles": 69,
+	"players": [
+		{"tehai": ["?","?","?","?","?","?","?","?","?","?","?","?","?"]},
+		{"tehai": ["?","?","?","?","?","?","?","?","?","?","?","?","?"]},
+		{
+			"tehai": ["1m","2m","3m","4p","5p","6p","7s","8s","9s","E","E","S","W"],
+			"tsumo": "S"
+		},
+		{"tehai": ["?","?","?","?","?","?","?","?","?","?","?","?","?"]}
+	],
+	"next_tsumo": 2,
+	"pending_dahai": 2,
+	"last_actor": 2
+}`
+
+func TestUnmarshal_HandWrittenBoard(t *testing.T) {
+	s, err := snapshot.Unmarshal([]byte(handWrittenBoard))
+	if err != nil {
+		t.Fatalf("Unmarshal() failed: %v", err)
+	}
+
+	if got := s.Honba(); got != 2 {
+		t.Errorf("Honba() = %d, want 2", got)
+	}
+	if got := s.SeatWind(seat.MustSeat(2)); got != wind.East {
+		t.Errorf("SeatWind(2) = %v, want %v", got, wind.East)
+	}
+
+	actions, err := s.LegalActions(seat.MustSeat(2))
+	if err != nil {
+		t.Fatalf("LegalActions(2) failed: %v", err)
+	}
+	hasRiichi := false
+	for _, a := range actions {
+		if _, ok := a.(*action.Riichi); ok {
+			hasRiichi = true
+		}
+	}
+	if !hasRiichi {
+		t.Errorf("LegalActions(2) = %v, want containing riichi", actions)
+	}
+
+	if err := s.Apply(event.NewRiichi(seat.MustSeat(2))); err != nil {
+		t.Errorf("Apply(Riichi) failed: %v", err)
+	}
+}
+
+func TestUnmarshal_ReturnsError(t *testing.T) {
+	tests := []struct {
+		name    string
+		replace [2]string
+		wantErr string
+	}{
+		{
+			name:    "unsupported version",
+			replace: [2]string{`"version": 1`, `"version": 2`},
+			wantErr: "unsupported snapshot version: 2",
+		},
+		{
+			name:    "invalid tile code",
+			replace: [2]string{`"tsumo": "S"`, `"tsumo": "0z"`},
+			wantErr: "invalid player 2: invalid tsumo",
+		},
+		{
+			name:    "invalid seat",
+			replace: [2]string{`"oya": 2`, `"oya": 4`},
+			wantErr: "invalid oya",
+		},
+		{
+			name:    "invalid reach",
+			replace: [2]string{`"tsumo": "S"`, `"tsumo": "S", "reach": "yes"`},
+			wantErr: `invalid player 2: invalid reach: "yes"`,
+		},
+		{
+			name:    "broken invariant",
+			replace: [2]string{`"num_left_tiles": 69`, `"num_left_tiles": 70`},
+			wantErr: "number of left tiles 70 does not match 1 tiles drawn by players",
+		},
+		{
+			name:    "malformed JSON",
+			replace: [2]string{`"version": 1,`, `"version": 1`},
+			wantErr: "failed to parse snapshot",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := strings.Replace(handWrittenBoard, tt.replace[0], tt.replace[1], 1)
+
+			_, err := snapshot.Unmarshal([]byte(b))
+			if err == nil {
+				t.Fatal("Unmarshal() succeeded unexpectedly")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Unmarshal() error = %q, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package application
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
@@ -16,11 +19,19 @@ type Bot struct {
 	gameState    *game.State
 	currentRound *round.State
 	reporter     Reporter
+	// fallback decides when the agent fails. A nil fallback returns errors
+	// instead of playing through them.
+	fallback ai.Agent
+	// diverged means the round could not be followed.
+	diverged bool
+	// lastEvent is the last event observed.
+	lastEvent event.Event
 }
 
 type Reporter interface {
 	ReportRoundState(state round.BoardRenderer) error
 	ReportDecisionTrace(trace string) error
+	ReportIncident(incident Incident) error
 }
 
 func NewBot(self seat.Seat, agent ai.Agent, reporter Reporter) *Bot {
@@ -33,6 +44,9 @@ func NewBot(self seat.Seat, agent ai.Agent, reporter Reporter) *Bot {
 }
 
 func (b *Bot) Process(ev event.Event) (Reaction, error) {
+	if b.fallback != nil {
+		return b.processResiliently(ev)
+	}
 	switch ev := ev.(type) {
 	case *event.StartRound:
 		return b.processStartRound(ev)
@@ -56,36 +70,171 @@ func (b *Bot) processStartRound(ev *event.StartRound) (Reaction, error) {
 	return NewNoReaction(), nil
 }
 
-func (b *Bot) processRoundEvent(ev event.Event) (Reaction, error) {
-	if b.currentRound == nil {
-		return Reaction{}, fmt.Errorf("cannot process %T: round has not started", ev)
-	}
-	if err := b.currentRound.Apply(ev); err != nil {
+// processResiliently is Process for a resilient bot.
+func (b *Bot) processResiliently(ev event.Event) (Reaction, error) {
+	if err := b.Observe(ev); err != nil {
 		return Reaction{}, err
 	}
-	if err := b.reportRoundState(); err != nil {
+	switch ev.(type) {
+	case *event.StartRound, *event.EndRound:
+		return NewNoReaction(), nil
+	}
+	decision, ok, err := b.Decide()
+	if err != nil {
 		return Reaction{}, err
 	}
+	if !ok {
+		return NewNoReaction(), nil
+	}
+	return NewDecisionReaction(decision), nil
+}
+
+// Observe applies an event without asking the agent for a decision. It is for
+// feeding a known history to the bot before calling Decide. A resilient bot
+// reports an event it cannot apply and diverges instead of returning the
+// error.
+func (b *Bot) Observe(ev event.Event) error {
+	b.lastEvent = ev
+	if b.diverged {
+		return b.observeDiverged(ev)
+	}
+	err := b.observe(ev)
+	if _, ok := errors.AsType[*reportError](err); err != nil && !ok {
+		return b.Diverge(err)
+	}
+	return err
+}
 
-	legalActions, err := b.currentRound.LegalActions(b.self)
+func (b *Bot) observe(ev event.Event) error {
+	var err error
+	switch ev := ev.(type) {
+	case *event.StartRound:
+		_, err = b.processStartRound(ev)
+	case *event.EndRound:
+		_, err = b.processEndRound()
+	default:
+		err = b.applyRoundEvent(ev)
+	}
+	return err
+}
+
+// Self returns the seat of the bot.
+func (b *Bot) Self() seat.Seat {
+	return b.self
+}
+
+// LegalActions returns the legal actions of the bot in the current state.
+func (b *Bot) LegalActions() ([]action.Action, error) {
+	if b.diverged {
+		return nil, fmt.Errorf("cannot list legal actions: round state has diverged")
+	}
+	if b.currentRound == nil {
+		return nil, fmt.Errorf("cannot list legal actions: round has not started")
+	}
+	return b.currentRound.LegalActions(b.self)
+}
+
+// RenderBoard returns the board of the current round, or an empty string
+// outside a round.
+func (b *Bot) RenderBoard() string {
+	if b.currentRound == nil {
+		return ""
+	}
+	return b.currentRound.RenderBoard()
+}
+
+// RenderUkeire returns the ukeire of the hand of the bot, or an empty string
+// outside a round.
+func (b *Bot) RenderUkeire() string {
+	if b.currentRound == nil {
+		return ""
+	}
+	return b.currentRound.RenderUkeire(b.self)
+}
+
+// Decide asks the agent for a decision in the current state. It returns false
+// when the bot has no legal action.
+func (b *Bot) Decide() (ai.Decision, bool, error) {
+	return b.DecideAmong(nil)
+}
+
+// DecideAmong is Decide with the legal actions narrowed to those allowed
+// accepts. A nil allowed accepts every legal action.
+func (b *Bot) DecideAmong(allowed func(action.Action) bool) (ai.Decision, bool, error) {
+	if b.diverged {
+		decision, ok := b.decideDiverged()
+		return decision, ok, nil
+	}
+	if b.currentRound == nil {
+		return ai.Decision{}, false, fmt.Errorf("cannot decide: round has not started")
+	}
+	var state round.ActionStateViewer = b.currentRound
+	if allowed != nil {
+		state = &restrictedRound{State: b.currentRound, allowed: allowed}
+	}
+	legalActions, err := state.LegalActions(b.self)
 	if err != nil {
-		return Reaction{}, err
+		if err := b.Diverge(err); err != nil {
+			return ai.Decision{}, false, err
+		}
+		decision, ok := b.decideDiverged()
+		return decision, ok, nil
 	}
 	if len(legalActions) == 0 {
-		return NewNoReaction(), nil
+		return ai.Decision{}, false, nil
 	}
 
-	decision, err := b.agent.Decide(ai.Request{
+	decision, err := b.askAgent(state)
+	if err != nil {
+		if b.fallback == nil {
+			return ai.Decision{}, false, err
+		}
+		return b.decideWithFallback(state, err)
+	}
+	if err := b.reportDecisionTrace(decision.Trace); err != nil {
+		return ai.Decision{}, false, err
+	}
+	return decision, true, nil
+}
+
+// askAgent asks the agent for a decision. A resilient bot also plays through
+// a panic of the agent.
+func (b *Bot) askAgent(state round.ActionStateViewer) (decision ai.Decision, err error) {
+	if b.fallback != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("agent panicked: %v", r)
+			}
+		}()
+	}
+	return b.agent.Decide(ai.Request{
 		Self:  b.self,
-		Round: b.currentRound,
+		Round: state,
 	})
-	if err != nil {
+}
+
+func (b *Bot) processRoundEvent(ev event.Event) (Reaction, error) {
+	if err := b.applyRoundEvent(ev); err != nil {
 		return Reaction{}, err
 	}
-	if err := b.reportDecisionTrace(decision.Trace); err != nil {
+	decision, ok, err := b.Decide()
+	if err != nil {
 		return Reaction{}, err
 	}
-	return NewActionReaction(decision.Action, decision.Log), nil
+	if !ok {
+		return NewNoReaction(), nil
+	}
+	return NewDecisionReaction(decision), nil
+}
+
+func (b *Bot) applyRoundEvent(ev event.Event) error {
+	if b.currentRound == nil {
+		return fmt.Errorf("cannot process %T: round has not started", ev)
+	}
+	if err := b.currentRound.Apply(ev); err != nil {
+		return err
+	}
+	return b.reportRoundState()
 }
 
 func (b *Bot) processEndRound() (Reaction, error) {
@@ -99,11 +248,47 @@ func (b *Bot) processEndRound() (Reaction, error) {
 	return NewNoReaction(), nil
 }
 
+// restrictedRound shows the agent a round in which only the allowed actions
+// are legal.
+type restrictedRound struct {
+	*round.State
+	allowed func(action.Action) bool
+}
+
+func (r *restrictedRound) LegalActions(playerSeat seat.Seat) ([]action.Action, error) {
+	legalActions, err := r.State.LegalActions(playerSeat)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(legalActions, func(a action.Action) bool { return !r.allowed(a) }), nil
+}
+
+// reportError is an error of the reporter, which a resilient bot does not
+// play through.
+type reportError struct {
+	err error
+}
+
+func (e *reportError) Error() string {
+	return e.err.Error()
+}
+
+func (e *reportError) Unwrap() error {
+	return e.err
+}
+
+func wrapReportError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &reportError{err: err}
+}
+
 func (b *Bot) reportRoundState() error {
 	if b.reporter == nil || b.currentRound == nil {
 		return nil
 	}
-	return b.reporter.ReportRoundState(b.currentRound)
+	return wrapReportError(b.reporter.ReportRoundState(b.currentRound))
 }
 
 func (b *Bot) reportDecisionTrace(trace string) error {
@@ -113,5 +298,5 @@ func (b *Bot) reportDecisionTrace(trace string) error {
 	if b.reporter == nil {
 		return nil
 	}
-	return b.reporter.ReportDecisionTrace(trace)
+	return wrapReportError(b.reporter.ReportDecisionTrace(trace))
 }
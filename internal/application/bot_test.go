@@ -229,6 +229,93 @@ func TestBot_Process_EndRound(t *testing.T) {
 	}
 }
 
+func TestBot_ObserveThenDecide(t *testing.T) {
+	self := seat.MustSeat(0)
+	agent := &countingAgent{}
+	bot := application.NewBot(self, agent, nil)
+
+	drawnTile := tile.MustTileFromCode("6m")
+	for _, ev := range []event.Event{
+		mustNewStartRoundForTest(t, newValidHands()),
+		event.NewDraw(self, drawnTile),
+	} {
+		if err := bot.Observe(ev); err != nil {
+			t.Fatalf("Observe(%T) failed: %v", ev, err)
+		}
+	}
+	if agent.calls != 0 {
+		t.Fatalf("agent was called %d times while observing, want 0", agent.calls)
+	}
+
+	decision, ok, err := bot.Decide()
+	if err != nil {
+		t.Fatalf("Decide() failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("Decide() = false, want a decision")
+	}
+	if discard, isDiscard := decision.Action.(*action.Discard); !isDiscard || discard.Tile().ID() != drawnTile.ID() {
+		t.Errorf("Action = %v, want tsumogiri of %v", decision.Action, drawnTile)
+	}
+
+	if err := bot.Observe(event.NewDiscard(self, drawnTile, true)); err != nil {
+		t.Fatalf("Observe(Discard) failed: %v", err)
+	}
+	if _, ok, err := bot.Decide(); err != nil || ok {
+		t.Errorf("Decide() after discard = %v, %v, want no decision", ok, err)
+	}
+}
+
+func TestBot_DecideAmong(t *testing.T) {
+	self := seat.MustSeat(0)
+	bot := application.NewBot(self, newTsumogiriAgentForTest(), nil)
+
+	drawnTile := tile.MustTileFromCode("6m")
+	for _, ev := range []event.Event{
+		mustNewStartRoundForTest(t, newValidHands()),
+		event.NewDraw(self, drawnTile),
+	} {
+		if err := bot.Observe(ev); err != nil {
+			t.Fatalf("Observe(%T) failed: %v", ev, err)
+		}
+	}
+
+	// Forbidding the tsumogiri makes the tsumogiri agent fall back to another
+	// action.
+	decision, ok, err := bot.DecideAmong(func(a action.Action) bool {
+		discard, isDiscard := a.(*action.Discard)
+		return !isDiscard || !discard.Tsumogiri()
+	})
+	if err != nil {
+		t.Fatalf("DecideAmong() failed: %v", err)
+	}
+	if !ok {
+		t.Fatal("DecideAmong() = false, want a decision")
+	}
+	if discard, isDiscard := decision.Action.(*action.Discard); isDiscard && discard.Tsumogiri() {
+		t.Errorf("Action = %v, want an action other than the tsumogiri", decision.Action)
+	}
+
+	if _, ok, err := bot.DecideAmong(func(action.Action) bool { return false }); err != nil || ok {
+		t.Errorf("DecideAmong(nothing) = %v, %v, want no decision", ok, err)
+	}
+
+	legalActions, err := bot.LegalActions()
+	if err != nil {
+		t.Fatalf("LegalActions() failed: %v", err)
+	}
+	if len(legalActions) == 0 {
+		t.Error("LegalActions() is empty after restricted decisions")
+	}
+}
+
+func TestBot_Decide_BeforeStartRound(t *testing.T) {
+	bot := mustNewBotForTest(t, seat.MustSeat(0))
+	if _, _, err := bot.Decide(); err == nil {
+		t.Fatal("Decide() succeeded unexpectedly")
+	}
+}
+
 func TestBot_Process_ReportsRoundStateAfterStateUpdate(t *testing.T) {
 	self := seat.MustSeat(0)
 	reporter := &recordingReporter{}
@@ -306,6 +393,7 @@ type recordingReporter struct {
 	calls     int
 	lastBoard string
 	lastTrace string
+	incidents []application.Incident
 }
 
 func (r *recordingReporter) ReportRoundState(state round.BoardRenderer) error {
@@ -319,6 +407,11 @@ func (r *recordingReporter) ReportDecisionTrace(trace string) error {
 	return nil
 }
 
+func (r *recordingReporter) ReportIncident(incident application.Incident) error {
+	r.incidents = append(r.incidents, incident)
+	return nil
+}
+
 type errorReporter struct {
 	err error
 }
@@ -331,6 +424,10 @@ func (r errorReporter) ReportDecisionTrace(string) error {
 	return r.err
 }
 
+func (r errorReporter) ReportIncident(application.Incident) error {
+	return r.err
+}
+
 type traceAgent struct {
 	trace string
 }
@@ -346,6 +443,17 @@ func (a traceAgent) Decide(request ai.Request) (ai.Decision, error) {
 	return decision, nil
 }
 
+type countingAgent struct {
+	calls int
+}
+
+func (*countingAgent) Reset() {}
+
+func (a *countingAgent) Decide(request ai.Request) (ai.Decision, error) {
+	a.calls++
+	return ai.NewTsumogiriAgent().Decide(request)
+}
+
 type firstLegalActionAgent struct{}
 
 func (firstLegalActionAgent) Reset() {}
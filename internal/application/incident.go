@@ -0,0 +1,154 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+)
+
+type IncidentKind int
+
+const (
+	// IncidentDiverged means the bot could not follow the round, so it stops
+	// tracking the round until the next StartRound.
+	IncidentDiverged IncidentKind = iota + 1
+	// IncidentAgentFailed means the agent returned an error, so the fallback
+	// decided instead.
+	IncidentAgentFailed
+	// IncidentResynced means a StartRound brought a diverged bot back to
+	// tracking the game.
+	IncidentResynced
+)
+
+var incidentKindNames = [...]string{
+	IncidentDiverged:    "diverged",
+	IncidentAgentFailed: "agent failed",
+	IncidentResynced:    "resynced",
+}
+
+func (k IncidentKind) String() string {
+	if k <= 0 || int(k) >= len(incidentKindNames) {
+		return fmt.Sprintf("IncidentKind(%d)", int(k))
+	}
+	return incidentKindNames[k]
+}
+
+// Incident is an error a resilient bot played through.
+type Incident struct {
+	Kind IncidentKind
+	// Err is the error played through. It is nil for IncidentResynced.
+	Err error
+	// Board is the board of the round as the bot tracks it, empty outside a
+	// round.
+	Board string
+	// Fallback is the action played instead of the agent's for
+	// IncidentAgentFailed, nil when the bot did not act.
+	Fallback action.Action
+}
+
+// SetFallback makes the bot resilient: instead of returning errors, it
+// reports them as incidents and plays on.
+//
+// When an event cannot be applied, the bot stops tracking the round. Until
+// the next StartRound it only discards the tiles it draws, keeps the scores
+// that ending events carry, and otherwise does not act. When the agent fails,
+// fallback decides instead; when fallback fails too, the bot acts as if it
+// did not track the round. Errors of the reporter are still returned.
+func (b *Bot) SetFallback(fallback ai.Agent) {
+	b.fallback = fallback
+}
+
+// Diverged reports whether a resilient bot has stopped tracking the round.
+func (b *Bot) Diverged() bool {
+	return b.diverged
+}
+
+// Diverge stops tracking the round because of err, which the caller found
+// while handling an event, and reports it. It returns err when the bot is not
+// resilient.
+func (b *Bot) Diverge(err error) error {
+	if b.fallback == nil {
+		return err
+	}
+	board := b.RenderBoard()
+	b.diverged = true
+	return b.reportIncident(Incident{Kind: IncidentDiverged, Err: err, Board: board})
+}
+
+// observeDiverged follows the game, but not the round, while the bot has
+// diverged.
+func (b *Bot) observeDiverged(ev event.Event) error {
+	switch ev := ev.(type) {
+	case *event.StartRound:
+		b.currentRound = nil
+		if _, err := b.processStartRound(ev); err != nil {
+			if _, ok := errors.AsType[*reportError](err); ok {
+				return err
+			}
+			return b.reportIncident(Incident{Kind: IncidentDiverged, Err: err})
+		}
+		b.diverged = false
+		return b.reportIncident(Incident{Kind: IncidentResynced, Board: b.RenderBoard()})
+	case *event.EndRound:
+		b.currentRound = nil
+	case *event.Win:
+		b.updateScores(ev.Scores())
+	case *event.DrawRound:
+		b.updateScores(ev.Scores())
+	case *event.RiichiAccepted:
+		b.updateScores(ev.Scores())
+	}
+	return nil
+}
+
+func (b *Bot) updateScores(scores *[common.NumPlayers]int) {
+	if scores != nil {
+		b.gameState.UpdateScores(*scores)
+	}
+}
+
+// decideDiverged discards the drawn tile when the last event is a draw of
+// the bot, and does not act otherwise.
+func (b *Bot) decideDiverged() (ai.Decision, bool) {
+	draw, ok := b.lastEvent.(*event.Draw)
+	if !ok || draw.Actor() != b.self || draw.Tile().IsUnknown() {
+		return ai.Decision{}, false
+	}
+	discard, err := action.NewDiscard(b.self, draw.Tile(), true)
+	if err != nil {
+		return ai.Decision{}, false
+	}
+	return ai.Decision{Action: discard}, true
+}
+
+// decideWithFallback decides with the fallback after the agent failed with
+// agentErr.
+func (b *Bot) decideWithFallback(state round.ActionStateViewer, agentErr error) (ai.Decision, bool, error) {
+	decision, err := b.fallback.Decide(ai.Request{Self: b.self, Round: state})
+	ok := err == nil
+	if err != nil {
+		agentErr = errors.Join(agentErr, fmt.Errorf("fallback: %w", err))
+		decision, ok = b.decideDiverged()
+	}
+	if err := b.reportIncident(Incident{
+		Kind:     IncidentAgentFailed,
+		Err:      agentErr,
+		Board:    b.RenderBoard(),
+		Fallback: decision.Action,
+	}); err != nil {
+		return ai.Decision{}, false, err
+	}
+	return decision, ok, nil
+}
+
+func (b *Bot) reportIncident(incident Incident) error {
+	if b.reporter == nil {
+		return nil
+	}
+	return wrapReportError(b.reporter.ReportIncident(incident))
+}
@@ -0,0 +1,242 @@
+package application_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/application"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+type failingAgent struct {
+	err   error
+	panic bool
+}
+
+func (failingAgent) Reset() {}
+
+func (a failingAgent) Decide(ai.Request) (ai.Decision, error) {
+	if a.panic {
+		panic(a.err)
+	}
+	return ai.Decision{}, a.err
+}
+
+func assertTsumogiri(t *testing.T, got application.Reaction, want tile.Tile) {
+	t.Helper()
+
+	if got.Kind() != application.ReactionAction {
+		t.Fatalf("Kind() = %v, want %v", got.Kind(), application.ReactionAction)
+	}
+	discard, ok := got.Action().(*action.Discard)
+	if !ok || discard.Tile() != want || !discard.Tsumogiri() {
+		t.Errorf("Action() = %#v, want tsumogiri of %v", got.Action(), want)
+	}
+}
+
+func TestBot_Process_ResilientBotDivergesAndResyncs(t *testing.T) {
+	self := seat.MustSeat(0)
+	reporter := &recordingReporter{}
+	bot := application.NewBot(self, newTsumogiriAgentForTest(), reporter)
+	bot.SetFallback(newTsumogiriAgentForTest())
+
+	if _, err := bot.Process(mustNewStartRoundForTest(t, newValidHands())); err != nil {
+		t.Fatalf("Process(StartRound) failed: %v", err)
+	}
+	if _, err := bot.Process(event.NewDraw(self, tile.MustTileFromCode("6m"))); err != nil {
+		t.Fatalf("Process(first Draw) failed: %v", err)
+	}
+	// The second draw without a discard cannot be applied.
+	got, err := bot.Process(event.NewDraw(self, tile.MustTileFromCode("7m")))
+	if err != nil {
+		t.Fatalf("Process(second Draw) failed: %v", err)
+	}
+	assertTsumogiri(t, got, tile.MustTileFromCode("7m"))
+	if !bot.Diverged() {
+		t.Fatal("Diverged() = false, want true")
+	}
+	if len(reporter.incidents) != 1 || reporter.incidents[0].Kind != application.IncidentDiverged || reporter.incidents[0].Err == nil {
+		t.Fatalf("incidents = %+v, want one diverged incident with the error", reporter.incidents)
+	}
+	if reporter.incidents[0].Board == "" {
+		t.Error("diverged incident has no board")
+	}
+
+	got, err = bot.Process(event.NewDiscard(seat.MustSeat(1), tile.MustTileFromCode("1m"), true))
+	if err != nil {
+		t.Fatalf("Process(other Discard) failed: %v", err)
+	}
+	if got.Kind() != application.ReactionNone {
+		t.Errorf("Kind() after other discard = %v, want %v", got.Kind(), application.ReactionNone)
+	}
+	if _, err := bot.LegalActions(); err == nil {
+		t.Error("LegalActions() succeeded after diverging")
+	}
+
+	scores := [common.NumPlayers]int{33000, 17000, 25000, 25000}
+	if _, err := bot.Process(event.NewDrawRound("fanpai", nil, nil, &scores)); err != nil {
+		t.Fatalf("Process(DrawRound) failed: %v", err)
+	}
+	if _, err := bot.Process(event.NewEndRound()); err != nil {
+		t.Fatalf("Process(EndRound) failed: %v", err)
+	}
+	if _, err := bot.Process(mustNewStartRoundForTest(t, newValidHands())); err != nil {
+		t.Fatalf("Process(StartRound) after diverging failed: %v", err)
+	}
+	if bot.Diverged() {
+		t.Fatal("Diverged() = true after StartRound, want false")
+	}
+	if len(reporter.incidents) != 2 || reporter.incidents[1].Kind != application.IncidentResynced {
+		t.Fatalf("incidents = %+v, want a resynced incident", reporter.incidents)
+	}
+	got, err = bot.Process(event.NewDraw(self, tile.MustTileFromCode("6m")))
+	if err != nil {
+		t.Fatalf("Process(Draw) after resyncing failed: %v", err)
+	}
+	assertTsumogiri(t, got, tile.MustTileFromCode("6m"))
+}
+
+func TestBot_Process_ResilientBotKeepsScoresWhileDiverged(t *testing.T) {
+	self := seat.MustSeat(0)
+	agent := &scoresAgent{Agent: newTsumogiriAgentForTest()}
+	bot := application.NewBot(self, agent, nil)
+	bot.SetFallback(newTsumogiriAgentForTest())
+
+	if _, err := bot.Process(mustNewStartRoundForTest(t, newValidHands())); err != nil {
+		t.Fatalf("Process(StartRound) failed: %v", err)
+	}
+	if err := bot.Diverge(errors.New("lost track")); err != nil {
+		t.Fatalf("Diverge() failed: %v", err)
+	}
+	scores := [common.NumPlayers]int{33000, 17000, 25000, 25000}
+	if _, err := bot.Process(event.NewDrawRound("fanpai", nil, nil, &scores)); err != nil {
+		t.Fatalf("Process(DrawRound) failed: %v", err)
+	}
+	if _, err := bot.Process(event.NewEndRound()); err != nil {
+		t.Fatalf("Process(EndRound) failed: %v", err)
+	}
+	startRound := event.NewStartRound(
+		wind.East,
+		2,
+		0,
+		0,
+		seat.MustSeat(1),
+		tile.MustTileFromCode("E"),
+		nil,
+		newValidHands(),
+	)
+	if _, err := bot.Process(startRound); err != nil {
+		t.Fatalf("Process(StartRound) without scores failed: %v", err)
+	}
+	for i := 1; i < common.NumPlayers; i++ {
+		other := seat.MustSeat(i)
+		if _, err := bot.Process(event.NewDraw(other, tile.MustTileFromCode("?"))); err != nil {
+			t.Fatalf("Process(other Draw) failed: %v", err)
+		}
+		if _, err := bot.Process(event.NewDiscard(other, tile.MustTileFromCode("9s"), true)); err != nil {
+			t.Fatalf("Process(other Discard) failed: %v", err)
+		}
+	}
+	if _, err := bot.Process(event.NewDraw(self, tile.MustTileFromCode("6m"))); err != nil {
+		t.Fatalf("Process(Draw) failed: %v", err)
+	}
+	if agent.scores != scores {
+		t.Errorf("scores = %v, want %v", agent.scores, scores)
+	}
+}
+
+type scoresAgent struct {
+	ai.Agent
+	scores [common.NumPlayers]int
+}
+
+func (a *scoresAgent) Decide(request ai.Request) (ai.Decision, error) {
+	a.scores = request.Round.Scores()
+	return a.Agent.Decide(request)
+}
+
+func TestBot_Process_ResilientBotFallsBackWhenAgentFails(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent failingAgent
+	}{
+		{name: "error", agent: failingAgent{err: errors.New("evaluation failed")}},
+		{name: "panic", agent: failingAgent{err: errors.New("index out of range"), panic: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			self := seat.MustSeat(0)
+			reporter := &recordingReporter{}
+			bot := application.NewBot(self, tt.agent, reporter)
+			bot.SetFallback(newTsumogiriAgentForTest())
+
+			if _, err := bot.Process(mustNewStartRoundForTest(t, newValidHands())); err != nil {
+				t.Fatalf("Process(StartRound) failed: %v", err)
+			}
+			got, err := bot.Process(event.NewDraw(self, tile.MustTileFromCode("6m")))
+			if err != nil {
+				t.Fatalf("Process(Draw) failed: %v", err)
+			}
+			assertTsumogiri(t, got, tile.MustTileFromCode("6m"))
+			if bot.Diverged() {
+				t.Error("Diverged() = true, want false after an agent failure")
+			}
+			if len(reporter.incidents) != 1 {
+				t.Fatalf("incidents = %+v, want one", reporter.incidents)
+			}
+			incident := reporter.incidents[0]
+			if incident.Kind != application.IncidentAgentFailed || incident.Err == nil || incident.Fallback != got.Action() {
+				t.Errorf("incident = %+v, want agent failed with the fallback action", incident)
+			}
+		})
+	}
+}
+
+func TestBot_Process_ResilientBotDiscardsDrawWhenFallbackFails(t *testing.T) {
+	self := seat.MustSeat(0)
+	reporter := &recordingReporter{}
+	bot := application.NewBot(self, failingAgent{err: errors.New("agent failed")}, reporter)
+	bot.SetFallback(failingAgent{err: errors.New("fallback failed")})
+
+	if _, err := bot.Process(mustNewStartRoundForTest(t, newValidHands())); err != nil {
+		t.Fatalf("Process(StartRound) failed: %v", err)
+	}
+	got, err := bot.Process(event.NewDraw(self, tile.MustTileFromCode("6m")))
+	if err != nil {
+		t.Fatalf("Process(Draw) failed: %v", err)
+	}
+	assertTsumogiri(t, got, tile.MustTileFromCode("6m"))
+	if len(reporter.incidents) != 1 || reporter.incidents[0].Kind != application.IncidentAgentFailed {
+		t.Fatalf("incidents = %+v, want one agent failed incident", reporter.incidents)
+	}
+}
+
+func TestBot_Process_ResilientBotReturnsReporterError(t *testing.T) {
+	wantErr := errors.New("report failed")
+	bot := application.NewBot(seat.MustSeat(0), newTsumogiriAgentForTest(), errorReporter{err: wantErr})
+	bot.SetFallback(newTsumogiriAgentForTest())
+
+	if _, err := bot.Process(mustNewStartRoundForTest(t, newValidHands())); !errors.Is(err, wantErr) {
+		t.Errorf("Process() error = %v, want %v", err, wantErr)
+	}
+	if bot.Diverged() {
+		t.Error("Diverged() = true, want false after a reporter error")
+	}
+}
+
+func TestBot_Diverge_ReturnsErrorWithoutFallback(t *testing.T) {
+	bot := mustNewBotForTest(t, seat.MustSeat(0))
+	wantErr := errors.New("lost track")
+	if err := bot.Diverge(wantErr); !errors.Is(err, wantErr) {
+		t.Errorf("Diverge() = %v, want %v", err, wantErr)
+	}
+	if bot.Diverged() {
+		t.Error("Diverged() = true, want false without fallback")
+	}
+}
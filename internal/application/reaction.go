@@ -1,6 +1,9 @@
 package application
 
-import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+)
 
 type ReactionKind int
 
@@ -10,9 +13,10 @@ const (
 )
 
 type Reaction struct {
-	kind   ReactionKind
-	action action.Action
-	log    string
+	kind       ReactionKind
+	action     action.Action
+	log        string
+	candidates []ai.CandidateEvaluation
 }
 
 func NewNoReaction() Reaction {
@@ -27,6 +31,14 @@ func NewActionReaction(a action.Action, log string) Reaction {
 	}
 }
 
+// NewDecisionReaction returns the reaction of an agent decision, which keeps
+// the evaluated candidates.
+func NewDecisionReaction(decision ai.Decision) Reaction {
+	r := NewActionReaction(decision.Action, decision.Log)
+	r.candidates = decision.Candidates
+	return r
+}
+
 func (r Reaction) Kind() ReactionKind {
 	return r.kind
 }
@@ -38,3 +50,9 @@ func (r Reaction) Action() action.Action {
 func (r Reaction) Log() string {
 	return r.log
 }
+
+// Candidates returns the evaluated candidates of the decision from best to
+// worst, if any.
+func (r Reaction) Candidates() []ai.CandidateEvaluation {
+	return r.candidates
+}
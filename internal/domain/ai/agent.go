@@ -15,9 +15,46 @@ type Decision struct {
 	Action action.Action
 	Log    string
 	Trace  string
+	// Candidates lists the evaluated candidates from best to worst. It is
+	// empty when the agent decides without evaluating candidates.
+	Candidates []CandidateEvaluation
+}
+
+// CandidateEvaluation is the evaluation of a candidate action, as shown in a
+// row of the decision log.
+type CandidateEvaluation struct {
+	// Key names the candidate in the decision log, such as "-1.9s".
+	Key string
+	// Action is the immediate action. For a riichi candidate it is the riichi
+	// declaration, and Key names the discard that follows.
+	Action                      action.Action
+	AverageRank                 float64
+	ExpectedPoints              float64
+	DealInProb                  float64
+	WinProb                     float64
+	ExhaustiveDrawProb          float64
+	OtherWinProb                float64
+	AverageWinPoints            float64
+	ExhaustiveDrawAveragePoints float64
+	// Shanten is the shanten number after the action, or
+	// service.InfinityShanten when the candidate gives up winning.
+	Shanten int
 }
 
 type Agent interface {
 	Reset()
 	Decide(request Request) (Decision, error)
 }
+
+// PlayerNamesReceiver is implemented by agents that adapt to the players at
+// the table. Drivers call SetPlayerNames after Reset when start_game names the
+// players; names are in seat order.
+type PlayerNamesReceiver interface {
+	SetPlayerNames(names []string)
+}
+
+// FallbackProvider is implemented by agents that know a simpler agent to
+// fall back on when they fail. Resilient drivers use it.
+type FallbackProvider interface {
+	Fallback() Agent
+}
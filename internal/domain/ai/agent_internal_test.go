@@ -129,6 +129,29 @@ func TestNewManueAgent(t *testing.T) {
 	}
 }
 
+func TestNewManueAgent_WinTrials(t *testing.T) {
+	agent, err := NewManueAgent(0, ManueAgentDeps{Stats: validStubManueStats(), Danger: stubDangerEstimator{}})
+	if err != nil {
+		t.Fatalf("NewManueAgent() failed: %v", err)
+	}
+	if agent.evaluator.trials != DefaultWinTrials {
+		t.Errorf("evaluator.trials = %d, want %d", agent.evaluator.trials, DefaultWinTrials)
+	}
+
+	agent, err = NewManueAgent(0, ManueAgentDeps{Stats: validStubManueStats(), Danger: stubDangerEstimator{}, WinTrials: 200})
+	if err != nil {
+		t.Fatalf("NewManueAgent() failed: %v", err)
+	}
+	agent.Reset()
+	if agent.evaluator.trials != 200 {
+		t.Errorf("evaluator.trials = %d after Reset(), want 200", agent.evaluator.trials)
+	}
+
+	if _, err := NewManueAgent(0, ManueAgentDeps{Stats: validStubManueStats(), Danger: stubDangerEstimator{}, WinTrials: -1}); err == nil {
+		t.Error("NewManueAgent() succeeded with negative win trials")
+	}
+}
+
 func TestManueAgent_decideSelfTurn_ReturnsOriginalStyleActionLog(t *testing.T) {
 	self := seat.MustSeat(0)
 	discard, err := action.NewDiscard(self, tile.MustTileFromCode("5m"), false)
@@ -275,6 +298,12 @@ func TestBuildCandidateDecision_ReturnsCallActionForWinningReactionCandidate(t *
 	if !strings.Contains(decision.Trace, "decidedKey 0.4m\n") {
 		t.Errorf("Trace = %q, want selected call candidate trace key", decision.Trace)
 	}
+	if len(decision.Candidates) != 2 || decision.Candidates[0].Key != "0.4m" || decision.Candidates[1].Key != "none" {
+		t.Fatalf("Candidates = %+v, want 0.4m then none", decision.Candidates)
+	}
+	if got := decision.Candidates[1]; got.Action != pass || got.AverageRank != 2.0 || got.ExpectedPoints != 1000 {
+		t.Errorf("Candidates[1] = %+v, want the pass evaluation", got)
+	}
 }
 
 func TestBuildCandidateDecision(t *testing.T) {
@@ -358,4 +387,7 @@ func TestBuildCandidateDecision_CanIgnoreBlackPreference(t *testing.T) {
 	if !strings.Contains(decision.Trace, "decidedKey -1.5mr\n") {
 		t.Errorf("Trace = %q, want selected red decidedKey", decision.Trace)
 	}
+	if len(decision.Candidates) != 2 || decision.Candidates[0].Key != "-1.5mr" {
+		t.Errorf("Candidates = %+v, want the selected candidate first", decision.Candidates)
+	}
 }
@@ -15,7 +15,9 @@ type stubPlayerViewer struct {
 	hand                      *hand.VisibleHand
 	riichiState               player.RiichiState
 	drawnTile                 *tile.Tile
+	melds                     []meld.Meld
 	discardedTiles            []tile.Tile
+	tsumogiris                []bool
 	riichiDiscardedTilesIndex int
 	hasRiichiDiscardIndex     bool
 }
@@ -28,9 +30,10 @@ func (p stubPlayerViewer) Hand() (*hand.VisibleHand, bool) {
 }
 func (p stubPlayerViewer) HandTiles() []tile.Tile          { return nil }
 func (p stubPlayerViewer) DrawnTile() *tile.Tile           { return p.drawnTile }
-func (p stubPlayerViewer) Melds() []meld.Meld              { return nil }
+func (p stubPlayerViewer) Melds() []meld.Meld              { return p.melds }
 func (p stubPlayerViewer) River() []tile.Tile              { return p.discardedTiles }
 func (p stubPlayerViewer) DiscardedTiles() []tile.Tile     { return p.discardedTiles }
+func (p stubPlayerViewer) DiscardTsumogiris() []bool       { return p.tsumogiris }
 func (p stubPlayerViewer) ExtraSafeTiles() []tile.Tile     { return nil }
 func (p stubPlayerViewer) IsFuriten() bool                 { return false }
 func (p stubPlayerViewer) CanRonBy(*tile.Tile) bool        { return true }
@@ -1,6 +1,11 @@
 package ai
 
-import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+import (
+	"maps"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+)
 
 // aheadVector is a NumPlayers-length 0/1 vector for rank estimation.
 //
@@ -21,13 +26,20 @@ func newAheadVectorProbDist(items map[aheadVector]float64) aheadVectorProbDist {
 	return dist
 }
 
+// values returns the outcomes in lexicographic order.
+func (d aheadVectorProbDist) values() []aheadVector {
+	return slices.SortedFunc(maps.Keys(d), func(lhs, rhs aheadVector) int {
+		return slices.Compare(lhs[:], rhs[:])
+	})
+}
+
 // mapValueScalar maps ahead-vector outcomes to scalar outcomes
 // while preserving their probabilities. Outcomes with the same mapped value are
 // merged.
 func (d aheadVectorProbDist) mapValueScalar(mapper func(aheadVector) float64) scalarProbDist {
 	scalars := make(scalarProbDist, len(d))
-	for value, prob := range d {
-		scalars[mapper(value)] += prob
+	for _, value := range d.values() {
+		scalars[mapper(value)] += d[value]
 	}
 	return newScalarProbDist(scalars)
 }
@@ -37,8 +49,8 @@ func (d aheadVectorProbDist) mapValueScalar(mapper func(aheadVector) float64) sc
 // merged.
 func (d aheadVectorProbDist) mapValueScoreDelta(mapper func(aheadVector) scoreDelta) scoreDeltaProbDist {
 	scoreDeltas := make(scoreDeltaProbDist, len(d))
-	for value, prob := range d {
-		scoreDeltas[mapper(value)] += prob
+	for _, value := range d.values() {
+		scoreDeltas[mapper(value)] += d[value]
 	}
 	return newScoreDeltaProbDist(scoreDeltas)
 }
@@ -47,13 +59,14 @@ func (d aheadVectorProbDist) mapValueScoreDelta(mapper func(aheadVector) scoreDe
 // two ahead-vector random variables are independent.
 func addAheadVectorProbDists(lhs, rhs aheadVectorProbDist) aheadVectorProbDist {
 	dist := make(aheadVectorProbDist, len(lhs)*len(rhs))
-	for lhsValue, lhsProb := range lhs {
-		for rhsValue, rhsProb := range rhs {
+	rhsValues := rhs.values()
+	for _, lhsValue := range lhs.values() {
+		for _, rhsValue := range rhsValues {
 			var value aheadVector
 			for i := range value {
 				value[i] = lhsValue[i] + rhsValue[i]
 			}
-			dist[value] += lhsProb * rhsProb
+			dist[value] += lhs[lhsValue] * rhs[rhsValue]
 		}
 	}
 	return newAheadVectorProbDist(dist)
@@ -20,7 +20,7 @@ func buildReactionCandidates(actions []action.Action, self player.PlayerViewer)
 
 	candidates := make([]actionCandidate, 0, len(actions))
 	if pass := firstActionOfType[*action.Pass](actions); pass != nil {
-		shanten, goals := service.AnalyzeShanten(h, service.AllowedExtraTiles(1))
+		shanten, goals := service.AnalyzeShanten(h, service.AllowedExtraTiles(1), service.Engine(service.TableEngine))
 		unknown := tile.MustTileFromCode("?")
 		// Passing the call keeps the hand closed, so it can still use original
 		// Manue's reachMode="default" future-riichi scoring.
@@ -70,7 +70,7 @@ func buildCallReactionCandidates(
 		return nil, fmt.Errorf("cannot build reaction candidates for call %d: %w", callIndex, err)
 	}
 	nextMelds := append(slices.Clone(baseMelds), callMeld)
-	turnShanten, turnGoals := service.AnalyzeShanten(turnHand, service.AllowedExtraTiles(1))
+	turnShanten, turnGoals := service.AnalyzeShanten(turnHand, service.AllowedExtraTiles(1), service.Engine(service.TableEngine))
 
 	// Original Manue passes reachMode="default" to call candidates, but addYaku
 	// gives riichi 0 han when the goal has furos. Represent that effective
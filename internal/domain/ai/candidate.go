@@ -52,7 +52,7 @@ func chooseBestCandidate(candidates []evaluatedActionCandidate, preferBlack bool
 
 func sortedCandidates(candidates []evaluatedActionCandidate, preferBlack bool) []evaluatedActionCandidate {
 	sortedCandidates := slices.Clone(candidates)
-	slices.SortFunc(sortedCandidates, func(lhs, rhs evaluatedActionCandidate) int {
+	slices.SortStableFunc(sortedCandidates, func(lhs, rhs evaluatedActionCandidate) int {
 		return compareCandidates(lhs, rhs, preferBlack)
 	})
 	return sortedCandidates
@@ -13,6 +13,10 @@ type candidateScore struct {
 	expectedPoints float64
 	// dealInProb is the deal-in probability.
 	dealInProb float64
+	// plannedDealInProb is the deal-in probability on the later discards of
+	// the defense plan. planned reports that the candidate has a plan.
+	plannedDealInProb float64
+	planned           bool
 	// winProb is the win probability.
 	winProb float64
 	// exhaustiveDrawProb is the exhaustive draw probability.
@@ -47,6 +51,8 @@ func evaluateCandidateFromComponents(
 	exhaustiveDrawProbOnSelfNoWin float64,
 	exhaustiveDrawAveragePoints float64,
 	immediateDist scoreDeltaProbDist,
+	plannedEstimates []dealInEstimate,
+	plannedDist scoreDeltaProbDist,
 	selfWinDist scoreDeltaProbDist,
 	exhaustiveDrawDist scoreDeltaProbDist,
 	otherWinDists []scoreDeltaProbDist,
@@ -55,6 +61,14 @@ func evaluateCandidateFromComponents(
 	self seat.Seat,
 ) (candidateScore, error) {
 	var score candidateScore
+	if plannedDist != nil {
+		plannedSafeProb, err := safeProb(plannedEstimates)
+		if err != nil {
+			return candidateScore{}, err
+		}
+		score.plannedDealInProb = 1.0 - plannedSafeProb
+		score.planned = true
+	}
 	safeProb, err := safeProb(dealInEstimates)
 	if err != nil {
 		return candidateScore{}, err
@@ -80,6 +94,11 @@ func evaluateCandidateFromComponents(
 		otherWinDists,
 		score.otherWinProb,
 	)
+	// The later discards of a defense plan come after the immediate one
+	// passes and before the rest of the round.
+	if plannedDist != nil {
+		futureDist = plannedDist.replace(scoreDelta{}, futureDist)
+	}
 	scoreChanges := immediateDist.replace(scoreDelta{}, futureDist)
 	scores := state.Scores()
 	startingDealer := state.StartingDealer()
@@ -3,6 +3,7 @@ package ai
 import (
 	"fmt"
 
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai/dangerfeature"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
@@ -16,11 +17,54 @@ type DangerTreeNode interface {
 }
 
 type DecisionTreeDangerEstimator struct {
-	root DangerTreeNode
+	root *dangerTreeNode
+	// err is why the tree cannot be parsed.
+	err error
 }
 
+// dangerTreeNode is a node of the danger tree with its feature parsed.
+type dangerTreeNode struct {
+	leaf     bool
+	prob     float64
+	feature  dangerfeature.Feature
+	negative *dangerTreeNode
+	positive *dangerTreeNode
+}
+
+// NewDangerEstimator parses the features of the tree at root once. When the
+// tree cannot be parsed, EstimateDealInProb returns the error.
 func NewDangerEstimator(root DangerTreeNode) *DecisionTreeDangerEstimator {
-	return &DecisionTreeDangerEstimator{root: root}
+	if root == nil {
+		return &DecisionTreeDangerEstimator{}
+	}
+	tree, err := parseDangerTree(root)
+	return &DecisionTreeDangerEstimator{root: tree, err: err}
+}
+
+func parseDangerTree(node DangerTreeNode) (*dangerTreeNode, error) {
+	if node == nil {
+		return nil, fmt.Errorf("cannot estimate deal-in probability: danger tree branch is nil")
+	}
+	if prob, ok := node.LeafProb(); ok {
+		return &dangerTreeNode{leaf: true, prob: prob}, nil
+	}
+	name, ok := node.Feature()
+	if !ok {
+		return nil, fmt.Errorf("cannot estimate deal-in probability: non-leaf node has no feature")
+	}
+	feature, err := dangerfeature.Parse(name)
+	if err != nil {
+		return nil, err
+	}
+	negative, err := parseDangerTree(node.NegativeNode())
+	if err != nil {
+		return nil, err
+	}
+	positive, err := parseDangerTree(node.PositiveNode())
+	if err != nil {
+		return nil, err
+	}
+	return &dangerTreeNode{feature: feature, negative: negative, positive: positive}, nil
 }
 
 func (e *DecisionTreeDangerEstimator) EstimateDealInProb(
@@ -29,36 +73,27 @@ func (e *DecisionTreeDangerEstimator) EstimateDealInProb(
 	winner seat.Seat,
 	discard tile.Tile,
 ) (float64, error) {
-	if e == nil || e.root == nil {
+	if e == nil || (e.root == nil && e.err == nil) {
 		return 0, fmt.Errorf("cannot estimate deal-in probability: danger tree is nil")
 	}
+	if e.err != nil {
+		return 0, e.err
+	}
 	discard = discard.RemoveRed()
 	if state.SafeTiles(winner).ContainsSameSymbol(discard) {
 		return 0, nil
 	}
-	scene := newDangerScene(state, self, winner)
-	return estimateDangerTreeProb(e.root, scene, discard)
+	scene := dangerfeature.NewScene(state, self, winner)
+	return estimateDangerTreeProb(e.root, &scene, discard), nil
 }
 
-func estimateDangerTreeProb(root DangerTreeNode, scene dangerScene, discard tile.Tile) (float64, error) {
-	node := root
-	for node != nil {
-		if prob, ok := node.LeafProb(); ok {
-			return prob, nil
-		}
-		feature, ok := node.Feature()
-		if !ok {
-			return 0, fmt.Errorf("cannot estimate deal-in probability: non-leaf node has no feature")
-		}
-		value, err := scene.evaluate(feature, discard)
-		if err != nil {
-			return 0, err
-		}
-		if value {
-			node = node.PositiveNode()
+func estimateDangerTreeProb(node *dangerTreeNode, scene *dangerfeature.Scene, discard tile.Tile) float64 {
+	for !node.leaf {
+		if node.feature.Eval(scene, discard) {
+			node = node.positive
 		} else {
-			node = node.NegativeNode()
+			node = node.negative
 		}
 	}
-	return 0, fmt.Errorf("cannot estimate deal-in probability: danger tree branch is nil")
+	return node.prob
 }
@@ -0,0 +1,351 @@
+// Package dangerfeature is the registry of the danger features: the
+// questions about a discard that the danger tree branches on. The bot
+// evaluates them at play time and estimate_danger extracts them for
+// training, so both share the definitions here. Where the CoffeeScript bot
+// and the Ruby training tool disagree, the features follow the bot, so a tree
+// trained on the extracted vectors branches on what the bot evaluates.
+package dangerfeature
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+type evalFunc func(s *Scene, discard tile.Tile) bool
+
+// Feature is a parsed danger feature.
+type Feature struct {
+	name string
+	eval evalFunc
+}
+
+func (f Feature) Name() string {
+	return f.name
+}
+
+// Eval reports whether the feature holds for discard in s. discard must not
+// be a red five.
+func (f Feature) Eval(s *Scene, discard tile.Tile) bool {
+	return f.eval(s, discard)
+}
+
+var fixedFeatures = map[string]evalFunc{
+	"anpai": func(s *Scene, discard tile.Tile) bool {
+		return tile.Tiles(s.safeTiles).ContainsSameSymbol(discard)
+	},
+	"tsupai": func(_ *Scene, discard tile.Tile) bool {
+		return discard.IsHonors()
+	},
+	"dora": func(s *Scene, discard tile.Tile) bool {
+		return tile.Tiles(s.doras).ContainsSameSymbol(discard)
+	},
+	"dora_suji": func(s *Scene, discard tile.Tile) bool {
+		return isSujiOf(discard, s.doras, true)
+	},
+	"dora_matagi": func(s *Scene, discard tile.Tile) bool {
+		return isMatagisujiOf(discard, s.doras, s.safeTiles)
+	},
+	"fanpai": func(s *Scene, discard tile.Tile) bool {
+		return fanpaiValue(discard, s.roundWind, s.targetWind) >= 1
+	},
+	"ryenfonpai": func(s *Scene, discard tile.Tile) bool {
+		return fanpaiValue(discard, s.roundWind, s.targetWind) >= 2
+	},
+	"fonpai": func(_ *Scene, discard tile.Tile) bool {
+		return discard.IsWind()
+	},
+	"sangenpai": func(_ *Scene, discard tile.Tile) bool {
+		return discard.IsDragon()
+	},
+	"bakaze": func(s *Scene, discard tile.Tile) bool {
+		return windTile(s.roundWind).HasSameSymbol(discard)
+	},
+	"jikaze": func(s *Scene, discard tile.Tile) bool {
+		return windTile(s.targetWind).HasSameSymbol(discard)
+	},
+	"suji": func(s *Scene, discard tile.Tile) bool {
+		return isSujiOf(discard, s.safeTiles, false)
+	},
+	"weak_suji": func(s *Scene, discard tile.Tile) bool {
+		return isSujiOf(discard, s.safeTiles, true)
+	},
+	"reach_suji": func(s *Scene, discard tile.Tile) bool {
+		return isSujiOf(discard, s.riichiDeclarationTiles, true)
+	},
+	"prereach_suji": func(s *Scene, discard tile.Tile) bool {
+		return isSujiOf(discard, s.preRiichiTiles, false)
+	},
+	"urasuji": func(s *Scene, discard tile.Tile) bool {
+		return isUrasujiOf(discard, s.preRiichiTiles, s.safeTiles)
+	},
+	"early_urasuji": func(s *Scene, discard tile.Tile) bool {
+		return isUrasujiOf(discard, s.earlyPreRiichiTiles, s.safeTiles)
+	},
+	"reach_urasuji": func(s *Scene, discard tile.Tile) bool {
+		return isUrasujiOf(discard, s.riichiDeclarationTiles, s.safeTiles)
+	},
+	// urasuji_of_5 comes from the Ruby training tool; the CoffeeScript bot
+	// never defined it.
+	"urasuji_of_5": func(s *Scene, discard tile.Tile) bool {
+		fives := slices.DeleteFunc(slices.Clone(s.preRiichiTiles), func(t tile.Tile) bool {
+			return !t.IsSuits() || t.Number() != 5
+		})
+		return isUrasujiOf(discard, fives, s.safeTiles)
+	},
+	"matagisuji": func(s *Scene, discard tile.Tile) bool {
+		return isMatagisujiOf(discard, s.preRiichiTiles, s.safeTiles)
+	},
+	"early_matagisuji": func(s *Scene, discard tile.Tile) bool {
+		return isMatagisujiOf(discard, s.earlyPreRiichiTiles, s.safeTiles)
+	},
+	"late_matagisuji": func(s *Scene, discard tile.Tile) bool {
+		return isMatagisujiOf(discard, s.latePreRiichiTiles, s.safeTiles)
+	},
+	"reach_matagisuji": func(s *Scene, discard tile.Tile) bool {
+		return isMatagisujiOf(discard, s.riichiDeclarationTiles, s.safeTiles)
+	},
+	"senkisuji": func(s *Scene, discard tile.Tile) bool {
+		return isSenkisujiOf(discard, s.preRiichiTiles, s.safeTiles)
+	},
+	"early_senkisuji": func(s *Scene, discard tile.Tile) bool {
+		return isSenkisujiOf(discard, s.earlyPreRiichiTiles, s.safeTiles)
+	},
+	"outer_prereach_sutehai": func(s *Scene, discard tile.Tile) bool {
+		return isOuter(discard, s.preRiichiTiles)
+	},
+	"outer_early_sutehai": func(s *Scene, discard tile.Tile) bool {
+		return isOuter(discard, s.earlyPreRiichiTiles)
+	},
+	"aida4ken": func(s *Scene, discard tile.Tile) bool {
+		return isAida4Ken(discard, s.preRiichiTiles)
+	},
+}
+
+// featureParser builds the evaluator of a parameterized feature. matched is
+// false when name is not of its form.
+type featureParser func(name string) (eval evalFunc, matched bool, err error)
+
+// parameterizedFeatures are tried in order, so a name is matched by the first
+// parser whose form it has.
+var parameterizedFeatures = []featureParser{
+	intPrefixFeature("chances<=", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return isNChanceOrLess(discard, n, s.visibleTiles)
+		}
+	}),
+	intPrefixFeature("visible>=", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return tile.Tiles(s.visibleTiles).CountSameSymbol(discard) >= n+1
+		}
+	}),
+	intPrefixFeature("suji_visible<=", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return isSujiVisibleNoMoreThan(discard, n, s.visibleTiles)
+		}
+	}),
+	intPrefixFeature("in_tehais>=", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return tile.Tiles(s.selfHand).CountSameSymbol(discard) >= n
+		}
+	}),
+	intPrefixFeature("suji_in_tehais>=", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return hasSujiSymbolCount(discard, n, s.selfHand)
+		}
+	}),
+	intPairFeature("", "<=n<=", func(minN int, maxN int) evalFunc {
+		return func(_ *Scene, discard tile.Tile) bool {
+			return discard.IsSuits() && minN <= discard.Number() && discard.Number() <= maxN
+		}
+	}),
+	intSuffixFeature("_outer_prereach_sutehai", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return isNOuterPreRiichiSutehai(discard, n, s.preRiichiTiles)
+		}
+	}),
+	intSuffixFeature("_inner_prereach_sutehai", func(n int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return isNOuterPreRiichiSutehai(discard, -n, s.preRiichiTiles)
+		}
+	}),
+	intPrefixFeature("same_type_in_prereach>=", func(n int) evalFunc {
+		// The discard itself counts, as in the CoffeeScript bot.
+		return func(s *Scene, discard tile.Tile) bool {
+			return discard.IsSuits() && countSameColor(s.preRiichiTiles, discard)+1 >= n
+		}
+	}),
+	intPairFeature("+-", "_in_prereach_sutehais>=", func(distance int, threshold int) evalFunc {
+		return func(s *Scene, discard tile.Tile) bool {
+			return hasNeighborPreRiichiSutehais(discard, distance, threshold, s.preRiichiTiles)
+		}
+	}),
+}
+
+// Parse returns the feature named name.
+func Parse(name string) (Feature, error) {
+	if eval, ok := fixedFeatures[name]; ok {
+		return Feature{name: name, eval: eval}, nil
+	}
+	for _, parse := range parameterizedFeatures {
+		eval, matched, err := parse(name)
+		if err != nil {
+			return Feature{}, err
+		}
+		if matched {
+			return Feature{name: name, eval: eval}, nil
+		}
+	}
+	return Feature{}, fmt.Errorf("unsupported danger feature %q", name)
+}
+
+// ParseAll parses names in order.
+func ParseAll(names []string) ([]Feature, error) {
+	features := make([]Feature, len(names))
+	for i, name := range names {
+		feature, err := Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		features[i] = feature
+	}
+	return features, nil
+}
+
+var defaultNames = []string{
+	"tsupai",
+	"suji",
+	"weak_suji",
+	"reach_suji",
+	"prereach_suji",
+	"urasuji",
+	"early_urasuji",
+	"reach_urasuji",
+	"urasuji_of_5",
+	"aida4ken",
+	"matagisuji",
+	"early_matagisuji",
+	"late_matagisuji",
+	"reach_matagisuji",
+	"senkisuji",
+	"early_senkisuji",
+	"outer_prereach_sutehai",
+	"outer_early_sutehai",
+	"chances<=0",
+	"chances<=1",
+	"chances<=2",
+	"chances<=3",
+	"visible>=1",
+	"visible>=2",
+	"visible>=3",
+	"suji_visible<=0",
+	"suji_visible<=1",
+	"suji_visible<=2",
+	"suji_visible<=3",
+	"2<=n<=8",
+	"3<=n<=7",
+	"4<=n<=6",
+	"5<=n<=5",
+	"dora",
+	"dora_suji",
+	"dora_matagi",
+	"in_tehais>=2",
+	"in_tehais>=3",
+	"in_tehais>=4",
+	"suji_in_tehais>=1",
+	"suji_in_tehais>=2",
+	"suji_in_tehais>=3",
+	"suji_in_tehais>=4",
+	"+-1_in_prereach_sutehais>=1",
+	"+-1_in_prereach_sutehais>=2",
+	"+-2_in_prereach_sutehais>=1",
+	"+-2_in_prereach_sutehais>=2",
+	"+-2_in_prereach_sutehais>=3",
+	"+-2_in_prereach_sutehais>=4",
+	"1_outer_prereach_sutehai",
+	"2_outer_prereach_sutehai",
+	"1_inner_prereach_sutehai",
+	"2_inner_prereach_sutehai",
+	"same_type_in_prereach>=1",
+	"same_type_in_prereach>=2",
+	"same_type_in_prereach>=3",
+	"same_type_in_prereach>=4",
+	"same_type_in_prereach>=5",
+	"same_type_in_prereach>=6",
+	"same_type_in_prereach>=7",
+	"same_type_in_prereach>=8",
+	"fanpai",
+	"ryenfonpai",
+	"sangenpai",
+	"fonpai",
+	"bakaze",
+	"jikaze",
+}
+
+// DefaultNames returns the features that estimate_danger extracts, in the
+// order of the feature vectors.
+func DefaultNames() []string {
+	return slices.Clone(defaultNames)
+}
+
+func intPrefixFeature(prefix string, build func(n int) evalFunc) featureParser {
+	return func(name string) (evalFunc, bool, error) {
+		param, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			return nil, false, nil
+		}
+		n, err := parseFeatureInt(name, param)
+		if err != nil {
+			return nil, true, err
+		}
+		return build(n), true, nil
+	}
+}
+
+func intSuffixFeature(suffix string, build func(n int) evalFunc) featureParser {
+	return func(name string) (evalFunc, bool, error) {
+		param, ok := strings.CutSuffix(name, suffix)
+		if !ok {
+			return nil, false, nil
+		}
+		n, err := parseFeatureInt(name, param)
+		if err != nil {
+			return nil, true, err
+		}
+		return build(n), true, nil
+	}
+}
+
+// intPairFeature parses names of the form prefix + M + sep + N.
+func intPairFeature(prefix string, sep string, build func(m int, n int) evalFunc) featureParser {
+	return func(name string) (evalFunc, bool, error) {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			return nil, false, nil
+		}
+		before, after, ok := strings.Cut(rest, sep)
+		if !ok {
+			return nil, false, nil
+		}
+		m, err := parseFeatureInt(name, before)
+		if err != nil {
+			return nil, true, err
+		}
+		n, err := parseFeatureInt(name, after)
+		if err != nil {
+			return nil, true, err
+		}
+		return build(m, n), true, nil
+	}
+}
+
+func parseFeatureInt(name string, param string) (int, error) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, fmt.Errorf("parse danger feature %q: %w", name, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,481 @@
+package dangerfeature
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+func evaluate(s Scene, name string, discard tile.Tile) (bool, error) {
+	f, err := Parse(name)
+	if err != nil {
+		return false, err
+	}
+	return f.Eval(&s, discard), nil
+}
+
+func TestEvaluateReturnsErrorWithUnknownFeature(t *testing.T) {
+	_, err := evaluate(Scene{}, "unknown_feature", tile.MustTileFromCode("5m"))
+	if err == nil {
+		t.Fatal("evaluate() succeeded unexpectedly")
+	}
+	if !strings.Contains(err.Error(), "unknown_feature") {
+		t.Errorf("evaluate() error = %v, want feature name", err)
+	}
+}
+
+func TestEvaluateReturnsErrorWithInvalidFeatureInteger(t *testing.T) {
+	_, err := evaluate(Scene{}, "visible>=invalid", tile.MustTileFromCode("5m"))
+	if err == nil {
+		t.Fatal("evaluate() succeeded unexpectedly")
+	}
+	if !strings.Contains(err.Error(), "visible>=invalid") {
+		t.Errorf("evaluate() error = %v, want feature name", err)
+	}
+}
+
+func TestEvaluateRejectsOuterPreRiichiFeatureWithTrailingText(t *testing.T) {
+	_, err := evaluate(Scene{}, "1_outer_prereach_sutehai_invalid", tile.MustTileFromCode("5m"))
+	if err == nil {
+		t.Fatal("evaluate() succeeded unexpectedly")
+	}
+}
+
+func TestEvaluateRejectsInnerPreRiichiFeatureWithTrailingText(t *testing.T) {
+	_, err := evaluate(Scene{}, "1_inner_prereach_sutehai_invalid", tile.MustTileFromCode("5m"))
+	if err == nil {
+		t.Fatal("evaluate() succeeded unexpectedly")
+	}
+}
+
+func TestEvaluateReturnsErrorWithInvalidOuterPreRiichiInteger(t *testing.T) {
+	_, err := evaluate(Scene{}, "invalid_outer_prereach_sutehai", tile.MustTileFromCode("5m"))
+	if err == nil {
+		t.Fatal("evaluate() succeeded unexpectedly")
+	}
+	if !strings.Contains(err.Error(), "invalid_outer_prereach_sutehai") {
+		t.Errorf("evaluate() error = %v, want feature name", err)
+	}
+}
+
+func TestEvaluateReturnsErrorWithInvalidInnerPreRiichiInteger(t *testing.T) {
+	_, err := evaluate(Scene{}, "invalid_inner_prereach_sutehai", tile.MustTileFromCode("5m"))
+	if err == nil {
+		t.Fatal("evaluate() succeeded unexpectedly")
+	}
+	if !strings.Contains(err.Error(), "invalid_inner_prereach_sutehai") {
+		t.Errorf("evaluate() error = %v, want feature name", err)
+	}
+}
+
+func TestEvaluateReturnsErrorWithInvalidNumberRangeInteger(t *testing.T) {
+	for _, feature := range []string{"invalid<=n<=6", "4<=n<=invalid"} {
+		t.Run(feature, func(t *testing.T) {
+			_, err := evaluate(Scene{}, feature, tile.MustTileFromCode("5m"))
+			if err == nil {
+				t.Fatal("evaluate() succeeded unexpectedly")
+			}
+			if !strings.Contains(err.Error(), feature) {
+				t.Errorf("evaluate() error = %v, want feature name", err)
+			}
+		})
+	}
+}
+
+func TestEvaluateReturnsErrorWithInvalidNeighborPreRiichiInteger(t *testing.T) {
+	for _, feature := range []string{
+		"+-invalid_in_prereach_sutehais>=1",
+		"+-1_in_prereach_sutehais>=invalid",
+	} {
+		t.Run(feature, func(t *testing.T) {
+			_, err := evaluate(Scene{}, feature, tile.MustTileFromCode("5m"))
+			if err == nil {
+				t.Fatal("evaluate() succeeded unexpectedly")
+			}
+			if !strings.Contains(err.Error(), feature) {
+				t.Errorf("evaluate() error = %v, want feature name", err)
+			}
+		})
+	}
+}
+
+func TestEvaluateKnownFeature(t *testing.T) {
+	got, err := evaluate(Scene{}, "sangenpai", tile.MustTileFromCode("P"))
+	if err != nil {
+		t.Fatalf("evaluate() failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate() = false, want true")
+	}
+}
+
+func TestEvaluateFanpaiFeatures(t *testing.T) {
+	scene := Scene{
+		roundWind:  wind.East,
+		targetWind: wind.East,
+	}
+	got, err := evaluate(scene, "ryenfonpai", tile.MustTileFromCode("E"))
+	if err != nil {
+		t.Fatalf("evaluate(ryenfonpai) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(ryenfonpai) = false, want true")
+	}
+
+	got, err = evaluate(scene, "fanpai", tile.MustTileFromCode("P"))
+	if err != nil {
+		t.Fatalf("evaluate(fanpai) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(fanpai) = false, want true")
+	}
+}
+
+func TestEvaluateVisibilityFeatures(t *testing.T) {
+	scene := Scene{
+		visibleTiles: []tile.Tile{
+			tile.MustTileFromCode("5m"),
+			tile.MustTileFromCode("5mr"),
+			tile.MustTileFromCode("2m"),
+		},
+	}
+
+	got, err := evaluate(scene, "visible>=1", tile.MustTileFromCode("5m"))
+	if err != nil {
+		t.Fatalf("evaluate(visible>=1) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(visible>=1) = false, want true")
+	}
+
+	got, err = evaluate(scene, "suji_visible<=0", tile.MustTileFromCode("5m"))
+	if err != nil {
+		t.Fatalf("evaluate(suji_visible<=0) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(suji_visible<=0) = false, want true because 8m is not visible")
+	}
+}
+
+func TestEvaluateSujiInTehaisCountsEachSujiSeparately(t *testing.T) {
+	tests := []struct {
+		name     string
+		discard  string
+		selfHand []string
+		want     bool
+	}{
+		{
+			name:     "combined suji count does not satisfy threshold",
+			discard:  "5m",
+			selfHand: []string{"2m", "8m"},
+			want:     false,
+		},
+		{
+			name:     "one suji satisfies threshold",
+			discard:  "5m",
+			selfHand: []string{"2m", "2m"},
+			want:     true,
+		},
+		{
+			name:     "honor has no suji",
+			discard:  "E",
+			selfHand: []string{"E", "E"},
+			want:     false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selfHand := make([]tile.Tile, 0, len(tt.selfHand))
+			for _, code := range tt.selfHand {
+				selfHand = append(selfHand, tile.MustTileFromCode(code))
+			}
+			scene := Scene{selfHand: selfHand}
+
+			got, err := evaluate(scene, "suji_in_tehais>=2", tile.MustTileFromCode(tt.discard))
+			if err != nil {
+				t.Fatalf("evaluate(suji_in_tehais>=2) failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate(suji_in_tehais>=2) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateChanceFeatureUsesKabeTiles(t *testing.T) {
+	scene := Scene{
+		visibleTiles: []tile.Tile{
+			tile.MustTileFromCode("2m"),
+			tile.MustTileFromCode("2m"),
+			tile.MustTileFromCode("2m"),
+			tile.MustTileFromCode("2m"),
+		},
+	}
+
+	got, err := evaluate(scene, "chances<=0", tile.MustTileFromCode("1m"))
+	if err != nil {
+		t.Fatalf("evaluate(chances<=0) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(chances<=0) = false, want true with 2m kabe")
+	}
+
+	got, err = evaluate(scene, "chances<=0", tile.MustTileFromCode("5m"))
+	if err != nil {
+		t.Fatalf("evaluate(chances<=0 middle) failed: %v", err)
+	}
+	if got {
+		t.Error("evaluate(chances<=0 middle) = true, want false for 4-6")
+	}
+}
+
+func TestEvaluateAida4KenMatchesOriginal(t *testing.T) {
+	scene := Scene{
+		preRiichiTiles: []tile.Tile{
+			tile.MustTileFromCode("1p"),
+			tile.MustTileFromCode("6p"),
+		},
+	}
+
+	wants := map[string]bool{
+		"1p": false,
+		"2p": true,
+		"3p": false,
+		"4p": false,
+		"5p": true,
+		"6p": false,
+		"7p": false,
+		"8p": false,
+		"9p": false,
+		"2m": false,
+	}
+	for code, want := range wants {
+		t.Run(code, func(t *testing.T) {
+			got, err := evaluate(scene, "aida4ken", tile.MustTileFromCode(code))
+			if err != nil {
+				t.Fatalf("evaluate(aida4ken) failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("evaluate(aida4ken) = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvaluateOuterPreRiichiMatchesOriginalDirection(t *testing.T) {
+	scene := Scene{
+		preRiichiTiles: []tile.Tile{tile.MustTileFromCode("4m")},
+	}
+
+	got, err := evaluate(scene, "1_outer_prereach_sutehai", tile.MustTileFromCode("3m"))
+	if err != nil {
+		t.Fatalf("evaluate(1_outer_prereach_sutehai) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(1_outer_prereach_sutehai) = false, want true for original direction")
+	}
+
+	got, err = evaluate(scene, "1_inner_prereach_sutehai", tile.MustTileFromCode("3m"))
+	if err != nil {
+		t.Fatalf("evaluate(1_inner_prereach_sutehai) failed: %v", err)
+	}
+	if got {
+		t.Error("evaluate(1_inner_prereach_sutehai) = true, want false without 2m")
+	}
+}
+
+func TestEvaluateSameTypeInPreRiichiCountsDistinctSuitNumbers(t *testing.T) {
+	scene := Scene{
+		preRiichiTiles: []tile.Tile{
+			tile.MustTileFromCode("5m"),
+			tile.MustTileFromCode("5mr"),
+			tile.MustTileFromCode("7m"),
+			tile.MustTileFromCode("1p"),
+		},
+	}
+
+	got, err := evaluate(scene, "same_type_in_prereach>=3", tile.MustTileFromCode("2m"))
+	if err != nil {
+		t.Fatalf("evaluate(same_type_in_prereach>=3) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(same_type_in_prereach>=3) = false, want true for two distinct prereach manzu plus discard")
+	}
+
+	got, err = evaluate(scene, "same_type_in_prereach>=4", tile.MustTileFromCode("2m"))
+	if err != nil {
+		t.Fatalf("evaluate(same_type_in_prereach>=4) failed: %v", err)
+	}
+	if got {
+		t.Error("evaluate(same_type_in_prereach>=4) = true, want false because duplicate 5m/5mr counts once")
+	}
+
+	got, err = evaluate(scene, "same_type_in_prereach>=1", tile.MustTileFromCode("E"))
+	if err != nil {
+		t.Fatalf("evaluate(same_type_in_prereach>=1 honors) failed: %v", err)
+	}
+	if got {
+		t.Error("evaluate(same_type_in_prereach>=1) = true, want false for honors")
+	}
+}
+
+func TestEvaluateNeighborPreRiichiMatchesOriginalRange(t *testing.T) {
+	tests := []struct {
+		name           string
+		feature        string
+		discard        string
+		preRiichiTiles []string
+		want           bool
+	}{
+		{
+			name:           "includes discard itself",
+			feature:        "+-1_in_prereach_sutehais>=1",
+			discard:        "5m",
+			preRiichiTiles: []string{"5m"},
+			want:           true,
+		},
+		{
+			name:           "distance two includes intermediate numbers",
+			feature:        "+-2_in_prereach_sutehais>=2",
+			discard:        "5m",
+			preRiichiTiles: []string{"4m", "6m"},
+			want:           true,
+		},
+		{
+			name:           "counts duplicate tiles as one number",
+			feature:        "+-1_in_prereach_sutehais>=2",
+			discard:        "1p",
+			preRiichiTiles: []string{"2p", "2p"},
+			want:           false,
+		},
+		{
+			name:           "counts distinct numbers",
+			feature:        "+-1_in_prereach_sutehais>=2",
+			discard:        "2p",
+			preRiichiTiles: []string{"1p", "3p"},
+			want:           true,
+		},
+		{
+			name:           "excludes tiles outside bounded range",
+			feature:        "+-2_in_prereach_sutehais>=2",
+			discard:        "1s",
+			preRiichiTiles: []string{"3s", "4s"},
+			want:           false,
+		},
+		{
+			name:           "honor has no numbered neighbors",
+			feature:        "+-1_in_prereach_sutehais>=1",
+			discard:        "E",
+			preRiichiTiles: []string{"E"},
+			want:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preRiichiTiles := make([]tile.Tile, 0, len(tt.preRiichiTiles))
+			for _, code := range tt.preRiichiTiles {
+				preRiichiTiles = append(preRiichiTiles, tile.MustTileFromCode(code))
+			}
+			scene := Scene{preRiichiTiles: preRiichiTiles}
+
+			got, err := evaluate(scene, tt.feature, tile.MustTileFromCode(tt.discard))
+			if err != nil {
+				t.Fatalf("evaluate(%s) failed: %v", tt.feature, err)
+			}
+			if got != tt.want {
+				t.Errorf("evaluate(%s) = %v, want %v", tt.feature, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSupportsDefaultNames(t *testing.T) {
+	features, err := ParseAll(DefaultNames())
+	if err != nil {
+		t.Fatalf("ParseAll(DefaultNames()) failed: %v", err)
+	}
+	for i, f := range features {
+		if f.Name() != DefaultNames()[i] {
+			t.Errorf("features[%d].Name() = %q, want %q", i, f.Name(), DefaultNames()[i])
+		}
+	}
+}
+
+func TestEvaluateUrasujiOf5(t *testing.T) {
+	scene := NewSceneFromParams(SceneParams{
+		SafeTiles:      mustTiles("1p"),
+		PreRiichiTiles: mustTiles("1p", "5s"),
+	})
+
+	got, err := evaluate(scene, "urasuji_of_5", tile.MustTileFromCode("1s"))
+	if err != nil {
+		t.Fatalf("evaluate(urasuji_of_5) failed: %v", err)
+	}
+	if !got {
+		t.Error("evaluate(urasuji_of_5, 1s) = false, want true")
+	}
+}
+
+// The Ruby training tool defined these features differently. They follow the
+// CoffeeScript bot.
+func TestParseFollowsTheBotDefinitions(t *testing.T) {
+	tests := []struct {
+		name      string
+		feature   string
+		preRiichi tile.Tiles
+		discard   string
+		want      bool
+	}{
+		{
+			name:      "same type counts the discard",
+			feature:   "same_type_in_prereach>=3",
+			preRiichi: mustTiles("1p", "3p"),
+			discard:   "5p",
+			want:      true,
+		},
+		{
+			name:      "no inner tile of a five",
+			feature:   "1_inner_prereach_sutehai",
+			preRiichi: mustTiles("6p", "7p"),
+			discard:   "5p",
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scene := NewSceneFromParams(SceneParams{PreRiichiTiles: tt.preRiichi})
+			discard := tile.MustTileFromCode(tt.discard)
+			f, err := Parse(tt.feature)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.feature, err)
+			}
+			if got := f.Eval(&scene, discard); got != tt.want {
+				t.Errorf("Parse(%q).Eval(%s) = %v, want %v", tt.feature, discard, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSceneWithSelfHandTileDoesNotShareHand(t *testing.T) {
+	scene := NewSceneFromParams(SceneParams{SelfHand: mustTiles("1m", "2m")})
+	added := scene.WithSelfHandTile(tile.MustTileFromCode("3m"))
+
+	if got := len(scene.SelfHand()); got != 2 {
+		t.Errorf("len(SelfHand()) = %d, want 2", got)
+	}
+	if got := len(added.SelfHand()); got != 3 {
+		t.Errorf("len(WithSelfHandTile().SelfHand()) = %d, want 3", got)
+	}
+}
+
+func mustTiles(codes ...string) tile.Tiles {
+	tiles := make(tile.Tiles, len(codes))
+	for i, code := range codes {
+		tiles[i] = tile.MustTileFromCode(code)
+	}
+	return tiles
+}
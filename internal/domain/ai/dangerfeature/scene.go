@@ -0,0 +1,106 @@
+package dangerfeature
+
+import (
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+// Scene is what a discarder sees of the target when judging the danger of
+// a discard.
+type Scene struct {
+	selfHand               []tile.Tile
+	safeTiles              []tile.Tile
+	visibleTiles           []tile.Tile
+	doras                  []tile.Tile
+	roundWind              wind.Wind
+	targetWind             wind.Wind
+	preRiichiTiles         []tile.Tile
+	earlyPreRiichiTiles    []tile.Tile
+	latePreRiichiTiles     []tile.Tile
+	riichiDeclarationTiles []tile.Tile
+}
+
+// NewScene builds the scene of self discarding against target.
+func NewScene(state round.StateViewer, self seat.Seat, target seat.Seat) Scene {
+	var selfHand []tile.Tile
+	selfPlayer := state.Player(self)
+	if h, ok := selfPlayer.Hand(); ok {
+		selfHand = h.ToTiles()
+		if drawnTile := selfPlayer.DrawnTile(); drawnTile != nil {
+			selfHand = append(selfHand, *drawnTile)
+		}
+	}
+
+	var preRiichiTiles []tile.Tile
+	var riichiDeclarationTiles []tile.Tile
+	targetPlayer := state.Player(target)
+	discardedTiles := targetPlayer.DiscardedTiles()
+	if idx := targetPlayer.RiichiDiscardedTilesIndex(); idx >= 0 && idx < len(discardedTiles) {
+		preRiichiTiles = discardedTiles[:idx+1]
+		riichiDeclarationTiles = []tile.Tile{discardedTiles[idx]}
+	}
+
+	half := len(preRiichiTiles) / 2
+
+	return Scene{
+		selfHand:               selfHand,
+		safeTiles:              state.SafeTiles(target),
+		visibleTiles:           state.VisibleTiles(self),
+		doras:                  state.Doras(),
+		roundWind:              state.RoundWind(),
+		targetWind:             state.SeatWind(target),
+		preRiichiTiles:         preRiichiTiles,
+		earlyPreRiichiTiles:    preRiichiTiles[:half],
+		latePreRiichiTiles:     preRiichiTiles[half:],
+		riichiDeclarationTiles: riichiDeclarationTiles,
+	}
+}
+
+// SceneParams are the tiles of a scene given directly. The last pre-riichi
+// tile is the riichi declaration tile.
+type SceneParams struct {
+	SelfHand       tile.Tiles
+	SafeTiles      tile.Tiles
+	VisibleTiles   tile.Tiles
+	Doras          tile.Tiles
+	PreRiichiTiles tile.Tiles
+	RoundWind      wind.Wind
+	TargetWind     wind.Wind
+}
+
+func NewSceneFromParams(params SceneParams) Scene {
+	s := Scene{
+		selfHand:       slices.Clone(params.SelfHand),
+		safeTiles:      slices.Clone(params.SafeTiles),
+		visibleTiles:   slices.Clone(params.VisibleTiles),
+		doras:          slices.Clone(params.Doras),
+		roundWind:      params.RoundWind,
+		targetWind:     params.TargetWind,
+		preRiichiTiles: slices.Clone(params.PreRiichiTiles),
+	}
+	half := len(s.preRiichiTiles) / 2
+	s.earlyPreRiichiTiles = s.preRiichiTiles[:half]
+	s.latePreRiichiTiles = s.preRiichiTiles[half:]
+	if len(s.preRiichiTiles) > 0 {
+		s.riichiDeclarationTiles = []tile.Tile{s.preRiichiTiles[len(s.preRiichiTiles)-1]}
+	}
+	return s
+}
+
+// WithSelfHandTile returns the scene with t added to self's hand.
+func (s Scene) WithSelfHandTile(t tile.Tile) Scene {
+	s.selfHand = append(slices.Clip(s.selfHand), t)
+	return s
+}
+
+func (s Scene) SelfHand() tile.Tiles {
+	return slices.Clone(s.selfHand)
+}
+
+func (s Scene) SafeTiles() tile.Tiles {
+	return slices.Clone(s.safeTiles)
+}
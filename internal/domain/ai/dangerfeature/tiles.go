@@ -1,4 +1,4 @@
-package ai
+package dangerfeature
 
 import (
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
@@ -259,3 +259,20 @@ func windTile(w wind.Wind) tile.Tile {
 		return tile.MustTileFromCode("N")
 	}
 }
+
+// hasNeighborPreRiichiSutehais reports whether at least threshold tiles within
+// distance of target, target included, are in the pre-riichi discards.
+func hasNeighborPreRiichiSutehais(target tile.Tile, distance int, threshold int, tiles []tile.Tile) bool {
+	if !target.IsSuits() {
+		return false
+	}
+	count := 0
+	for offset := -distance; offset <= distance; offset++ {
+		if neighbor := target.Next(offset); neighbor != nil {
+			if tile.Tiles(tiles).ContainsSameSymbol(*neighbor) {
+				count++
+			}
+		}
+	}
+	return count >= threshold
+}
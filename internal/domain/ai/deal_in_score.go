@@ -28,6 +28,7 @@ func immediateDealInScoreDeltaDist(
 	selfID int,
 	dealInProb float64,
 	pointsDist scalarProbDist,
+	bonus roundBonus,
 ) (scoreDeltaProbDist, error) {
 	if dealInProb < 0.0 || dealInProb > 1.0 {
 		return nil, fmt.Errorf("cannot build immediate deal-in score delta distribution: deal-in probability must be between 0 and 1")
@@ -36,11 +37,11 @@ func immediateDealInScoreDeltaDist(
 	var dealInFactor scoreDelta
 	dealInFactor[winnerID] = 1.0
 	dealInFactor[selfID] = -1.0
-	unitDist := newScoreDeltaProbDist(map[scoreDelta]float64{
-		dealInFactor: dealInProb,
-		{}:           1.0 - dealInProb,
-	})
-	return multiplyScalarScoreDeltaProbDists(pointsDist, unitDist), nil
+	dealInDist := multiplyScalarScoreDeltaProbDists(pointsDist, scoreDeltaProbDist{dealInFactor: 1.0})
+	return mergeScoreDeltaProbDists([]weightedScoreDeltaProbDist{
+		{dist: dealInDist.shift(bonus.winDelta(winnerID, selfID)), prob: dealInProb},
+		{dist: scoreDeltaProbDist{{}: 1.0}, prob: 1.0 - dealInProb},
+	}), nil
 }
 
 func immediateDealInScoreDeltaDistFromStats(
@@ -49,13 +50,15 @@ func immediateDealInScoreDeltaDistFromStats(
 	dealerID int,
 	dealInProb float64,
 	stats WinScoreStats,
+	profile OpponentProfile,
+	bonus roundBonus,
 ) (scoreDeltaProbDist, error) {
 	pointFreqs := stats.NonDealerWinPointFreqs()
 	if winnerID == dealerID {
 		pointFreqs = stats.DealerWinPointFreqs()
 	}
-	pointsDist := winPointsDist(pointFreqs)
-	return immediateDealInScoreDeltaDist(winnerID, selfID, dealInProb, pointsDist)
+	pointsDist := profile.winPointsDist(winPointsDist(pointFreqs))
+	return immediateDealInScoreDeltaDist(winnerID, selfID, dealInProb, pointsDist, bonus)
 }
 
 func immediateScoreDeltaDistFromStats(
@@ -63,6 +66,8 @@ func immediateScoreDeltaDistFromStats(
 	dealerID int,
 	estimates []dealInEstimate,
 	stats WinScoreStats,
+	opponents opponentTable,
+	bonus roundBonus,
 ) (scoreDeltaProbDist, error) {
 	dists := make([]scoreDeltaProbDist, 0, len(estimates))
 	for _, estimate := range estimates {
@@ -72,6 +77,8 @@ func immediateScoreDeltaDistFromStats(
 			dealerID,
 			estimate.prob,
 			stats,
+			opponents[estimate.winnerID],
+			bonus,
 		)
 		if err != nil {
 			return nil, err
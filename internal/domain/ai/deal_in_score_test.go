@@ -38,7 +38,7 @@ func TestImmediateDealInScoreDeltaDist(t *testing.T) {
 	got, err := immediateDealInScoreDeltaDist(2, 0, 0.25, scalarProbDist{
 		1000: 0.4,
 		2000: 0.6,
-	})
+	}, roundBonus{})
 	if err != nil {
 		t.Fatalf("immediateDealInScoreDeltaDist() failed: %v", err)
 	}
@@ -51,8 +51,21 @@ func TestImmediateDealInScoreDeltaDist(t *testing.T) {
 	assertScoreDeltaProbDist(t, got, want)
 }
 
+func TestImmediateDealInScoreDeltaDist_AddsRoundBonus(t *testing.T) {
+	got, err := immediateDealInScoreDeltaDist(2, 0, 0.25, scalarProbDist{1000: 1}, roundBonus{honba: 2, riichiSticks: 1})
+	if err != nil {
+		t.Fatalf("immediateDealInScoreDeltaDist() failed: %v", err)
+	}
+
+	want := scoreDeltaProbDist{
+		{}:                  0.75,
+		{-1600, 0, 2600, 0}: 0.25,
+	}
+	assertScoreDeltaProbDist(t, got, want)
+}
+
 func TestImmediateDealInScoreDeltaDist_ReturnsErrorWithInvalidDealInProb(t *testing.T) {
-	_, err := immediateDealInScoreDeltaDist(2, 0, -0.1, scalarProbDist{1000: 1})
+	_, err := immediateDealInScoreDeltaDist(2, 0, -0.1, scalarProbDist{1000: 1}, roundBonus{})
 	if err == nil {
 		t.Fatal("immediateDealInScoreDeltaDist() succeeded unexpectedly")
 	}
@@ -68,7 +81,7 @@ func TestImmediateDealInScoreDeltaDistFromStats_SelectsDealerPointFreqs(t *testi
 			"2000":  1,
 			"total": 1,
 		},
-	})
+	}, OpponentProfile{}, roundBonus{})
 	if err != nil {
 		t.Fatalf("immediateDealInScoreDeltaDistFromStats() failed: %v", err)
 	}
@@ -90,7 +103,7 @@ func TestImmediateDealInScoreDeltaDistFromStats_SelectsNonDealerPointFreqs(t *te
 			"2000":  1,
 			"total": 1,
 		},
-	})
+	}, OpponentProfile{}, roundBonus{})
 	if err != nil {
 		t.Fatalf("immediateDealInScoreDeltaDistFromStats() failed: %v", err)
 	}
@@ -135,7 +148,7 @@ func TestImmediateScoreDeltaDistFromStats(t *testing.T) {
 			"2000":  1,
 			"total": 1,
 		},
-	})
+	}, opponentTable{}, roundBonus{})
 	if err != nil {
 		t.Fatalf("immediateScoreDeltaDistFromStats() failed: %v", err)
 	}
@@ -156,7 +169,7 @@ func TestImmediateScoreDeltaDistFromStats_ReturnsErrorWithInvalidEstimate(t *tes
 			"1000":  1,
 			"total": 1,
 		},
-	})
+	}, opponentTable{}, roundBonus{})
 	if err == nil {
 		t.Fatal("immediateScoreDeltaDistFromStats() succeeded unexpectedly")
 	}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// defensePlan is the order in which a folding hand discards over the next
+// turns so that the cumulative deal-in probability is the lowest.
+type defensePlan struct {
+	// discards starts with the discard of the candidate, if it has one.
+	discards []tile.Tile
+	// later is the probability of dealing in to each opponent on any
+	// discard of the plan after the discard of the candidate. It is nil when
+	// the plan has no later discards or they are all safe copies of the
+	// first.
+	later []dealInEstimate
+}
+
+// defenseKind is a kind of tile the hand can fold with.
+type defenseKind struct {
+	tile      tile.Tile
+	count     int
+	estimates []dealInEstimate
+	// cost is -log of the probability that the kind passes, so that the cost
+	// of a plan is the sum of the costs of its kinds.
+	cost float64
+}
+
+// planDefense plans turns discards starting with first, followed by tiles of
+// rest, the hand after first is discarded. first is unknown when the
+// candidate discards nothing this turn, which still counts as one of the
+// turns. danger returns the deal-in estimates of a kind of tile for the
+// current state. The deal-in of first itself is left to the caller.
+//
+// The danger of a tile does not change during the plan, except that a kind
+// that passes once is safe for the rest of the plan: an opponent in riichi is
+// furiten on it, and an opponent without riichi is taken to have no wait on
+// it. Keeping the other copies of a kind is therefore worth a safe tile for
+// each copy. Tiles drawn during the plan are not known and are left out, so
+// the plan discards at most the tiles of rest after first.
+func planDefense(
+	first tile.Tile,
+	rest *hand.TileCounts34,
+	turns int,
+	danger func(tile.Tile) ([]dealInEstimate, error),
+) (defensePlan, error) {
+	var plan defensePlan
+	numFree := 0
+	if !first.IsUnknown() {
+		first = first.RemoveRed()
+		plan.discards = []tile.Tile{first}
+		numFree = rest[first.ID()]
+	}
+	numFutureDiscards := min(turns-1, rest.NumTiles())
+	if numFutureDiscards <= 0 {
+		return plan, nil
+	}
+
+	var kinds []defenseKind
+	for id, count := range rest {
+		if count == 0 || (!first.IsUnknown() && id == first.ID()) {
+			continue
+		}
+		t := tile.MustTileFromID(id)
+		estimates, err := danger(t)
+		if err != nil {
+			return defensePlan{}, err
+		}
+		safe, err := safeProb(estimates)
+		if err != nil {
+			return defensePlan{}, err
+		}
+		kinds = append(kinds, defenseKind{tile: t, count: count, estimates: estimates, cost: -math.Log(safe)})
+	}
+
+	// The copies of first are safe once it passes, so only the remaining
+	// discards need kinds. Choosing the kinds is a knapsack problem: the
+	// cheapest set of kinds whose copies cover the discards.
+	numFree = min(numFree, numFutureDiscards)
+	need := numFutureDiscards - numFree
+	type cover struct {
+		ok    bool
+		cost  float64
+		kinds []int
+	}
+	best := make([]cover, need+1)
+	best[0] = cover{ok: true}
+	for i, kind := range kinds {
+		// Descending order takes each kind at most once.
+		for covered := need; covered >= 0; covered-- {
+			if !best[covered].ok {
+				continue
+			}
+			next := min(need, covered+kind.count)
+			cost := best[covered].cost + kind.cost
+			if !best[next].ok || cost < best[next].cost {
+				best[next] = cover{ok: true, cost: cost, kinds: append(slices.Clone(best[covered].kinds), i)}
+			}
+		}
+	}
+
+	for range numFree {
+		plan.discards = append(plan.discards, first)
+	}
+	numDiscards := len(plan.discards) + need
+	chosen := best[need].kinds
+	// The safest kinds go first because the round may end before the plan
+	// does.
+	slices.SortStableFunc(chosen, func(lhs, rhs int) int {
+		return cmp.Compare(kinds[lhs].cost, kinds[rhs].cost)
+	})
+	for _, i := range chosen {
+		kind := kinds[i]
+		for range min(kind.count, numDiscards-len(plan.discards)) {
+			plan.discards = append(plan.discards, kind.tile)
+		}
+		plan.later = combineDealInEstimates(plan.later, kind.estimates)
+	}
+	return plan, nil
+}
+
+// combineDealInEstimates returns the probability of dealing in to each
+// opponent on either of two discards. a is nil before the first discard.
+func combineDealInEstimates(a, b []dealInEstimate) []dealInEstimate {
+	if a == nil {
+		return slices.Clone(b)
+	}
+	combined := slices.Clone(a)
+	for i := range combined {
+		for _, other := range b {
+			if other.winnerID == combined[i].winnerID {
+				combined[i].prob = 1 - (1-combined[i].prob)*(1-other.prob)
+			}
+		}
+	}
+	return combined
+}
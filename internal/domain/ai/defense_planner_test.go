@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+// stubTileDanger returns the deal-in estimate of one opponent by tile.
+func stubTileDanger(probs map[string]float64) func(tile.Tile) ([]dealInEstimate, error) {
+	return func(t tile.Tile) ([]dealInEstimate, error) {
+		return []dealInEstimate{{winnerID: 1, prob: probs[t.String()]}}, nil
+	}
+}
+
+func tileCountsFromCodes(codes ...string) *hand.TileCounts34 {
+	return visibleHandFromCodes(codes...).ToTileCounts34()
+}
+
+func tilesFromCodes(codes ...string) []tile.Tile {
+	tiles := make([]tile.Tile, 0, len(codes))
+	for _, code := range codes {
+		tiles = append(tiles, tile.MustTileFromCode(code))
+	}
+	return tiles
+}
+
+func TestPlanDefense(t *testing.T) {
+	danger := stubTileDanger(map[string]float64{"E": 0, "5m": 0.1, "9p": 0.05})
+	tests := []struct {
+		name          string
+		first         string
+		rest          []string
+		turns         int
+		wantDiscards  []string
+		wantLaterProb float64
+	}{
+		{
+			name:          "safe tile first keeps the pair for later",
+			first:         "E",
+			rest:          []string{"5m", "5m", "9p"},
+			turns:         3,
+			wantDiscards:  []string{"E", "5m", "5m"},
+			wantLaterProb: 0.1,
+		},
+		{
+			name:          "a tile of the pair makes the other safe",
+			first:         "5m",
+			rest:          []string{"E", "5m", "9p"},
+			turns:         3,
+			wantDiscards:  []string{"5m", "5m", "E"},
+			wantLaterProb: 0,
+		},
+		{
+			name:          "the least dangerous tile first costs the most",
+			first:         "9p",
+			rest:          []string{"E", "5m", "5m"},
+			turns:         3,
+			wantDiscards:  []string{"9p", "5m", "5m"},
+			wantLaterProb: 0.1,
+		},
+		{
+			name:          "one turn is the discard alone",
+			first:         "9p",
+			rest:          []string{"E", "5m", "5m"},
+			turns:         1,
+			wantDiscards:  []string{"9p"},
+			wantLaterProb: 0,
+		},
+		{
+			name:          "the plan stops when the hand runs out",
+			first:         "9p",
+			rest:          []string{"E"},
+			turns:         5,
+			wantDiscards:  []string{"9p", "E"},
+			wantLaterProb: 0,
+		},
+		{
+			name:          "no discard this turn",
+			first:         "?",
+			rest:          []string{"5m", "9p"},
+			turns:         2,
+			wantDiscards:  []string{"9p"},
+			wantLaterProb: 0.05,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planDefense(tile.MustTileFromCode(tt.first), tileCountsFromCodes(tt.rest...), tt.turns, danger)
+			if err != nil {
+				t.Fatalf("planDefense() failed: %v", err)
+			}
+			if want := tilesFromCodes(tt.wantDiscards...); !slices.Equal(plan.discards, want) {
+				t.Errorf("discards = %v, want %v", plan.discards, want)
+			}
+			safe, err := safeProb(plan.later)
+			if err != nil {
+				t.Fatalf("safeProb() failed: %v", err)
+			}
+			if !almostEqual(1-safe, tt.wantLaterProb) {
+				t.Errorf("later = %v, want deal-in probability %v", plan.later, tt.wantLaterProb)
+			}
+		})
+	}
+}
+
+func TestPlanDefense_RedFiveIsItsKind(t *testing.T) {
+	danger := stubTileDanger(map[string]float64{"5m": 0.1, "9p": 0.05})
+	plan, err := planDefense(tile.MustTileFromCode("5mr"), tileCountsFromCodes("5m", "9p"), 2, danger)
+	if err != nil {
+		t.Fatalf("planDefense() failed: %v", err)
+	}
+	if want := tilesFromCodes("5m", "5m"); !slices.Equal(plan.discards, want) {
+		t.Errorf("discards = %v, want %v", plan.discards, want)
+	}
+}
+
+func TestCombineDealInEstimates(t *testing.T) {
+	got := combineDealInEstimates(
+		[]dealInEstimate{{winnerID: 1, prob: 0.2}, {winnerID: 2, prob: 0.5}},
+		[]dealInEstimate{{winnerID: 2, prob: 0.5}, {winnerID: 1, prob: 0.25}},
+	)
+	want := []dealInEstimate{{winnerID: 1, prob: 0.4}, {winnerID: 2, prob: 0.75}}
+	for i := range want {
+		if got[i].winnerID != want[i].winnerID || !almostEqual(got[i].prob, want[i].prob) {
+			t.Errorf("combineDealInEstimates()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+type stubTileDangerEstimator map[string]float64
+
+func (s stubTileDangerEstimator) EstimateDealInProb(_ round.StateViewer, _ seat.Seat, _ seat.Seat, discard tile.Tile) (float64, error) {
+	return s[discard.RemoveRed().String()], nil
+}
+
+func TestCandidateEvaluator_plannedDealInEvaluation(t *testing.T) {
+	riichi := stubPlayerViewer{riichiState: player.RiichiAccepted}
+	state := stubCandidateEvaluationStateViewer{
+		roundWind:    wind.East,
+		seatWinds:    [common.NumPlayers]wind.Wind{wind.East, wind.South, wind.West, wind.North},
+		dealer:       seat.MustSeat(0),
+		numLeftTiles: common.NumPlayers * 3,
+		players:      [common.NumPlayers]player.PlayerViewer{stubPlayerViewer{}, riichi, riichi, riichi},
+	}
+	folding := actionCandidate{
+		traceKey:         "9p",
+		discardTile:      tile.MustTileFromCode("9p"),
+		afterDiscardHand: visibleHandFromCodes("E", "5m", "5m"),
+		shanten:          service.InfinityShanten,
+	}
+	pushing := folding
+	pushing.shanten = 1
+	evaluator := candidateEvaluator{
+		stats:  validStubManueStats(),
+		danger: stubTileDangerEstimator{"5m": 0.2, "9p": 0.1},
+		tenpai: NewYamitenTenpaiEstimator(validStubManueStats()),
+	}
+	context := candidateEvaluationContext{
+		stats:                 validStubManueStats(),
+		state:                 state,
+		self:                  seat.MustSeat(0),
+		dealInEstimatesByKind: make(map[tile.Tile][]dealInEstimate),
+	}
+
+	for _, tt := range []struct {
+		name      string
+		candidate actionCandidate
+		turns     int
+		planned   bool
+		want      float64
+	}{
+		{name: "planning off", candidate: folding, turns: 0},
+		{name: "folding", candidate: folding, turns: 3, planned: true, want: 0.2},
+		// The round has three turns left.
+		{name: "beyond the round", candidate: folding, turns: 5, planned: true, want: 0.2},
+		{name: "not folding", candidate: pushing, turns: 3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			evaluator.defenseTurns = tt.turns
+			immediate, _, err := evaluator.immediateDealInEvaluation(context, tt.candidate)
+			if err != nil {
+				t.Fatalf("immediateDealInEvaluation() failed: %v", err)
+			}
+			for _, estimate := range immediate {
+				if !almostEqual(estimate.prob, 0.1) {
+					t.Errorf("immediate deal-in prob for winner %d = %v, want 0.1", estimate.winnerID, estimate.prob)
+				}
+			}
+
+			planned, plannedDist, err := evaluator.plannedDealInEvaluation(context, tt.candidate)
+			if err != nil {
+				t.Fatalf("plannedDealInEvaluation() failed: %v", err)
+			}
+			if (plannedDist != nil) != tt.planned {
+				t.Fatalf("planned distribution = %v, want a plan %v", plannedDist, tt.planned)
+			}
+			if !tt.planned {
+				return
+			}
+			if len(planned) != common.NumPlayers-1 {
+				t.Fatalf("len(planned) = %d, want %d", len(planned), common.NumPlayers-1)
+			}
+			for _, estimate := range planned {
+				if !almostEqual(estimate.prob, tt.want) {
+					t.Errorf("planned deal-in prob for winner %d = %v, want %v", estimate.winnerID, estimate.prob, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -10,6 +10,22 @@ import (
 type ManueAgentDeps struct {
 	Stats  ManueStats
 	Danger DangerEstimator
+	// Tenpai is optional. Without it the tenpai rate of a player without
+	// riichi is looked up in the yamiten table of the stats.
+	Tenpai TenpaiEstimator
+	// Profiles is optional. Without it every opponent is the average player
+	// of the stats.
+	Profiles OpponentProfiles
+	// DefenseTurns is optional. When it is 2 or more, a folding discard is
+	// also weighed by the deal-in of the discards planned after it over that
+	// many turns, so that a folding hand keeps its safe tiles for later
+	// turns. Without it only the discard itself is considered, as the
+	// original does.
+	DefenseTurns int
+	// WinTrials is optional. It is the number of Monte Carlo trials of the
+	// win estimate of each candidate. Without it DefaultWinTrials trials are
+	// run, as the original does.
+	WinTrials int
 }
 
 // ManueStats provides read-only access to immutable statistical data used by
@@ -56,3 +72,15 @@ type RankStats interface {
 type DangerEstimator interface {
 	EstimateDealInProb(state round.StateViewer, self seat.Seat, winner seat.Seat, discard tile.Tile) (float64, error)
 }
+
+// TenpaiEstimator estimates the probability that target is tenpai. A player
+// in riichi is always tenpai.
+type TenpaiEstimator interface {
+	EstimateTenpaiProb(state round.StateViewer, target seat.Seat) (float64, error)
+}
+
+// OpponentProfiles looks up the profile of a player by the name in
+// start_game.
+type OpponentProfiles interface {
+	OpponentProfile(name string) (OpponentProfile, bool)
+}
@@ -7,11 +7,14 @@ import (
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
 )
 
-const defaultWinEstimateTrials = 1000
+// DefaultWinTrials is the number of Monte Carlo trials of the win estimate of
+// each candidate, as the original runs.
+const DefaultWinTrials = 1000
 
 type candidateEvaluationContext struct {
 	stats                         ManueStats
@@ -23,6 +26,16 @@ type candidateEvaluationContext struct {
 	exhaustiveDrawIfTenpaiNow     exhaustiveDrawEvaluation
 	exhaustiveDrawIfNotenNow      exhaustiveDrawEvaluation
 	otherWinDists                 []scoreDeltaProbDist
+	bonus                         roundBonus
+	// riichiDeclared means self has declared riichi and the stick is paid
+	// once the discard passes.
+	riichiDeclared bool
+	// otherWinDistsAfterRiichi is otherWinDists after self's riichi stick is
+	// paid.
+	otherWinDistsAfterRiichi []scoreDeltaProbDist
+	// dealInEstimatesByKind caches the deal-in estimates of the kinds of
+	// tiles defense plans use.
+	dealInEstimatesByKind map[tile.Tile][]dealInEstimate
 }
 
 type exhaustiveDrawEvaluation struct {
@@ -30,23 +43,45 @@ type exhaustiveDrawEvaluation struct {
 	averagePoints float64
 }
 
+// shift returns the evaluation with delta added to every outcome.
+func (e exhaustiveDrawEvaluation) shift(delta scoreDelta, self seat.Seat) exhaustiveDrawEvaluation {
+	dist := e.dist.shift(delta)
+	return exhaustiveDrawEvaluation{
+		dist:          dist,
+		averagePoints: dist.expected()[self.Index()],
+	}
+}
+
 type candidateEvaluationSummary struct {
 	winEstimateGoalCounts []int
+	bonus                 roundBonus
 }
 
 type candidateEvaluator struct {
-	stats  ManueStats
-	danger DangerEstimator
-	rng    *rand.Rand
-	trials int
+	stats     ManueStats
+	danger    DangerEstimator
+	tenpai    TenpaiEstimator
+	opponents opponentTable
+	rng       *rand.Rand
+	trials    int
+	// defenseTurns is the number of turns a discard is planned over. A value
+	// below 2 keeps the deal-in estimate of the discard alone, as the
+	// original does.
+	defenseTurns int
 }
 
-func newCandidateEvaluator(stats ManueStats, danger DangerEstimator, rng *rand.Rand) candidateEvaluator {
+func newCandidateEvaluator(
+	stats ManueStats,
+	danger DangerEstimator,
+	tenpai TenpaiEstimator,
+	rng *rand.Rand,
+) candidateEvaluator {
 	return candidateEvaluator{
 		stats:  stats,
 		danger: danger,
+		tenpai: tenpai,
 		rng:    rng,
-		trials: defaultWinEstimateTrials,
+		trials: DefaultWinTrials,
 	}
 }
 
@@ -70,6 +105,7 @@ func (e candidateEvaluator) evaluateCandidates(
 	}
 	return evaluated, candidateEvaluationSummary{
 		winEstimateGoalCounts: context.winEstimateGoalCounts,
+		bonus:                 context.bonus,
 	}, nil
 }
 
@@ -104,7 +140,16 @@ func (e candidateEvaluator) newEvaluationContext(
 	if err != nil {
 		return candidateEvaluationContext{}, err
 	}
-	baseTenpaiProbs := currentTenpaiProbs(e.stats, state, self)
+	baseTenpaiProbs, err := currentTenpaiProbs(e.tenpai, e.opponents, state, self)
+	if err != nil {
+		return candidateEvaluationContext{}, err
+	}
+	bonus := newRoundBonus(state)
+	stake := riichiStakeDelta(self.Index())
+	otherWinDistsAfterRiichi := otherWinScoreDeltaDists(e.stats, e.opponents, state, self, bonus.withRiichiDeclaration())
+	for i, dist := range otherWinDistsAfterRiichi {
+		otherWinDistsAfterRiichi[i] = dist.shift(stake)
+	}
 
 	return candidateEvaluationContext{
 		stats:                         e.stats,
@@ -115,7 +160,11 @@ func (e candidateEvaluator) newEvaluationContext(
 		exhaustiveDrawProbOnSelfNoWin: exhaustiveDrawProbOnSelfNoWin,
 		exhaustiveDrawIfTenpaiNow:     newExhaustiveDrawEvaluation(baseTenpaiProbs, self, notenTenpaiProb, true),
 		exhaustiveDrawIfNotenNow:      newExhaustiveDrawEvaluation(baseTenpaiProbs, self, notenTenpaiProb, false),
-		otherWinDists:                 otherWinScoreDeltaDists(e.stats, state, self),
+		otherWinDists:                 otherWinScoreDeltaDists(e.stats, e.opponents, state, self, bonus),
+		bonus:                         bonus,
+		riichiDeclared:                selfPlayer.RiichiState() == player.RiichiDeclared,
+		otherWinDistsAfterRiichi:      otherWinDistsAfterRiichi,
+		dealInEstimatesByKind:         make(map[tile.Tile][]dealInEstimate),
 	}, nil
 }
 
@@ -127,22 +176,41 @@ func (e candidateEvaluator) evaluateCandidate(
 	if !ok {
 		return evaluatedActionCandidate{}, fmt.Errorf("missing win estimate")
 	}
+	// A riichi stick is on the table only after the declaration tile passes,
+	// so the immediate deal-in keeps the current bonus.
+	riichiStake := candidate.riichi || context.riichiDeclared
+	bonus := context.bonus
+	if riichiStake {
+		bonus = bonus.withRiichiDeclaration()
+	}
 	selfWinDist := winScoreDeltaDist(
 		context.self.Index(),
 		context.state.Dealer().Index(),
 		context.stats,
 		winEstimate.pointsDist,
+		bonus,
 	)
 
 	dealInEstimates, immediateDist, err := e.immediateDealInEvaluation(context, candidate)
 	if err != nil {
 		return evaluatedActionCandidate{}, err
 	}
+	plannedEstimates, plannedDist, err := e.plannedDealInEvaluation(context, candidate)
+	if err != nil {
+		return evaluatedActionCandidate{}, err
+	}
 
 	exhaustiveDrawEvaluation := context.exhaustiveDrawIfNotenNow
 	if candidate.shanten <= 0 {
 		exhaustiveDrawEvaluation = context.exhaustiveDrawIfTenpaiNow
 	}
+	otherWinDists := context.otherWinDists
+	if riichiStake {
+		stake := riichiStakeDelta(context.self.Index())
+		selfWinDist = selfWinDist.shift(stake)
+		exhaustiveDrawEvaluation = exhaustiveDrawEvaluation.shift(stake, context.self)
+		otherWinDists = context.otherWinDistsAfterRiichi
+	}
 
 	score, err := evaluateCandidateFromComponents(
 		dealInEstimates,
@@ -150,9 +218,11 @@ func (e candidateEvaluator) evaluateCandidate(
 		context.exhaustiveDrawProbOnSelfNoWin,
 		exhaustiveDrawEvaluation.averagePoints,
 		immediateDist,
+		plannedEstimates,
+		plannedDist,
 		selfWinDist,
 		exhaustiveDrawEvaluation.dist,
-		context.otherWinDists,
+		otherWinDists,
 		context.stats,
 		context.state,
 		context.self,
@@ -173,7 +243,6 @@ func (e candidateEvaluator) immediateDealInEvaluation(
 	if candidate.discardTile.IsUnknown() {
 		return nil, immediateScoreDeltaDist(nil), nil
 	}
-
 	dealInEstimates, err := e.dealInEstimates(context.state, context.self, candidate.discardTile)
 	if err != nil {
 		return nil, scoreDeltaProbDist{}, fmt.Errorf("deal-in estimates: %w", err)
@@ -183,6 +252,8 @@ func (e candidateEvaluator) immediateDealInEvaluation(
 		context.state.Dealer().Index(),
 		dealInEstimates,
 		context.stats,
+		e.opponents,
+		context.bonus,
 	)
 	if err != nil {
 		return nil, scoreDeltaProbDist{}, fmt.Errorf("immediate distribution: %w", err)
@@ -190,6 +261,66 @@ func (e candidateEvaluator) immediateDealInEvaluation(
 	return dealInEstimates, immediateDist, nil
 }
 
+// plannedDealInEvaluation returns the deal-in on the discards of the defense
+// plan of candidate after its own discard, which the immediate evaluation
+// leaves out. The distribution is nil when candidate has no plan.
+func (e candidateEvaluator) plannedDealInEvaluation(
+	context candidateEvaluationContext,
+	candidate actionCandidate,
+) ([]dealInEstimate, scoreDeltaProbDist, error) {
+	if !e.plansDefense(candidate) {
+		return nil, nil, nil
+	}
+	plan, err := planDefense(
+		candidate.discardTile,
+		candidate.afterDiscardHand.ToTileCounts34(),
+		min(e.defenseTurns, stateNumRemainTurns(context.state)),
+		func(t tile.Tile) ([]dealInEstimate, error) {
+			return e.cachedDealInEstimates(context, t)
+		},
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("defense plan: %w", err)
+	}
+	plannedDist, err := immediateScoreDeltaDistFromStats(
+		context.self.Index(),
+		context.state.Dealer().Index(),
+		plan.later,
+		context.stats,
+		e.opponents,
+		context.bonus,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("planned distribution: %w", err)
+	}
+	return plan.later, plannedDist, nil
+}
+
+// plansDefense tells whether the deal-in of candidate is planned over the
+// next turns. Only a folding candidate, whose discard gives up winning, is
+// planned; a hand that still aims to win discards by its goals instead.
+func (e candidateEvaluator) plansDefense(candidate actionCandidate) bool {
+	return e.defenseTurns > 1 &&
+		e.danger != nil &&
+		candidate.afterDiscardHand != nil &&
+		candidate.shanten == service.InfinityShanten
+}
+
+func (e candidateEvaluator) cachedDealInEstimates(
+	context candidateEvaluationContext,
+	t tile.Tile,
+) ([]dealInEstimate, error) {
+	if estimates, ok := context.dealInEstimatesByKind[t]; ok {
+		return estimates, nil
+	}
+	estimates, err := e.dealInEstimates(context.state, context.self, t)
+	if err != nil {
+		return nil, err
+	}
+	context.dealInEstimatesByKind[t] = estimates
+	return estimates, nil
+}
+
 func newExhaustiveDrawEvaluation(
 	baseTenpaiProbs [common.NumPlayers]float64,
 	self seat.Seat,
@@ -217,15 +348,16 @@ func (e candidateEvaluator) dealInEstimates(
 	if e.danger == nil {
 		return nil, nil
 	}
-	remainTurns := stateNumRemainTurns(state)
 	estimates := make([]dealInEstimate, 0, common.NumPlayers-1)
 	for i := range common.NumPlayers {
 		winner := seat.MustSeat(i)
 		if winner == self {
 			continue
 		}
-		winnerPlayer := state.Player(winner)
-		tenpai := tenpaiProb(e.stats, winnerPlayer.RiichiState() != player.NotRiichi, remainTurns, len(winnerPlayer.Melds()))
+		tenpai, err := e.opponents[i].tenpaiProb(e.tenpai, state, winner)
+		if err != nil {
+			return nil, err
+		}
 		rawProb, err := e.danger.EstimateDealInProb(state, self, winner, discard)
 		if err != nil {
 			return nil, err
@@ -238,28 +370,41 @@ func (e candidateEvaluator) dealInEstimates(
 	return estimates, nil
 }
 
-func currentTenpaiProbs(stats TenpaiEstimatorStats, state round.StateViewer, self seat.Seat) [common.NumPlayers]float64 {
-	remainTurns := stateNumRemainTurns(state)
+func currentTenpaiProbs(
+	estimator TenpaiEstimator,
+	opponents opponentTable,
+	state round.StateViewer,
+	self seat.Seat,
+) ([common.NumPlayers]float64, error) {
 	var probs [common.NumPlayers]float64
 	for i := range common.NumPlayers {
 		playerSeat := seat.MustSeat(i)
 		if playerSeat == self {
 			continue
 		}
-		p := state.Player(playerSeat)
-		probs[i] = tenpaiProb(stats, p.RiichiState() != player.NotRiichi, remainTurns, len(p.Melds()))
+		prob, err := opponents[i].tenpaiProb(estimator, state, playerSeat)
+		if err != nil {
+			return probs, fmt.Errorf("tenpai probability of player %d: %w", i, err)
+		}
+		probs[i] = prob
 	}
-	return probs
+	return probs, nil
 }
 
-func otherWinScoreDeltaDists(stats WinScoreStats, state round.StateViewer, self seat.Seat) []scoreDeltaProbDist {
+func otherWinScoreDeltaDists(
+	stats WinScoreStats,
+	opponents opponentTable,
+	state round.StateViewer,
+	self seat.Seat,
+	bonus roundBonus,
+) []scoreDeltaProbDist {
 	dists := make([]scoreDeltaProbDist, 0, common.NumPlayers-1)
 	for i := range common.NumPlayers {
 		actor := seat.MustSeat(i)
 		if actor == self {
 			continue
 		}
-		dists = append(dists, randomWinScoreDeltaDist(actor.Index(), state.Dealer().Index(), stats))
+		dists = append(dists, randomWinScoreDeltaDist(actor.Index(), state.Dealer().Index(), stats, opponents[i], bonus))
 	}
 	return dists
 }
@@ -43,6 +43,8 @@ func TestEvaluateCandidateFromComponents(t *testing.T) {
 		0.375,
 		1200,
 		immediateDist,
+		nil,
+		nil,
 		selfWinDist,
 		exhaustiveDrawDist,
 		otherWinDists,
@@ -88,6 +90,49 @@ func TestEvaluateCandidateFromComponents(t *testing.T) {
 	}
 }
 
+func TestEvaluateCandidateFromComponents_PlannedDealInPrecedesTheRestOfTheRound(t *testing.T) {
+	got, err := evaluateCandidateFromComponents(
+		[]dealInEstimate{{winnerID: 1, prob: 0.25}},
+		winEstimate{prob: 0.2},
+		0.375,
+		0,
+		scoreDeltaProbDist{{}: 0.75, {-1000, 1000}: 0.25},
+		[]dealInEstimate{{winnerID: 1, prob: 0.2}},
+		scoreDeltaProbDist{{}: 0.8, {-1000, 1000}: 0.2},
+		scoreDeltaProbDist{{1000, 0, 0, 0}: 1},
+		scoreDeltaProbDist{{}: 1},
+		[]scoreDeltaProbDist{{{}: 1}, {{}: 1}},
+		stubManueStats{
+			relativeWinProbs: map[string]map[string]float64{
+				"E1,0,1": {"-1000": 0.5, "0": 0.5, "1000": 0.5},
+				"E1,0,2": {"-1000": 0.5, "0": 0.5, "1000": 0.5},
+				"E1,0,3": {"-1000": 0.5, "0": 0.5, "1000": 0.5},
+			},
+		},
+		stubRankStateViewer{
+			nextRoundWind:  wind.East,
+			nextRoundNum:   1,
+			scores:         [common.NumPlayers]int{25000, 25000, 25000, 25000},
+			startingDealer: seat.MustSeat(0),
+		},
+		seat.MustSeat(0),
+	)
+	if err != nil {
+		t.Fatalf("evaluateCandidateFromComponents() failed: %v", err)
+	}
+
+	if !almostEqual(got.dealInProb, 0.25) {
+		t.Errorf("dealInProb = %v, want 0.25", got.dealInProb)
+	}
+	if !got.planned || !almostEqual(got.plannedDealInProb, 0.2) {
+		t.Errorf("plannedDealInProb = %v (planned %v), want 0.2", got.plannedDealInProb, got.planned)
+	}
+	// -1000 * 0.25 + 0.75 * (-1000 * 0.2 + 0.8 * 1000 * 0.2)
+	if !almostEqual(got.expectedPoints, -280) {
+		t.Errorf("expectedPoints = %v, want -280", got.expectedPoints)
+	}
+}
+
 func TestEvaluateCandidateFromComponents_ReturnsErrorWithInvalidEstimate(t *testing.T) {
 	_, err := evaluateCandidateFromComponents(
 		[]dealInEstimate{{winnerID: 1, prob: 1.1}},
@@ -98,6 +143,8 @@ func TestEvaluateCandidateFromComponents_ReturnsErrorWithInvalidEstimate(t *test
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
 		stubManueStats{},
 		stubRankStateViewer{},
 		seat.MustSeat(0),
@@ -200,6 +247,7 @@ func TestCandidateEvaluator_dealInEstimates_SafeTileHasZeroDealInProb(t *testing
 	evaluator := candidateEvaluator{
 		stats:  validStubManueStats(),
 		danger: NewDangerEstimator(stubDangerTreeLeaf{prob: 0.75}),
+		tenpai: NewYamitenTenpaiEstimator(validStubManueStats()),
 	}
 
 	got, err := evaluator.dealInEstimates(state, self, discard)
@@ -27,6 +27,15 @@ func NewManueAgent(seed uint64, deps ManueAgentDeps) (*ManueAgent, error) {
 	if deps.Danger == nil {
 		return nil, fmt.Errorf("cannot create ManueAgent: danger estimator dependency is required")
 	}
+	if deps.DefenseTurns < 0 {
+		return nil, fmt.Errorf("cannot create ManueAgent: defense turns must not be negative")
+	}
+	if deps.WinTrials < 0 {
+		return nil, fmt.Errorf("cannot create ManueAgent: win trials must not be negative")
+	}
+	if deps.Tenpai == nil {
+		deps.Tenpai = NewYamitenTenpaiEstimator(deps.Stats)
+	}
 	agent := &ManueAgent{
 		seed: seed,
 		deps: deps,
@@ -42,7 +51,22 @@ func (a *ManueAgent) Reset() {
 	// result, the same state, for example South 2, can get different evaluation
 	// values when reached from East 1 than when started directly from that round.
 	rng := rand.New(rand.NewPCG(a.seed, 0))
-	a.evaluator = newCandidateEvaluator(a.deps.Stats, a.deps.Danger, rng)
+	a.evaluator = newCandidateEvaluator(a.deps.Stats, a.deps.Danger, a.deps.Tenpai, rng)
+	a.evaluator.defenseTurns = a.deps.DefenseTurns
+	if a.deps.WinTrials > 0 {
+		a.evaluator.trials = a.deps.WinTrials
+	}
+}
+
+// SetPlayerNames looks up the profiles of the players of the game. Players
+// without a profile are the average player.
+func (a *ManueAgent) SetPlayerNames(names []string) {
+	a.evaluator.opponents = newOpponentTable(a.deps.Profiles, names)
+}
+
+// Fallback returns a SafeAgent with the danger estimator of the agent.
+func (a *ManueAgent) Fallback() Agent {
+	return NewSafeAgent(a.deps.Danger)
 }
 
 func (a *ManueAgent) Decide(request Request) (Decision, error) {
@@ -131,7 +155,10 @@ func (a *ManueAgent) decideFromCandidates(
 	if err != nil {
 		return Decision{}, err
 	}
-	tenpaiProbs := currentTenpaiProbs(a.deps.Stats, state, selfSeat)
+	tenpaiProbs, err := currentTenpaiProbs(a.deps.Tenpai, a.evaluator.opponents, state, selfSeat)
+	if err != nil {
+		return Decision{}, err
+	}
 	return buildCandidateDecision(evaluatedCandidates, preferBlack, tenpaiProbs, selfSeat, summary), nil
 }
 
@@ -145,10 +172,31 @@ func buildCandidateDecision(
 	selected := chooseBestCandidate(candidates, preferBlack)
 	log := formatCandidateLog(candidates, tenpaiProbs, self)
 	return Decision{
-		Action: selected.candidate.action,
-		Log:    log,
-		Trace:  formatDecisionTrace(log, &selected, summary),
+		Action:     selected.candidate.action,
+		Log:        log,
+		Trace:      formatDecisionTrace(log, &selected, summary),
+		Candidates: candidateEvaluations(sortedCandidates(candidates, preferBlack)),
+	}
+}
+
+func candidateEvaluations(candidates []evaluatedActionCandidate) []CandidateEvaluation {
+	evaluations := make([]CandidateEvaluation, len(candidates))
+	for i, c := range candidates {
+		evaluations[i] = CandidateEvaluation{
+			Key:                         c.candidate.traceKey,
+			Action:                      c.candidate.action,
+			AverageRank:                 c.score.averageRank,
+			ExpectedPoints:              c.score.expectedPoints,
+			DealInProb:                  c.score.dealInProb,
+			WinProb:                     c.score.winProb,
+			ExhaustiveDrawProb:          c.score.exhaustiveDrawProb,
+			OtherWinProb:                c.score.otherWinProb,
+			AverageWinPoints:            c.score.averageWinPoints,
+			ExhaustiveDrawAveragePoints: c.score.exhaustiveDrawAveragePoints,
+			Shanten:                     c.candidate.shanten,
+		}
 	}
+	return evaluations
 }
 
 func firstActionOfType[T action.Action](actions []action.Action) T {
@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+// OpponentProfile describes how a player differs from the average player of
+// the training logs. Each scale multiplies the corresponding estimate. A zero
+// scale leaves the estimate as is, so the zero profile is the average player.
+//
+// Riichi and call tendencies show up in the tenpai scales: a player who
+// rarely stays dama is less often tenpai without riichi.
+type OpponentProfile struct {
+	// ClosedTenpaiScale scales the probability that the player is tenpai
+	// without riichi while having no melds.
+	ClosedTenpaiScale float64
+	// OpenTenpaiScale scales the probability that the player is tenpai
+	// without riichi while having melds.
+	OpenTenpaiScale float64
+	// WinPointsScale scales the points of the player's wins.
+	WinPointsScale float64
+}
+
+func scaleOrOne(scale float64) float64 {
+	if scale == 0 {
+		return 1.0
+	}
+	return scale
+}
+
+// tenpaiProb returns the probability that target is tenpai, scaling the
+// estimate of the average player. A riichi player is always tenpai.
+func (o OpponentProfile) tenpaiProb(estimator TenpaiEstimator, state round.StateViewer, target seat.Seat) (float64, error) {
+	prob, err := estimator.EstimateTenpaiProb(state, target)
+	if err != nil {
+		return 0, err
+	}
+	p := state.Player(target)
+	if p.RiichiState() != player.NotRiichi {
+		return prob, nil
+	}
+	scale := o.ClosedTenpaiScale
+	if len(p.Melds()) > 0 {
+		scale = o.OpenTenpaiScale
+	}
+	return min(prob*scaleOrOne(scale), 1.0), nil
+}
+
+// winPointsDist scales the points of a win distribution.
+func (o OpponentProfile) winPointsDist(dist scalarProbDist) scalarProbDist {
+	scale := scaleOrOne(o.WinPointsScale)
+	if scale == 1.0 {
+		return dist
+	}
+	scaled := make(scalarProbDist, len(dist))
+	for points, prob := range dist {
+		scaled[points*scale] += prob
+	}
+	return scaled
+}
+
+// opponentTable holds the profiles of the players at the table by seat.
+type opponentTable [common.NumPlayers]OpponentProfile
+
+func newOpponentTable(profiles OpponentProfiles, names []string) opponentTable {
+	var table opponentTable
+	if profiles == nil || len(names) != common.NumPlayers {
+		return table
+	}
+	for i, name := range names {
+		if profile, ok := profiles.OpponentProfile(name); ok {
+			table[i] = profile
+		}
+	}
+	return table
+}
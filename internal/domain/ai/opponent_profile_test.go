@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+func TestOpponentProfile_TenpaiProb(t *testing.T) {
+	stats := stubManueStats{yamitenCounts: map[string]yamitenCount{
+		"10,0": {total: 10, tenpai: 2},
+		"10,1": {total: 10, tenpai: 6},
+	}}
+	pon := meld.MustPon(tile.MustTileFromCode("E"), [2]tile.Tile{tile.MustTileFromCode("E"), tile.MustTileFromCode("E")}, seat.MustSeat(1))
+	closed := stubPlayerViewer{riichiState: player.NotRiichi}
+	open := stubPlayerViewer{riichiState: player.NotRiichi, melds: []meld.Meld{pon}}
+	riichi := stubPlayerViewer{riichiState: player.RiichiAccepted}
+	profile := OpponentProfile{ClosedTenpaiScale: 1.5, OpenTenpaiScale: 2}
+
+	tests := []struct {
+		name    string
+		profile OpponentProfile
+		player  player.PlayerViewer
+		want    float64
+	}{
+		{name: "zero profile is average", profile: OpponentProfile{}, player: closed, want: 0.2},
+		{name: "closed", profile: profile, player: closed, want: 0.3},
+		{name: "open is capped", profile: profile, player: open, want: 1},
+		{name: "riichi", profile: profile, player: riichi, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := stubStateWithSelf(stubPlayerViewer{})
+			state.players[1] = tt.player
+			state.numLeftTiles = 10 * common.NumPlayers
+			got, err := tt.profile.tenpaiProb(NewYamitenTenpaiEstimator(stats), state, seat.MustSeat(1))
+			if err != nil {
+				t.Fatalf("tenpaiProb() failed: %v", err)
+			}
+			if !almostEqual(got, tt.want) {
+				t.Errorf("tenpaiProb() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpponentProfile_WinPointsDist(t *testing.T) {
+	dist := scalarProbDist{1000: 0.5, 2000: 0.5}
+	assertScalarProbDist(t, OpponentProfile{}.winPointsDist(dist), dist)
+	assertScalarProbDist(t, OpponentProfile{WinPointsScale: 1.5}.winPointsDist(dist), scalarProbDist{1500: 0.5, 3000: 0.5})
+}
+
+type stubOpponentProfiles map[string]OpponentProfile
+
+func (s stubOpponentProfiles) OpponentProfile(name string) (OpponentProfile, bool) {
+	p, ok := s[name]
+	return p, ok
+}
+
+func TestNewOpponentTable(t *testing.T) {
+	profiles := stubOpponentProfiles{"Mortal": {ClosedTenpaiScale: 1.2}}
+
+	got := newOpponentTable(profiles, []string{"Manue", "Mortal", "x", "y"})
+	if got[1].ClosedTenpaiScale != 1.2 || got[0] != (OpponentProfile{}) {
+		t.Errorf("newOpponentTable() = %+v, want the profile of Mortal at seat 1 only", got)
+	}
+	if got := newOpponentTable(nil, []string{"Manue", "Mortal", "x", "y"}); got != (opponentTable{}) {
+		t.Errorf("newOpponentTable(nil) = %+v, want average players", got)
+	}
+	if got := newOpponentTable(profiles, nil); got != (opponentTable{}) {
+		t.Errorf("newOpponentTable() without names = %+v, want average players", got)
+	}
+}
+
+func TestManueAgent_ResetClearsPlayerNames(t *testing.T) {
+	agent, err := NewManueAgent(0, ManueAgentDeps{
+		Stats:    validStubManueStats(),
+		Danger:   stubDangerEstimator{},
+		Profiles: stubOpponentProfiles{"Mortal": {ClosedTenpaiScale: 1.2}},
+	})
+	if err != nil {
+		t.Fatalf("NewManueAgent() failed: %v", err)
+	}
+
+	agent.SetPlayerNames([]string{"Manue", "Mortal", "x", "y"})
+	if agent.evaluator.opponents[1].ClosedTenpaiScale != 1.2 {
+		t.Fatalf("opponents = %+v, want the profile of Mortal at seat 1", agent.evaluator.opponents)
+	}
+	agent.Reset()
+	if agent.evaluator.opponents != (opponentTable{}) {
+		t.Errorf("opponents after Reset() = %+v, want average players", agent.evaluator.opponents)
+	}
+}
@@ -0,0 +1,36 @@
+package ai
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func randomScoreDeltaProbDist(rng *rand.Rand, n int) scoreDeltaProbDist {
+	dist := make(scoreDeltaProbDist, n)
+	for len(dist) < n {
+		var value scoreDelta
+		for i := range value {
+			value[i] = float64(rng.IntN(97)-48) * 1000
+		}
+		dist[value] = rng.Float64()
+	}
+	return dist
+}
+
+func BenchmarkScoreDeltaProbDist_MapValueScalar(b *testing.B) {
+	dist := randomScoreDeltaProbDist(rand.New(rand.NewPCG(1, 2)), 256)
+	mapper := func(value scoreDelta) float64 { return value[0] - value[1] }
+	for b.Loop() {
+		_ = dist.mapValueScalar(mapper)
+	}
+}
+
+func BenchmarkAddAheadVectorProbDists(b *testing.B) {
+	lhs := make(aheadVectorProbDist)
+	for i := range 16 {
+		lhs[aheadVector{i & 1, i >> 1 & 1, i >> 2 & 1, i >> 3 & 1}] = 1.0 / 16
+	}
+	for b.Loop() {
+		_ = addAheadVectorProbDists(lhs, lhs)
+	}
+}
@@ -69,10 +69,12 @@ func averageRank(
 	selfPosition int,
 	opponents []rankOpponent,
 ) float64 {
+	outcomes := scoreChanges.values()
 	winsDist := aheadVectorProbDist{{}: 1.0}
 	for _, opponent := range opponents {
 		winProb := winProbAgainst(
 			scoreChanges,
+			outcomes,
 			selfID,
 			opponent.id,
 			selfScore,
@@ -97,9 +99,11 @@ func averageRank(
 }
 
 // winProbAgainst returns the probability that self finishes ahead of another
-// player after applying a score-delta distribution.
+// player after applying a score-delta distribution. outcomes are the values of
+// scoreChanges.
 func winProbAgainst(
 	scoreChanges scoreDeltaProbDist,
+	outcomes []scoreDelta,
 	selfID int,
 	otherID int,
 	selfScore float64,
@@ -108,13 +112,13 @@ func winProbAgainst(
 	otherPosition int,
 	winProbs relativeWinProbTable,
 ) float64 {
-	relativeScoreDist := scoreChanges.mapValueScalar(func(scoreChange scoreDelta) float64 {
+	relativeScoreDist := scoreChanges.mapValuesScalar(outcomes, func(scoreChange scoreDelta) float64 {
 		return (selfScore + scoreChange[selfID]) - (otherScore + scoreChange[otherID])
 	})
 
 	winProb := 0.0
-	for relativeScore, prob := range relativeScoreDist {
-		winProb += prob * winProbFromRelativeScore(
+	for _, relativeScore := range relativeScoreDist.values() {
+		winProb += relativeScoreDist[relativeScore] * winProbFromRelativeScore(
 			relativeScore,
 			winProbs,
 			selfPosition,
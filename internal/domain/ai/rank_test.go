@@ -143,6 +143,7 @@ func TestWinProbAgainst(t *testing.T) {
 
 	got := winProbAgainst(
 		scoreChanges,
+		scoreChanges.values(),
 		0,
 		1,
 		25000,
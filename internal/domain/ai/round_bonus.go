@@ -0,0 +1,59 @@
+package ai
+
+import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+
+const (
+	// honbaWinPoints is what the winner collects per honba in total: the
+	// discarder pays all of it on ron, and every other player pays a third on
+	// self-draw.
+	honbaWinPoints    = 300
+	riichiStickPoints = 1000
+)
+
+// roundBonus is what the winner of the round collects on top of the win
+// points: the honba bonus and the riichi sticks on the table. An exhaustive
+// draw pays no honba and leaves the sticks on the table, so only wins carry
+// the bonus.
+type roundBonus struct {
+	honba        int
+	riichiSticks int
+}
+
+func newRoundBonus(state interface {
+	Honba() int
+	RiichiDeposit() int
+}) roundBonus {
+	return roundBonus{honba: state.Honba(), riichiSticks: state.RiichiDeposit()}
+}
+
+// withRiichiDeclaration returns the bonus after self's riichi stick is put on
+// the table.
+func (b roundBonus) withRiichiDeclaration() roundBonus {
+	b.riichiSticks++
+	return b
+}
+
+// winDelta returns the score change of the bonus when actorID wins. targetID
+// is the winner for self-draw wins, or the discarder for ron wins.
+func (b roundBonus) winDelta(actorID int, targetID int) scoreDelta {
+	var delta scoreDelta
+	honba := float64(b.honba * honbaWinPoints)
+	if targetID == actorID {
+		for id := range delta {
+			delta[id] = -honba / float64(common.NumPlayers-1)
+		}
+	} else {
+		delta[targetID] = -honba
+	}
+	delta[actorID] = honba + float64(b.riichiSticks*riichiStickPoints)
+	return delta
+}
+
+// riichiStakeDelta is self's payment of a riichi stick. The stick is paid only
+// when the declaration tile is not ronned, so it applies to the branches after
+// the immediate discard.
+func riichiStakeDelta(selfID int) scoreDelta {
+	var delta scoreDelta
+	delta[selfID] = -riichiStickPoints
+	return delta
+}
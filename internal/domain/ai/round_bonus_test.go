@@ -0,0 +1,31 @@
+package ai
+
+import "testing"
+
+func TestRoundBonus_WinDelta(t *testing.T) {
+	bonus := roundBonus{honba: 3, riichiSticks: 2}
+
+	tests := []struct {
+		name     string
+		actorID  int
+		targetID int
+		want     scoreDelta
+	}{
+		{name: "ron", actorID: 1, targetID: 3, want: scoreDelta{0, 2900, 0, -900}},
+		{name: "self-draw", actorID: 1, targetID: 1, want: scoreDelta{-300, 2900, -300, -300}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bonus.winDelta(tt.actorID, tt.targetID); got != tt.want {
+				t.Errorf("winDelta(%d, %d) = %v, want %v", tt.actorID, tt.targetID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundBonus_WithRiichiDeclaration(t *testing.T) {
+	got := roundBonus{honba: 1, riichiSticks: 2}.withRiichiDeclaration()
+	if want := (roundBonus{honba: 1, riichiSticks: 3}); got != want {
+		t.Errorf("withRiichiDeclaration() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// SafeAgent plays without evaluating the hand, for when another agent fails.
+// It wins when it can, passes on calls, keeps discarding the drawn tile after
+// riichi, and otherwise discards the tile least likely to deal in, keeping the
+// widest ukeire among equally safe tiles.
+type SafeAgent struct {
+	danger DangerEstimator
+}
+
+// NewSafeAgent returns a SafeAgent. Without a danger estimator it discards
+// the tile that keeps the widest ukeire.
+func NewSafeAgent(danger DangerEstimator) *SafeAgent {
+	return &SafeAgent{danger: danger}
+}
+
+func (*SafeAgent) Reset() {}
+
+func (a *SafeAgent) Decide(request Request) (Decision, error) {
+	legalActions, err := request.Round.LegalActions(request.Self)
+	if err != nil {
+		return Decision{}, err
+	}
+	if len(legalActions) == 0 {
+		return Decision{}, fmt.Errorf("cannot decide: no legal actions for player %d", request.Self.Index())
+	}
+
+	if win := firstActionOfType[*action.Win](legalActions); win != nil {
+		return Decision{Action: win}, nil
+	}
+	if pass := firstActionOfType[*action.Pass](legalActions); pass != nil {
+		return Decision{Action: pass}, nil
+	}
+	tsumogiri := tsumogiriDiscard(legalActions)
+	if tsumogiri != nil && request.Round.Player(request.Self).RiichiState() == player.RiichiAccepted {
+		return Decision{Action: tsumogiri}, nil
+	}
+	if discard := a.safestDiscard(request.Round, request.Self, legalActions, tsumogiri); discard != nil {
+		return Decision{Action: discard}, nil
+	}
+	return Decision{Action: legalActions[0]}, nil
+}
+
+// safestDiscard returns the discard with the lowest sum of deal-in
+// probabilities over the opponents. Ties go to the discard that leaves the
+// lower shanten number and then more live accepted tiles, and then to
+// preferred. Every discard ties when the danger cannot be estimated.
+func (a *SafeAgent) safestDiscard(
+	state round.StateViewer,
+	self seat.Seat,
+	legalActions []action.Action,
+	preferred *action.Discard,
+) *action.Discard {
+	var discards []*action.Discard
+	for _, candidate := range legalActions {
+		if discard, ok := candidate.(*action.Discard); ok {
+			discards = append(discards, discard)
+		}
+	}
+	dangers := a.dangers(state, self, discards)
+	ukeire := discardUkeire(state, self)
+
+	var safest *action.Discard
+	safestIndex := 0
+	for i, discard := range discards {
+		if safest == nil {
+			safest, safestIndex = discard, i
+			continue
+		}
+		order := cmp.Compare(dangers[i], dangers[safestIndex])
+		if order == 0 {
+			order = compareUkeire(ukeire[discard.Tile()], ukeire[safest.Tile()])
+		}
+		if order < 0 || (order == 0 && discard == preferred) {
+			safest, safestIndex = discard, i
+		}
+	}
+	return safest
+}
+
+// dangers returns the sum of deal-in probabilities of each discard, or all
+// zeros when the danger cannot be estimated.
+func (a *SafeAgent) dangers(state round.StateViewer, self seat.Seat, discards []*action.Discard) []float64 {
+	dangers := make([]float64, len(discards))
+	if a.danger == nil {
+		return dangers
+	}
+	for i, discard := range discards {
+		for j := range common.NumPlayers {
+			winner := seat.MustSeat(j)
+			if winner == self {
+				continue
+			}
+			prob, err := a.danger.EstimateDealInProb(state, self, winner, discard.Tile())
+			if err != nil {
+				return make([]float64, len(discards))
+			}
+			dangers[i] += prob
+		}
+	}
+	return dangers
+}
+
+// discardUkeire returns the ukeire after each discard from the hand of self,
+// or nil when the hand is not visible.
+func discardUkeire(state round.StateViewer, self seat.Seat) map[tile.Tile]*service.Ukeire {
+	h, err := selfTurnHand(state.Player(self))
+	if err != nil {
+		return nil
+	}
+	results := service.AnalyzeDiscardUkeire(h, state.VisibleTiles(self))
+	ukeire := make(map[tile.Tile]*service.Ukeire, len(results))
+	for i := range results {
+		ukeire[results[i].Discard] = &results[i].Ukeire
+	}
+	return ukeire
+}
+
+// compareUkeire orders the lower shanten number and then more live accepted
+// tiles first. Missing ukeire is equal to each other.
+func compareUkeire(lhs *service.Ukeire, rhs *service.Ukeire) int {
+	if lhs == nil || rhs == nil {
+		return 0
+	}
+	if lhs.Shanten != rhs.Shanten {
+		return cmp.Compare(lhs.Shanten, rhs.Shanten)
+	}
+	return cmp.Compare(rhs.NumAccepted, lhs.NumAccepted)
+}
@@ -0,0 +1,126 @@
+package ai_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+type tileDangerEstimator struct {
+	probs map[string]float64
+	err   error
+}
+
+func (e tileDangerEstimator) EstimateDealInProb(_ round.StateViewer, _ seat.Seat, _ seat.Seat, t tile.Tile) (float64, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	if prob, ok := e.probs[t.String()]; ok {
+		return prob, nil
+	}
+	return 0.1, nil
+}
+
+func TestSafeAgent_Decide_Discard(t *testing.T) {
+	tests := []struct {
+		name          string
+		danger        ai.DangerEstimator
+		wantTile      string
+		wantTsumogiri bool
+	}{
+		{
+			// Discarding 1p keeps more accepted tiles than the drawn 6m.
+			name:     "without danger",
+			danger:   nil,
+			wantTile: "1p",
+		},
+		{
+			name:     "safest tile",
+			danger:   tileDangerEstimator{probs: map[string]float64{"2p": 0.01}},
+			wantTile: "2p",
+		},
+		{
+			name:     "tie keeps the widest ukeire",
+			danger:   tileDangerEstimator{probs: map[string]float64{}},
+			wantTile: "1p",
+		},
+		{
+			name:          "tie prefers the drawn tile",
+			danger:        tileDangerEstimator{probs: map[string]float64{"1p": 0.2, "2p": 0.2, "3p": 0.2, "4p": 0.2}},
+			wantTile:      "6m",
+			wantTsumogiri: true,
+		},
+		{
+			name:     "danger error",
+			danger:   tileDangerEstimator{err: errors.New("estimate failed")},
+			wantTile: "1p",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			self := seat.MustSeat(0)
+			roundState := mustNewRoundStateForTest(t, newValidHands())
+			if err := roundState.Apply(event.NewDraw(self, tile.MustTileFromCode("6m"))); err != nil {
+				t.Fatalf("Apply(Draw) failed: %v", err)
+			}
+
+			got, err := ai.NewSafeAgent(tt.danger).Decide(ai.Request{Self: self, Round: roundState})
+			if err != nil {
+				t.Fatalf("Decide() failed: %v", err)
+			}
+			discard, ok := got.Action.(*action.Discard)
+			if !ok {
+				t.Fatalf("Action = %T, want *action.Discard", got.Action)
+			}
+			if discard.Tile().String() != tt.wantTile || discard.Tsumogiri() != tt.wantTsumogiri {
+				t.Errorf("Action = %v (tsumogiri %t), want %s (tsumogiri %t)",
+					discard.Tile(), discard.Tsumogiri(), tt.wantTile, tt.wantTsumogiri)
+			}
+		})
+	}
+}
+
+func TestSafeAgent_Decide_PassesOnCalls(t *testing.T) {
+	self := seat.MustSeat(0)
+	kamicha := seat.MustSeat(3)
+	roundState := mustNewRoundStateForTest(t, newValidHands())
+	for _, ev := range []event.Event{
+		event.NewDraw(self, tile.MustTileFromCode("6m")),
+		event.NewDiscard(self, tile.MustTileFromCode("6m"), true),
+		event.NewDraw(seat.MustSeat(1), tile.MustTileFromCode("?")),
+		event.NewDiscard(seat.MustSeat(1), tile.MustTileFromCode("9s"), true),
+		event.NewDraw(seat.MustSeat(2), tile.MustTileFromCode("?")),
+		event.NewDiscard(seat.MustSeat(2), tile.MustTileFromCode("9s"), true),
+		event.NewDraw(kamicha, tile.MustTileFromCode("?")),
+		event.NewDiscard(kamicha, tile.MustTileFromCode("5p"), true),
+	} {
+		if err := roundState.Apply(ev); err != nil {
+			t.Fatalf("Apply(%T) failed: %v", ev, err)
+		}
+	}
+
+	got, err := ai.NewSafeAgent(nil).Decide(ai.Request{Self: self, Round: roundState})
+	if err != nil {
+		t.Fatalf("Decide() failed: %v", err)
+	}
+	if _, ok := got.Action.(*action.Pass); !ok {
+		t.Errorf("Action = %T, want *action.Pass", got.Action)
+	}
+}
+
+func TestSafeAgent_Decide_NoLegalActions(t *testing.T) {
+	roundState := mustNewRoundStateForTest(t, newValidHands())
+
+	if _, err := ai.NewSafeAgent(nil).Decide(ai.Request{
+		Self:  seat.MustSeat(0),
+		Round: roundState,
+	}); err == nil {
+		t.Fatal("Decide() succeeded unexpectedly")
+	}
+}
@@ -1,5 +1,10 @@
 package ai
 
+import (
+	"maps"
+	"slices"
+)
+
 type scalarProbDist map[float64]float64
 
 // newScalarProbDist builds a scalar probability distribution and drops
@@ -17,8 +22,14 @@ func newScalarProbDist(items map[float64]float64) scalarProbDist {
 // expected returns the expected scalar value of the distribution.
 func (d scalarProbDist) expected() float64 {
 	result := 0.0
-	for value, prob := range d {
-		result += prob * value
+	for _, value := range d.values() {
+		result += d[value] * value
 	}
 	return result
 }
+
+// values returns the outcomes in ascending order. Accumulating over them
+// instead of ranging over the map keeps float sums reproducible.
+func (d scalarProbDist) values() []float64 {
+	return slices.Sorted(maps.Keys(d))
+}
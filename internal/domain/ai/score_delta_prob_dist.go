@@ -1,6 +1,11 @@
 package ai
 
-import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+import (
+	"maps"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+)
 
 // scoreDelta is a NumPlayers-length score change vector.
 type scoreDelta [common.NumPlayers]float64
@@ -33,7 +38,8 @@ func newScoreDeltaProbDist(items map[scoreDelta]float64) scoreDeltaProbDist {
 // It is sum(probability * scoreDelta) element by element.
 func (d scoreDeltaProbDist) expected() scoreDelta {
 	var result scoreDelta
-	for value, prob := range d {
+	for _, value := range d.values() {
+		prob := d[value]
 		for i, v := range value {
 			result[i] += prob * v
 		}
@@ -41,6 +47,17 @@ func (d scoreDeltaProbDist) expected() scoreDelta {
 	return result
 }
 
+// values returns the outcomes in lexicographic order. Float sums of three or
+// more terms depend on their order, so operations that accumulate several
+// outcomes into one value range over values instead of the map to stay
+// reproducible. Operations that add at most two terms to a value range over
+// the map.
+func (d scoreDeltaProbDist) values() []scoreDelta {
+	return slices.SortedFunc(maps.Keys(d), func(lhs, rhs scoreDelta) int {
+		return slices.Compare(lhs[:], rhs[:])
+	})
+}
+
 // replace expands one outcome into another distribution.
 //
 // In Manue this connects immediate and future score changes. For example,
@@ -68,12 +85,32 @@ func (d scoreDeltaProbDist) replace(oldValue scoreDelta, newDist scoreDeltaProbD
 	return newScoreDeltaProbDist(dist)
 }
 
+// shift adds delta to every outcome.
+func (d scoreDeltaProbDist) shift(delta scoreDelta) scoreDeltaProbDist {
+	dist := make(scoreDeltaProbDist, len(d))
+	for value, prob := range d {
+		var shifted scoreDelta
+		for i := range shifted {
+			shifted[i] = value[i] + delta[i]
+		}
+		// Scores are whole points, so shifting never merges outcomes.
+		dist[shifted] += prob
+	}
+	return dist
+}
+
 // mapValueScalar maps score-delta outcomes to scalar outcomes while preserving
 // their probabilities. Outcomes that map to the same scalar value are merged.
 func (d scoreDeltaProbDist) mapValueScalar(mapper func(scoreDelta) float64) scalarProbDist {
+	return d.mapValuesScalar(d.values(), mapper)
+}
+
+// mapValuesScalar is mapValueScalar over values, the result of d.values(), for
+// callers that map one distribution several times and sort it once.
+func (d scoreDeltaProbDist) mapValuesScalar(values []scoreDelta, mapper func(scoreDelta) float64) scalarProbDist {
 	dist := make(scalarProbDist, len(d))
-	for value, prob := range d {
-		dist[mapper(value)] += prob
+	for _, value := range values {
+		dist[mapper(value)] += d[value]
 	}
 	return newScalarProbDist(dist)
 }
@@ -82,13 +119,14 @@ func (d scoreDeltaProbDist) mapValueScalar(mapper func(scoreDelta) float64) scal
 // assuming the scalar and score-delta random variables are independent.
 func multiplyScalarScoreDeltaProbDists(lhs scalarProbDist, rhs scoreDeltaProbDist) scoreDeltaProbDist {
 	dist := make(scoreDeltaProbDist, len(lhs)*len(rhs))
-	for lhsValue, lhsProb := range lhs {
-		for rhsValue, rhsProb := range rhs {
+	rhsValues := rhs.values()
+	for _, lhsValue := range lhs.values() {
+		for _, rhsValue := range rhsValues {
 			var value scoreDelta
 			for i := range value {
 				value[i] = lhsValue * rhsValue[i]
 			}
-			dist[value] += lhsProb * rhsProb
+			dist[value] += lhs[lhsValue] * rhs[rhsValue]
 		}
 	}
 	return newScoreDeltaProbDist(dist)
@@ -101,6 +139,7 @@ func multiplyScalarScoreDeltaProbDists(lhs scalarProbDist, rhs scoreDeltaProbDis
 func mergeScoreDeltaProbDists(items []weightedScoreDeltaProbDist) scoreDeltaProbDist {
 	dist := make(scoreDeltaProbDist)
 	for _, item := range items {
+		// Each item adds one term to a value, in the order of items.
 		for value, prob := range item.dist {
 			dist[value] += prob * item.prob
 		}
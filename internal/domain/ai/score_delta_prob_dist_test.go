@@ -53,6 +53,20 @@ func TestScoreDeltaProbDist_replace_DropsNonPositiveProbability(t *testing.T) {
 	assertScoreDeltaProbDist(t, got, want)
 }
 
+func TestScoreDeltaProbDist_shift(t *testing.T) {
+	dist := newScoreDeltaProbDist(map[scoreDelta]float64{
+		{}:                  0.8,
+		{1000, -1000, 0, 0}: 0.2,
+	})
+
+	got := dist.shift(scoreDelta{-1000, 0, 0, 0})
+	want := scoreDeltaProbDist{
+		{-1000, 0, 0, 0}: 0.8,
+		{0, -1000, 0, 0}: 0.2,
+	}
+	assertScoreDeltaProbDist(t, got, want)
+}
+
 func TestMultiplyScalarScoreDeltaProbDists(t *testing.T) {
 	lhs := newScalarProbDist(map[float64]float64{2: 0.25, 3: 0.75})
 	rhs := newScoreDeltaProbDist(map[scoreDelta]float64{
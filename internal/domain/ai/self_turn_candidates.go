@@ -15,7 +15,7 @@ func buildSelfTurnCandidates(actions []action.Action, self player.PlayerViewer)
 	if err != nil {
 		return nil, fmt.Errorf("cannot build self-turn candidates: %w", err)
 	}
-	turnShanten, turnGoals := service.AnalyzeShanten(h, service.AllowedExtraTiles(1))
+	turnShanten, turnGoals := service.AnalyzeShanten(h, service.AllowedExtraTiles(1), service.Engine(service.TableEngine))
 
 	// Self-turn candidates cover discard and riichi+discard only.
 	// Match original Manue: concealed kan and promoted kan are intentionally not
@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai/tenpaifeature"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+// YamitenTenpaiEstimator looks up the tenpai rate of a player without riichi
+// by the remaining turns and the number of melds. It is the default
+// TenpaiEstimator of ManueAgent.
+type YamitenTenpaiEstimator struct {
+	stats TenpaiEstimatorStats
+}
+
+func NewYamitenTenpaiEstimator(stats TenpaiEstimatorStats) *YamitenTenpaiEstimator {
+	return &YamitenTenpaiEstimator{stats: stats}
+}
+
+func (e *YamitenTenpaiEstimator) EstimateTenpaiProb(state round.StateViewer, target seat.Seat) (float64, error) {
+	p := state.Player(target)
+	riichi := p.RiichiState() != player.NotRiichi
+	return tenpaiProb(e.stats, riichi, stateNumRemainTurns(state), len(p.Melds())), nil
+}
+
+// FeatureTenpaiEstimator estimates the tenpai probability of a player
+// without riichi by logistic regression on tenpai features, as trained by
+// tools/estimate_tenpai.
+type FeatureTenpaiEstimator struct {
+	bias     float64
+	features []tenpaifeature.Feature
+	weights  []float64
+}
+
+// NewFeatureTenpaiEstimator builds an estimator from the weights of the
+// features keyed by name. It fails on a feature the bot cannot evaluate.
+func NewFeatureTenpaiEstimator(bias float64, weights map[string]float64) (*FeatureTenpaiEstimator, error) {
+	// Sorting keeps the sum, and thus the decisions, independent of map order.
+	names := slices.Sorted(maps.Keys(weights))
+	features, err := tenpaifeature.ParseAll(names)
+	if err != nil {
+		return nil, err
+	}
+	e := &FeatureTenpaiEstimator{bias: bias, features: features, weights: make([]float64, len(names))}
+	for i, name := range names {
+		w := weights[name]
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("weight of tenpai feature %q is not finite", name)
+		}
+		e.weights[i] = w
+	}
+	return e, nil
+}
+
+func (e *FeatureTenpaiEstimator) EstimateTenpaiProb(state round.StateViewer, target seat.Seat) (float64, error) {
+	if e == nil {
+		return 0, fmt.Errorf("cannot estimate tenpai probability: model is nil")
+	}
+	if state.Player(target).RiichiState() != player.NotRiichi {
+		return 1.0, nil
+	}
+	scene := tenpaifeature.NewScene(state, target)
+	z := e.bias
+	for i, f := range e.features {
+		z += e.weights[i] * f.Eval(&scene)
+	}
+	return 1.0 / (1.0 + math.Exp(-z)), nil
+}
@@ -0,0 +1,71 @@
+package ai
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+func TestYamitenTenpaiEstimator(t *testing.T) {
+	stats := stubManueStats{yamitenCounts: map[string]yamitenCount{"10,0": {total: 10, tenpai: 2}}}
+	state := stubStateWithSelf(stubPlayerViewer{})
+	state.players[1] = stubPlayerViewer{riichiState: player.NotRiichi}
+	state.players[2] = stubPlayerViewer{riichiState: player.RiichiAccepted}
+	state.numLeftTiles = 10 * common.NumPlayers
+	estimator := NewYamitenTenpaiEstimator(stats)
+
+	for target, want := range map[int]float64{1: 0.2, 2: 1} {
+		got, err := estimator.EstimateTenpaiProb(state, seat.MustSeat(target))
+		if err != nil {
+			t.Fatalf("EstimateTenpaiProb(%d) failed: %v", target, err)
+		}
+		if !almostEqual(got, want) {
+			t.Errorf("EstimateTenpaiProb(%d) = %v, want %v", target, got, want)
+		}
+	}
+}
+
+func TestFeatureTenpaiEstimator(t *testing.T) {
+	estimator, err := NewFeatureTenpaiEstimator(-2, map[string]float64{"remain_turns": -0.1, "num_melds": 0.5})
+	if err != nil {
+		t.Fatalf("NewFeatureTenpaiEstimator() failed: %v", err)
+	}
+	state := stubStateWithSelf(stubPlayerViewer{})
+	state.players[1] = stubPlayerViewer{riichiState: player.NotRiichi}
+	state.players[2] = stubPlayerViewer{riichiState: player.RiichiAccepted}
+	state.numLeftTiles = 10 * common.NumPlayers
+
+	got, err := estimator.EstimateTenpaiProb(state, seat.MustSeat(1))
+	if err != nil {
+		t.Fatalf("EstimateTenpaiProb() failed: %v", err)
+	}
+	if want := 1 / (1 + math.Exp(3)); !almostEqual(got, want) {
+		t.Errorf("EstimateTenpaiProb() = %v, want %v", got, want)
+	}
+	if got, _ := estimator.EstimateTenpaiProb(state, seat.MustSeat(2)); got != 1 {
+		t.Errorf("EstimateTenpaiProb() in riichi = %v, want 1", got)
+	}
+}
+
+func TestNewFeatureTenpaiEstimator_ReturnsErrorWithInvalidWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights map[string]float64
+		wantErr string
+	}{
+		{name: "unknown feature", weights: map[string]float64{"unknown": 1}, wantErr: `"unknown"`},
+		{name: "NaN weight", weights: map[string]float64{"num_melds": math.NaN()}, wantErr: "not finite"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFeatureTenpaiEstimator(0, tt.weights)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewFeatureTenpaiEstimator() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package tenpaifeature is the registry of the tenpai features: the signals
+// that a player without riichi may be tenpai. The feature-based tenpai
+// estimator weighs them at play time and estimate_tenpai extracts them for
+// training, so both share the definitions here.
+package tenpaifeature
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+const (
+	// lateDiscardsFrom is the index of the first discard counted as late,
+	// the seventh discard: the end of the first row of the river.
+	lateDiscardsFrom = 6
+	// numRecentDiscards is the number of last discards counted as recent.
+	numRecentDiscards = 3
+)
+
+type evalFunc func(s *Scene) float64
+
+// Feature is a parsed tenpai feature.
+type Feature struct {
+	name string
+	eval evalFunc
+}
+
+func (f Feature) Name() string {
+	return f.name
+}
+
+// Eval returns the value of the feature for s.
+func (f Feature) Eval(s *Scene) float64 {
+	return f.eval(s)
+}
+
+var features = map[string]evalFunc{
+	"remain_turns": func(s *Scene) float64 {
+		return float64(s.remainTurns)
+	},
+	"num_melds": func(s *Scene) float64 {
+		return float64(len(s.melds))
+	},
+	// Honors are usually discarded first; one cut late was kept for a
+	// reason and is let go as the hand becomes ready.
+	"late_honor_discards": func(s *Scene) float64 {
+		return float64(s.countLateDiscards(func(i int) bool {
+			return s.discardedTiles[i].IsHonors()
+		}))
+	},
+	"late_middle_tedashi": func(s *Scene) float64 {
+		return float64(s.countLateDiscards(func(i int) bool {
+			t := s.discardedTiles[i]
+			return s.isTedashi(i) && t.IsSuits() && 3 <= t.Number() && t.Number() <= 7
+		}))
+	},
+	"recent_tedashi": func(s *Scene) float64 {
+		return float64(s.countRecentTedashi())
+	},
+	// The turn of a call is not kept, so recent tedashi of an open hand
+	// stands in for tedashi after the calls.
+	"open_recent_tedashi": func(s *Scene) float64 {
+		if len(s.melds) == 0 {
+			return 0
+		}
+		return float64(s.countRecentTedashi())
+	},
+	"tsumogiri_streak": func(s *Scene) float64 {
+		n := 0
+		for i := len(s.discardedTiles) - 1; i >= 0 && !s.isTedashi(i); i-- {
+			n++
+		}
+		return float64(n)
+	},
+	"dora_pons": func(s *Scene) float64 {
+		n := 0
+		for _, t := range s.tripletTiles() {
+			if tile.Tiles(s.doras).ContainsSameSymbol(t) {
+				n++
+			}
+		}
+		return float64(n)
+	},
+	"yakuhai_pons": func(s *Scene) float64 {
+		n := 0
+		for _, t := range s.tripletTiles() {
+			if t.IsDragon() || windTile(s.roundWind).HasSameSymbol(t) || windTile(s.targetWind).HasSameSymbol(t) {
+				n++
+			}
+		}
+		return float64(n)
+	},
+}
+
+func (s *Scene) countLateDiscards(pred func(i int) bool) int {
+	n := 0
+	for i := lateDiscardsFrom; i < len(s.discardedTiles); i++ {
+		if pred(i) {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Scene) countRecentTedashi() int {
+	n := 0
+	for i := max(len(s.discardedTiles)-numRecentDiscards, 0); i < len(s.discardedTiles); i++ {
+		if s.isTedashi(i) {
+			n++
+		}
+	}
+	return n
+}
+
+func Parse(name string) (Feature, error) {
+	eval, ok := features[name]
+	if !ok {
+		return Feature{}, fmt.Errorf("unsupported tenpai feature %q", name)
+	}
+	return Feature{name: name, eval: eval}, nil
+}
+
+// ParseAll parses names in order.
+func ParseAll(names []string) ([]Feature, error) {
+	parsed := make([]Feature, len(names))
+	for i, name := range names {
+		feature, err := Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = feature
+	}
+	return parsed, nil
+}
+
+var defaultNames = []string{
+	"remain_turns",
+	"num_melds",
+	"late_honor_discards",
+	"late_middle_tedashi",
+	"recent_tedashi",
+	"open_recent_tedashi",
+	"tsumogiri_streak",
+	"dora_pons",
+	"yakuhai_pons",
+}
+
+// DefaultNames returns the features that estimate_tenpai trains on by
+// default.
+func DefaultNames() []string {
+	return slices.Clone(defaultNames)
+}
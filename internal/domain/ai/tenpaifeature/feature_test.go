@@ -0,0 +1,101 @@
+package tenpaifeature
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+func evaluate(t *testing.T, s Scene, name string) float64 {
+	t.Helper()
+	f, err := Parse(name)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", name, err)
+	}
+	return f.Eval(&s)
+}
+
+func tiles(codes ...string) tile.Tiles {
+	ts := make(tile.Tiles, len(codes))
+	for i, code := range codes {
+		ts[i] = tile.MustTileFromCode(code)
+	}
+	return ts
+}
+
+func pon(code string) *meld.Pon {
+	t := tile.MustTileFromCode(code)
+	return meld.MustPon(t, [2]tile.Tile{t, t}, seat.MustSeat(1))
+}
+
+func TestParseRejectsUnknownFeature(t *testing.T) {
+	_, err := Parse("unknown_feature")
+	if err == nil {
+		t.Fatal("Parse() succeeded unexpectedly")
+	}
+	if !strings.Contains(err.Error(), "unknown_feature") {
+		t.Errorf("Parse() error = %v, want feature name", err)
+	}
+}
+
+func TestDefaultNamesAreParsable(t *testing.T) {
+	if _, err := ParseAll(DefaultNames()); err != nil {
+		t.Errorf("ParseAll(DefaultNames()) failed: %v", err)
+	}
+}
+
+func TestFeatures(t *testing.T) {
+	// The 7th and 9th discards are late honors, the 8th a late middle tile
+	// from the hand, and the last two are tsumogiri.
+	discards := tiles("1m", "9p", "N", "2s", "8m", "1p", "E", "5s", "C", "9s", "7p")
+	tsumogiris := []bool{false, false, false, false, false, false, false, false, false, true, true}
+	s := NewSceneFromParams(SceneParams{
+		RemainTurns:    8,
+		Melds:          []meld.Meld{pon("6m"), pon("P")},
+		DiscardedTiles: discards,
+		Tsumogiris:     tsumogiris,
+		Doras:          tiles("6m"),
+		RoundWind:      wind.East,
+		TargetWind:     wind.South,
+	})
+
+	tests := []struct {
+		name string
+		want float64
+	}{
+		{name: "remain_turns", want: 8},
+		{name: "num_melds", want: 2},
+		{name: "late_honor_discards", want: 2},
+		{name: "late_middle_tedashi", want: 1},
+		{name: "recent_tedashi", want: 1},
+		{name: "open_recent_tedashi", want: 1},
+		{name: "tsumogiri_streak", want: 2},
+		{name: "dora_pons", want: 1},
+		{name: "yakuhai_pons", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluate(t, s, tt.name); got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeaturesWithoutTsumogiriFlags(t *testing.T) {
+	s := NewSceneFromParams(SceneParams{DiscardedTiles: tiles("1m", "9p", "N")})
+
+	if got := evaluate(t, s, "recent_tedashi"); got != 3 {
+		t.Errorf("recent_tedashi = %v, want every discard from the hand", got)
+	}
+	if got := evaluate(t, s, "open_recent_tedashi"); got != 0 {
+		t.Errorf("open_recent_tedashi = %v, want 0 for a closed hand", got)
+	}
+	if got := evaluate(t, s, "tsumogiri_streak"); got != 0 {
+		t.Errorf("tsumogiri_streak = %v, want 0", got)
+	}
+}
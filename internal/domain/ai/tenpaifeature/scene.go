@@ -0,0 +1,96 @@
+package tenpaifeature
+
+import (
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+// Scene is what the other players see of the target when guessing whether
+// it is tenpai.
+type Scene struct {
+	remainTurns    int
+	melds          []meld.Meld
+	discardedTiles []tile.Tile
+	tsumogiris     []bool
+	doras          []tile.Tile
+	roundWind      wind.Wind
+	targetWind     wind.Wind
+}
+
+// NewScene builds the scene of target.
+func NewScene(state round.StateViewer, target seat.Seat) Scene {
+	p := state.Player(target)
+	return Scene{
+		remainTurns:    state.NumLeftTiles() / common.NumPlayers,
+		melds:          p.Melds(),
+		discardedTiles: p.DiscardedTiles(),
+		tsumogiris:     p.DiscardTsumogiris(),
+		doras:          state.Doras(),
+		roundWind:      state.RoundWind(),
+		targetWind:     state.SeatWind(target),
+	}
+}
+
+// SceneParams are the parts of a scene given directly. Tsumogiris parallels
+// DiscardedTiles; a nil Tsumogiris treats every discard as a tile from the
+// hand.
+type SceneParams struct {
+	RemainTurns    int
+	Melds          []meld.Meld
+	DiscardedTiles tile.Tiles
+	Tsumogiris     []bool
+	Doras          tile.Tiles
+	RoundWind      wind.Wind
+	TargetWind     wind.Wind
+}
+
+func NewSceneFromParams(params SceneParams) Scene {
+	tsumogiris := make([]bool, len(params.DiscardedTiles))
+	copy(tsumogiris, params.Tsumogiris)
+	return Scene{
+		remainTurns:    params.RemainTurns,
+		melds:          slices.Clone(params.Melds),
+		discardedTiles: slices.Clone(params.DiscardedTiles),
+		tsumogiris:     tsumogiris,
+		doras:          slices.Clone(params.Doras),
+		roundWind:      params.RoundWind,
+		targetWind:     params.TargetWind,
+	}
+}
+
+// isTedashi reports whether the i-th discard came from the hand. A discard
+// without a recorded flag counts as one.
+func (s *Scene) isTedashi(i int) bool {
+	return i >= len(s.tsumogiris) || !s.tsumogiris[i]
+}
+
+// tripletTiles returns a tile of each pon and kan.
+func (s *Scene) tripletTiles() []tile.Tile {
+	var tiles []tile.Tile
+	for _, m := range s.melds {
+		switch m.(type) {
+		case *meld.Pon, *meld.CalledKan, *meld.ConcealedKan, *meld.PromotedKan:
+			tiles = append(tiles, m.ToTiles()[0])
+		}
+	}
+	return tiles
+}
+
+func windTile(w wind.Wind) tile.Tile {
+	switch w {
+	case wind.East:
+		return tile.MustTileFromCode("E")
+	case wind.South:
+		return tile.MustTileFromCode("S")
+	case wind.West:
+		return tile.MustTileFromCode("W")
+	default:
+		return tile.MustTileFromCode("N")
+	}
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -35,6 +36,10 @@ func formatGoalCountTrace(summary candidateEvaluationSummary) string {
 	for _, count := range summary.winEstimateGoalCounts {
 		fmt.Fprintf(&b, "goals %d\n", count)
 	}
+	if bonus := summary.bonus; bonus != (roundBonus{}) {
+		fmt.Fprintf(&b, "roundBonus honba %d (+%d) kyotaku %d (+%d)\n",
+			bonus.honba, bonus.honba*honbaWinPoints, bonus.riichiSticks, bonus.riichiSticks*riichiStickPoints)
+	}
 	return b.String()
 }
 
@@ -65,36 +70,58 @@ func formatCandidateTrace(candidates []evaluatedActionCandidate) string {
 		return ""
 	}
 
+	// planHojuProb appears only when a candidate has a defense plan, so
+	// that the trace stays the original's otherwise.
+	planned := slices.ContainsFunc(candidates, func(c evaluatedActionCandidate) bool {
+		return c.score.planned
+	})
 	rows := make([][]string, n+1)
 	rows[0] = []string{
 		"action",
 		"avgRank",
 		"expPt",
 		"hojuProb",
+	}
+	if planned {
+		rows[0] = append(rows[0], "planHojuProb")
+	}
+	rows[0] = append(rows[0],
 		"myHoraProb",
 		"ryukyokuProb",
 		"otherHoraProb",
 		"avgHoraPt",
 		"ryukyokuAvgPt",
 		"shanten",
-	}
+	)
 	for i, candidate := range sortedCandidates(candidates, true) {
-		rows[i+1] = []string{
+		row := []string{
 			candidate.candidate.traceKey,
 			strconv.FormatFloat(candidate.score.averageRank, 'f', 4, 64),
 			strconv.FormatFloat(candidate.score.expectedPoints, 'f', 0, 64),
 			strconv.FormatFloat(candidate.score.dealInProb, 'f', 3, 64),
+		}
+		if planned {
+			row = append(row, formatPlannedDealInTraceValue(candidate.score))
+		}
+		rows[i+1] = append(row,
 			strconv.FormatFloat(candidate.score.winProb, 'f', 3, 64),
 			strconv.FormatFloat(candidate.score.exhaustiveDrawProb, 'f', 3, 64),
 			strconv.FormatFloat(candidate.score.otherWinProb, 'f', 3, 64),
 			strconv.FormatFloat(candidate.score.averageWinPoints, 'f', 0, 64),
 			strconv.FormatFloat(candidate.score.exhaustiveDrawAveragePoints, 'f', 0, 64),
 			formatShantenTraceValue(candidate.candidate.shanten),
-		}
+		)
 	}
 	return formatTraceTable(rows)
 }
 
+func formatPlannedDealInTraceValue(score candidateScore) string {
+	if !score.planned {
+		return "-"
+	}
+	return strconv.FormatFloat(score.plannedDealInProb, 'f', 3, 64)
+}
+
 func formatShantenTraceValue(shanten int) string {
 	if shanten == service.InfinityShanten {
 		return "Inf"
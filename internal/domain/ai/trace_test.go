@@ -54,6 +54,39 @@ func TestFormatCandidateTrace(t *testing.T) {
 	}
 }
 
+func TestFormatCandidateTrace_ShowsPlannedDealIn(t *testing.T) {
+	self := seat.MustSeat(0)
+	fold, err := action.NewDiscard(self, tile.MustTileFromCode("E"), false)
+	if err != nil {
+		t.Fatalf("NewDiscard() failed: %v", err)
+	}
+	push, err := action.NewDiscard(self, tile.MustTileFromCode("5m"), false)
+	if err != nil {
+		t.Fatalf("NewDiscard() failed: %v", err)
+	}
+
+	got := formatCandidateTrace([]evaluatedActionCandidate{
+		evaluatedCandidateForTest(actionCandidate{
+			traceKey:    "-1.E",
+			action:      fold,
+			discardTile: fold.Tile(),
+			shanten:     service.InfinityShanten,
+		}, candidateScore{averageRank: 2.5, plannedDealInProb: 0.25, planned: true}),
+		evaluatedCandidateForTest(actionCandidate{
+			traceKey:    "-1.5m",
+			action:      push,
+			discardTile: push.Tile(),
+			shanten:     1,
+		}, candidateScore{averageRank: 2.75, dealInProb: 0.125}),
+	})
+	want := "| action | avgRank | expPt | hojuProb | planHojuProb | myHoraProb | ryukyokuProb | otherHoraProb | avgHoraPt | ryukyokuAvgPt | shanten | \n" +
+		"|   -1.E |  2.5000 |     0 |    0.000 |        0.250 |      0.000 |        0.000 |         0.000 |         0 |             0 |     Inf | \n" +
+		"|  -1.5m |  2.7500 |     0 |    0.125 |            - |      0.000 |        0.000 |         0.000 |         0 |             0 |       1 | \n"
+	if got != want {
+		t.Errorf("formatCandidateTrace() =\n%q\nwant\n%q", got, want)
+	}
+}
+
 func TestFormatCandidateTrace_FormatsInfinityShanten(t *testing.T) {
 	self := seat.MustSeat(0)
 	discard, err := action.NewDiscard(self, tile.MustTileFromCode("5m"), false)
@@ -139,3 +172,15 @@ func TestFormatDecisionTrace_AppendsDecidedKey(t *testing.T) {
 		t.Errorf("formatDecisionTrace() =\n%q\nwant\n%q", got, want)
 	}
 }
+
+func TestFormatDecisionTrace_ShowsRoundBonus(t *testing.T) {
+	got := formatDecisionTrace("", nil, candidateEvaluationSummary{
+		winEstimateGoalCounts: []int{7},
+		bonus:                 roundBonus{honba: 3, riichiSticks: 3},
+	})
+	want := "goals 7\n" +
+		"roundBonus honba 3 (+900) kyotaku 3 (+3000)\n"
+	if got != want {
+		t.Errorf("formatDecisionTrace() =\n%q\nwant\n%q", got, want)
+	}
+}
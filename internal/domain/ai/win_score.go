@@ -50,19 +50,21 @@ func winScoreFactor(actorID int, targetID int, dealerID int) scoreDelta {
 // treated as uniformly distributed among the other players.
 func winScoreFactorDist(actorID int, dealerID int, selfDrawProb float64) scoreDeltaProbDist {
 	dist := make(scoreDeltaProbDist, common.NumPlayers)
-	ronTargetProb := (1.0 - selfDrawProb) / float64(common.NumPlayers-1)
 	for targetID := range common.NumPlayers {
-		var prob float64
-		if targetID == actorID {
-			prob = selfDrawProb
-		} else {
-			prob = ronTargetProb
-		}
-		dist[winScoreFactor(actorID, targetID, dealerID)] = prob
+		dist[winScoreFactor(actorID, targetID, dealerID)] = winTargetProb(actorID, targetID, selfDrawProb)
 	}
 	return newScoreDeltaProbDist(dist)
 }
 
+// winTargetProb returns the probability that targetID is the target of a win
+// by actorID.
+func winTargetProb(actorID int, targetID int, selfDrawProb float64) float64 {
+	if targetID == actorID {
+		return selfDrawProb
+	}
+	return (1.0 - selfDrawProb) / float64(common.NumPlayers-1)
+}
+
 func winPointsDist(pointFreqs map[string]int) scalarProbDist {
 	totalFreqsFloat := float64(pointFreqs["total"])
 	dist := make(map[float64]float64, len(pointFreqs)-1)
@@ -76,20 +78,37 @@ func winPointsDist(pointFreqs map[string]int) scalarProbDist {
 	return newScalarProbDist(dist)
 }
 
-func randomWinScoreDeltaDist(actorID int, dealerID int, stats WinScoreStats) scoreDeltaProbDist {
+func randomWinScoreDeltaDist(
+	actorID int,
+	dealerID int,
+	stats WinScoreStats,
+	profile OpponentProfile,
+	bonus roundBonus,
+) scoreDeltaProbDist {
 	pointFreqs := stats.NonDealerWinPointFreqs()
 	if actorID == dealerID {
 		pointFreqs = stats.DealerWinPointFreqs()
 	}
-	return multiplyScalarScoreDeltaProbDists(
-		winPointsDist(pointFreqs),
-		winScoreFactorDist(actorID, dealerID, float64(stats.NumSelfDrawWins())/float64(stats.NumWins())),
-	)
+	return winScoreDeltaDist(actorID, dealerID, stats, profile.winPointsDist(winPointsDist(pointFreqs)), bonus)
 }
 
-func winScoreDeltaDist(actorID int, dealerID int, stats WinScoreStats, pointsDist scalarProbDist) scoreDeltaProbDist {
-	return multiplyScalarScoreDeltaProbDists(
-		pointsDist,
-		winScoreFactorDist(actorID, dealerID, float64(stats.NumSelfDrawWins())/float64(stats.NumWins())),
-	)
+// winScoreDeltaDist returns the score changes of a win by actorID. The bonus
+// depends on who pays, so each target is expanded separately.
+func winScoreDeltaDist(
+	actorID int,
+	dealerID int,
+	stats WinScoreStats,
+	pointsDist scalarProbDist,
+	bonus roundBonus,
+) scoreDeltaProbDist {
+	selfDrawProb := float64(stats.NumSelfDrawWins()) / float64(stats.NumWins())
+	items := make([]weightedScoreDeltaProbDist, 0, common.NumPlayers)
+	for targetID := range common.NumPlayers {
+		factor := scoreDeltaProbDist{winScoreFactor(actorID, targetID, dealerID): 1.0}
+		items = append(items, weightedScoreDeltaProbDist{
+			dist: multiplyScalarScoreDeltaProbDists(pointsDist, factor).shift(bonus.winDelta(actorID, targetID)),
+			prob: winTargetProb(actorID, targetID, selfDrawProb),
+		})
+	}
+	return mergeScoreDeltaProbDists(items)
 }
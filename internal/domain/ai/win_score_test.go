@@ -66,7 +66,7 @@ func TestRandomWinScoreDeltaDist_SelectsDealerPointFreqs(t *testing.T) {
 			"2000":  1,
 			"total": 1,
 		},
-	})
+	}, OpponentProfile{}, roundBonus{})
 
 	want := scoreDeltaProbDist{
 		{2000, -2000.0 / 3.0, -2000.0 / 3.0, -2000.0 / 3.0}: 0.4,
@@ -89,7 +89,7 @@ func TestRandomWinScoreDeltaDist_SelectsNonDealerPointFreqs(t *testing.T) {
 			"2000":  1,
 			"total": 1,
 		},
-	})
+	}, OpponentProfile{}, roundBonus{})
 
 	want := scoreDeltaProbDist{
 		{-500, 1000, -250, -250}: 0.4,
@@ -107,7 +107,7 @@ func TestWinScoreDeltaDist(t *testing.T) {
 	}, scalarProbDist{
 		1000: 0.25,
 		2000: 0.75,
-	})
+	}, roundBonus{})
 
 	want := scoreDeltaProbDist{
 		{-500, 1000, -250, -250}:  0.10,
@@ -121,3 +121,18 @@ func TestWinScoreDeltaDist(t *testing.T) {
 	}
 	assertScoreDeltaProbDist(t, got, want)
 }
+
+func TestWinScoreDeltaDist_AddsRoundBonus(t *testing.T) {
+	got := winScoreDeltaDist(1, 0, stubManueStats{
+		numWins:         10,
+		numSelfDrawWins: 4,
+	}, scalarProbDist{1000: 1}, roundBonus{honba: 3, riichiSticks: 2})
+
+	want := scoreDeltaProbDist{
+		{-800, 3900, -550, -550}: 0.4,
+		{-1900, 3900, 0, 0}:      0.2,
+		{0, 3900, -1900, 0}:      0.2,
+		{0, 3900, 0, -1900}:      0.2,
+	}
+	assertScoreDeltaProbDist(t, got, want)
+}
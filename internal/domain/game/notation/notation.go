@@ -0,0 +1,307 @@
+// Package notation parses and formats tiles, hands, and melds in the compact
+// mpsz notation of Tenhou and most mahjong tools, such as 406m123p55z.
+//
+// Digits are followed by their suit: m, p, and s for the suits with 0 for a
+// red five, and z for honors from 1z (E) to 7z (C). An unknown tile is a
+// lone ?. A meld is written in brackets after the concealed tiles:
+//
+//   - [312m@3] is an open meld whose first tile is the one taken from the
+//     player at seat 3. Three tiles in a sequence are a chii, three of a kind
+//     a pon, and four of a kind a called kan. @N is required.
+//   - [555+0p@2] is a promoted kan: a pon of 555p taken from seat 2 with the
+//     red five added.
+//   - (5555z) is a concealed kan. (555z) is short for it, and a short kan of
+//     fives takes a red five as the fourth tile.
+package notation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+var honorCodes = [7]string{"E", "S", "W", "N", "P", "F", "C"}
+
+// newTile returns the tile of a digit and a suit.
+func newTile(digit byte, suit byte) (tile.Tile, error) {
+	if digit < '0' || '9' < digit {
+		return tile.Tile{}, fmt.Errorf("invalid tile: %c%c", digit, suit)
+	}
+	n := int(digit - '0')
+	switch suit {
+	case 'm', 'p', 's':
+		if n == 0 {
+			return tile.NewTileFromCode(fmt.Sprintf("5%cr", suit))
+		}
+		return tile.NewTileFromCode(fmt.Sprintf("%d%c", n, suit))
+	case 'z':
+		if n < 1 || 7 < n {
+			return tile.Tile{}, fmt.Errorf("invalid tile: %dz", n)
+		}
+		return tile.NewTileFromCode(honorCodes[n-1])
+	default:
+		return tile.Tile{}, fmt.Errorf("invalid suit: %c", suit)
+	}
+}
+
+// digitAndSuit returns how t is written. Unknown tiles have no suit.
+func digitAndSuit(t tile.Tile) (byte, byte) {
+	switch {
+	case t.IsUnknown():
+		return '?', 0
+	case t.IsHonors():
+		return byte('0' + t.Number()), 'z'
+	case t.IsRed():
+		return '0', byte(t.Color())
+	default:
+		return byte('0' + t.Number()), byte(t.Color())
+	}
+}
+
+// ParseTiles parses tiles without melds, such as 406m123p55z or 19m???. The
+// tiles keep the order they are written in.
+func ParseTiles(s string) (tile.Tiles, error) {
+	var tiles tile.Tiles
+	var digits []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case '0' <= c && c <= '9':
+			digits = append(digits, c)
+		case c == '?':
+			if len(digits) > 0 {
+				return nil, fmt.Errorf("digits without a suit before ? in %q", s)
+			}
+			tiles = append(tiles, tile.MustTileFromCode("?"))
+		default:
+			if len(digits) == 0 {
+				return nil, fmt.Errorf("suit %c without digits in %q", c, s)
+			}
+			for _, d := range digits {
+				t, err := newTile(d, c)
+				if err != nil {
+					return nil, fmt.Errorf("%w in %q", err, s)
+				}
+				tiles = append(tiles, t)
+			}
+			digits = digits[:0]
+		}
+	}
+	if len(digits) > 0 {
+		return nil, fmt.Errorf("digits without a suit at the end of %q", s)
+	}
+	return tiles, nil
+}
+
+// MustParseTiles is ParseTiles for tests and fixtures. It panics on an
+// error.
+func MustParseTiles(s string) tile.Tiles {
+	tiles, err := ParseTiles(s)
+	if err != nil {
+		panic(err)
+	}
+	return tiles
+}
+
+// FormatTiles writes tiles in their order, with the suit after each run of
+// tiles of the same suit.
+func FormatTiles(tiles []tile.Tile) string {
+	var sb strings.Builder
+	var suit byte
+	for _, t := range tiles {
+		d, s := digitAndSuit(t)
+		if suit != 0 && s != suit {
+			sb.WriteByte(suit)
+		}
+		sb.WriteByte(d)
+		suit = s
+	}
+	if suit != 0 {
+		sb.WriteByte(suit)
+	}
+	return sb.String()
+}
+
+// ParseHand parses a concealed hand followed by its melds, such as
+// 123m456p789s1z[555z@2].
+func ParseHand(s string) (*hand.VisibleHand, []meld.Meld, error) {
+	concealed, meldStrs, err := splitMelds(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	tiles, err := ParseTiles(concealed)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := hand.NewVisibleHand(tiles)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid hand %q: %w", s, err)
+	}
+	var melds []meld.Meld
+	for _, m := range meldStrs {
+		parsed, err := ParseMeld(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		melds = append(melds, parsed)
+	}
+	return h, melds, nil
+}
+
+// MustParseHand is ParseHand for tests and fixtures. It panics on an error.
+func MustParseHand(s string) (*hand.VisibleHand, []meld.Meld) {
+	h, melds, err := ParseHand(s)
+	if err != nil {
+		panic(err)
+	}
+	return h, melds
+}
+
+// splitMelds separates the concealed tiles of a hand from its melds.
+func splitMelds(s string) (string, []string, error) {
+	i := strings.IndexAny(s, "[(")
+	if i < 0 {
+		if j := strings.IndexAny(s, "])"); j >= 0 {
+			return "", nil, fmt.Errorf("unopened %c in %q", s[j], s)
+		}
+		return s, nil, nil
+	}
+	concealed, rest := s[:i], s[i:]
+	var melds []string
+	for rest != "" {
+		closing := "]"
+		if rest[0] == '(' {
+			closing = ")"
+		} else if rest[0] != '[' {
+			return "", nil, fmt.Errorf("tiles after melds in %q", s)
+		}
+		end := strings.Index(rest, closing)
+		if end < 0 {
+			return "", nil, fmt.Errorf("unclosed %c in %q", rest[0], s)
+		}
+		melds = append(melds, rest[:end+1])
+		rest = rest[end+1:]
+	}
+	return concealed, melds, nil
+}
+
+// FormatHand writes the concealed tiles sorted, then the melds in order.
+func FormatHand(h *hand.VisibleHand, melds []meld.Meld) string {
+	tiles := tile.Tiles(h.ToTiles())
+	tiles.Sort()
+	var sb strings.Builder
+	sb.WriteString(FormatTiles(tiles))
+	for _, m := range melds {
+		sb.WriteString(FormatMeld(m))
+	}
+	return sb.String()
+}
+
+// ParseMeld parses a meld in brackets or parentheses.
+func ParseMeld(s string) (meld.Meld, error) {
+	if len(s) < 2 {
+		return nil, fmt.Errorf("invalid meld %q", s)
+	}
+	switch {
+	case s[0] == '(' && s[len(s)-1] == ')':
+		return parseConcealedKan(s)
+	case s[0] == '[' && s[len(s)-1] == ']':
+		return parseOpenMeld(s)
+	default:
+		return nil, fmt.Errorf("invalid meld %q", s)
+	}
+}
+
+func parseConcealedKan(s string) (meld.Meld, error) {
+	tiles, err := ParseTiles(s[1 : len(s)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(tiles) == 3 && tiles.CountSameSymbol(tiles[0]) == 3 {
+		base := tiles[0].RemoveRed()
+		tiles = tile.Tiles{base, base, base, base}
+		if base.IsSuits() && base.Number() == 5 {
+			tiles[3] = base.AddRed()
+		}
+	}
+	if len(tiles) != 4 {
+		return nil, fmt.Errorf("invalid concealed kan %q: want 4 tiles", s)
+	}
+	k, err := meld.NewConcealedKan([4]tile.Tile(tiles))
+	if err != nil {
+		return nil, fmt.Errorf("invalid concealed kan %q: %w", s, err)
+	}
+	return k, nil
+}
+
+func parseOpenMeld(s string) (meld.Meld, error) {
+	body := s[1 : len(s)-1]
+	i := strings.IndexByte(body, '@')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid meld %q: want the target seat as @N", s)
+	}
+	if len(body) != i+2 {
+		return nil, fmt.Errorf("invalid meld %q: want one digit after @", s)
+	}
+	target, err := seat.NewSeat(int(body[i+1]) - '0')
+	if err != nil {
+		return nil, fmt.Errorf("invalid meld %q: %w", s, err)
+	}
+	body = body[:i]
+	var added *tile.Tile
+	if i := strings.IndexByte(body, '+'); i >= 0 {
+		if len(body) != i+3 {
+			return nil, fmt.Errorf("invalid meld %q: want one tile after +", s)
+		}
+		t, err := newTile(body[i+1], body[len(body)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid meld %q: %w", s, err)
+		}
+		added = &t
+		body = body[:i] + body[len(body)-1:]
+	}
+	tiles, err := ParseTiles(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var m meld.Meld
+	switch {
+	case added != nil && len(tiles) == 3:
+		m, err = meld.NewPromotedKan(tiles[0], [2]tile.Tile(tiles[1:]), *added, target)
+	case added != nil:
+		err = fmt.Errorf("want 3 tiles before +")
+	case len(tiles) == 4:
+		m, err = meld.NewCalledKan(tiles[0], [3]tile.Tile(tiles[1:]), target)
+	case len(tiles) == 3 && tiles.CountSameSymbol(tiles[0]) == 3:
+		m, err = meld.NewPon(tiles[0], [2]tile.Tile(tiles[1:]), target)
+	case len(tiles) == 3:
+		m, err = meld.NewChii(tiles[0], [2]tile.Tile(tiles[1:]), target)
+	default:
+		err = fmt.Errorf("want 3 or 4 tiles")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid meld %q: %w", s, err)
+	}
+	return m, nil
+}
+
+// FormatMeld writes a meld with the taken tile first and the target seat.
+func FormatMeld(m meld.Meld) string {
+	switch m := m.(type) {
+	case *meld.ConcealedKan:
+		return "(" + FormatTiles(m.Consumed()) + ")"
+	case *meld.PromotedKan:
+		pon := FormatTiles(append([]tile.Tile{m.Taken()}, m.Consumed()...))
+		d, _ := digitAndSuit(m.Added())
+		return fmt.Sprintf("[%s+%c%s@%d]", pon[:len(pon)-1], d, pon[len(pon)-1:], m.Target().Index())
+	case meld.OpenMeld:
+		return fmt.Sprintf("[%s@%d]", FormatTiles(append([]tile.Tile{m.Taken()}, m.Consumed()...)), m.Target().Index())
+	default:
+		return FormatTiles(m.ToTiles())
+	}
+}
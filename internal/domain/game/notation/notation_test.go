@@ -0,0 +1,124 @@
+package notation_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/notation"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+func codes(tiles []tile.Tile) []string {
+	s := make([]string, len(tiles))
+	for i, t := range tiles {
+		s[i] = t.String()
+	}
+	return s
+}
+
+func TestParseTiles(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"406m123p55z", []string{"4m", "5mr", "6m", "1p", "2p", "3p", "P", "P"}},
+		{"1234567z", []string{"E", "S", "W", "N", "P", "F", "C"}},
+		{"0s9m?", []string{"5sr", "9m", "?"}},
+		{"??1p", []string{"?", "?", "1p"}},
+		{"", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := notation.ParseTiles(tt.s)
+		if err != nil {
+			t.Errorf("ParseTiles(%q) failed: %v", tt.s, err)
+			continue
+		}
+		if !slices.Equal(codes(got), tt.want) {
+			t.Errorf("ParseTiles(%q) = %v, want %v", tt.s, codes(got), tt.want)
+		}
+		if tt.s != "" && notation.FormatTiles(got) != tt.s {
+			t.Errorf("FormatTiles(%v) = %q, want %q", codes(got), notation.FormatTiles(got), tt.s)
+		}
+	}
+}
+
+func TestParseTiles_RejectsInvalidNotation(t *testing.T) {
+	for _, s := range []string{"123", "m", "8z", "0z", "12?m", "1x", "1m]"} {
+		if _, err := notation.ParseTiles(s); err == nil {
+			t.Errorf("ParseTiles(%q) succeeded unexpectedly", s)
+		}
+	}
+}
+
+func TestParseHand_RoundTrip(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"406m123p55z[312s@3]", "406m123p55z[312s@3]"},
+		{"11s(555z)", "11s(5555z)"},
+		{"11s(555m)", "11s(5550m)"},
+		{"11s[0555p@1][555+0s@2][777z@3]", "11s[0555p@1][555+0s@2][777z@3]"},
+	}
+	for _, tt := range tests {
+		h, melds, err := notation.ParseHand(tt.s)
+		if err != nil {
+			t.Errorf("ParseHand(%q) failed: %v", tt.s, err)
+			continue
+		}
+		got := notation.FormatHand(h, melds)
+		if got != tt.want {
+			t.Errorf("FormatHand(ParseHand(%q)) = %q, want %q", tt.s, got, tt.want)
+		}
+		if _, _, err := notation.ParseHand(got); err != nil {
+			t.Errorf("ParseHand(%q) failed: %v", got, err)
+		}
+	}
+}
+
+func TestParseHand_BuildsMelds(t *testing.T) {
+	h, melds := notation.MustParseHand("1m[312s@3][555+0s@2](1111z)")
+
+	if got := codes(h.ToTiles()); !slices.Equal(got, []string{"1m"}) {
+		t.Errorf("hand = %v, want [1m]", got)
+	}
+	if len(melds) != 3 {
+		t.Fatalf("melds = %v, want 3 melds", melds)
+	}
+	chii, ok := melds[0].(*meld.Chii)
+	if !ok || chii.Taken().String() != "3s" || chii.Target() != seat.MustSeat(3) {
+		t.Errorf("melds[0] = %v, want a chii of 3s from seat 3", melds[0])
+	}
+	kan, ok := melds[1].(*meld.PromotedKan)
+	if !ok || kan.Added().String() != "5sr" || kan.Target() != seat.MustSeat(2) {
+		t.Errorf("melds[1] = %v, want a promoted kan adding 5sr", melds[1])
+	}
+	if _, ok := melds[2].(*meld.ConcealedKan); !ok {
+		t.Errorf("melds[2] = %v, want a concealed kan", melds[2])
+	}
+}
+
+func TestParseHand_RejectsInvalidNotation(t *testing.T) {
+	for _, s := range []string{
+		"1m?",          // unknown tile in a visible hand
+		"1m[123m",      // unclosed meld
+		"[123m@1]1m",   // tiles after melds
+		"[124m@1]",     // not a sequence
+		"[123m]",       // missing target seat
+		"[123m@4]",     // invalid seat
+		"[55+5z@1]",    // promoted kan of two tiles
+		"(1234z)",      // concealed kan of different tiles
+		"11111m",       // five copies
+		"1m(555m",      // unclosed concealed kan
+		"1m)",          // unopened
+		"[1111m+1m@1]", // four tiles before +
+		"[123m@]",      // no digit after @
+		"[555+55m@1]",  // two added tiles
+	} {
+		if _, _, err := notation.ParseHand(s); err == nil {
+			t.Errorf("ParseHand(%q) succeeded unexpectedly", s)
+		}
+	}
+}
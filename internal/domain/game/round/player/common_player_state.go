@@ -13,6 +13,7 @@ type commonPlayerState struct {
 	melds                     []meld.Meld
 	river                     []tile.Tile
 	discardedTiles            []tile.Tile
+	tsumogiris                []bool
 	extraSafeTiles            []tile.Tile
 	riichiState               RiichiState
 	riichiRiverIndex          int
@@ -30,6 +31,7 @@ func newCommonPlayerState() commonPlayerState {
 		melds:                     make([]meld.Meld, 0, maxNumMelds),
 		river:                     make([]tile.Tile, 0, maxNumRiver),
 		discardedTiles:            make([]tile.Tile, 0, maxNumDiscardedTiles),
+		tsumogiris:                make([]bool, 0, maxNumDiscardedTiles),
 		extraSafeTiles:            make([]tile.Tile, 0, 3),
 		riichiState:               NotRiichi,
 		riichiRiverIndex:          -1,
@@ -57,6 +59,10 @@ func (s *commonPlayerState) DiscardedTiles() []tile.Tile {
 	return slices.Clone(s.discardedTiles)
 }
 
+func (s *commonPlayerState) DiscardTsumogiris() []bool {
+	return slices.Clone(s.tsumogiris)
+}
+
 func (s *commonPlayerState) ExtraSafeTiles() []tile.Tile {
 	return slices.Clone(s.extraSafeTiles)
 }
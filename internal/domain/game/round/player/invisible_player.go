@@ -108,6 +108,7 @@ func (p *InvisiblePlayer) Discard(t tile.Tile, tsumogiri bool) error {
 	p.drawnTile = nil
 	p.river = append(p.river, t)
 	p.discardedTiles = append(p.discardedTiles, t)
+	p.tsumogiris = append(p.tsumogiris, tsumogiri)
 	p.swapCallTiles = nil
 	return nil
 }
@@ -39,6 +39,9 @@ type PlayerViewer interface {
 	// DiscardedTiles returns the discarded tiles (捨て牌).
 	// It includes the tiles that have been called.
 	DiscardedTiles() []tile.Tile
+	// DiscardTsumogiris returns, for each discarded tile, whether it was the
+	// drawn tile (ツモ切り) rather than a tile from the hand (手出し).
+	DiscardTsumogiris() []bool
 	// ExtraSafeTiles returns extra safe tiles (安全牌).
 	// The tiles that are safe in the same turn and the tiles that are safe after riichi.
 	ExtraSafeTiles() []tile.Tile
@@ -94,4 +97,5 @@ type PlayerActor interface {
 type Player interface {
 	PlayerViewer
 	PlayerActor
+	Snapshot() Snapshot
 }
@@ -0,0 +1,223 @@
+package player
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// Snapshot is a plain copy of a player state.
+// A hand of unknown tiles ("?") describes an invisible player.
+type Snapshot struct {
+	Hand                      tile.Tiles
+	DrawnTile                 *tile.Tile
+	Melds                     []meld.Meld
+	River                     tile.Tiles
+	DiscardedTiles            tile.Tiles
+	ExtraSafeTiles            tile.Tiles
+	RiichiState               RiichiState
+	RiichiRiverIndex          int
+	RiichiDiscardedTilesIndex int
+	SwapCallTiles             tile.Tiles
+	NeedsDeadWallDraw         bool
+	// IsFuriten is ignored for invisible players.
+	IsFuriten bool
+	// DiscardTsumogiris parallels DiscardedTiles. Nil means unknown, which
+	// restores every discard as a tile from the hand.
+	DiscardTsumogiris []bool
+}
+
+func (s *commonPlayerState) snapshot(handTiles []tile.Tile) Snapshot {
+	var drawnTile *tile.Tile
+	if s.drawnTile != nil {
+		t := *s.drawnTile
+		drawnTile = &t
+	}
+	return Snapshot{
+		Hand:                      handTiles,
+		DrawnTile:                 drawnTile,
+		Melds:                     slices.Clone(s.melds),
+		River:                     slices.Clone(s.river),
+		DiscardedTiles:            slices.Clone(s.discardedTiles),
+		ExtraSafeTiles:            slices.Clone(s.extraSafeTiles),
+		RiichiState:               s.riichiState,
+		RiichiRiverIndex:          s.riichiRiverIndex,
+		RiichiDiscardedTilesIndex: s.riichiDiscardedTilesIndex,
+		SwapCallTiles:             slices.Clone(s.swapCallTiles),
+		NeedsDeadWallDraw:         s.needsDeadWallDraw,
+		DiscardTsumogiris:         slices.Clone(s.tsumogiris),
+	}
+}
+
+func (p *VisiblePlayer) Snapshot() Snapshot {
+	s := p.snapshot(p.hand.ToTiles())
+	s.IsFuriten = p.isFuriten
+	return s
+}
+
+func (p *InvisiblePlayer) Snapshot() Snapshot {
+	return p.snapshot(p.hand.ToTiles())
+}
+
+// NewPlayerFromSnapshot restores a player from a snapshot after checking the
+// invariants that event application maintains.
+func NewPlayerFromSnapshot(s *Snapshot) (Player, error) {
+	commonState, err := newCommonPlayerStateFromSnapshot(s)
+	if err != nil {
+		return nil, err
+	}
+
+	numUnknown := 0
+	for _, t := range s.Hand {
+		if t.IsUnknown() {
+			numUnknown++
+		}
+	}
+
+	switch numUnknown {
+	case len(s.Hand):
+		h, err := hand.NewInvisibleHand(s.Hand)
+		if err != nil {
+			return nil, err
+		}
+		return &InvisiblePlayer{hand: *h, commonPlayerState: commonState}, nil
+	case 0:
+		if s.DrawnTile != nil && s.DrawnTile.IsUnknown() {
+			return nil, fmt.Errorf("visible player cannot have an unknown drawn tile")
+		}
+		h, err := hand.NewVisibleHand(s.Hand)
+		if err != nil {
+			return nil, err
+		}
+		p := &VisiblePlayer{hand: *h, commonPlayerState: commonState, isFuriten: s.IsFuriten}
+		p.updateWaits()
+		if !p.isFuriten && slices.ContainsFunc(p.discardedTiles, p.waits.Has) {
+			return nil, fmt.Errorf("player waiting on a discarded tile must be furiten")
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("hand cannot mix known and unknown tiles")
+	}
+}
+
+func newCommonPlayerStateFromSnapshot(s *Snapshot) (commonPlayerState, error) {
+	numMelds := len(s.Melds)
+	if numMelds > maxNumMelds {
+		return commonPlayerState{}, fmt.Errorf("too many melds: %d", numMelds)
+	}
+	if len(s.River) > maxNumRiver {
+		return commonPlayerState{}, fmt.Errorf("too many river tiles: %d", len(s.River))
+	}
+	if len(s.DiscardedTiles) > maxNumDiscardedTiles {
+		return commonPlayerState{}, fmt.Errorf("too many discarded tiles: %d", len(s.DiscardedTiles))
+	}
+	if s.DiscardTsumogiris != nil && len(s.DiscardTsumogiris) != len(s.DiscardedTiles) {
+		return commonPlayerState{}, fmt.Errorf(
+			"tsumogiri flags (%d) do not match discarded tiles (%d)", len(s.DiscardTsumogiris), len(s.DiscardedTiles))
+	}
+	if len(s.River) > len(s.DiscardedTiles) {
+		return commonPlayerState{}, fmt.Errorf(
+			"river has more tiles (%d) than discarded tiles (%d)", len(s.River), len(s.DiscardedTiles))
+	}
+	for _, ts := range []tile.Tiles{s.River, s.DiscardedTiles, s.ExtraSafeTiles} {
+		if slices.ContainsFunc(ts, tile.Tile.IsUnknown) {
+			return commonPlayerState{}, fmt.Errorf("river and safe tiles cannot contain unknown tiles")
+		}
+	}
+
+	isConcealed := true
+	var lastMeld meld.Meld
+	for _, m := range s.Melds {
+		if _, ok := m.(meld.OpenMeld); ok {
+			isConcealed = false
+		}
+		lastMeld = m
+	}
+
+	// A kan takes four tiles but fills one of the four sets, like any other meld.
+	handSize := len(s.Hand)
+	if s.DrawnTile != nil {
+		handSize++
+	}
+	restSize := 13 - 3*numMelds
+	hasExtraTile := s.DrawnTile != nil || s.SwapCallTiles != nil
+	switch {
+	case handSize == restSize+1 && hasExtraTile:
+	case handSize == restSize && !hasExtraTile:
+	default:
+		return commonPlayerState{}, fmt.Errorf("invalid number of hand tiles: %d with %d melds", handSize, numMelds)
+	}
+
+	if s.SwapCallTiles != nil {
+		if s.DrawnTile != nil {
+			return commonPlayerState{}, fmt.Errorf("swap-call tiles cannot be set with a drawn tile")
+		}
+		if _, ok := lastMeld.(meld.ChiiPon); !ok {
+			return commonPlayerState{}, fmt.Errorf("swap-call tiles require a chii or pon as the last meld")
+		}
+	}
+	if s.NeedsDeadWallDraw {
+		if s.DrawnTile != nil {
+			return commonPlayerState{}, fmt.Errorf("dead wall draw cannot be pending with a drawn tile")
+		}
+		switch lastMeld.(type) {
+		case *meld.CalledKan, *meld.ConcealedKan, *meld.PromotedKan:
+		default:
+			return commonPlayerState{}, fmt.Errorf("dead wall draw requires a kan")
+		}
+	}
+
+	switch s.RiichiState {
+	case NotRiichi, RiichiDeclared:
+		if s.RiichiRiverIndex != -1 || s.RiichiDiscardedTilesIndex != -1 {
+			return commonPlayerState{}, fmt.Errorf("riichi indices must be -1 before riichi is accepted")
+		}
+	case RiichiAccepted:
+		if s.RiichiRiverIndex < 0 || len(s.River) < s.RiichiRiverIndex {
+			return commonPlayerState{}, fmt.Errorf("riichi river index out of range: %d", s.RiichiRiverIndex)
+		}
+		if s.RiichiDiscardedTilesIndex < 0 || len(s.DiscardedTiles) <= s.RiichiDiscardedTilesIndex {
+			return commonPlayerState{}, fmt.Errorf(
+				"riichi discarded tiles index out of range: %d", s.RiichiDiscardedTilesIndex)
+		}
+	default:
+		return commonPlayerState{}, fmt.Errorf("invalid riichi state: %d", s.RiichiState)
+	}
+	if s.RiichiState != NotRiichi && !isConcealed {
+		return commonPlayerState{}, fmt.Errorf("riichi requires a concealed hand")
+	}
+
+	var drawnTile *tile.Tile
+	if s.DrawnTile != nil {
+		t := *s.DrawnTile
+		drawnTile = &t
+	}
+	melds := make([]meld.Meld, 0, maxNumMelds)
+	melds = append(melds, s.Melds...)
+	river := make([]tile.Tile, 0, maxNumRiver)
+	river = append(river, s.River...)
+	discardedTiles := make([]tile.Tile, 0, maxNumDiscardedTiles)
+	discardedTiles = append(discardedTiles, s.DiscardedTiles...)
+	tsumogiris := make([]bool, len(s.DiscardedTiles), maxNumDiscardedTiles)
+	copy(tsumogiris, s.DiscardTsumogiris)
+	extraSafeTiles := make([]tile.Tile, 0, 3)
+	extraSafeTiles = append(extraSafeTiles, s.ExtraSafeTiles...)
+
+	return commonPlayerState{
+		drawnTile:                 drawnTile,
+		melds:                     melds,
+		river:                     river,
+		discardedTiles:            discardedTiles,
+		tsumogiris:                tsumogiris,
+		extraSafeTiles:            extraSafeTiles,
+		riichiState:               s.RiichiState,
+		riichiRiverIndex:          s.RiichiRiverIndex,
+		riichiDiscardedTilesIndex: s.RiichiDiscardedTilesIndex,
+		isConcealed:               isConcealed,
+		swapCallTiles:             slices.Clone(s.SwapCallTiles),
+		needsDeadWallDraw:         s.NeedsDeadWallDraw,
+	}, nil
+}
@@ -66,40 +66,96 @@ var (
 	}()
 )
 
+// ShantenEngine selects how the regular form shanten number is calculated.
+// Both engines give the same results.
+type ShantenEngine int
+
+const (
+	// SearchEngine searches the nearest winning hands by pruning DFS.
+	SearchEngine ShantenEngine = iota
+	// TableEngine looks the shanten number up in per-suit tables built on
+	// first use. Goals are still searched, but only up to the known shanten
+	// number.
+	TableEngine
+)
+
 type shantenConfig struct {
 	allowedExtraTiles int
 	upperBound        int
+	engine            ShantenEngine
+}
+
+func newShantenConfig(opts []ShantenOption) *shantenConfig {
+	cfg := &shantenConfig{
+		allowedExtraTiles: 0,
+		upperBound:        MaxShantenNumber,
+		engine:            SearchEngine,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
 }
 
-type shantenOption func(*shantenConfig)
+// ShantenOption configures shanten analysis.
+type ShantenOption func(*shantenConfig)
 
-func AllowedExtraTiles(n int) shantenOption {
+func AllowedExtraTiles(n int) ShantenOption {
 	return func(cfg *shantenConfig) {
 		cfg.allowedExtraTiles = n
 	}
 }
 
-func UpperBound(n int) shantenOption {
+func UpperBound(n int) ShantenOption {
 	return func(cfg *shantenConfig) {
 		cfg.upperBound = n
 	}
 }
 
-// AnalyzeShanten calculates the shanten number and the list of Goal for the given hand.
-// When the list of Goal is empty, `InfinityShanten` is returned as the shanten number.
-// It does not consider Seven Pairs or Thirteen Orphans.
-func AnalyzeShanten(h *hand.VisibleHand, opts ...shantenOption) (int, []Goal) {
-	cfg := &shantenConfig{
-		allowedExtraTiles: 0,
-		upperBound:        MaxShantenNumber,
+func Engine(e ShantenEngine) ShantenOption {
+	return func(cfg *shantenConfig) {
+		cfg.engine = e
 	}
+}
 
-	for _, opt := range opts {
-		opt(cfg)
+// Shanten calculates the shanten number of the given hand without the goals.
+// It returns `InfinityShanten` when the shanten number exceeds the upper bound.
+// AllowedExtraTiles has no effect. It does not consider Seven Pairs or
+// Thirteen Orphans.
+func Shanten(h *hand.VisibleHand, opts ...ShantenOption) int {
+	cfg := newShantenConfig(opts)
+	if cfg.engine != TableEngine {
+		shanten, _ := AnalyzeShanten(h, opts...)
+		return shanten
+	}
+
+	tc34 := h.ToTileCounts34()
+	shanten := tableShanten(tc34)
+	if shanten > cfg.upperBound {
+		return InfinityShanten
 	}
+	return shanten
+}
 
+// AnalyzeShanten calculates the shanten number and the list of Goal for the given hand.
+// When the list of Goal is empty, `InfinityShanten` is returned as the shanten number.
+// It does not consider Seven Pairs or Thirteen Orphans.
+func AnalyzeShanten(h *hand.VisibleHand, opts ...ShantenOption) (int, []Goal) {
+	cfg := newShantenConfig(opts)
 	tc34 := h.ToTileCounts34()
 
+	upperBound := cfg.upperBound
+	if cfg.engine == TableEngine {
+		// With the shanten number known, the search only visits the goals
+		// it keeps.
+		shanten := tableShanten(tc34)
+		if shanten > cfg.upperBound {
+			return InfinityShanten, []Goal{}
+		}
+		upperBound = shanten
+	}
+
 	targetVector := hand.TileCounts34{}
 	numRequiredMelds := min(tc34.NumTiles()/3, 4)
 	blocks := make([]block.Block, 0, numRequiredMelds+1) // +1 for the pair
@@ -111,7 +167,7 @@ func AnalyzeShanten(h *hand.VisibleHand, opts ...shantenOption) (int, []Goal) {
 		-1,
 		numRequiredMelds,
 		0,
-		cfg.upperBound,
+		upperBound,
 		blocks,
 		&allGoals,
 		cfg.allowedExtraTiles,
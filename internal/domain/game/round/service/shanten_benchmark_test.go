@@ -211,3 +211,53 @@ func BenchmarkShantenAnalysis_14_NonSimple(b *testing.B) {
 		_, _ = service.AnalyzeShanten(hand)
 	}
 }
+
+func BenchmarkShantenAnalysis_Table_Normal(b *testing.B) {
+	rng := createRNG()
+	for b.Loop() {
+		b.StopTimer()
+		hand := generateRandomPureHand(rng)
+		b.StartTimer()
+		_, _ = service.AnalyzeShanten(hand, service.Engine(service.TableEngine))
+	}
+}
+
+func BenchmarkShantenAnalysis_Table_FullFlush(b *testing.B) {
+	rng := createRNG()
+	for b.Loop() {
+		b.StopTimer()
+		hand := generateRandomFullFlushPureHand(rng)
+		b.StartTimer()
+		_, _ = service.AnalyzeShanten(hand, service.Engine(service.TableEngine))
+	}
+}
+
+func BenchmarkShantenAnalysis_Table_14_Normal(b *testing.B) {
+	rng := createRNG()
+	for b.Loop() {
+		b.StopTimer()
+		hand := generateRandomPureHand14(rng)
+		b.StartTimer()
+		_, _ = service.AnalyzeShanten(hand, service.Engine(service.TableEngine))
+	}
+}
+
+func BenchmarkShantenAnalysis_Table_14_FullFlush(b *testing.B) {
+	rng := createRNG()
+	for b.Loop() {
+		b.StopTimer()
+		hand := generateRandomFullFlushPureHand14(rng)
+		b.StartTimer()
+		_, _ = service.AnalyzeShanten(hand, service.Engine(service.TableEngine))
+	}
+}
+
+func BenchmarkShanten_Table_14_Normal(b *testing.B) {
+	rng := createRNG()
+	for b.Loop() {
+		b.StopTimer()
+		hand := generateRandomPureHand14(rng)
+		b.StartTimer()
+		_ = service.Shanten(hand, service.Engine(service.TableEngine))
+	}
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+)
+
+// The table engine follows the per-suit table approach of the calculators by
+// tomohxx and Nyanten. The regular form shanten number of a hand is the
+// number of tiles it lacks for the nearest winning hand, minus one. The lack
+// of a hand is the sum of the lacks of its suits, so each suit only needs,
+// for every possible content, the lack for each number of melds and pairs the
+// suit provides. A suit holds at most 4 of each tile, so the contents of a
+// suit of n kinds are indexed as base-5 numbers below 5^n.
+
+const (
+	numNumberKinds = 9
+	numHonorKinds  = 7
+	// maxSuitMelds is the number of melds of a winning hand.
+	maxSuitMelds = 4
+	// numSuitGoals is the number of (melds, pairs) combinations of a suit:
+	// 0 to 4 melds with no pair or one pair.
+	numSuitGoals = (maxSuitMelds + 1) * 2
+)
+
+// suitLacks holds the number of tiles a suit lacks for each combination of
+// melds and pairs, indexed by suitGoalIndex.
+type suitLacks [numSuitGoals]uint8
+
+func suitGoalIndex(melds, pairs int) int {
+	return melds*2 + pairs
+}
+
+type shantenTables struct {
+	numbers []suitLacks
+	honors  []suitLacks
+}
+
+// loadShantenTables builds the tables on first use. It takes a fraction of
+// a second and about 20 MB, which the search engine does not pay.
+var loadShantenTables = sync.OnceValue(func() *shantenTables {
+	return &shantenTables{
+		numbers: buildSuitTable(numNumberKinds, true),
+		honors:  buildSuitTable(numHonorKinds, false),
+	}
+})
+
+// buildSuitTable builds the lack table of a suit of numKinds kinds. Honors
+// cannot form sequences.
+func buildSuitTable(numKinds int, sequences bool) []suitLacks {
+	place := make([]int, numKinds+1)
+	place[0] = 1
+	for i := range numKinds {
+		place[i+1] = place[i] * 5
+	}
+	size := place[numKinds]
+
+	// covers[idx] has the bit of a goal when the content idx holds a winning
+	// form of the suit with that goal. Winning forms are first marked
+	// exactly and then spread to every content that contains one.
+	covers := make([]uint16, size)
+	counts := make([]int, numKinds)
+	var addForms func(firstMeld, melds, idx int)
+	addForms = func(firstMeld, melds, idx int) {
+		covers[idx] |= 1 << suitGoalIndex(melds, 0)
+		for i := range numKinds {
+			if counts[i]+2 <= 4 {
+				covers[idx+2*place[i]] |= 1 << suitGoalIndex(melds, 1)
+			}
+		}
+		if melds == maxSuitMelds {
+			return
+		}
+		// Melds are numbered triplets first, then sequences, and taken in
+		// non-decreasing order.
+		for m := firstMeld; m < 2*numKinds; m++ {
+			if m < numKinds {
+				if counts[m]+3 > 4 {
+					continue
+				}
+				counts[m] += 3
+				// A triplet cannot be taken twice anyway.
+				addForms(m+1, melds+1, idx+3*place[m])
+				counts[m] -= 3
+				continue
+			}
+			i := m - numKinds
+			if !sequences || i+2 >= numKinds || counts[i] >= 4 || counts[i+1] >= 4 || counts[i+2] >= 4 {
+				continue
+			}
+			counts[i]++
+			counts[i+1]++
+			counts[i+2]++
+			addForms(m, melds+1, idx+place[i]+place[i+1]+place[i+2])
+			counts[i]--
+			counts[i+1]--
+			counts[i+2]--
+		}
+	}
+	addForms(0, 0, 0)
+
+	// A content holds a form if removing one of its tiles leaves a content
+	// that holds it. Removing a tile lowers the index, so ascending order
+	// sees the smaller contents first.
+	for idx := range size {
+		for i, rest := 0, idx; i < numKinds; i, rest = i+1, rest/5 {
+			if rest%5 > 0 {
+				covers[idx] |= covers[idx-place[i]]
+			}
+		}
+	}
+
+	// A content that does not hold a form lacks one more tile than the best
+	// content with one more tile, because the nearest form always has a tile
+	// the content lacks. Adding a tile raises the index, so descending order
+	// sees the larger contents first.
+	lacks := make([]suitLacks, size)
+	for idx := size - 1; idx >= 0; idx-- {
+		for g := range numSuitGoals {
+			if covers[idx]&(1<<g) != 0 {
+				continue
+			}
+			best := uint8(255)
+			for i, rest := 0, idx; i < numKinds; i, rest = i+1, rest/5 {
+				if rest%5 < 4 {
+					best = min(best, lacks[idx+place[i]][g])
+				}
+			}
+			lacks[idx][g] = best + 1
+		}
+	}
+	return lacks
+}
+
+// tableShanten returns the regular form shanten number of tc34 from the
+// tables. It is the shanten number AnalyzeShanten returns without an upper
+// bound.
+func tableShanten(tc34 *hand.TileCounts34) int {
+	tables := loadShantenTables()
+	numRequiredMelds := min(tc34.NumTiles()/3, maxSuitMelds)
+
+	lacks := tables.numbers[suitIndex(tc34[0:numNumberKinds])]
+	for _, suit := range [...]struct {
+		table []suitLacks
+		start int
+		kinds int
+	}{
+		{tables.numbers, 9, numNumberKinds},
+		{tables.numbers, 18, numNumberKinds},
+		{tables.honors, 27, numHonorKinds},
+	} {
+		lacks = combineSuitLacks(&lacks, &suit.table[suitIndex(tc34[suit.start:suit.start+suit.kinds])], numRequiredMelds)
+	}
+	return int(lacks[suitGoalIndex(numRequiredMelds, 1)]) - 1
+}
+
+// suitIndex returns the table index of the tile counts of a suit.
+func suitIndex(counts []int) int {
+	idx := 0
+	for i := len(counts) - 1; i >= 0; i-- {
+		idx = idx*5 + counts[i]
+	}
+	return idx
+}
+
+// combineSuitLacks returns the lacks of the union of two groups of suits,
+// for up to maxMelds melds.
+func combineSuitLacks(a, b *suitLacks, maxMelds int) suitLacks {
+	combined := suitLacks{}
+	for melds := range maxMelds + 1 {
+		for pairs := range 2 {
+			best := uint8(255)
+			for aMelds := range melds + 1 {
+				for aPairs := range pairs + 1 {
+					best = min(best, a[suitGoalIndex(aMelds, aPairs)]+b[suitGoalIndex(melds-aMelds, pairs-aPairs)])
+				}
+			}
+			combined[suitGoalIndex(melds, pairs)] = best
+		}
+	}
+	return combined
+}
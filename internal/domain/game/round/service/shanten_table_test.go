@@ -0,0 +1,160 @@
+package service_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+)
+
+var shantenEngines = []struct {
+	name   string
+	engine service.ShantenEngine
+}{
+	{"search", service.SearchEngine},
+	{"table", service.TableEngine},
+}
+
+func handFromCounts(tc34 *[34]int) *hand.VisibleHand {
+	wall := []int{}
+	for id, count := range tc34 {
+		for range count {
+			wall = append(wall, id)
+		}
+	}
+	return fillHand(wall, len(wall))
+}
+
+// forEachSuitContent calls f with every content of the numKinds kinds from
+// start whose number of tiles is a hand length.
+func forEachSuitContent(start, numKinds int, f func(tc34 *[34]int)) {
+	tc34 := [34]int{}
+	var fill func(i, numTiles int)
+	fill = func(i, numTiles int) {
+		if i == numKinds {
+			if numTiles%3 != 0 {
+				f(&tc34)
+			}
+			return
+		}
+		for c := 0; c <= 4 && numTiles+c <= 14; c++ {
+			tc34[start+i] = c
+			fill(i+1, numTiles+c)
+		}
+		tc34[start+i] = 0
+	}
+	fill(0, 0)
+}
+
+func TestShanten_EnginesAgreeOnSingleSuits(t *testing.T) {
+	if testing.Short() {
+		t.Skip("every single suit hand takes a while")
+	}
+	for _, suit := range []struct {
+		name     string
+		start    int
+		numKinds int
+	}{
+		{"numbers", 0, 9},
+		{"honors", 27, 7},
+	} {
+		t.Run(suit.name, func(t *testing.T) {
+			forEachSuitContent(suit.start, suit.numKinds, func(tc34 *[34]int) {
+				h := handFromCounts(tc34)
+				want := service.Shanten(h, service.Engine(service.SearchEngine))
+				got := service.Shanten(h, service.Engine(service.TableEngine))
+				if got != want {
+					t.Fatalf("Shanten(%v) = %v with the table engine, want %v", h, got, want)
+				}
+			})
+		})
+	}
+}
+
+func TestAnalyzeShanten_EnginesAgreeOnRandomHands(t *testing.T) {
+	rng := createRNG()
+	options := []struct {
+		allowedExtraTiles int
+		upperBound        int
+	}{
+		{0, service.MaxShantenNumber},
+		{1, service.MaxShantenNumber},
+		{1, 2},
+		{0, 0},
+	}
+	for range 200 {
+		hands := []*hand.VisibleHand{
+			generateRandomPureHand(rng),
+			generateRandomHalfFlushPureHand(rng),
+			generateRandomFullFlushPureHand(rng),
+			generateRandomNonSimplePureHand(rng),
+		}
+		for _, h := range hands {
+			for _, o := range options {
+				extra, upper := o.allowedExtraTiles, o.upperBound
+				wantShanten, wantGoals := service.AnalyzeShanten(h,
+					service.AllowedExtraTiles(extra), service.UpperBound(upper))
+				gotShanten, gotGoals := service.AnalyzeShanten(h,
+					service.AllowedExtraTiles(extra), service.UpperBound(upper), service.Engine(service.TableEngine))
+				if gotShanten != wantShanten || !reflect.DeepEqual(gotGoals, wantGoals) {
+					t.Fatalf("AnalyzeShanten(%v, %d, %d) = %v, %d goals with the table engine, want %v, %d goals",
+						h, extra, upper, gotShanten, len(gotGoals), wantShanten, len(wantGoals))
+				}
+				if got := service.Shanten(h, service.UpperBound(upper), service.Engine(service.TableEngine)); got != wantShanten {
+					t.Fatalf("Shanten(%v, %d) = %v with the table engine, want %v", h, upper, got, wantShanten)
+				}
+			}
+		}
+	}
+}
+
+func TestShanten(t *testing.T) {
+	tests := []struct {
+		name        string
+		codes       []string
+		upperBound  int
+		wantShanten int
+	}{
+		{
+			name:        "tenpai",
+			codes:       []string{"1m", "2m", "3m", "7m", "8m", "9m", "2s", "3s", "4s", "S", "S", "S", "W"},
+			upperBound:  service.MaxShantenNumber,
+			wantShanten: 0,
+		},
+		{
+			name:        "above the upper bound",
+			codes:       []string{"1m", "2m", "3m", "7m", "8m", "9m", "2s", "3s", "4s", "S", "S", "S", "W"},
+			upperBound:  -1,
+			wantShanten: service.InfinityShanten,
+		},
+		{
+			name:        "waiting for the 5th tile",
+			codes:       []string{"E", "E", "E", "E", "S", "S", "S", "S", "W", "W", "W", "N", "N", "N"},
+			upperBound:  service.MaxShantenNumber,
+			wantShanten: 1,
+		},
+		{
+			name:        "4 honors",
+			codes:       []string{"E", "E", "E", "E"},
+			upperBound:  service.MaxShantenNumber,
+			wantShanten: 1,
+		},
+		{
+			name:        "empty",
+			codes:       []string{},
+			upperBound:  service.MaxShantenNumber,
+			wantShanten: 1,
+		},
+	}
+	for _, e := range shantenEngines {
+		for _, tt := range tests {
+			t.Run(e.name+"/"+tt.name, func(t *testing.T) {
+				got := service.Shanten(hand.CodesToHand(tt.codes), service.UpperBound(tt.upperBound), service.Engine(e.engine))
+				if got != tt.wantShanten {
+					t.Errorf("Shanten() = %v, want %v", got, tt.wantShanten)
+				}
+			})
+		}
+	}
+}
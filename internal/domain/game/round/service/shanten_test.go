@@ -195,17 +195,19 @@ func TestAnalyzeShanten(t *testing.T) {
 			wantGoalsCount: 4,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			hand := hand.CodesToHand(tt.codes)
-			got, got2 := service.AnalyzeShanten(hand)
-			if got != tt.wantShanten {
-				t.Errorf("AnalyzeShanten() = %v, want %v", got, tt.wantShanten)
-			}
-			if len(got2) != tt.wantGoalsCount {
-				t.Errorf("AnalyzeShanten() = %v, want %v", len(got2), tt.wantGoalsCount)
-			}
-		})
+	for _, e := range shantenEngines {
+		for _, tt := range tests {
+			t.Run(e.name+"/"+tt.name, func(t *testing.T) {
+				hand := hand.CodesToHand(tt.codes)
+				got, got2 := service.AnalyzeShanten(hand, service.Engine(e.engine))
+				if got != tt.wantShanten {
+					t.Errorf("AnalyzeShanten() = %v, want %v", got, tt.wantShanten)
+				}
+				if len(got2) != tt.wantGoalsCount {
+					t.Errorf("AnalyzeShanten() = %v, want %v", len(got2), tt.wantGoalsCount)
+				}
+			})
+		}
 	}
 }
 
@@ -243,21 +245,24 @@ func TestAnalyzeShanten_Options(t *testing.T) {
 			wantGoalsCount:    0,
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			hand := hand.CodesToHand(tt.codes)
-			got, got2 := service.AnalyzeShanten(
-				hand,
-				service.AllowedExtraTiles(tt.allowedExtraTiles),
-				service.UpperBound(tt.upperBound),
-			)
-			if got != tt.wantShanten {
-				t.Errorf("AnalyzeShanten() = %v, want %v", got, tt.wantShanten)
-			}
-			if len(got2) != tt.wantGoalsCount {
-				t.Errorf("AnalyzeShanten() = %v, want %v", len(got2), tt.wantGoalsCount)
-			}
-		})
+	for _, e := range shantenEngines {
+		for _, tt := range tests {
+			t.Run(e.name+"/"+tt.name, func(t *testing.T) {
+				hand := hand.CodesToHand(tt.codes)
+				got, got2 := service.AnalyzeShanten(
+					hand,
+					service.AllowedExtraTiles(tt.allowedExtraTiles),
+					service.UpperBound(tt.upperBound),
+					service.Engine(e.engine),
+				)
+				if got != tt.wantShanten {
+					t.Errorf("AnalyzeShanten() = %v, want %v", got, tt.wantShanten)
+				}
+				if len(got2) != tt.wantGoalsCount {
+					t.Errorf("AnalyzeShanten() = %v, want %v", len(got2), tt.wantGoalsCount)
+				}
+			})
+		}
 	}
 }
 
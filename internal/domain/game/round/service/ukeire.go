@@ -0,0 +1,392 @@
+package service
+
+import (
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service/block"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// HandForm is a bit set of winning forms considered by ukeire (受け入れ) analysis.
+type HandForm uint8
+
+const (
+	RegularForm HandForm = 1 << iota
+	ChiitoitsuForm
+	KokushimusouForm
+
+	AllForms = RegularForm | ChiitoitsuForm | KokushimusouForm
+)
+
+// WaitShape is a bit set of tenpai wait shapes (待ちの形).
+type WaitShape uint8
+
+const (
+	RyanmenWait WaitShape = 1 << iota // 両面
+	KanchanWait                       // 嵌張
+	PenchanWait                       // 辺張
+	ShanponWait                       // 双碰
+	TankiWait                         // 単騎
+)
+
+// AcceptedTile is a tile kind that lowers the shanten number when drawn.
+type AcceptedTile struct {
+	// Tile is the accepted tile kind. It never is a red five.
+	Tile tile.Tile
+	// NumLive is the number of copies not yet visible to the player.
+	NumLive int
+	// Forms is the set of winning forms whose shanten number the tile lowers.
+	Forms HandForm
+	// Shapes is the union of the shapes of Readings, with TankiWait added when
+	// the tile completes chiitoitsu or kokushimusou. It is empty unless the
+	// hand is tenpai.
+	Shapes WaitShape
+	// Readings lists the regular-form decompositions the tile completes. A
+	// tile completes more than one when the hand can be read in several ways,
+	// such as 4p of 23456p read as 234p+56p or as 23p+456p.
+	Readings []WaitReading
+}
+
+// WaitReading is one regular-form decomposition of a tenpai hand completed by
+// an accepted tile.
+type WaitReading struct {
+	// Blocks are the blocks of the winning hand, including the completed one.
+	Blocks []block.Block
+	// Completed is the block the tile completes.
+	Completed block.Block
+	// Shape is the single wait shape of Completed.
+	Shape WaitShape
+}
+
+// Improvement is a tile kind that keeps the shanten number but, after the best
+// discard, widens the accepted tiles (2-step improvement, 改良).
+type Improvement struct {
+	// Tile is the improving tile kind. It never is a red five.
+	Tile tile.Tile
+	// NumLive is the number of copies not yet visible to the player.
+	NumLive int
+	// Discard is the discard after drawing Tile that maximizes NumAccepted.
+	Discard tile.Tile
+	// NumAccepted is the number of live accepted tiles after the discard.
+	NumAccepted int
+}
+
+// Ukeire is the tile acceptance of a hand waiting for a draw (3n+1 tiles).
+type Ukeire struct {
+	// Shanten is the minimum shanten number over the analyzed forms.
+	Shanten int
+	// Accepted lists the accepted tile kinds in tile ID order.
+	Accepted []AcceptedTile
+	// NumAccepted is the total number of live accepted tiles.
+	NumAccepted int
+	// Improvements lists 2-step improvement tiles in tile ID order.
+	// It is empty unless requested with IncludeImprovements.
+	Improvements []Improvement
+}
+
+// DiscardUkeire is the tile acceptance after discarding a tile from a hand
+// that has just drawn (3n+2 tiles).
+type DiscardUkeire struct {
+	Discard tile.Tile
+	Ukeire
+}
+
+type ukeireConfig struct {
+	forms        HandForm
+	improvements bool
+}
+
+// UkeireOption configures ukeire analysis.
+type UkeireOption func(*ukeireConfig)
+
+// Forms restricts the winning forms considered by ukeire analysis.
+func Forms(forms HandForm) UkeireOption {
+	return func(cfg *ukeireConfig) {
+		cfg.forms = forms
+	}
+}
+
+// IncludeImprovements enables 2-step improvement analysis.
+// It is much slower than plain ukeire analysis.
+func IncludeImprovements() UkeireOption {
+	return func(cfg *ukeireConfig) {
+		cfg.improvements = true
+	}
+}
+
+// Waits returns the union of the wait shapes of all accepted tiles.
+func (u *Ukeire) Waits() WaitShape {
+	var shapes WaitShape
+	for _, a := range u.Accepted {
+		shapes |= a.Shapes
+	}
+	return shapes
+}
+
+// IsGoodShape reports whether a tenpai hand has a good-shaped wait (良形):
+// it waits on a two-sided wait, or on two or more tile kinds that are not only
+// a dual pon wait.
+func (u *Ukeire) IsGoodShape() bool {
+	if u.Shanten != 0 {
+		return false
+	}
+	waits := u.Waits()
+	if waits&RyanmenWait != 0 {
+		return true
+	}
+	return len(u.Accepted) >= 2 && waits != ShanponWait
+}
+
+// AnalyzeUkeire calculates the accepted tiles of a hand with 3n+1 tiles.
+//
+// visibleTiles are the tiles visible to the player, as returned by
+// `round.StateViewer.VisibleTiles`. Tiles in the hand count as visible even if
+// they are missing from visibleTiles.
+func AnalyzeUkeire(h *hand.VisibleHand, visibleTiles tile.Tiles, opts ...UkeireOption) Ukeire {
+	cfg := newUkeireConfig(opts)
+	seen := seenTileCounts(h, visibleTiles)
+	return analyzeUkeire(h, &seen, cfg)
+}
+
+// AnalyzeDiscardUkeire calculates the accepted tiles after each distinct
+// discard from a hand with 3n+2 tiles. Red and normal fives are analyzed as
+// separate discards. The result is sorted by shanten number, then by the
+// number of live accepted tiles in descending order, then by tile order.
+func AnalyzeDiscardUkeire(h *hand.VisibleHand, visibleTiles tile.Tiles, opts ...UkeireOption) []DiscardUkeire {
+	cfg := newUkeireConfig(opts)
+	seen := seenTileCounts(h, visibleTiles)
+
+	discards := tile.Tiles(h.ToTiles()).Distinct(nil)
+	results := make([]DiscardUkeire, 0, len(discards))
+	for _, d := range discards {
+		afterDiscard, err := h.Discard(d)
+		if err != nil {
+			panic(err) // unreachable: d is taken from the hand
+		}
+		results = append(results, DiscardUkeire{
+			Discard: d,
+			Ukeire:  analyzeUkeire(afterDiscard, &seen, cfg),
+		})
+	}
+
+	slices.SortStableFunc(results, func(lhs, rhs DiscardUkeire) int {
+		if lhs.Shanten != rhs.Shanten {
+			return lhs.Shanten - rhs.Shanten
+		}
+		return rhs.NumAccepted - lhs.NumAccepted
+	})
+	return results
+}
+
+func newUkeireConfig(opts []UkeireOption) *ukeireConfig {
+	cfg := &ukeireConfig{forms: AllForms}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// seenTileCounts counts the copies of each tile kind that cannot be drawn.
+func seenTileCounts(h *hand.VisibleHand, visibleTiles tile.Tiles) hand.TileCounts34 {
+	var seen hand.TileCounts34
+	for _, t := range visibleTiles {
+		if t.IsUnknown() {
+			continue
+		}
+		seen[t.RemoveRed().ID()]++
+	}
+	for i, c := range h.ToTileCounts34() {
+		seen[i] = min(max(seen[i], c), 4)
+	}
+	return seen
+}
+
+func analyzeUkeire(h *hand.VisibleHand, seen *hand.TileCounts34, cfg *ukeireConfig) Ukeire {
+	shanten := shantenOfForms(h, cfg.forms, MaxShantenNumber)
+	u := Ukeire{Shanten: shanten}
+	if shanten == InfinityShanten {
+		return u
+	}
+
+	u.Accepted, u.NumAccepted = acceptedTiles(h, seen, shanten, cfg.forms)
+	if shanten == 0 && cfg.forms&RegularForm != 0 {
+		classifyRegularWaits(h, u.Accepted)
+	}
+	if cfg.improvements && shanten >= 0 {
+		u.Improvements = improvements(h, seen, shanten, u.NumAccepted, cfg.forms)
+	}
+	return u
+}
+
+func acceptedTiles(
+	h *hand.VisibleHand,
+	seen *hand.TileCounts34,
+	shanten int,
+	forms HandForm,
+) ([]AcceptedTile, int) {
+	var accepted []AcceptedTile
+	numAccepted := 0
+	for id := range tile.NumTileType34 {
+		t := tile.MustTileFromID(id)
+		drawn, err := h.Draw(t)
+		if err != nil {
+			continue
+		}
+
+		var shapes WaitShape
+		var reducedForms HandForm
+		for _, form := range []HandForm{RegularForm, ChiitoitsuForm, KokushimusouForm} {
+			if forms&form != 0 && shantenOfForms(drawn, form, shanten-1) < shanten {
+				reducedForms |= form
+			}
+		}
+		if reducedForms == 0 {
+			continue
+		}
+		if shanten == 0 && reducedForms&(ChiitoitsuForm|KokushimusouForm) != 0 {
+			shapes |= TankiWait
+		}
+
+		numLive := 4 - seen[id]
+		accepted = append(accepted, AcceptedTile{
+			Tile:    t,
+			NumLive: numLive,
+			Forms:   reducedForms,
+			Shapes:  shapes,
+		})
+		numAccepted += numLive
+	}
+	return accepted, numAccepted
+}
+
+// shantenOfForms returns the minimum shanten number over forms. The regular
+// form is searched only up to upperBound, so results above it may be
+// InfinityShanten.
+func shantenOfForms(h *hand.VisibleHand, forms HandForm, upperBound int) int {
+	shanten := InfinityShanten
+	if forms&ChiitoitsuForm != 0 {
+		shanten = min(shanten, AnalyzeShantenChiitoitsu(h))
+	}
+	if forms&KokushimusouForm != 0 {
+		shanten = min(shanten, AnalyzeShantenKokushimusou(h))
+	}
+	// The cheap special forms tighten the bound of the regular form search.
+	upperBound = min(upperBound, shanten)
+	if forms&RegularForm != 0 && upperBound >= -1 {
+		s, _ := AnalyzeShanten(h, UpperBound(upperBound))
+		shanten = min(shanten, s)
+	}
+	return shanten
+}
+
+// classifyRegularWaits adds the regular-form readings of a tenpai hand to the
+// accepted tiles.
+func classifyRegularWaits(h *hand.VisibleHand, accepted []AcceptedTile) {
+	shanten, goals := AnalyzeShanten(h, UpperBound(0))
+	if shanten != 0 {
+		return
+	}
+	for _, goal := range goals {
+		waitID := slices.IndexFunc(goal.RequiredVector[:], func(c int) bool { return c > 0 })
+		if waitID < 0 {
+			continue
+		}
+		i := slices.IndexFunc(accepted, func(a AcceptedTile) bool { return a.Tile.ID() == waitID })
+		if i < 0 {
+			continue
+		}
+		wait := tile.MustTileFromID(waitID)
+		// The hand equals the goal without the wait tile, so each block that
+		// contains the wait tile is a separate reading of the hand, in which
+		// that block is the incomplete one.
+		for j, b := range goal.Blocks {
+			shape := waitShapeOf(b, wait)
+			if shape == 0 || slices.ContainsFunc(goal.Blocks[:j], func(other block.Block) bool { return sameBlock(b, other) }) {
+				continue
+			}
+			accepted[i].Readings = append(accepted[i].Readings, WaitReading{Blocks: goal.Blocks, Completed: b, Shape: shape})
+			accepted[i].Shapes |= shape
+		}
+	}
+}
+
+// sameBlock reports whether two blocks have the same tiles, which makes them
+// the same kind of block.
+func sameBlock(lhs block.Block, rhs block.Block) bool {
+	return slices.Equal(lhs.ToTiles(), rhs.ToTiles())
+}
+
+func waitShapeOf(b block.Block, wait tile.Tile) WaitShape {
+	tiles := b.ToTiles()
+	pos := slices.Index(tiles, wait)
+	if pos < 0 {
+		return 0
+	}
+	switch b.(type) {
+	case *block.Pair:
+		return TankiWait
+	case *block.Triplet:
+		return ShanponWait
+	case *block.Sequence:
+		switch {
+		case pos == 1:
+			return KanchanWait
+		case pos == 2 && tiles[0].Number() == 1, pos == 0 && tiles[2].Number() == 9:
+			return PenchanWait
+		default:
+			return RyanmenWait
+		}
+	default:
+		return 0
+	}
+}
+
+func improvements(
+	h *hand.VisibleHand,
+	seen *hand.TileCounts34,
+	shanten int,
+	numAccepted int,
+	forms HandForm,
+) []Improvement {
+	var result []Improvement
+	for id := range tile.NumTileType34 {
+		if seen[id] >= 4 {
+			continue
+		}
+		t := tile.MustTileFromID(id)
+		drawn, err := h.Draw(t)
+		if err != nil {
+			continue
+		}
+		if shantenOfForms(drawn, forms, shanten-1) < shanten {
+			// Accepted tiles are not improvements.
+			continue
+		}
+
+		seenAfterDraw := *seen
+		seenAfterDraw[id]++
+		best := Improvement{Tile: t, NumLive: 4 - seen[id], NumAccepted: numAccepted}
+		for _, d := range tile.Tiles(drawn.ToTiles()).Distinct(nil) {
+			if d.RemoveRed() == t {
+				continue
+			}
+			afterDiscard, err := drawn.Discard(d)
+			if err != nil {
+				panic(err) // unreachable: d is taken from the hand
+			}
+			if shantenOfForms(afterDiscard, forms, shanten) != shanten {
+				continue
+			}
+			_, n := acceptedTiles(afterDiscard, &seenAfterDraw, shanten, forms)
+			if n > best.NumAccepted {
+				best.Discard = d
+				best.NumAccepted = n
+			}
+		}
+		if best.NumAccepted > numAccepted {
+			result = append(result, best)
+		}
+	}
+	return result
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+func TestAnalyzeUkeire(t *testing.T) {
+	tests := []struct {
+		name            string
+		codes           []string
+		visible         []string
+		regularOnly     bool
+		wantShanten     int
+		wantAccepted    []string
+		wantNumAccepted int
+		wantWaits       service.WaitShape
+		wantGoodShape   bool
+	}{
+		{
+			name:            "ryanmen",
+			codes:           []string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "3s", "4s"},
+			wantShanten:     0,
+			wantAccepted:    []string{"2s", "5s"},
+			wantNumAccepted: 8,
+			wantWaits:       service.RyanmenWait,
+			wantGoodShape:   true,
+		},
+		{
+			name:            "kanchan with visible tiles",
+			codes:           []string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "3s", "5s"},
+			visible:         []string{"4s", "4s", "E"},
+			wantShanten:     0,
+			wantAccepted:    []string{"4s"},
+			wantNumAccepted: 2,
+			wantWaits:       service.KanchanWait,
+			wantGoodShape:   false,
+		},
+		{
+			name:            "penchan",
+			codes:           []string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "8m", "9m"},
+			wantShanten:     0,
+			wantAccepted:    []string{"7m"},
+			wantNumAccepted: 4,
+			wantWaits:       service.PenchanWait,
+			wantGoodShape:   false,
+		},
+		{
+			name:            "shanpon",
+			codes:           []string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "S", "S"},
+			wantShanten:     0,
+			wantAccepted:    []string{"E", "S"},
+			wantNumAccepted: 4,
+			wantWaits:       service.ShanponWait,
+			wantGoodShape:   false,
+		},
+		{
+			name:            "nobetan",
+			codes:           []string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "2p", "3p", "4p", "5pr"},
+			wantShanten:     0,
+			wantAccepted:    []string{"2p", "5p"},
+			wantNumAccepted: 5,
+			wantWaits:       service.TankiWait | service.KanchanWait,
+			wantGoodShape:   true,
+		},
+		{
+			name:            "chiitoitsu",
+			codes:           []string{"1m", "1m", "2m", "2m", "3p", "3p", "4p", "4p", "5s", "5s", "7s", "7s", "E"},
+			wantShanten:     0,
+			wantAccepted:    []string{"E"},
+			wantNumAccepted: 3,
+			wantWaits:       service.TankiWait,
+			wantGoodShape:   false,
+		},
+		{
+			name:            "chiitoitsu excluded",
+			codes:           []string{"1m", "1m", "2m", "2m", "3p", "3p", "4p", "4p", "5s", "5s", "7s", "7s", "E"},
+			regularOnly:     true,
+			wantShanten:     3,
+			wantAccepted:    []string{"1m", "2m", "3m", "2p", "3p", "4p", "5p", "5s", "6s", "7s"},
+			wantNumAccepted: 28,
+		},
+		{
+			name:            "thirteen-sided kokushimusou",
+			codes:           []string{"1m", "9m", "1p", "9p", "1s", "9s", "E", "S", "W", "N", "P", "F", "C"},
+			wantShanten:     0,
+			wantAccepted:    []string{"1m", "9m", "1p", "9p", "1s", "9s", "E", "S", "W", "N", "P", "F", "C"},
+			wantNumAccepted: 39,
+			wantWaits:       service.TankiWait,
+			wantGoodShape:   true,
+		},
+		{
+			name:            "with melds",
+			codes:           []string{"2m", "3m", "E", "E"},
+			wantShanten:     0,
+			wantAccepted:    []string{"1m", "4m"},
+			wantNumAccepted: 8,
+			wantWaits:       service.RyanmenWait,
+			wantGoodShape:   true,
+		},
+		{
+			name:  "four identical tiles",
+			codes: []string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "E", "E"},
+			// Any other tile becomes a single wait after discarding E.
+			wantShanten: 1,
+			wantAccepted: []string{
+				"1m", "2m", "3m", "4m", "5m", "6m", "7m", "8m", "9m",
+				"1p", "2p", "3p", "4p", "5p", "6p", "7p", "8p", "9p",
+				"1s", "2s", "3s", "4s", "5s", "6s", "7s", "8s", "9s",
+				"S", "W", "N", "P", "F", "C",
+			},
+			wantNumAccepted: 123,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forms := service.AllForms
+			if tt.regularOnly {
+				forms = service.RegularForm
+			}
+
+			got := service.AnalyzeUkeire(hand.CodesToHand(tt.codes), codesToTiles(tt.visible), service.Forms(forms))
+
+			if got.Shanten != tt.wantShanten {
+				t.Errorf("AnalyzeUkeire().Shanten = %d, want %d", got.Shanten, tt.wantShanten)
+			}
+			if gotAccepted := acceptedCodes(got.Accepted); !slices.Equal(gotAccepted, tt.wantAccepted) {
+				t.Errorf("AnalyzeUkeire().Accepted = %v, want %v", gotAccepted, tt.wantAccepted)
+			}
+			if got.NumAccepted != tt.wantNumAccepted {
+				t.Errorf("AnalyzeUkeire().NumAccepted = %d, want %d", got.NumAccepted, tt.wantNumAccepted)
+			}
+			if got.Waits() != tt.wantWaits {
+				t.Errorf("AnalyzeUkeire().Waits() = %b, want %b", got.Waits(), tt.wantWaits)
+			}
+			if got.IsGoodShape() != tt.wantGoodShape {
+				t.Errorf("AnalyzeUkeire().IsGoodShape() = %t, want %t", got.IsGoodShape(), tt.wantGoodShape)
+			}
+		})
+	}
+}
+
+func TestAnalyzeUkeire_ReadingsHaveOneShapeEach(t *testing.T) {
+	h := hand.CodesToHand([]string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "2p", "3p", "4p", "5p"})
+
+	got := service.AnalyzeUkeire(h, nil)
+
+	i := slices.IndexFunc(got.Accepted, func(a service.AcceptedTile) bool { return a.Tile == tile.MustTileFromCode("5p") })
+	if i < 0 {
+		t.Fatalf("AnalyzeUkeire().Accepted = %v, want 5p", acceptedCodes(got.Accepted))
+	}
+	var readings []string
+	for _, r := range got.Accepted[i].Readings {
+		readings = append(readings, fmt.Sprintf("%v:%b", codes(r.Completed.ToTiles()), r.Shape))
+	}
+	slices.Sort(readings)
+	want := []string{
+		fmt.Sprintf("%v:%b", []string{"4p", "5p", "6p"}, service.KanchanWait),
+		fmt.Sprintf("%v:%b", []string{"5p", "5p"}, service.TankiWait),
+	}
+	if !slices.Equal(readings, want) {
+		t.Errorf("readings of 5p = %v, want %v", readings, want)
+	}
+}
+
+func TestAnalyzeUkeire_Improvements(t *testing.T) {
+	h := hand.CodesToHand([]string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "3s", "5s"})
+
+	got := service.AnalyzeUkeire(h, nil, service.IncludeImprovements())
+
+	want := []service.Improvement{
+		{Tile: tile.MustTileFromCode("2s"), NumLive: 4, Discard: tile.MustTileFromCode("5s"), NumAccepted: 8},
+		{Tile: tile.MustTileFromCode("6s"), NumLive: 4, Discard: tile.MustTileFromCode("3s"), NumAccepted: 7},
+	}
+	if !slices.Equal(got.Improvements, want) {
+		t.Errorf("AnalyzeUkeire().Improvements = %v, want %v", got.Improvements, want)
+	}
+}
+
+func TestAnalyzeUkeire_NoImprovementsByDefault(t *testing.T) {
+	h := hand.CodesToHand([]string{"1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "3s", "5s"})
+
+	got := service.AnalyzeUkeire(h, nil)
+
+	if len(got.Improvements) != 0 {
+		t.Errorf("AnalyzeUkeire().Improvements = %v, want empty", got.Improvements)
+	}
+}
+
+func TestAnalyzeDiscardUkeire(t *testing.T) {
+	h := hand.CodesToHand([]string{"1m", "2m", "3m", "4p", "5pr", "6p", "7s", "8s", "9s", "E", "E", "3s", "4s", "N"})
+
+	got := service.AnalyzeDiscardUkeire(h, codesToTiles([]string{"2s"}))
+
+	if len(got) != 13 {
+		t.Fatalf("len(AnalyzeDiscardUkeire()) = %d, want %d", len(got), 13)
+	}
+	best := got[0]
+	if best.Discard != tile.MustTileFromCode("N") {
+		t.Errorf("AnalyzeDiscardUkeire()[0].Discard = %s, want %s", best.Discard, "N")
+	}
+	if best.Shanten != 0 {
+		t.Errorf("AnalyzeDiscardUkeire()[0].Shanten = %d, want %d", best.Shanten, 0)
+	}
+	if best.NumAccepted != 7 {
+		t.Errorf("AnalyzeDiscardUkeire()[0].NumAccepted = %d, want %d", best.NumAccepted, 7)
+	}
+	for i := 1; i < len(got); i++ {
+		prev, cur := got[i-1], got[i]
+		if prev.Shanten > cur.Shanten || (prev.Shanten == cur.Shanten && prev.NumAccepted < cur.NumAccepted) {
+			t.Errorf("AnalyzeDiscardUkeire() is not sorted at %d: %s(%d, %d) before %s(%d, %d)",
+				i, prev.Discard, prev.Shanten, prev.NumAccepted, cur.Discard, cur.Shanten, cur.NumAccepted)
+		}
+	}
+	if !slices.ContainsFunc(got, func(d service.DiscardUkeire) bool { return d.Discard == tile.MustTileFromCode("5pr") }) {
+		t.Errorf("AnalyzeDiscardUkeire() does not contain the red five discard")
+	}
+}
+
+func codesToTiles(codes []string) tile.Tiles {
+	tiles := make(tile.Tiles, len(codes))
+	for i, code := range codes {
+		tiles[i] = tile.MustTileFromCode(code)
+	}
+	return tiles
+}
+
+func codes(tiles []tile.Tile) []string {
+	s := make([]string, len(tiles))
+	for i, t := range tiles {
+		s[i] = t.String()
+	}
+	return s
+}
+
+func acceptedCodes(accepted []service.AcceptedTile) []string {
+	var codes []string
+	for _, a := range accepted {
+		codes = append(codes, a.Tile.String())
+	}
+	return codes
+}
@@ -0,0 +1,345 @@
+package round
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+// KanReplacement is the timing of the pending replacement tile draw (嶺上ツモ)
+// after a kan.
+type KanReplacement int
+
+const (
+	NoPendingReplacement KanReplacement = iota
+	// ReplacementBeforeDora follows a called or promoted kan: the replacement
+	// tile is drawn before the kan dora is revealed.
+	ReplacementBeforeDora
+	// ReplacementAfterDora follows a concealed kan: the kan dora is revealed
+	// before the replacement tile is drawn.
+	ReplacementAfterDora
+)
+
+// Snapshot is a plain description of a round state. It can be taken from a
+// state in the middle of a round, or written by hand to set up a board.
+type Snapshot struct {
+	RoundWind      wind.Wind
+	RoundNumber    int
+	Honba          int
+	RiichiDeposit  int
+	Scores         [common.NumPlayers]int
+	Dealer         seat.Seat
+	StartingDealer seat.Seat
+	DoraIndicators tile.Tiles
+	NumLeftTiles   int
+	Players        [common.NumPlayers]player.Snapshot
+
+	NextDraw                seat.Seat
+	PendingDiscard          *seat.Seat
+	PendingRiichiAcceptance *seat.Seat
+	// KanActor is the player whose kan still waits for the replacement tile
+	// draw or for the kan dora reveals.
+	KanActor           *seat.Seat
+	KanReplacement     KanReplacement
+	PendingDoraReveals int
+	// RobbedKanTile is the tile added by a promoted kan that can still be
+	// robbed (搶槓).
+	RobbedKanTile *tile.Tile
+	// ExtraSafeDiscard is the last discard that becomes an extra safe tile
+	// for the other players unless it is called.
+	ExtraSafeDiscard       *ExtraSafeDiscard
+	LastDrawWasReplacement bool
+	CanKyushukyuhai        [common.NumPlayers]bool
+	LastActor              *seat.Seat
+	LegalActionsSuppressed bool
+
+	RoundEnded bool
+	// WinTarget is the player who dealt the winning tile, or the winner of a
+	// self-drawn win. It is nil unless the round ended by a win.
+	WinTarget *seat.Seat
+	WinActors [common.NumPlayers]bool
+}
+
+type ExtraSafeDiscard struct {
+	Actor seat.Seat
+	Tile  tile.Tile
+}
+
+// Snapshot returns a copy of the state that does not share memory with it.
+func (s *State) Snapshot() Snapshot {
+	snap := Snapshot{
+		RoundWind:               s.roundWind,
+		RoundNumber:             s.roundNumber,
+		Honba:                   s.honba,
+		RiichiDeposit:           s.riichiDeposit,
+		Scores:                  s.scores,
+		Dealer:                  s.dealer,
+		StartingDealer:          s.startingDealer,
+		DoraIndicators:          slices.Clone(s.doraIndicators),
+		NumLeftTiles:            s.numLeftTiles,
+		NextDraw:                s.nextDraw,
+		PendingDiscard:          cloneSeat(s.pendingDiscard),
+		PendingRiichiAcceptance: cloneSeat(s.pendingRiichiAcceptance),
+		KanActor:                cloneSeat(s.pendingKanActor),
+		KanReplacement:          KanReplacement(s.kanProgress),
+		PendingDoraReveals:      s.pendingDoraReveals,
+		LastDrawWasReplacement:  s.lastDrawWasReplacement,
+		CanKyushukyuhai:         s.canKyushukyuhai,
+		LastActor:               cloneSeat(s.lastActor),
+		LegalActionsSuppressed:  s.legalActionsSuppressed,
+		RoundEnded:              s.roundEnded,
+		WinActors:               s.winActors,
+	}
+	if s.pendingRobbedKanTile != nil {
+		t := *s.pendingRobbedKanTile
+		snap.RobbedKanTile = &t
+	}
+	if s.pendingExtraSafeDiscard != nil {
+		snap.ExtraSafeDiscard = &ExtraSafeDiscard{
+			Actor: s.pendingExtraSafeDiscard.actor,
+			Tile:  s.pendingExtraSafeDiscard.tile,
+		}
+	}
+	if s.roundEndedByWin {
+		snap.WinTarget = cloneSeat(s.winTarget)
+	}
+	for i, p := range s.players {
+		snap.Players[i] = p.Snapshot()
+	}
+	return snap
+}
+
+// NewStateFromSnapshot restores a state from a snapshot. It rejects snapshots
+// that no sequence of events can produce, as far as the snapshot tells.
+func NewStateFromSnapshot(snap *Snapshot) (*State, error) {
+	if snap.RoundWind < wind.East || wind.North < snap.RoundWind {
+		return nil, fmt.Errorf("invalid round wind: %v", snap.RoundWind)
+	}
+	if snap.RoundNumber < minRoundNumber || maxRoundNumber < snap.RoundNumber {
+		return nil, fmt.Errorf("invalid round number: %d", snap.RoundNumber)
+	}
+	if snap.Honba < 0 {
+		return nil, fmt.Errorf("invalid honba: %d", snap.Honba)
+	}
+	if snap.RiichiDeposit < 0 {
+		return nil, fmt.Errorf("invalid riichi deposit: %d", snap.RiichiDeposit)
+	}
+	if snap.NumLeftTiles < 0 || NumInitWall < snap.NumLeftTiles {
+		return nil, fmt.Errorf("invalid number of left tiles: %d", snap.NumLeftTiles)
+	}
+	numDora := len(snap.DoraIndicators)
+	if numDora < 1 || MaxNumDoraIndicators < numDora {
+		return nil, fmt.Errorf("invalid number of dora indicators: %d", numDora)
+	}
+	if slices.ContainsFunc(snap.DoraIndicators, tile.Tile.IsUnknown) {
+		return nil, fmt.Errorf("dora indicators cannot contain unknown tiles")
+	}
+
+	s := &State{
+		roundWind:               snap.RoundWind,
+		roundNumber:             snap.RoundNumber,
+		honba:                   snap.Honba,
+		riichiDeposit:           snap.RiichiDeposit,
+		scores:                  snap.Scores,
+		dealer:                  snap.Dealer,
+		startingDealer:          snap.StartingDealer,
+		doraIndicators:          make(tile.Tiles, 0, MaxNumDoraIndicators),
+		numLeftTiles:            snap.NumLeftTiles,
+		pendingDoraReveals:      snap.PendingDoraReveals,
+		nextDraw:                snap.NextDraw,
+		pendingDiscard:          cloneSeat(snap.PendingDiscard),
+		pendingRiichiAcceptance: cloneSeat(snap.PendingRiichiAcceptance),
+		pendingKanActor:         cloneSeat(snap.KanActor),
+		lastDrawWasReplacement:  snap.LastDrawWasReplacement,
+		canKyushukyuhai:         snap.CanKyushukyuhai,
+		roundEnded:              snap.RoundEnded,
+		roundEndedByWin:         snap.WinTarget != nil,
+		winTarget:               cloneSeat(snap.WinTarget),
+		winActors:               snap.WinActors,
+		lastActor:               cloneSeat(snap.LastActor),
+		legalActionsSuppressed:  snap.LegalActionsSuppressed,
+	}
+	s.doraIndicators = append(s.doraIndicators, snap.DoraIndicators...)
+
+	numDraws := 0
+	for i := range snap.Players {
+		p, err := player.NewPlayerFromSnapshot(&snap.Players[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid player %d: %w", i, err)
+		}
+		s.players[i] = p
+
+		n, err := numDrawsOf(&snap.Players[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid player %d: %w", i, err)
+		}
+		numDraws += n
+		for _, m := range snap.Players[i].Melds {
+			switch m.(type) {
+			case *meld.CalledKan, *meld.ConcealedKan, *meld.PromotedKan:
+				s.numKans++
+			}
+		}
+	}
+	if numDraws != NumInitWall-snap.NumLeftTiles {
+		return nil, fmt.Errorf(
+			"number of left tiles %d does not match %d tiles drawn by players", snap.NumLeftTiles, numDraws)
+	}
+	if err := validateTileCounts(snap); err != nil {
+		return nil, err
+	}
+
+	if err := s.restoreKanProgress(snap); err != nil {
+		return nil, err
+	}
+	if snap.RobbedKanTile != nil {
+		t := *snap.RobbedKanTile
+		s.pendingRobbedKanTile = &t
+	}
+	if snap.ExtraSafeDiscard != nil {
+		if snap.ExtraSafeDiscard.Tile.IsUnknown() {
+			return nil, fmt.Errorf("extra safe discard cannot be an unknown tile")
+		}
+		s.pendingExtraSafeDiscard = &pendingExtraSafeDiscard{
+			actor: snap.ExtraSafeDiscard.Actor,
+			tile:  snap.ExtraSafeDiscard.Tile,
+		}
+	}
+
+	if err := s.validateTurn(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *State) restoreKanProgress(snap *Snapshot) error {
+	if s.numKans > maxNumKan {
+		return fmt.Errorf("too many kans: %d", s.numKans)
+	}
+	if snap.PendingDoraReveals < 0 {
+		return fmt.Errorf("invalid number of pending dora reveals: %d", snap.PendingDoraReveals)
+	}
+	if len(s.doraIndicators)+snap.PendingDoraReveals != 1+s.numKans {
+		return fmt.Errorf(
+			"%d dora indicators and %d pending reveals do not match %d kans",
+			len(s.doraIndicators), snap.PendingDoraReveals, s.numKans)
+	}
+
+	switch snap.KanReplacement {
+	case NoPendingReplacement:
+		s.kanProgress = noKanProgress
+	case ReplacementBeforeDora:
+		s.kanProgress = waitingReplacementBeforeDora
+	case ReplacementAfterDora:
+		s.kanProgress = waitingReplacementAfterDora
+	default:
+		return fmt.Errorf("invalid kan replacement: %d", snap.KanReplacement)
+	}
+	if s.kanProgress != noKanProgress && s.pendingKanActor == nil {
+		return fmt.Errorf("pending kan replacement requires a kan actor")
+	}
+	if s.pendingKanActor != nil && s.kanProgress == noKanProgress && s.pendingDoraReveals == 0 {
+		return fmt.Errorf("kan actor %d has nothing pending", s.pendingKanActor.Index())
+	}
+	return nil
+}
+
+func (s *State) validateTurn() error {
+	if s.pendingDiscard != nil && !s.players[s.pendingDiscard.Index()].CanDiscard() {
+		return fmt.Errorf("pending discard player %d cannot discard", s.pendingDiscard.Index())
+	}
+	if s.pendingRiichiAcceptance != nil &&
+		s.players[s.pendingRiichiAcceptance.Index()].RiichiState() != player.RiichiDeclared {
+		return fmt.Errorf("pending riichi acceptance player %d has not declared riichi", s.pendingRiichiAcceptance.Index())
+	}
+	numDiscardable := 0
+	for _, p := range s.players {
+		if p.CanDiscard() {
+			numDiscardable++
+		}
+	}
+	if numDiscardable > 1 {
+		return fmt.Errorf("%d players can discard at the same time", numDiscardable)
+	}
+	if s.winTarget != nil && !s.roundEnded {
+		return fmt.Errorf("win target requires the round to have ended")
+	}
+	return nil
+}
+
+// numDrawsOf counts the tiles a player has drawn from the wall, derived from
+// the tiles held, called, and discarded.
+func numDrawsOf(p *player.Snapshot) (int, error) {
+	held := len(p.Hand)
+	if p.DrawnTile != nil {
+		held++
+	}
+	numTaken := 0
+	for _, m := range p.Melds {
+		held += len(m.ToTiles())
+		if _, ok := m.(meld.OpenMeld); ok {
+			numTaken++
+		}
+	}
+	n := held + len(p.DiscardedTiles) - numTaken - common.InitHandSize
+	if n < 0 {
+		return 0, fmt.Errorf("holds fewer tiles than dealt")
+	}
+	return n, nil
+}
+
+// validateTileCounts checks that no tile appears more often than it exists
+// among the tiles whose identity the snapshot tells.
+func validateTileCounts(snap *Snapshot) error {
+	var counts [tile.NumTileType34]int
+	var redCounts [tile.NumTileType37]int
+	add := func(ts ...tile.Tile) {
+		for _, t := range ts {
+			if t.IsUnknown() {
+				continue
+			}
+			counts[t.RemoveRed().ID()]++
+			if t.IsRed() {
+				redCounts[t.ID()]++
+			}
+		}
+	}
+
+	add(snap.DoraIndicators...)
+	for _, p := range snap.Players {
+		add(p.Hand...)
+		if p.DrawnTile != nil {
+			add(*p.DrawnTile)
+		}
+		for _, m := range p.Melds {
+			add(m.ToTiles()...)
+		}
+		add(p.River...)
+	}
+
+	for id, n := range counts {
+		if n > 4 {
+			return fmt.Errorf("tile %s appears %d times", tile.MustTileFromID(id), n)
+		}
+	}
+	for id, n := range redCounts {
+		if n > 1 {
+			return fmt.Errorf("red five %s appears %d times", tile.MustTileFromID(id), n)
+		}
+	}
+	return nil
+}
+
+func cloneSeat(s *seat.Seat) *seat.Seat {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
@@ -0,0 +1,227 @@
+package round
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+func snapshotHandsForTest() [common.NumPlayers][common.InitHandSize]tile.Tile {
+	return [common.NumPlayers][common.InitHandSize]tile.Tile{
+		handTilesForTest("1m", "2m", "3m", "4p", "5p", "6p", "7s", "8s", "9s", "E", "E", "S", "W"),
+		handTilesForTest("W", "W", "2m", "3m", "4m", "6p", "7p", "8p", "1s", "2s", "3s", "N", "N"),
+		handTilesForTest("1p", "1p", "9m", "9m", "5s", "6s", "7s", "C", "C", "P", "F", "2p", "3p"),
+		handTilesForTest("?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?"),
+	}
+}
+
+// newStateForSnapshotTest plays until player 3 deals E to player 0, who is in
+// riichi waiting on E and S.
+func newStateForSnapshotTest(t *testing.T) *State {
+	t.Helper()
+
+	s := mustNewRoundStateForTest(t, snapshotHandsForTest())
+	events := []event.Event{
+		event.NewDraw(seat.MustSeat(0), tile.MustTileFromCode("S")),
+		event.NewRiichi(seat.MustSeat(0)),
+		event.NewDiscard(seat.MustSeat(0), tile.MustTileFromCode("W"), false),
+		event.NewRiichiAccepted(seat.MustSeat(0), nil, nil),
+		event.NewPon(seat.MustSeat(1), seat.MustSeat(0), tile.MustTileFromCode("W"),
+			[2]tile.Tile{tile.MustTileFromCode("W"), tile.MustTileFromCode("W")}),
+		event.NewDiscard(seat.MustSeat(1), tile.MustTileFromCode("N"), false),
+		event.NewDraw(seat.MustSeat(2), tile.MustTileFromCode("1p")),
+		event.NewDiscard(seat.MustSeat(2), tile.MustTileFromCode("F"), false),
+		event.NewDraw(seat.MustSeat(3), tile.MustTileFromCode("?")),
+		event.NewDiscard(seat.MustSeat(3), tile.MustTileFromCode("E"), true),
+	}
+	for _, ev := range events {
+		if err := s.Apply(ev); err != nil {
+			t.Fatalf("Apply(%T) failed: %v", ev, err)
+		}
+	}
+	return s
+}
+
+func TestState_Snapshot_RoundTrip(t *testing.T) {
+	s := newStateForSnapshotTest(t)
+
+	snap := s.Snapshot()
+	restored, err := NewStateFromSnapshot(&snap)
+	if err != nil {
+		t.Fatalf("NewStateFromSnapshot() failed: %v", err)
+	}
+
+	if got := restored.Snapshot(); !reflect.DeepEqual(got, snap) {
+		t.Errorf("restored Snapshot() = %+v, want %+v", got, snap)
+	}
+	if got, want := restored.RenderBoard(), s.RenderBoard(); got != want {
+		t.Errorf("restored RenderBoard() =\n%s\nwant\n%s", got, want)
+	}
+	// Player 3 is invisible and has no legal actions to compare.
+	for i := range common.NumPlayers - 1 {
+		playerSeat := seat.MustSeat(i)
+		want, err := s.LegalActions(playerSeat)
+		if err != nil {
+			t.Fatalf("LegalActions(%d) failed: %v", i, err)
+		}
+		got, err := restored.LegalActions(playerSeat)
+		if err != nil {
+			t.Fatalf("restored LegalActions(%d) failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("restored LegalActions(%d) = %v, want %v", i, got, want)
+		}
+	}
+	if len(snap.Players[0].River) != 0 || snap.Players[0].RiichiRiverIndex != 0 {
+		t.Errorf("player 0 River = %v with riichi index %d, want empty with index 0",
+			snap.Players[0].River, snap.Players[0].RiichiRiverIndex)
+	}
+	if got := snap.Players[3].DiscardTsumogiris; !reflect.DeepEqual(got, []bool{true}) {
+		t.Errorf("player 3 DiscardTsumogiris = %v, want [true]", got)
+	}
+	if got := snap.Players[3].Hand; len(got) != common.InitHandSize || !got.ContainsUnknown() {
+		t.Errorf("player 3 Hand = %v, want 13 unknown tiles", got)
+	}
+}
+
+func TestState_Snapshot_RestoredStateAcceptsNextEvents(t *testing.T) {
+	s := newStateForSnapshotTest(t)
+	snap := s.Snapshot()
+	restored, err := NewStateFromSnapshot(&snap)
+	if err != nil {
+		t.Fatalf("NewStateFromSnapshot() failed: %v", err)
+	}
+
+	for _, st := range []*State{s, restored} {
+		if err := st.Apply(event.NewDraw(seat.MustSeat(0), tile.MustTileFromCode("9p"))); err != nil {
+			t.Fatalf("Apply(Draw) failed: %v", err)
+		}
+	}
+
+	// The E dealt by player 3 made player 0 furiten in riichi.
+	if !restored.Player(seat.MustSeat(0)).IsFuriten() {
+		t.Error("restored player 0 IsFuriten() = false, want true")
+	}
+	if got, want := restored.Snapshot(), s.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored Snapshot() after Draw = %+v, want %+v", got, want)
+	}
+}
+
+func TestState_Snapshot_DoesNotShareMemory(t *testing.T) {
+	s := newStateForSnapshotTest(t)
+	snap := s.Snapshot()
+
+	snap.DoraIndicators[0] = tile.MustTileFromCode("C")
+	snap.Players[0].DiscardedTiles[0] = tile.MustTileFromCode("C")
+
+	if got := s.DoraIndicators()[0]; got != tile.MustTileFromCode("E") {
+		t.Errorf("DoraIndicators()[0] = %v, want E", got)
+	}
+	if got := s.Player(seat.MustSeat(0)).DiscardedTiles()[0]; got != tile.MustTileFromCode("W") {
+		t.Errorf("DiscardedTiles()[0] = %v, want W", got)
+	}
+}
+
+func TestNewStateFromSnapshot_ReturnsErrorOnBrokenInvariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Snapshot)
+		wantErr string
+	}{
+		{
+			name:    "invalid round number",
+			modify:  func(s *Snapshot) { s.RoundNumber = 5 },
+			wantErr: "invalid round number",
+		},
+		{
+			name:    "wall count does not match draws",
+			modify:  func(s *Snapshot) { s.NumLeftTiles-- },
+			wantErr: "does not match",
+		},
+		{
+			name:    "dora indicators do not match kans",
+			modify:  func(s *Snapshot) { s.PendingDoraReveals = 1 },
+			wantErr: "do not match 0 kans",
+		},
+		{
+			name:    "negative pending dora reveals",
+			modify:  func(s *Snapshot) { s.PendingDoraReveals = -1 },
+			wantErr: "invalid number of pending dora reveals",
+		},
+		{
+			name: "five copies of a tile",
+			modify: func(s *Snapshot) {
+				s.Players[2].Hand[0] = tile.MustTileFromCode("E")
+			},
+			wantErr: "tile E appears 5 times",
+		},
+		{
+			name: "hand size does not match melds",
+			modify: func(s *Snapshot) {
+				s.Players[1].Hand = s.Players[1].Hand[1:]
+			},
+			wantErr: "invalid player 1: invalid number of hand tiles",
+		},
+		{
+			name:    "riichi index out of range",
+			modify:  func(s *Snapshot) { s.Players[0].RiichiDiscardedTilesIndex = 5 },
+			wantErr: "invalid player 0: riichi discarded tiles index out of range",
+		},
+		{
+			name:    "tsumogiri flags do not match discards",
+			modify:  func(s *Snapshot) { s.Players[0].DiscardTsumogiris = []bool{} },
+			wantErr: "invalid player 0: tsumogiri flags (0) do not match discarded tiles (1)",
+		},
+		{
+			name:    "riichi with open hand",
+			modify:  func(s *Snapshot) { s.Players[1].RiichiState = player.RiichiDeclared },
+			wantErr: "invalid player 1: riichi requires a concealed hand",
+		},
+		{
+			name: "mixed known and unknown tiles",
+			modify: func(s *Snapshot) {
+				s.Players[3].Hand[0] = tile.MustTileFromCode("9p")
+			},
+			wantErr: "invalid player 3: hand cannot mix known and unknown tiles",
+		},
+		{
+			name: "waiting on a discarded tile without furiten",
+			modify: func(s *Snapshot) {
+				s.Players[0].IsFuriten = false
+				s.Players[0].DiscardedTiles[0] = tile.MustTileFromCode("S")
+			},
+			wantErr: "invalid player 0: player waiting on a discarded tile must be furiten",
+		},
+		{
+			name:    "pending discard player without a tile to discard",
+			modify:  func(s *Snapshot) { s.PendingDiscard = new(seat.MustSeat(2)) },
+			wantErr: "pending discard player 2 cannot discard",
+		},
+		{
+			name:    "kan replacement without kan actor",
+			modify:  func(s *Snapshot) { s.KanReplacement = ReplacementBeforeDora },
+			wantErr: "pending kan replacement requires a kan actor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap := newStateForSnapshotTest(t).Snapshot()
+			tt.modify(&snap)
+
+			_, err := NewStateFromSnapshot(&snap)
+			if err == nil {
+				t.Fatal("NewStateFromSnapshot() succeeded unexpectedly")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewStateFromSnapshot() error = %q, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
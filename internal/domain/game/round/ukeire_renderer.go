@@ -0,0 +1,51 @@
+package round
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+)
+
+// RenderUkeire returns the ukeire of the hand of playerSeat on one line, or an
+// empty string when the hand is not visible. A hand that has just drawn or
+// called shows the discard that keeps the most accepted tiles.
+func (s *State) RenderUkeire(playerSeat seat.Seat) string {
+	p := s.Player(playerSeat)
+	h, ok := p.Hand()
+	if !ok {
+		return ""
+	}
+	if drawnTile := p.DrawnTile(); drawnTile != nil {
+		withDrawnTile, err := h.Draw(*drawnTile)
+		if err != nil {
+			return ""
+		}
+		h = withDrawnTile
+	}
+
+	var b strings.Builder
+	b.WriteString("ukeire:")
+	var u service.Ukeire
+	if len(h.ToTiles())%3 == 2 {
+		best := service.AnalyzeDiscardUkeire(h, s.VisibleTiles(playerSeat))[0]
+		fmt.Fprintf(&b, " dahai %s ", best.Discard)
+		u = best.Ukeire
+	} else {
+		u = service.AnalyzeUkeire(h, s.VisibleTiles(playerSeat))
+	}
+	if u.Shanten == service.InfinityShanten {
+		b.WriteString(" -\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, " shanten %d  %d tiles: ", u.Shanten, u.NumAccepted)
+	for i, a := range u.Accepted {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(a.Tile.String())
+	}
+	b.WriteByte('\n')
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package round
+
+import (
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+)
+
+func TestState_RenderUkeire(t *testing.T) {
+	hands := newValidHands()
+	hands[1] = unknownHandForLegalActionsTest()
+	ev := event.NewStartRound(
+		wind.East,
+		1,
+		0,
+		0,
+		seat.MustSeat(0),
+		tile.MustTileFromCode("E"),
+		&[4]int{25000, 25000, 25000, 25000},
+		hands,
+	)
+	s, err := NewState(ev, [4]int{25000, 25000, 25000, 25000})
+	if err != nil {
+		t.Fatalf("NewState() failed: %v", err)
+	}
+
+	if got, want := s.RenderUkeire(seat.MustSeat(0)), "ukeire: shanten 1  19 tiles: 3m 6m 1p 4p 1s 4s\n"; got != want {
+		t.Errorf("RenderUkeire() before the draw = %q, want %q", got, want)
+	}
+	if err := s.Apply(event.NewDraw(seat.MustSeat(0), tile.MustTileFromCode("6m"))); err != nil {
+		t.Fatalf("Apply(Draw) failed: %v", err)
+	}
+	if got, want := s.RenderUkeire(seat.MustSeat(0)), "ukeire: dahai 1p  shanten 0  6 tiles: 1s 4s\n"; got != want {
+		t.Errorf("RenderUkeire() after the draw = %q, want %q", got, want)
+	}
+	if got := s.RenderUkeire(seat.MustSeat(1)); got != "" {
+		t.Errorf("RenderUkeire() of a hidden hand = %q, want empty", got)
+	}
+}
@@ -0,0 +1,112 @@
+// Package ai provides the agents that decide the actions of a player:
+// ManueAgent, the port of mjai-manue, and the simple TsumogiriAgent and
+// SafeAgent. ManueAgent takes its statistics and deal-in estimates through
+// the interfaces of ManueAgentDeps, so they can be replaced.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package ai
+
+import (
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/configs"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+)
+
+type (
+	// Agent decides the action of a player.
+	Agent = ai.Agent
+	// Request asks an agent for the action of Self.
+	Request = ai.Request
+	// Decision is the action an agent decided, with its log.
+	Decision = ai.Decision
+	// CandidateEvaluation is the evaluation of a candidate action.
+	CandidateEvaluation = ai.CandidateEvaluation
+	// PlayerNamesReceiver is implemented by agents that adapt to the
+	// players at the table.
+	PlayerNamesReceiver = ai.PlayerNamesReceiver
+	// FallbackProvider is implemented by agents that know a simpler agent
+	// to fall back on when they fail.
+	FallbackProvider = ai.FallbackProvider
+)
+
+// ManueAgent is the port of mjai-manue.
+type ManueAgent = ai.ManueAgent
+
+// ManueAgentDeps are the dependencies of ManueAgent. They are read-only and
+// can be shared between agents.
+type ManueAgentDeps = ai.ManueAgentDeps
+
+type (
+	// ManueStats provides the statistics of games that ManueAgent uses.
+	ManueStats           = ai.ManueStats
+	WinScoreStats        = ai.WinScoreStats
+	RoundEndStats        = ai.RoundEndStats
+	DrawTenpaiStats      = ai.DrawTenpaiStats
+	TenpaiEstimatorStats = ai.TenpaiEstimatorStats
+	DealInStats          = ai.DealInStats
+	RankStats            = ai.RankStats
+	// DangerEstimator estimates the probability that a discard deals in.
+	DangerEstimator = ai.DangerEstimator
+	// TenpaiEstimator estimates the probability that a player is tenpai.
+	TenpaiEstimator = ai.TenpaiEstimator
+	// OpponentProfiles looks up the profile of a player by name.
+	OpponentProfiles = ai.OpponentProfiles
+	// OpponentProfile scales the estimates of a player.
+	OpponentProfile = ai.OpponentProfile
+	// DangerTreeNode is a node of the decision tree that
+	// NewDangerEstimator walks.
+	DangerTreeNode = ai.DangerTreeNode
+)
+
+// NewManueAgent returns a ManueAgent. seed seeds its random choices, so the
+// same seed and the same game give the same decisions.
+func NewManueAgent(seed uint64, deps ManueAgentDeps) (*ManueAgent, error) {
+	return ai.NewManueAgent(seed, deps)
+}
+
+// DefaultManueAgentDeps returns the statistics and the danger tree embedded
+// in the module, which the mjai-manue command uses by default.
+func DefaultManueAgentDeps() (ManueAgentDeps, error) {
+	stats, err := configs.LoadGameStats()
+	if err != nil {
+		return ManueAgentDeps{}, fmt.Errorf("failed to load game stats: %w", err)
+	}
+	dangerTree, err := configs.LoadDangerTree()
+	if err != nil {
+		return ManueAgentDeps{}, fmt.Errorf("failed to load danger tree: %w", err)
+	}
+	return ManueAgentDeps{
+		Stats:  stats,
+		Danger: ai.NewDangerEstimator(dangerTree),
+	}, nil
+}
+
+// DecisionTreeDangerEstimator estimates deal-in probabilities with a danger
+// decision tree.
+type DecisionTreeDangerEstimator = ai.DecisionTreeDangerEstimator
+
+// NewDangerEstimator returns the estimator that walks a danger decision
+// tree, such as the one of configs.LoadDangerTree.
+func NewDangerEstimator(root DangerTreeNode) *DecisionTreeDangerEstimator {
+	return ai.NewDangerEstimator(root)
+}
+
+// TsumogiriAgent always discards the drawn tile and passes otherwise.
+type TsumogiriAgent = ai.TsumogiriAgent
+
+func NewTsumogiriAgent() *TsumogiriAgent {
+	return ai.NewTsumogiriAgent()
+}
+
+// SafeAgent plays without evaluating the hand: it wins when it can, passes
+// on calls, and otherwise discards the tile least likely to deal in, keeping
+// the widest ukeire among equally safe tiles.
+type SafeAgent = ai.SafeAgent
+
+// NewSafeAgent returns a SafeAgent. Without a danger estimator it discards
+// the tile that keeps the widest ukeire.
+func NewSafeAgent(danger DangerEstimator) *SafeAgent {
+	return ai.NewSafeAgent(danger)
+}
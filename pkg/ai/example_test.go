@@ -0,0 +1,73 @@
+package ai_test
+
+import (
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/pkg/ai"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/round"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/pkg/mjai"
+)
+
+// cautiousDanger doubles the deal-in probabilities of another estimator.
+type cautiousDanger struct {
+	base ai.DangerEstimator
+}
+
+func (d cautiousDanger) EstimateDealInProb(state round.StateViewer, self, winner seat.Seat, discard tile.Tile) (float64, error) {
+	prob, err := d.base.EstimateDealInProb(state, self, winner, discard)
+	return min(2*prob, 1), err
+}
+
+// This example builds a ManueAgent with the embedded statistics and a custom
+// danger estimator, and asks it for a discard on a board described as a
+// snapshot.
+func ExampleNewManueAgent() {
+	deps, err := ai.DefaultManueAgentDeps()
+	if err != nil {
+		panic(err)
+	}
+	deps.Danger = cautiousDanger{base: deps.Danger}
+	agent, err := ai.NewManueAgent(0, deps)
+	if err != nil {
+		panic(err)
+	}
+
+	state, err := mjai.UnmarshalSnapshot([]byte(`{
+		"version": 1,
+		"bakaze": "E", "kyoku": 1, "honba": 0, "kyotaku": 0, "oya": 0, "chicha": 0,
+		"scores": [25000, 25000, 25000, 25000],
+		"dora_markers": ["1m"],
+		"num_left_tiles": 69,
+		"players": [
+			{"tehai": ["1m", "2m", "3m", "4m", "5m", "6m", "7m", "8m", "1p", "2p", "3p", "5p", "5p"], "tsumo": "N", "tsumogiri": []},
+			{"tehai": ["?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?"], "tsumogiri": []},
+			{"tehai": ["?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?"], "tsumogiri": []},
+			{"tehai": ["?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?", "?"], "tsumogiri": []}
+		],
+		"next_tsumo": 0,
+		"pending_dahai": 0,
+		"can_kyushukyuhai": [true, true, true, true],
+		"last_actor": 0
+	}`))
+	if err != nil {
+		panic(err)
+	}
+
+	decision, err := agent.Decide(ai.Request{Self: seat.MustSeat(0), Round: state})
+	if err != nil {
+		panic(err)
+	}
+	msg, err := mjai.ToMessage(decision.Action, "")
+	if err != nil {
+		panic(err)
+	}
+	line, err := mjai.MarshalMessage(msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(line))
+	// Output:
+	// {"type":"dahai","actor":0,"pai":"N","tsumogiri":true}
+}
@@ -0,0 +1,81 @@
+// Package action provides the actions a player can take, as listed by
+// [github.com/Apricot-S/mjai-manue-go/pkg/game/round.State.LegalActions] and
+// returned by agents. [github.com/Apricot-S/mjai-manue-go/pkg/mjai.ToMessage]
+// converts them to mjai messages.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package action
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/tile"
+)
+
+// Action is one of the action types of this package.
+type Action = action.Action
+
+type (
+	// Pass declines to call or win on a discard.
+	Pass = action.Pass
+	// Discard is dahai.
+	Discard = action.Discard
+	// Chii is chi.
+	Chii = action.Chii
+	// Pon is pon.
+	Pon = action.Pon
+	// CalledKan is daiminkan.
+	CalledKan = action.CalledKan
+	// ConcealedKan is ankan.
+	ConcealedKan = action.ConcealedKan
+	// PromotedKan is kakan.
+	PromotedKan = action.PromotedKan
+	// Riichi is reach.
+	Riichi = action.Riichi
+	// Win is hora.
+	Win = action.Win
+	// Kyushukyuhai is the abortive draw with nine kinds of terminals and
+	// honors.
+	Kyushukyuhai = action.Kyushukyuhai
+)
+
+func NewPass(actor seat.Seat) *Pass {
+	return action.NewPass(actor)
+}
+
+func NewDiscard(actor seat.Seat, discardedTile tile.Tile, tsumogiri bool) (*Discard, error) {
+	return action.NewDiscard(actor, discardedTile, tsumogiri)
+}
+
+func NewChii(actor, target seat.Seat, taken tile.Tile, consumed [2]tile.Tile) (*Chii, error) {
+	return action.NewChii(actor, target, taken, consumed)
+}
+
+func NewPon(actor, target seat.Seat, taken tile.Tile, consumed [2]tile.Tile) (*Pon, error) {
+	return action.NewPon(actor, target, taken, consumed)
+}
+
+func NewCalledKan(actor, target seat.Seat, taken tile.Tile, consumed [3]tile.Tile) (*CalledKan, error) {
+	return action.NewCalledKan(actor, target, taken, consumed)
+}
+
+func NewConcealedKan(actor seat.Seat, consumed [4]tile.Tile) (*ConcealedKan, error) {
+	return action.NewConcealedKan(actor, consumed)
+}
+
+func NewPromotedKan(actor seat.Seat, added tile.Tile, consumed [3]tile.Tile) (*PromotedKan, error) {
+	return action.NewPromotedKan(actor, added, consumed)
+}
+
+func NewRiichi(actor seat.Seat) *Riichi {
+	return action.NewRiichi(actor)
+}
+
+func NewWin(actor, target seat.Seat, winningTile tile.Tile) (*Win, error) {
+	return action.NewWin(actor, target, winningTile)
+}
+
+func NewKyushukyuhai(actor seat.Seat) *Kyushukyuhai {
+	return action.NewKyushukyuhai(actor)
+}
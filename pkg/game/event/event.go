@@ -0,0 +1,131 @@
+// Package event provides the events of a round, which
+// [github.com/Apricot-S/mjai-manue-go/pkg/game/round.State.Apply] applies.
+// [github.com/Apricot-S/mjai-manue-go/pkg/mjai.ParseEvent] converts mjai
+// messages to events.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package event
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/wind"
+)
+
+// Event is one of the event types of this package.
+type Event = event.Event
+
+type (
+	// StartRound is start_kyoku.
+	StartRound = event.StartRound
+	// Draw is tsumo.
+	Draw = event.Draw
+	// Discard is dahai.
+	Discard = event.Discard
+	// Chii is chi.
+	Chii = event.Chii
+	// Pon is pon.
+	Pon = event.Pon
+	// CalledKan is daiminkan.
+	CalledKan = event.CalledKan
+	// ConcealedKan is ankan.
+	ConcealedKan = event.ConcealedKan
+	// PromotedKan is kakan.
+	PromotedKan = event.PromotedKan
+	// Dora is dora, the reveal of a kan dora indicator.
+	Dora = event.Dora
+	// Riichi is reach, the declaration of riichi.
+	Riichi = event.Riichi
+	// RiichiAccepted is reach_accepted.
+	RiichiAccepted = event.RiichiAccepted
+	// Win is hora.
+	Win = event.Win
+	// DrawRound is ryukyoku.
+	DrawRound = event.DrawRound
+	// EndRound is end_kyoku.
+	EndRound = event.EndRound
+)
+
+// NewStartRound returns a round start. scores may be nil. Hidden hands hold
+// unknown tiles.
+func NewStartRound(
+	roundWind wind.Wind,
+	roundNumber int,
+	honba int,
+	riichiDeposit int,
+	dealer seat.Seat,
+	doraIndicator tile.Tile,
+	scores *[game.NumPlayers]int,
+	hands [game.NumPlayers][game.InitHandSize]tile.Tile,
+) *StartRound {
+	return event.NewStartRound(roundWind, roundNumber, honba, riichiDeposit, dealer, doraIndicator, scores, hands)
+}
+
+func NewDraw(actor seat.Seat, drawnTile tile.Tile) *Draw {
+	return event.NewDraw(actor, drawnTile)
+}
+
+func NewDiscard(actor seat.Seat, discardedTile tile.Tile, tsumogiri bool) *Discard {
+	return event.NewDiscard(actor, discardedTile, tsumogiri)
+}
+
+func NewChii(actor, target seat.Seat, taken tile.Tile, consumed [2]tile.Tile) *Chii {
+	return event.NewChii(actor, target, taken, consumed)
+}
+
+func NewPon(actor, target seat.Seat, taken tile.Tile, consumed [2]tile.Tile) *Pon {
+	return event.NewPon(actor, target, taken, consumed)
+}
+
+func NewCalledKan(actor, target seat.Seat, taken tile.Tile, consumed [3]tile.Tile) *CalledKan {
+	return event.NewCalledKan(actor, target, taken, consumed)
+}
+
+func NewConcealedKan(actor seat.Seat, consumed [4]tile.Tile) *ConcealedKan {
+	return event.NewConcealedKan(actor, consumed)
+}
+
+func NewPromotedKan(actor seat.Seat, added tile.Tile, consumed [3]tile.Tile) *PromotedKan {
+	return event.NewPromotedKan(actor, added, consumed)
+}
+
+func NewDora(indicator tile.Tile) *Dora {
+	return event.NewDora(indicator)
+}
+
+func NewRiichi(actor seat.Seat) *Riichi {
+	return event.NewRiichi(actor)
+}
+
+// NewRiichiAccepted returns an accepted riichi. deltas and scores may be nil.
+func NewRiichiAccepted(actor seat.Seat, deltas, scores *[game.NumPlayers]int) *RiichiAccepted {
+	return event.NewRiichiAccepted(actor, deltas, scores)
+}
+
+// NewWin returns a win. winningTile, deltas and scores may be nil.
+func NewWin(
+	actor, target seat.Seat,
+	winningTile *tile.Tile,
+	winningPoints int,
+	deltas *[game.NumPlayers]int,
+	scores *[game.NumPlayers]int,
+) *Win {
+	return event.NewWin(actor, target, winningTile, winningPoints, deltas, scores)
+}
+
+// NewDrawRound returns a draw. tenpais, deltas and scores may be nil.
+func NewDrawRound(
+	reason string,
+	tenpais *[game.NumPlayers]bool,
+	deltas *[game.NumPlayers]int,
+	scores *[game.NumPlayers]int,
+) *DrawRound {
+	return event.NewDrawRound(reason, tenpais, deltas, scores)
+}
+
+func NewEndRound() *EndRound {
+	return event.NewEndRound()
+}
@@ -0,0 +1,24 @@
+// Package game holds the constants shared by the packages of the game
+// engine:
+//
+//   - [github.com/Apricot-S/mjai-manue-go/pkg/game/tile], seat and wind model
+//     tiles, seats and winds.
+//   - [github.com/Apricot-S/mjai-manue-go/pkg/game/event] and action model
+//     what happens in a round and what a player can do.
+//   - [github.com/Apricot-S/mjai-manue-go/pkg/game/round] tracks the state of
+//     a round as events are applied and lists the legal actions.
+//   - [github.com/Apricot-S/mjai-manue-go/pkg/game/service] analyzes hands:
+//     shanten, ukeire, waits, yaku and points.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package game
+
+import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+
+const (
+	// NumPlayers is the number of players.
+	NumPlayers = common.NumPlayers
+	// InitHandSize is the number of tiles dealt to each player.
+	InitHandSize = common.InitHandSize
+)
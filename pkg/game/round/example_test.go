@@ -0,0 +1,63 @@
+package round_test
+
+import (
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/event"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/round"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/pkg/mjai"
+)
+
+// This example replays mjai messages and lists the legal actions of the
+// player whose hand is known.
+func Example() {
+	lines := []string{
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","4m","5m","6m","7m","8m","9m","1p","2p","3p","5p"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]],"scores":[25000,25000,25000,25000]}`,
+		`{"type":"tsumo","actor":0,"pai":"E"}`,
+		`{"type":"dahai","actor":0,"pai":"E","tsumogiri":true}`,
+		`{"type":"tsumo","actor":1,"pai":"?"}`,
+		`{"type":"dahai","actor":1,"pai":"5p","tsumogiri":true}`,
+	}
+
+	var state *round.State
+	for _, line := range lines {
+		msg, err := mjai.ParseMessage([]byte(line))
+		if err != nil {
+			panic(err)
+		}
+		ev, err := mjai.ParseEvent(msg)
+		if err != nil {
+			panic(err)
+		}
+		if start, ok := ev.(*event.StartRound); ok {
+			state, err = round.NewState(start, [4]int{25000, 25000, 25000, 25000})
+		} else {
+			err = state.Apply(ev)
+		}
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	fmt.Println("left tiles:", state.NumLeftTiles())
+	actions, err := state.LegalActions(seat.MustSeat(0))
+	if err != nil {
+		panic(err)
+	}
+	for _, a := range actions {
+		msg, err := mjai.ToMessage(a, "")
+		if err != nil {
+			panic(err)
+		}
+		line, err := mjai.MarshalMessage(msg)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(line))
+	}
+	// Output:
+	// left tiles: 68
+	// {"type":"hora","actor":0,"target":1,"pai":"5p"}
+	// {"type":"none","actor":0}
+}
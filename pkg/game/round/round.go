@@ -0,0 +1,90 @@
+// Package round tracks the state of a round. A State starts from a
+// start_kyoku event, applies the events that follow and checks them against
+// the rules, and lists the legal actions of each player whose hand is known.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package round
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/meld"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/event"
+)
+
+// State is the state of a round. It is not safe for concurrent use.
+type State = round.State
+
+type (
+	// RawStateViewer reads what the table shows.
+	RawStateViewer = round.RawStateViewer
+	// DerivedStateViewer reads what follows from the table, such as the
+	// doras and the tiles each player can see.
+	DerivedStateViewer = round.DerivedStateViewer
+	// StateViewer reads a round without changing it. Agents and danger
+	// estimators receive one.
+	StateViewer = round.StateViewer
+	// ActionOpportunityViewer lists the legal actions.
+	ActionOpportunityViewer = round.ActionOpportunityViewer
+	// ActionStateViewer is a StateViewer that also lists the legal actions.
+	ActionStateViewer = round.ActionStateViewer
+)
+
+type (
+	// PlayerViewer reads the hand, melds and river of a player.
+	PlayerViewer = player.PlayerViewer
+	// RiichiState is whether a player has declared riichi and whether it is
+	// accepted.
+	RiichiState = player.RiichiState
+	// Meld is a meld of a player.
+	Meld = meld.Meld
+	// OpenMeld is a meld called from another player.
+	OpenMeld = meld.OpenMeld
+)
+
+const (
+	NotRiichi      = player.NotRiichi
+	RiichiDeclared = player.RiichiDeclared
+	RiichiAccepted = player.RiichiAccepted
+)
+
+type (
+	// Snapshot is a plain description of a round state, taken with
+	// State.Snapshot. [github.com/Apricot-S/mjai-manue-go/pkg/mjai] reads
+	// and writes it as JSON.
+	Snapshot = round.Snapshot
+	// PlayerSnapshot is the part of a Snapshot of one player.
+	PlayerSnapshot   = player.Snapshot
+	ExtraSafeDiscard = round.ExtraSafeDiscard
+	// KanReplacement is the timing of the pending replacement tile draw
+	// after a kan.
+	KanReplacement = round.KanReplacement
+)
+
+const (
+	NoPendingReplacement  = round.NoPendingReplacement
+	ReplacementBeforeDora = round.ReplacementBeforeDora
+	ReplacementAfterDora  = round.ReplacementAfterDora
+)
+
+const (
+	// MaxNumDoraIndicators is the number of dora indicators after four kans.
+	MaxNumDoraIndicators = round.MaxNumDoraIndicators
+	// NumInitWall is the number of tiles left to draw after the deal.
+	NumInitWall = round.NumInitWall
+	// FinalTurn is the turn of the last draw, as returned by Turn.
+	FinalTurn = round.FinalTurn
+)
+
+// NewState starts a round. previousScores are the scores when ev does not
+// carry them.
+func NewState(ev *event.StartRound, previousScores [game.NumPlayers]int) (*State, error) {
+	return round.NewState(ev, previousScores)
+}
+
+// NewStateFromSnapshot restores a state, checking the invariants of a round.
+func NewStateFromSnapshot(snap *Snapshot) (*State, error) {
+	return round.NewStateFromSnapshot(snap)
+}
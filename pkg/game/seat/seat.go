@@ -0,0 +1,21 @@
+// Package seat provides the seats of the four players, numbered 0 to 3 from
+// the first dealer.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package seat
+
+import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+
+// Seat is the seat of a player.
+type Seat = seat.Seat
+
+// NewSeat returns the seat of index, which must be in [0, 3].
+func NewSeat(index int) (Seat, error) {
+	return seat.NewSeat(index)
+}
+
+// MustSeat is like NewSeat but panics on an invalid index.
+func MustSeat(index int) Seat {
+	return seat.MustSeat(index)
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/service"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/tile"
+)
+
+func hand(codes ...string) *service.VisibleHand {
+	tiles := make([]tile.Tile, len(codes))
+	for i, code := range codes {
+		tiles[i] = tile.MustTileFromCode(code)
+	}
+	return service.MustVisibleHand(tiles)
+}
+
+func ExampleShanten() {
+	h := hand("1m", "2m", "3m", "4m", "6m", "7m", "9m", "1p", "2p", "3p", "5p", "5p", "E")
+
+	fmt.Println(service.Shanten(h))
+	fmt.Println(service.Shanten(h, service.Engine(service.TableEngine)))
+	// Output:
+	// 1
+	// 1
+}
+
+func ExampleAnalyzeUkeire() {
+	h := hand("1m", "2m", "3m", "4m", "5m", "6m", "7m", "8m", "1p", "2p", "3p", "5p", "5p")
+
+	ukeire := service.AnalyzeUkeire(h, nil)
+	fmt.Println("shanten:", ukeire.Shanten)
+	for _, accepted := range ukeire.Accepted {
+		fmt.Println(accepted.Tile, accepted.NumLive)
+	}
+	fmt.Println("total:", ukeire.NumAccepted)
+	// Output:
+	// shanten: 0
+	// 3m 3
+	// 6m 3
+	// 9m 4
+	// total: 10
+}
+
+func ExampleAnalyzeDiscardUkeire() {
+	h := hand("1m", "2m", "3m", "4m", "5m", "6m", "7m", "8m", "1p", "2p", "3p", "5p", "5p", "N")
+
+	for _, d := range service.AnalyzeDiscardUkeire(h, nil) {
+		if d.Shanten == 0 {
+			fmt.Println("discard", d.Discard, "for", d.NumAccepted, "tiles")
+		}
+	}
+	// Output:
+	// discard N for 10 tiles
+}
+
+func ExampleWaitsFor() {
+	h := hand("1m", "1m", "1m", "2m", "3m", "4m", "5m", "6m", "7m", "8m", "9m", "9m", "9m")
+
+	waits := service.WaitsFor(h)
+	for id := range tile.NumTileType34 {
+		if t := tile.MustTileFromID(id); waits.Has(t) {
+			fmt.Print(t, " ")
+		}
+	}
+	fmt.Println()
+	// Output:
+	// 1m 2m 3m 4m 5m 6m 7m 8m 9m
+}
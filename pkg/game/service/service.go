@@ -0,0 +1,238 @@
+// Package service analyzes hands: shanten numbers and the nearest winning
+// hands, tile acceptance (ukeire), waits, winning forms, yaku and points.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package service
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player/hand"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service/block"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/round"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/tile"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/wind"
+)
+
+// VisibleHand is a hand of known tiles, without the melds. Its operations
+// return a new hand.
+type VisibleHand = hand.VisibleHand
+
+// TileCounts34 counts the tiles of each kind, with red fives counted as
+// fives.
+type TileCounts34 = hand.TileCounts34
+
+// NewVisibleHand returns the hand of tiles, which must be known and hold at
+// most one red five per suit.
+func NewVisibleHand(tiles []tile.Tile) (*VisibleHand, error) {
+	return hand.NewVisibleHand(tiles)
+}
+
+// MustVisibleHand is like NewVisibleHand but panics on invalid tiles.
+func MustVisibleHand(tiles []tile.Tile) *VisibleHand {
+	return hand.MustVisibleHand(tiles)
+}
+
+// Block is a pair, sequence, triplet or quad of a winning hand.
+type Block = block.Block
+
+// Goal is a nearest winning hand found by AnalyzeShanten.
+type Goal = service.Goal
+
+type (
+	// ShantenEngine selects how the regular form shanten number is
+	// calculated. Both engines give the same results.
+	ShantenEngine = service.ShantenEngine
+	// ShantenOption configures Shanten and AnalyzeShanten.
+	ShantenOption = service.ShantenOption
+)
+
+const (
+	// InfinityShanten is the shanten number beyond the upper bound.
+	InfinityShanten = service.InfinityShanten
+	// MaxShantenNumber is the default upper bound.
+	MaxShantenNumber = service.MaxShantenNumber
+	// SearchEngine searches the nearest winning hands by pruning DFS. It is
+	// the default.
+	SearchEngine = service.SearchEngine
+	// TableEngine looks the shanten number up in per-suit tables, built on
+	// first use.
+	TableEngine = service.TableEngine
+)
+
+// AllowedExtraTiles lets AnalyzeShanten list goals up to n shanten beyond
+// the nearest.
+func AllowedExtraTiles(n int) ShantenOption {
+	return service.AllowedExtraTiles(n)
+}
+
+// UpperBound sets the largest shanten number searched.
+func UpperBound(n int) ShantenOption {
+	return service.UpperBound(n)
+}
+
+// Engine selects the engine.
+func Engine(e ShantenEngine) ShantenOption {
+	return service.Engine(e)
+}
+
+// Shanten returns the regular form shanten number of h, or InfinityShanten
+// beyond the upper bound.
+func Shanten(h *VisibleHand, opts ...ShantenOption) int {
+	return service.Shanten(h, opts...)
+}
+
+// AnalyzeShanten returns the regular form shanten number of h and the
+// nearest winning hands.
+func AnalyzeShanten(h *VisibleHand, opts ...ShantenOption) (int, []Goal) {
+	return service.AnalyzeShanten(h, opts...)
+}
+
+// AnalyzeShantenChiitoitsu returns the seven pairs shanten number of h.
+func AnalyzeShantenChiitoitsu(h *VisibleHand) int {
+	return service.AnalyzeShantenChiitoitsu(h)
+}
+
+// AnalyzeShantenKokushimusou returns the thirteen orphans shanten number of
+// h.
+func AnalyzeShantenKokushimusou(h *VisibleHand) int {
+	return service.AnalyzeShantenKokushimusou(h)
+}
+
+type (
+	// HandForm is a bit set of winning forms.
+	HandForm = service.HandForm
+	// WaitShape is a bit set of wait shapes.
+	WaitShape = service.WaitShape
+	// AcceptedTile is a tile kind that lowers the shanten number.
+	AcceptedTile = service.AcceptedTile
+	// Improvement is a tile kind that widens the accepted tiles.
+	Improvement = service.Improvement
+	// Ukeire is the tile acceptance of a hand of 3n+1 tiles.
+	Ukeire = service.Ukeire
+	// DiscardUkeire is the tile acceptance after a discard from a hand of
+	// 3n+2 tiles.
+	DiscardUkeire = service.DiscardUkeire
+	// UkeireOption configures AnalyzeUkeire and AnalyzeDiscardUkeire.
+	UkeireOption = service.UkeireOption
+)
+
+const (
+	RegularForm      = service.RegularForm
+	ChiitoitsuForm   = service.ChiitoitsuForm
+	KokushimusouForm = service.KokushimusouForm
+	AllForms         = service.AllForms
+
+	RyanmenWait = service.RyanmenWait
+	KanchanWait = service.KanchanWait
+	PenchanWait = service.PenchanWait
+	ShanponWait = service.ShanponWait
+	TankiWait   = service.TankiWait
+)
+
+// Forms restricts the winning forms considered. All forms are considered by
+// default.
+func Forms(forms HandForm) UkeireOption {
+	return service.Forms(forms)
+}
+
+// IncludeImprovements adds the 2-step improvements, which is much slower.
+func IncludeImprovements() UkeireOption {
+	return service.IncludeImprovements()
+}
+
+// AnalyzeUkeire returns the tile acceptance of h. visibleTiles are the tiles
+// the player can see, including h, from which the live copies are counted.
+func AnalyzeUkeire(h *VisibleHand, visibleTiles tile.Tiles, opts ...UkeireOption) Ukeire {
+	return service.AnalyzeUkeire(h, visibleTiles, opts...)
+}
+
+// AnalyzeDiscardUkeire returns the tile acceptance after each discard from
+// h.
+func AnalyzeDiscardUkeire(h *VisibleHand, visibleTiles tile.Tiles, opts ...UkeireOption) []DiscardUkeire {
+	return service.AnalyzeDiscardUkeire(h, visibleTiles, opts...)
+}
+
+// WaitSet is a set of tile kinds.
+type WaitSet = service.WaitSet
+
+// WaitsFor returns the tile kinds that complete h.
+func WaitsFor(h *VisibleHand) WaitSet {
+	return service.WaitsFor(h)
+}
+
+// IsTenpaiGeneral reports whether h is one tile from a regular winning form.
+func IsTenpaiGeneral(h *VisibleHand) bool {
+	return service.IsTenpaiGeneral(h)
+}
+
+// IsTenpaiAll reports whether h is one tile from any winning form.
+func IsTenpaiAll(h *VisibleHand) bool {
+	return service.IsTenpaiAll(h)
+}
+
+// IsWinningForm reports whether h is in any winning form.
+func IsWinningForm(h *VisibleHand) bool {
+	return service.IsWinningForm(h)
+}
+
+func IsWinningFormGeneral(h *VisibleHand) bool {
+	return service.IsWinningFormGeneral(h)
+}
+
+func IsWinningFormChiitoitsu(h *VisibleHand) bool {
+	return service.IsWinningFormChiitoitsu(h)
+}
+
+func IsWinningFormKokushimusou(h *VisibleHand) bool {
+	return service.IsWinningFormKokushimusou(h)
+}
+
+// WinEvent is a circumstance of a win that gives a yaku.
+type WinEvent = service.WinEvent
+
+const (
+	NoEvent     = service.NoEvent
+	RobbingAKan = service.RobbingAKan
+	AfterAKan   = service.AfterAKan
+	LastTile    = service.LastTile
+)
+
+// CalculateFuHan returns the fu, the han and the han of each yaku of a
+// winning hand, split into handBlocks as in Goal.Blocks.
+func CalculateFuHan(
+	h *VisibleHand,
+	handBlocks []Block,
+	melds []round.Meld,
+	prevalentWind wind.Wind,
+	seatWind wind.Wind,
+	doraIndicators []tile.Tile,
+	riichi bool,
+) (fu int, han int, yakus map[string]int) {
+	return service.CalculateFuHan(h, handBlocks, melds, prevalentWind, seatWind, doraIndicators, riichi)
+}
+
+// Has1Han reports whether h, completed by winningTile, has a yaku.
+func Has1Han(
+	h *VisibleHand,
+	melds []round.Meld,
+	winningTile tile.Tile,
+	prevalentWind wind.Wind,
+	seatWind wind.Wind,
+	tsumo bool,
+	riichi bool,
+	event WinEvent,
+) bool {
+	return service.Has1Han(h, melds, winningTile, prevalentWind, seatWind, tsumo, riichi, event)
+}
+
+// RonPoints returns the points of a ron of fu and han.
+func RonPoints(fu, han int, isDealer bool) int {
+	return service.RonPoints(fu, han, isDealer)
+}
+
+// RyukyokuPoints returns the score changes of an exhaustive draw.
+func RyukyokuPoints(tenpais [game.NumPlayers]bool) [game.NumPlayers]int {
+	return service.RyukyokuPoints(tenpais)
+}
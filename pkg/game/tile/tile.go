@@ -0,0 +1,63 @@
+// Package tile provides mahjong tiles and their mjai codes, such as "5mr" for
+// the red five of characters.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package tile
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/notation"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// Tile is a tile, including the unknown tile "?" of a hidden hand.
+type Tile = tile.Tile
+
+// Tiles is a list of tiles.
+type Tiles = tile.Tiles
+
+const (
+	ManzuColor   = tile.ManzuColor
+	PinzuColor   = tile.PinzuColor
+	SouzuColor   = tile.SouzuColor
+	HonorsColor  = tile.HonorsColor
+	UnknownColor = tile.UnknownColor
+)
+
+const (
+	// NumTileType34 is the number of kinds of tiles without red fives.
+	NumTileType34 = tile.NumTileType34
+	// NumTileType37 is the number of kinds of tiles with red fives.
+	NumTileType37 = tile.NumTileType37
+	// NumTileType38 is NumTileType37 plus the unknown tile.
+	NumTileType38 = tile.NumTileType38
+)
+
+// NewTileFromCode returns the tile of an mjai code.
+func NewTileFromCode(code string) (Tile, error) {
+	return tile.NewTileFromCode(code)
+}
+
+// MustTileFromCode is like NewTileFromCode but panics on an invalid code.
+func MustTileFromCode(code string) Tile {
+	return tile.MustTileFromCode(code)
+}
+
+// MustTileFromID returns the tile of an ID in [0, NumTileType38), ordered as
+// the manzu, pinzu, souzu and honors, then the red fives and the unknown
+// tile. It panics on an invalid ID.
+func MustTileFromID(id int) Tile {
+	return tile.MustTileFromID(id)
+}
+
+// ParseNotation parses tiles in the mpsz notation of Tenhou, such as
+// "406m123p55z", where 0 is a red five, 1z to 7z are the honors from east to
+// red dragon, and "?" is the unknown tile.
+func ParseNotation(s string) (Tiles, error) {
+	return notation.ParseTiles(s)
+}
+
+// FormatNotation writes tiles in the mpsz notation, keeping their order.
+func FormatNotation(tiles []Tile) string {
+	return notation.FormatTiles(tiles)
+}
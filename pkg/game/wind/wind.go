@@ -0,0 +1,22 @@
+// Package wind provides the round and seat winds.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package wind
+
+import "github.com/Apricot-S/mjai-manue-go/internal/domain/game/wind"
+
+// Wind is a wind.
+type Wind = wind.Wind
+
+const (
+	East  = wind.East
+	South = wind.South
+	West  = wind.West
+	North = wind.North
+)
+
+// NewWind returns the wind of an mjai code: "E", "S", "W" or "N".
+func NewWind(w string) (Wind, error) {
+	return wind.NewWind(w)
+}
@@ -0,0 +1,43 @@
+package mjai_test
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/Apricot-S/mjai-manue-go/pkg/ai"
+	"github.com/Apricot-S/mjai-manue-go/pkg/mjai"
+)
+
+// This example feeds the messages of a session to a driver that plays a
+// tsumogiri agent, and prints its answers.
+func ExampleDriver() {
+	driver := mjai.NewDriver("tsumogiri", "default", 0, ai.NewTsumogiriAgent(), io.Discard)
+
+	for _, line := range []string{
+		`{"type":"hello","protocol":"mjsonp","protocol_version":3}`,
+		`{"type":"start_game","id":0,"names":["tsumogiri","b","c","d"]}`,
+		`{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","4m","5m","6m","7m","8m","1p","2p","3p","5p","5p"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]]}`,
+		`{"type":"tsumo","actor":0,"pai":"N"}`,
+	} {
+		msg, err := mjai.ParseMessage([]byte(line))
+		if err != nil {
+			panic(err)
+		}
+		reply, err := driver.Handle(msg)
+		if err != nil {
+			panic(err)
+		}
+		if reply == nil {
+			// The player has nothing to say. A session over TCP answers none.
+			continue
+		}
+		out, err := mjai.MarshalMessage(reply)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(out))
+	}
+	// Output:
+	// {"type":"join","name":"tsumogiri","room":"default"}
+	// {"type":"dahai","actor":0,"pai":"N","tsumogiri":true}
+}
@@ -0,0 +1,175 @@
+// Package mjai reads and writes mjai protocol messages, converts them to and
+// from the events and actions of the game engine, and runs an agent on an
+// mjai session.
+//
+// It is part of the Go API of mjai-manue-go, whose stability guarantees are
+// described under "Go API" in the README of the module.
+package mjai
+
+import (
+	"io"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/snapshot"
+	"github.com/Apricot-S/mjai-manue-go/pkg/ai"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/action"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/event"
+	"github.com/Apricot-S/mjai-manue-go/pkg/game/round"
+)
+
+// Message is a message from the server, one of the message types of this
+// package.
+type Message = inbound.Message
+
+type (
+	Hello         = inbound.Hello
+	StartGame     = inbound.StartGame
+	EndGame       = inbound.EndGame
+	Error         = inbound.Error
+	StartKyoku    = inbound.StartKyoku
+	Tsumo         = inbound.Tsumo
+	Dahai         = inbound.Dahai
+	Reach         = inbound.Reach
+	ReachAccepted = inbound.ReachAccepted
+	Pon           = inbound.Pon
+	Chi           = inbound.Chi
+	Ankan         = inbound.Ankan
+	Kakan         = inbound.Kakan
+	Daiminkan     = inbound.Daiminkan
+	Dora          = inbound.Dora
+	Hora          = inbound.Hora
+	Ryukyoku      = inbound.Ryukyoku
+	EndKyoku      = inbound.EndKyoku
+)
+
+// UnsupportedTypeError is the error of ParseMessage for a message type it
+// does not know.
+type UnsupportedTypeError = inbound.UnsupportedTypeError
+
+// PossibleAction is an entry of the possible_actions a server attaches to a
+// message.
+type PossibleAction = inbound.PossibleAction
+
+// ParseMessage decodes a line from the server.
+func ParseMessage(b []byte) (Message, error) {
+	return inbound.ParseMessage(b)
+}
+
+// ParseEvent converts a message within a round, from start_kyoku to
+// end_kyoku, to its event.
+func ParseEvent(msg Message) (event.Event, error) {
+	return inbound.ParseEvent(msg)
+}
+
+// PossibleActionsOf returns the possible_actions of msg. It returns false
+// when the message does not carry them, which differs from an empty list.
+func PossibleActionsOf(msg Message) ([]PossibleAction, bool) {
+	return inbound.PossibleActionsOf(msg)
+}
+
+// OutboundMessage is a message to the server.
+type OutboundMessage = outbound.Message
+
+// Meta is the evaluation of a decision that an action message can carry.
+type Meta = outbound.Meta
+
+// ToMessage converts an action to its message. log is the decision log the
+// message carries, which may be empty.
+func ToMessage(a action.Action, log string) (OutboundMessage, error) {
+	return outbound.ToMessage(a, log)
+}
+
+// ToMessageWithMeta is like ToMessage but attaches meta.
+func ToMessageWithMeta(a action.Action, log string, meta *Meta) (OutboundMessage, error) {
+	return outbound.ToMessageWithMeta(a, log, meta)
+}
+
+// NewJoin returns the join message that answers hello.
+func NewJoin(name string, room string) OutboundMessage {
+	return outbound.NewJoin(name, room)
+}
+
+// NewNone returns the none message that answers a message without acting.
+func NewNone() OutboundMessage {
+	return outbound.NewNone()
+}
+
+// MarshalMessage encodes a message to the server as a line without the
+// newline.
+func MarshalMessage(msg OutboundMessage) ([]byte, error) {
+	return outbound.MarshalMessage(msg)
+}
+
+// MarshalSnapshot encodes the state of a round as a snapshot in mjai tile
+// codes.
+func MarshalSnapshot(s *round.State) ([]byte, error) {
+	return snapshot.Marshal(s)
+}
+
+// UnmarshalSnapshot decodes a snapshot and restores the state it describes.
+func UnmarshalSnapshot(b []byte) (*round.State, error) {
+	return snapshot.Unmarshal(b)
+}
+
+// Driver plays an agent through the messages of one session: it answers
+// hello with join, tracks the game, and asks the agent when the player can
+// act. Its Handle returns a nil message when the player has nothing to say.
+type Driver = mjairuntime.Driver
+
+// NewDriver returns a driver that joins room as name. fallbackID is the seat
+// of the player until start_game tells it, and log receives the decision
+// logs and errors.
+func NewDriver(name string, room string, fallbackID int, agent ai.Agent, log io.Writer) *Driver {
+	return mjairuntime.NewDriver(name, room, fallbackID, agent, log)
+}
+
+type (
+	// StdioConfig configures RunStdio.
+	StdioConfig = mjairuntime.StdioConfig
+	// TCPConfig configures RunTCP.
+	TCPConfig = mjairuntime.TCPConfig
+	// PossibleActionsMode selects how the possible_actions of the server are
+	// cross-checked with the legal actions.
+	PossibleActionsMode = mjairuntime.PossibleActionsMode
+	// LogConfig selects the format, the channel levels, and the per-game
+	// files of the log of a session.
+	LogConfig = mjairuntime.LogConfig
+	// LogChannel is a stream of the log with a level of its own.
+	LogChannel = mjairuntime.LogChannel
+	// LogFormat is how log records are written.
+	LogFormat = mjairuntime.LogFormat
+)
+
+const (
+	PossibleActionsOff      = mjairuntime.PossibleActionsOff
+	PossibleActionsWarn     = mjairuntime.PossibleActionsWarn
+	PossibleActionsRestrict = mjairuntime.PossibleActionsRestrict
+	PossibleActionsStrict   = mjairuntime.PossibleActionsStrict
+)
+
+const (
+	LogProtocol = mjairuntime.LogProtocol
+	LogBoard    = mjairuntime.LogBoard
+	LogDecision = mjairuntime.LogDecision
+	LogError    = mjairuntime.LogError
+
+	LogFormatPlain = mjairuntime.LogFormatPlain
+	LogFormatText  = mjairuntime.LogFormatText
+	LogFormatJSON  = mjairuntime.LogFormatJSON
+
+	// LogLevelOff turns a channel off.
+	LogLevelOff = mjairuntime.LogLevelOff
+)
+
+// RunStdio plays a session over newline-delimited JSON on cfg.In and
+// cfg.Out.
+func RunStdio(cfg StdioConfig) error {
+	return mjairuntime.RunStdio(cfg)
+}
+
+// RunTCP plays a session on an mjsonp:// server.
+func RunTCP(cfg TCPConfig) error {
+	return mjairuntime.RunTCP(cfg)
+}
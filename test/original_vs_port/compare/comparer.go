@@ -281,6 +281,12 @@ func (fc *fileComparer) ReportRoundState(state round.BoardRenderer) error {
 	return nil
 }
 
+// ReportIncident is never called because the comparer does not set a
+// fallback on the bot.
+func (fc *fileComparer) ReportIncident(application.Incident) error {
+	return nil
+}
+
 func (fc *fileComparer) ReportDecisionTrace(trace string) error {
 	fc.lastTrace = trace
 	if trace != "" && fc.parent.log != nil {
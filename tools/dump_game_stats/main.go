@@ -13,7 +13,7 @@ import (
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
-	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
 	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
 	"github.com/schollz/progressbar/v3"
 )
@@ -81,11 +81,12 @@ func (c *winPointsCounter) onEvent(ev event.Event, state round.StateViewer) erro
 }
 
 type yamitenCounter struct {
-	stats map[string]configs.YamitenStat
+	archive *archive.Archive
+	stats   map[string]configs.YamitenStat
 }
 
-func newYamitenCounter() *yamitenCounter {
-	return &yamitenCounter{stats: make(map[string]configs.YamitenStat)}
+func newYamitenCounter(a *archive.Archive) *yamitenCounter {
+	return &yamitenCounter{archive: a, stats: make(map[string]configs.YamitenStat)}
 }
 
 func (c *yamitenCounter) onEvent(ev event.Event, state round.StateViewer) error {
@@ -100,9 +101,13 @@ func (c *yamitenCounter) onEvent(ev event.Event, state round.StateViewer) error
 	}
 
 	key := fmt.Sprintf("%d,%d", state.NumLeftTiles()/common.NumPlayers, len(actor.Melds()))
+	tenpai, err := isTenpai(c.archive, discard.Actor())
+	if err != nil {
+		return err
+	}
 	stat := c.stats[key]
 	stat.Total++
-	if isTenpai(actor) {
+	if tenpai {
 		stat.Tenpai++
 	}
 	c.stats[key] = stat
@@ -110,16 +115,18 @@ func (c *yamitenCounter) onEvent(ev event.Event, state round.StateViewer) error
 }
 
 type drawTenpaiCounter struct {
+	archive     *archive.Archive
 	stats       configs.RyukyokuTenpaiStat
 	tenpaiTurns [common.NumPlayers]*float64
 }
 
-func newDrawTenpaiCounter() *drawTenpaiCounter {
+func newDrawTenpaiCounter(a *archive.Archive) *drawTenpaiCounter {
 	turnDistribution := make(map[string]int)
 	for turn := 0.0; turn <= round.FinalTurn; turn += 0.25 {
 		turnDistribution[strconv.FormatFloat(turn, 'f', -1, 64)] = 0
 	}
 	return &drawTenpaiCounter{
+		archive: a,
 		stats: configs.RyukyokuTenpaiStat{
 			TenpaiTurnDistribution: turnDistribution,
 		},
@@ -132,7 +139,14 @@ func (c *drawTenpaiCounter) onEvent(ev event.Event, state round.StateViewer) err
 		c.tenpaiTurns = [common.NumPlayers]*float64{}
 	case *event.Discard:
 		actorIndex := ev.Actor().Index()
-		if c.tenpaiTurns[actorIndex] == nil && isTenpai(state.Player(ev.Actor())) {
+		if c.tenpaiTurns[actorIndex] != nil {
+			break
+		}
+		tenpai, err := isTenpai(c.archive, ev.Actor())
+		if err != nil {
+			return err
+		}
+		if tenpai {
 			c.tenpaiTurns[actorIndex] = new(state.Turn())
 		}
 	case *event.DrawRound:
@@ -153,11 +167,15 @@ func (c *drawTenpaiCounter) onEvent(ev event.Event, state round.StateViewer) err
 	return nil
 }
 
-func isTenpai(p player.PlayerViewer) bool {
-	hand, ok := p.Hand()
+// isTenpai reads the true hand of s from the omniscient archive.
+func isTenpai(a *archive.Archive, s seat.Seat) (bool, error) {
+	truth, ok := a.Truth(s)
+	if !ok {
+		return false, fmt.Errorf("hand of player %d is unknown", s.Index())
+	}
 	// Match the original dump_game_stats implementation: it intentionally
 	// does not count Chiitoitsu or Kokushi Musou tenpai here.
-	return ok && service.IsTenpaiGeneral(hand)
+	return truth.RegularShanten <= 0, nil
 }
 
 func run(patterns []string) (*configs.GameStats, error) {
@@ -169,14 +187,15 @@ func run(patterns []string) (*configs.GameStats, error) {
 		return nil, fmt.Errorf("no input files matched")
 	}
 
+	// Server logs show every hand, so tenpai is read from the real hands.
+	a := archive.NewArchive(archive.Omniscient())
 	basic := &basicCounter{}
 	winPoints := newWinPointsCounter()
-	yamiten := newYamitenCounter()
-	drawTenpai := newDrawTenpaiCounter()
+	yamiten := newYamitenCounter(a)
+	drawTenpai := newDrawTenpaiCounter(a)
 	counters := []counter{basic, winPoints, yamiten, drawTenpai}
 
 	bar := progressbar.Default(int64(len(paths)))
-	a := archive.NewArchive()
 	err = a.PlayPaths(paths, archive.Handlers{
 		OnMessage: func(msg inbound.Message) error {
 			if _, ok := msg.(*inbound.Error); ok {
@@ -4,6 +4,7 @@ import (
 	"encoding/json/v2"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Apricot-S/mjai-manue-go/configs"
@@ -92,6 +93,13 @@ func TestRunRyukyokuStats(t *testing.T) {
 	}
 }
 
+func TestRunRejectsHiddenHands(t *testing.T) {
+	hidden := strings.Replace(ryukyokuLog, `["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"]`, `["?","?","?","?","?","?","?","?","?","?","?","?","?"]`, 1)
+	if _, err := run([]string{writeLogFile(t, hidden)}); err == nil {
+		t.Fatal("run() succeeded unexpectedly")
+	}
+}
+
 func TestRunRejectsNoMatches(t *testing.T) {
 	if _, err := run([]string{filepath.Join(t.TempDir(), "*.mjson")}); err == nil {
 		t.Fatal("run() succeeded unexpectedly")
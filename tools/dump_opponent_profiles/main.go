@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json/v2"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Apricot-S/mjai-manue-go/configs"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+	"github.com/schollz/progressbar/v3"
+)
+
+const (
+	// priorTenpais is the number of expected tenpai discards that pull a
+	// tenpai scale toward 1, so that a player seen a few times stays close
+	// to the average player.
+	priorTenpais = 10.0
+	// priorWins pulls the win points scale toward 1 in the same way.
+	priorWins = 10.0
+)
+
+// yamitenKey groups non-riichi discards the same way as yamitenStats in
+// game_stats.json.
+type yamitenKey struct {
+	remainTurns int
+	numMelds    int
+}
+
+type yamitenCount struct {
+	total  int
+	tenpai int
+}
+
+type playerCounter struct {
+	numGames       int
+	yamiten        map[yamitenKey]yamitenCount
+	numWins        int
+	totalWinPoints int
+}
+
+type profileCounter struct {
+	archive *archive.Archive
+	names   [common.NumPlayers]string
+	players map[string]*playerCounter
+}
+
+func newProfileCounter(a *archive.Archive) *profileCounter {
+	return &profileCounter{archive: a, players: make(map[string]*playerCounter)}
+}
+
+func (c *profileCounter) player(playerID int) *playerCounter {
+	name := c.names[playerID]
+	p, ok := c.players[name]
+	if !ok {
+		p = &playerCounter{yamiten: make(map[yamitenKey]yamitenCount)}
+		c.players[name] = p
+	}
+	return p
+}
+
+func (c *profileCounter) onStartGame(names []string) error {
+	if len(names) != common.NumPlayers {
+		return fmt.Errorf("start_game must have %d player names, got %d", common.NumPlayers, len(names))
+	}
+	copy(c.names[:], names)
+	for playerID := range common.NumPlayers {
+		c.player(playerID).numGames++
+	}
+	return nil
+}
+
+func (c *profileCounter) onEvent(ev event.Event, state round.StateViewer) error {
+	switch ev := ev.(type) {
+	case *event.Discard:
+		actor := state.Player(ev.Actor())
+		if actor.RiichiState() != player.NotRiichi {
+			return nil
+		}
+		truth, ok := c.archive.Truth(ev.Actor())
+		if !ok {
+			return fmt.Errorf("hand of player %d is unknown", ev.Actor().Index())
+		}
+		p := c.player(ev.Actor().Index())
+		key := yamitenKey{remainTurns: state.NumLeftTiles() / common.NumPlayers, numMelds: len(actor.Melds())}
+		count := p.yamiten[key]
+		count.total++
+		// Match yamitenStats, which counts regular form tenpai only.
+		if truth.RegularShanten <= 0 {
+			count.tenpai++
+		}
+		p.yamiten[key] = count
+	case *event.Win:
+		p := c.player(ev.Actor().Index())
+		p.numWins++
+		p.totalWinPoints += ev.WinningPoints()
+	}
+	return nil
+}
+
+// profiles compares each player with all players of the logs. A tenpai scale
+// is the ratio of the tenpai discards of the player to those expected from
+// the average tenpai rate of each turn and number of melds.
+func (c *profileCounter) profiles() map[string]configs.OpponentProfile {
+	overall := make(map[yamitenKey]yamitenCount)
+	numWins, totalWinPoints := 0, 0
+	for _, p := range c.players {
+		for key, count := range p.yamiten {
+			sum := overall[key]
+			sum.total += count.total
+			sum.tenpai += count.tenpai
+			overall[key] = sum
+		}
+		numWins += p.numWins
+		totalWinPoints += p.totalWinPoints
+	}
+
+	profiles := make(map[string]configs.OpponentProfile, len(c.players))
+	for name, p := range c.players {
+		var observed, expected [2]float64 // closed, open
+		for key, count := range p.yamiten {
+			i := 0
+			if key.numMelds > 0 {
+				i = 1
+			}
+			sum := overall[key]
+			observed[i] += float64(count.tenpai)
+			expected[i] += float64(count.total) * float64(sum.tenpai) / float64(sum.total)
+		}
+
+		profile := configs.OpponentProfile{
+			NumGames:          p.numGames,
+			ClosedTenpaiScale: (observed[0] + priorTenpais) / (expected[0] + priorTenpais),
+			OpenTenpaiScale:   (observed[1] + priorTenpais) / (expected[1] + priorTenpais),
+			WinPointsScale:    1.0,
+		}
+		if numWins > 0 {
+			average := float64(totalWinPoints) / float64(numWins)
+			profile.WinPointsScale = (float64(p.totalWinPoints) + priorWins*average) / (float64(p.numWins) + priorWins) / average
+		}
+		profiles[name] = profile
+	}
+	return profiles
+}
+
+func run(patterns []string) (map[string]configs.OpponentProfile, error) {
+	paths, err := archive.GlobAll(patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no input files matched")
+	}
+
+	// Server logs show every hand, so tenpai is read from the real hands.
+	a := archive.NewArchive(archive.Omniscient())
+	counter := newProfileCounter(a)
+
+	bar := progressbar.Default(int64(len(paths)))
+	err = a.PlayPaths(paths, archive.Handlers{
+		OnMessage: func(msg inbound.Message) error {
+			switch msg := msg.(type) {
+			case *inbound.Error:
+				return fmt.Errorf("error in the log")
+			case *inbound.StartGame:
+				return counter.onStartGame(msg.Names)
+			}
+			return nil
+		},
+		OnEvent: func(ev event.Event, a *archive.Archive) error {
+			state, ok := a.StateViewer()
+			if !ok {
+				return nil
+			}
+			return counter.onEvent(ev, state)
+		},
+		OnFileDone: func(string) error {
+			return bar.Add(1)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counter.profiles(), nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintf(os.Stderr, "Usage: %s <LOG_GLOB_PATTERNS>...\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	output, err := run(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := json.MarshalWrite(os.Stdout, output, json.Deterministic(true)); err != nil {
+		log.Fatalf("failed to output result: %v", err)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const startKyoku = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"],["1m","1m","2m","2m","3m","3m","4m","4m","5m","5m","6m","6m","7m"],["1m","2m","3m","1p","2p","3p","1s","2s","3s","5m","5m","6m","7m"],["1p","1p","2p","2p","3p","3p","4p","4p","5p","5p","6p","6p","7p"]],"scores":[25000,25000,25000,25000]}
+`
+
+// discardLog has "a" discard noten and "b" discard tenpai at the same turn,
+// then "c" wins 4000 points.
+const discardLog = `{"type":"start_game","names":["a","b","c","d"]}
+` + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"8m"}
+{"type":"dahai","actor":1,"pai":"8m","tsumogiri":true}
+{"type":"tsumo","actor":2,"pai":"9m"}
+{"type":"hora","actor":2,"target":2,"hora_points":4000,"scores":[24000,24000,29000,23000]}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+
+// winLog has "a" win 8000 points.
+const winLog = `{"type":"start_game","names":["a","b","c","d"]}
+` + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"hora","actor":0,"target":0,"hora_points":8000,"scores":[33000,22000,22000,23000]}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+
+func TestRunBuildsProfiles(t *testing.T) {
+	got, err := run([]string{writeLogFile(t, "discard.mjson", discardLog), writeLogFile(t, "win.mjson", winLog)})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("profiles = %v, want 4 players", got)
+	}
+
+	// The average tenpai rate at the turn is 0.5, so "a" is expected to be
+	// tenpai 0.5 times and "b" is tenpai once.
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "a closed tenpai", got: got["a"].ClosedTenpaiScale, want: 10 / 10.5},
+		{name: "b closed tenpai", got: got["b"].ClosedTenpaiScale, want: 11 / 10.5},
+		{name: "a open tenpai", got: got["a"].OpenTenpaiScale, want: 1},
+		{name: "a win points", got: got["a"].WinPointsScale, want: (8000 + 10*6000) / 11.0 / 6000},
+		{name: "c win points", got: got["c"].WinPointsScale, want: (4000 + 10*6000) / 11.0 / 6000},
+		{name: "d win points", got: got["d"].WinPointsScale, want: 1},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-12 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if got["a"].NumGames != 2 {
+		t.Errorf("a NumGames = %d, want 2", got["a"].NumGames)
+	}
+}
+
+func TestRunRejectsHiddenHands(t *testing.T) {
+	hidden := strings.Replace(discardLog, `["1p","1p","2p","2p","3p","3p","4p","4p","5p","5p","6p","6p","7p"]`, `["?","?","?","?","?","?","?","?","?","?","?","?","?"]`, 1)
+	if _, err := run([]string{writeLogFile(t, "game.mjson", hidden)}); err == nil {
+		t.Fatal("run() succeeded unexpectedly")
+	}
+}
+
+func TestRunRejectsNoMatches(t *testing.T) {
+	if _, err := run([]string{filepath.Join(t.TempDir(), "*.mjson")}); err == nil {
+		t.Fatal("run() succeeded unexpectedly")
+	}
+}
+
+func writeLogFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	return path
+}
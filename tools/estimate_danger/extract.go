@@ -214,10 +214,7 @@ func (e *extractor) onDiscard(ev *event.Discard, state round.StateViewer) error
 	candidates := make([]CandidateInfo, 0, len(sceneCandidates))
 	for _, candidate := range sceneCandidates {
 		hit := e.waits.Has(candidate)
-		featureVector, err := scene.FeatureVector(candidate)
-		if err != nil {
-			return err
-		}
+		featureVector := scene.FeatureVector(candidate)
 		storedScene.Candidates = append(storedScene.Candidates, Candidate{
 			FeatureVector: featureVector,
 			Hit:           hit,
@@ -15,81 +15,49 @@ func mustTiles(codes ...string) tile.Tiles {
 	return tiles
 }
 
-func TestSceneEvaluateRubyOnlyUrasujiOf5(t *testing.T) {
+func TestSceneFeatureVectorRubyOnlyUrasujiOf5(t *testing.T) {
 	scene := NewSceneFromParams(nil, mustTiles("1p"), nil, nil, mustTiles("1p", "5s"), wind.East, wind.South)
 
-	got, err := scene.evaluate("urasuji_of_5", tile.MustTileFromCode("1s"))
-	if err != nil {
-		t.Fatalf("evaluate() error = %v", err)
-	}
-	if !got {
-		t.Errorf("evaluate(urasuji_of_5, 1s) = false, want true")
+	if !GetFeatureValue(scene.FeatureVector(tile.MustTileFromCode("1s")), "urasuji_of_5") {
+		t.Errorf("FeatureVector(1s) did not set urasuji_of_5")
 	}
 }
 
-func TestSceneEvaluateUrasujiOf5DoesNotMutatePreRiichiTiles(t *testing.T) {
+func TestSceneFeatureVectorUrasujiOf5DoesNotMutatePreRiichiTiles(t *testing.T) {
 	scene := NewSceneFromParams(nil, nil, nil, nil, mustTiles("1p", "5s"), wind.East, wind.South)
 
-	if _, err := scene.evaluate("urasuji_of_5", tile.MustTileFromCode("1s")); err != nil {
-		t.Fatalf("evaluate(urasuji_of_5) error = %v", err)
-	}
-	got, err := scene.evaluate("urasuji", tile.MustTileFromCode("2p"))
-	if err != nil {
-		t.Fatalf("evaluate(urasuji) error = %v", err)
-	}
-	if !got {
-		t.Errorf("evaluate(urasuji, 2p) after urasuji_of_5 = false, want true")
+	scene.FeatureVector(tile.MustTileFromCode("1s"))
+	if !GetFeatureValue(scene.FeatureVector(tile.MustTileFromCode("2p")), "urasuji") {
+		t.Errorf("FeatureVector(2p) after urasuji_of_5 did not set urasuji")
 	}
 }
 
-func TestSceneEvaluateSameTypeInPrereachMatchesRubyTool(t *testing.T) {
+// The features that the Ruby training tool defined differently follow the
+// bot, so a trained tree branches on what the bot evaluates.
+func TestSceneFeatureVectorSameTypeInPrereachMatchesBot(t *testing.T) {
 	scene := NewSceneFromParams(nil, nil, nil, nil, mustTiles("1p", "3p"), wind.East, wind.South)
 
-	got, err := scene.evaluate("same_type_in_prereach>=3", tile.MustTileFromCode("5p"))
-	if err != nil {
-		t.Fatalf("evaluate() error = %v", err)
-	}
-	if got {
-		t.Errorf("evaluate(same_type_in_prereach>=3) = true, want false")
+	if !GetFeatureValue(scene.FeatureVector(tile.MustTileFromCode("5p")), "same_type_in_prereach>=3") {
+		t.Errorf("FeatureVector(5p) did not set same_type_in_prereach>=3")
 	}
 }
 
-func TestSceneEvaluateInnerPreRiichiDiscardMatchesRubyTool(t *testing.T) {
+func TestSceneFeatureVectorInnerPreRiichiDiscardMatchesBot(t *testing.T) {
 	scene := NewSceneFromParams(nil, nil, nil, nil, mustTiles("6p", "7p"), wind.East, wind.South)
+	vector := scene.FeatureVector(tile.MustTileFromCode("5p"))
 
 	tests := []struct {
-		name    string
 		feature string
-		discard tile.Tile
 		want    bool
 	}{
-		{
-			name:    "one inner from five",
-			feature: "1_inner_prereach_sutehai",
-			discard: tile.MustTileFromCode("5p"),
-			want:    true,
-		},
-		{
-			name:    "two inner from five",
-			feature: "2_inner_prereach_sutehai",
-			discard: tile.MustTileFromCode("5p"),
-			want:    true,
-		},
-		{
-			name:    "two outer does not match center",
-			feature: "2_outer_prereach_sutehai",
-			discard: tile.MustTileFromCode("5p"),
-			want:    false,
-		},
+		{feature: "1_inner_prereach_sutehai", want: false},
+		{feature: "2_inner_prereach_sutehai", want: false},
+		{feature: "2_outer_prereach_sutehai", want: false},
 	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := scene.evaluate(tt.feature, tt.discard)
-			if err != nil {
-				t.Fatalf("evaluate() error = %v", err)
-			}
-			if got != tt.want {
-				t.Errorf("evaluate(%s, %s) = %v, want %v", tt.feature, tt.discard, got, tt.want)
+		t.Run(tt.feature, func(t *testing.T) {
+			if got := GetFeatureValue(vector, tt.feature); got != tt.want {
+				t.Errorf("GetFeatureValue(%s) = %v, want %v", tt.feature, got, tt.want)
 			}
 		})
 	}
@@ -98,14 +66,8 @@ func TestSceneEvaluateInnerPreRiichiDiscardMatchesRubyTool(t *testing.T) {
 func TestSceneFeatureVectorTreatsRedFiveAsNormalFive(t *testing.T) {
 	scene := NewSceneFromParams(mustTiles("5mr"), nil, nil, nil, nil, wind.East, wind.South)
 
-	redVector, err := scene.FeatureVector(tile.MustTileFromCode("5mr"))
-	if err != nil {
-		t.Fatalf("FeatureVector() error = %v", err)
-	}
-	normalVector, err := scene.FeatureVector(tile.MustTileFromCode("5m"))
-	if err != nil {
-		t.Fatalf("FeatureVector() error = %v", err)
-	}
+	redVector := scene.FeatureVector(tile.MustTileFromCode("5mr"))
+	normalVector := scene.FeatureVector(tile.MustTileFromCode("5m"))
 	if redVector.Cmp(normalVector) != 0 {
 		t.Errorf("FeatureVector(5mr) = %v, want %v", redVector, normalVector)
 	}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai/tenpaifeature"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+// sample is a discard made without riichi: the features of the discarder
+// after the discard and whether the discarder was tenpai.
+type sample struct {
+	features []float64
+	tenpai   bool
+}
+
+// yamitenKey groups samples the same way as the yamiten table of the bot.
+type yamitenKey struct {
+	remainTurns int
+	numMelds    int
+}
+
+type yamitenCount struct {
+	total  int
+	tenpai int
+}
+
+type extractor struct {
+	features []tenpaifeature.Feature
+	samples  []sample
+	yamiten  map[yamitenKey]yamitenCount
+	keys     []yamitenKey
+}
+
+func newExtractor(features []tenpaifeature.Feature) *extractor {
+	return &extractor{features: features, yamiten: make(map[yamitenKey]yamitenCount)}
+}
+
+func (x *extractor) onEvent(ev event.Event, a *archive.Archive) error {
+	discard, ok := ev.(*event.Discard)
+	if !ok {
+		return nil
+	}
+	state, ok := a.StateViewer()
+	if !ok {
+		return nil
+	}
+	actor := state.Player(discard.Actor())
+	if actor.RiichiState() != player.NotRiichi {
+		return nil
+	}
+	truth, ok := a.Truth(discard.Actor())
+	if !ok {
+		return fmt.Errorf("hand of player %d is unknown", discard.Actor().Index())
+	}
+
+	scene := tenpaifeature.NewScene(state, discard.Actor())
+	s := sample{features: make([]float64, len(x.features)), tenpai: truth.Shanten <= 0}
+	for i, f := range x.features {
+		s.features[i] = f.Eval(&scene)
+	}
+	x.samples = append(x.samples, s)
+
+	key := yamitenKey{remainTurns: state.NumLeftTiles() / common.NumPlayers, numMelds: len(actor.Melds())}
+	count := x.yamiten[key]
+	count.total++
+	if s.tenpai {
+		count.tenpai++
+	}
+	x.yamiten[key] = count
+	x.keys = append(x.keys, key)
+	return nil
+}
+
+// tableProbs returns the tenpai probability of each sample by the yamiten
+// table built from the same samples, the estimate the model replaces.
+func (x *extractor) tableProbs() []float64 {
+	probs := make([]float64, len(x.keys))
+	for i, key := range x.keys {
+		count := x.yamiten[key]
+		probs[i] = float64(count.tenpai) / float64(count.total)
+	}
+	return probs
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json/v2"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Apricot-S/mjai-manue-go/configs"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai/tenpaifeature"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+	"github.com/schollz/progressbar/v3"
+)
+
+// report compares the trained model with the yamiten table on the training
+// samples.
+type report struct {
+	numSamples     int
+	modelLogLoss   float64
+	tableLogLoss   float64
+	baseTenpaiRate float64
+}
+
+func run(patterns []string, opts trainOptions) (configs.TenpaiModelFile, report, error) {
+	paths, err := archive.GlobAll(patterns)
+	if err != nil {
+		return configs.TenpaiModelFile{}, report{}, err
+	}
+	if len(paths) == 0 {
+		return configs.TenpaiModelFile{}, report{}, fmt.Errorf("no input files matched")
+	}
+
+	names := tenpaifeature.DefaultNames()
+	features, err := tenpaifeature.ParseAll(names)
+	if err != nil {
+		return configs.TenpaiModelFile{}, report{}, err
+	}
+
+	// Server logs show every hand, so the labels are read from the real
+	// hands.
+	a := archive.NewArchive(archive.Omniscient())
+	x := newExtractor(features)
+
+	bar := progressbar.Default(int64(len(paths)))
+	err = a.PlayPaths(paths, archive.Handlers{
+		OnMessage: func(msg inbound.Message) error {
+			if _, ok := msg.(*inbound.Error); ok {
+				return fmt.Errorf("error in the log")
+			}
+			return nil
+		},
+		OnEvent: func(ev event.Event, a *archive.Archive) error {
+			return x.onEvent(ev, a)
+		},
+		OnFileDone: func(string) error {
+			return bar.Add(1)
+		},
+	})
+	if err != nil {
+		return configs.TenpaiModelFile{}, report{}, err
+	}
+	if len(x.samples) == 0 {
+		return configs.TenpaiModelFile{}, report{}, fmt.Errorf("no discards without riichi in the logs")
+	}
+
+	m := train(x.samples, len(features), opts)
+	file := configs.TenpaiModelFile{
+		NumSamples: len(x.samples),
+		Bias:       m.bias,
+		Weights:    make(map[string]float64, len(names)),
+	}
+	for i, name := range names {
+		file.Weights[name] = m.weights[i]
+	}
+
+	modelProbs := make([]float64, len(x.samples))
+	numTenpai := 0
+	for j, s := range x.samples {
+		modelProbs[j] = m.prob(s.features)
+		if s.tenpai {
+			numTenpai++
+		}
+	}
+	r := report{
+		numSamples:     len(x.samples),
+		modelLogLoss:   logLoss(x.samples, modelProbs),
+		tableLogLoss:   logLoss(x.samples, x.tableProbs()),
+		baseTenpaiRate: float64(numTenpai) / float64(len(x.samples)),
+	}
+	return file, r, nil
+}
+
+func main() {
+	fs := flag.NewFlagSet("estimate_tenpai", flag.ExitOnError)
+	opts := trainOptions{}
+	fs.IntVar(&opts.iterations, "iterations", 1000, "number of gradient descent iterations")
+	fs.Float64Var(&opts.learningRate, "learning_rate", 0.5, "gradient descent step size")
+	fs.Float64Var(&opts.l2, "l2", 1e-4, "L2 penalty on the standardized weights")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [OPTIONS]... <LOG_GLOB_PATTERNS>...\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	file, r, err := run(fs.Args(), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "samples: %d (tenpai %.3f)\nlog loss: model %.5f, yamiten table %.5f\n",
+		r.numSamples, r.baseTenpaiRate, r.modelLogLoss, r.tableLogLoss)
+	if err := json.MarshalWrite(os.Stdout, file, json.Deterministic(true)); err != nil {
+		log.Fatalf("failed to output result: %v", err)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/ai/tenpaifeature"
+)
+
+const startKyoku = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"],["1m","1m","2m","2m","3m","3m","4m","4m","5m","5m","6m","6m","7m"],["1m","2m","3m","1p","2p","3p","1s","2s","3s","5m","5m","6m","7m"],["1p","1p","2p","2p","3p","3p","4p","4p","5p","5p","6p","6p","7p"]],"scores":[25000,25000,25000,25000]}
+`
+
+// discardLog has player 0 discard noten, player 1 discard tenpai and player
+// 2 declare riichi, whose discard is not a sample.
+const discardLog = `{"type":"start_game","names":["a","b","c","d"]}
+` + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"8m"}
+{"type":"dahai","actor":1,"pai":"8m","tsumogiri":true}
+{"type":"tsumo","actor":2,"pai":"9p"}
+{"type":"reach","actor":2}
+{"type":"dahai","actor":2,"pai":"9p","tsumogiri":true}
+{"type":"reach_accepted","actor":2,"scores":[25000,25000,24000,25000]}
+{"type":"ryukyoku","tenpais":[false,true,true,true],"scores":[22000,26000,25000,26000]}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+
+func TestRunTrainsModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.mjson")
+	if err := os.WriteFile(path, []byte(discardLog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, r, err := run([]string{path}, trainOptions{iterations: 10, learningRate: 0.5})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if file.NumSamples != 2 || r.numSamples != 2 || r.baseTenpaiRate != 0.5 {
+		t.Errorf("samples = %d/%d with tenpai rate %v, want 2 samples with rate 0.5", file.NumSamples, r.numSamples, r.baseTenpaiRate)
+	}
+	if len(file.Weights) != len(tenpaifeature.DefaultNames()) {
+		t.Errorf("weights = %v, want every default feature", file.Weights)
+	}
+	// Both samples share the turn and the number of melds, so the table
+	// predicts the base rate.
+	if !almostEqual(r.tableLogLoss, -logHalf()) {
+		t.Errorf("table log loss = %v, want %v", r.tableLogLoss, -logHalf())
+	}
+	if _, err := ai.NewFeatureTenpaiEstimator(file.Bias, file.Weights); err != nil {
+		t.Errorf("the bot cannot load the model: %v", err)
+	}
+}
+
+func TestRunRejectsNoMatches(t *testing.T) {
+	if _, _, err := run([]string{filepath.Join(t.TempDir(), "*.mjson")}, trainOptions{}); err == nil {
+		t.Fatal("run() succeeded unexpectedly")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"math"
+)
+
+type trainOptions struct {
+	iterations   int
+	learningRate float64
+	// l2 is the strength of the L2 penalty on the standardized weights.
+	l2 float64
+}
+
+// model is a logistic regression on raw feature values.
+type model struct {
+	bias    float64
+	weights []float64
+}
+
+func (m model) prob(features []float64) float64 {
+	z := m.bias
+	for i, v := range features {
+		z += m.weights[i] * v
+	}
+	return sigmoid(z)
+}
+
+func sigmoid(z float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-z))
+}
+
+// train fits a logistic regression by batch gradient descent. Features are
+// standardized during training so that one learning rate suits all of them,
+// and the weights are converted back to raw feature values afterwards. A
+// feature that never varies gets weight 0.
+func train(samples []sample, numFeatures int, opts trainOptions) model {
+	mean := make([]float64, numFeatures)
+	scale := make([]float64, numFeatures)
+	for _, s := range samples {
+		for i, v := range s.features {
+			mean[i] += v
+		}
+	}
+	n := float64(len(samples))
+	for i := range mean {
+		mean[i] /= n
+	}
+	for _, s := range samples {
+		for i, v := range s.features {
+			scale[i] += (v - mean[i]) * (v - mean[i])
+		}
+	}
+	for i := range scale {
+		scale[i] = math.Sqrt(scale[i] / n)
+	}
+
+	standardized := make([][]float64, len(samples))
+	for j, s := range samples {
+		standardized[j] = make([]float64, numFeatures)
+		for i, v := range s.features {
+			if scale[i] > 0 {
+				standardized[j][i] = (v - mean[i]) / scale[i]
+			}
+		}
+	}
+
+	m := model{weights: make([]float64, numFeatures)}
+	gradient := make([]float64, numFeatures)
+	for range opts.iterations {
+		biasGradient := 0.0
+		clear(gradient)
+		for j, s := range samples {
+			residual := m.prob(standardized[j])
+			if s.tenpai {
+				residual -= 1
+			}
+			biasGradient += residual
+			for i, v := range standardized[j] {
+				gradient[i] += residual * v
+			}
+		}
+		m.bias -= opts.learningRate * biasGradient / n
+		for i := range m.weights {
+			m.weights[i] -= opts.learningRate * (gradient[i]/n + opts.l2*m.weights[i])
+		}
+	}
+
+	raw := model{bias: m.bias, weights: make([]float64, numFeatures)}
+	for i, w := range m.weights {
+		if scale[i] == 0 {
+			continue
+		}
+		raw.weights[i] = w / scale[i]
+		raw.bias -= w * mean[i] / scale[i]
+	}
+	return raw
+}
+
+// logLoss returns the average negative log likelihood of the labels of
+// samples under probs.
+func logLoss(samples []sample, probs []float64) float64 {
+	const eps = 1e-12
+	sum := 0.0
+	for j, s := range samples {
+		p := min(max(probs[j], eps), 1-eps)
+		if s.tenpai {
+			sum -= math.Log(p)
+		} else {
+			sum -= math.Log(1 - p)
+		}
+	}
+	return sum / float64(len(samples))
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func logHalf() float64 {
+	return math.Log(0.5)
+}
+
+func TestTrainLearnsSignal(t *testing.T) {
+	// The first feature raises the tenpai rate from 0.25 to 0.75 and the
+	// second never varies.
+	var samples []sample
+	for _, tenpai := range []bool{true, false, false, false} {
+		samples = append(samples, sample{features: []float64{0, 3}, tenpai: tenpai})
+	}
+	for _, tenpai := range []bool{true, true, true, false} {
+		samples = append(samples, sample{features: []float64{1, 3}, tenpai: tenpai})
+	}
+
+	m := train(samples, 2, trainOptions{iterations: 2000, learningRate: 0.5})
+	if m.weights[1] != 0 {
+		t.Errorf("weight of a constant feature = %v, want 0", m.weights[1])
+	}
+	if got := m.prob([]float64{0, 3}); math.Abs(got-0.25) > 1e-3 {
+		t.Errorf("prob without the feature = %v, want 0.25", got)
+	}
+	if got := m.prob([]float64{1, 3}); math.Abs(got-0.75) > 1e-3 {
+		t.Errorf("prob with the feature = %v, want 0.75", got)
+	}
+}
+
+func TestLogLoss(t *testing.T) {
+	samples := []sample{{tenpai: true}, {tenpai: false}}
+	if got := logLoss(samples, []float64{0.5, 0.5}); !almostEqual(got, -logHalf()) {
+		t.Errorf("logLoss() = %v, want %v", got, -logHalf())
+	}
+	if got := logLoss(samples, []float64{1, 0}); got > 1e-9 {
+		t.Errorf("logLoss() of a perfect prediction = %v, want 0", got)
+	}
+}
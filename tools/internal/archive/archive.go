@@ -9,6 +9,7 @@ import (
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
 )
 
 const InitialScore = 25000
@@ -21,12 +22,27 @@ type Handlers struct {
 }
 
 type Archive struct {
-	state  *round.State
-	scores [common.NumPlayers]int
+	state      *round.State
+	scores     [common.NumPlayers]int
+	omniscient bool
+	truths     [common.NumPlayers]*PlayerTruth
 }
 
-func NewArchive() *Archive {
+type archiveOption func(*Archive)
+
+// Omniscient requires every hand to be known, as in logs written by a game
+// server, and enables Truth.
+func Omniscient() archiveOption {
+	return func(a *Archive) {
+		a.omniscient = true
+	}
+}
+
+func NewArchive(opts ...archiveOption) *Archive {
 	a := &Archive{}
+	for _, opt := range opts {
+		opt(a)
+	}
 	a.resetScores()
 	return a
 }
@@ -46,6 +62,23 @@ func (a *Archive) Scores() [common.NumPlayers]int {
 	return a.scores
 }
 
+// Truth returns the true state of the hand of s after the current event. It
+// returns false unless the archive is omniscient and a round is in progress.
+func (a *Archive) Truth(s seat.Seat) (PlayerTruth, bool) {
+	if !a.omniscient || a.state == nil {
+		return PlayerTruth{}, false
+	}
+	if cached := a.truths[s.Index()]; cached != nil {
+		return *cached, true
+	}
+	truth, ok := newPlayerTruth(a.state.Player(s))
+	if !ok {
+		return PlayerTruth{}, false
+	}
+	a.truths[s.Index()] = &truth
+	return truth, true
+}
+
 func (a *Archive) PlayPaths(paths []string, h Handlers) error {
 	for _, p := range paths {
 		if err := a.playFile(p, h); err != nil {
@@ -61,7 +94,7 @@ func (a *Archive) PlayPaths(paths []string, h Handlers) error {
 }
 
 func (a *Archive) playFile(path string, h Handlers) error {
-	reader, err := openMaybeGzip(path)
+	reader, err := Open(path)
 	if err != nil {
 		return err
 	}
@@ -137,12 +170,20 @@ func (a *Archive) handleLifecycleMessage(msg inbound.Message) {
 }
 
 func (a *Archive) applyEvent(ev event.Event) error {
+	a.truths = [common.NumPlayers]*PlayerTruth{}
 	switch ev := ev.(type) {
 	case *event.StartRound:
 		state, err := round.NewState(ev, a.scores)
 		if err != nil {
 			return fmt.Errorf("failed to start round: %w", err)
 		}
+		if a.omniscient {
+			for i := range common.NumPlayers {
+				if _, ok := state.Player(seat.MustSeat(i)).Hand(); !ok {
+					return fmt.Errorf("omniscient replay needs every hand: hand of player %d is hidden", i)
+				}
+			}
+		}
 		a.state = state
 		a.scores = state.Scores()
 		return nil
@@ -2,12 +2,17 @@ package archive
 
 import (
 	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
 	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
 )
 
 const sampleLog = `{"type":"start_game","names":["a","b","c","d"]}
@@ -102,6 +107,45 @@ func TestArchivePlayRejectsEmptyLine(t *testing.T) {
 	}
 }
 
+func TestCreateRoundTripsThroughOpen(t *testing.T) {
+	for _, name := range []string{"game.mjson", "game.mjson.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			w, err := Create(path)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if _, err := w.Write([]byte(sampleLog)); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", path, err)
+			}
+			if compressed := !strings.HasPrefix(string(raw), "{"); compressed != strings.HasSuffix(name, ".gz") {
+				t.Errorf("compressed = %v for %s", compressed, name)
+			}
+
+			r, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			defer r.Close()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != sampleLog {
+				t.Errorf("read %q, want %q", got, sampleLog)
+			}
+		})
+	}
+}
+
 func TestGlobAll(t *testing.T) {
 	dir := t.TempDir()
 	writeTempFileAt(t, filepath.Join(dir, "a.mjson"), "{}\n")
@@ -163,3 +207,83 @@ func writeTempGzipFile(t *testing.T, name string, content string) string {
 	}
 	return path
 }
+
+func TestArchiveOmniscientTruth(t *testing.T) {
+	const log = `{"type":"start_game","names":["a","b","c","d"]}
+{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","1p","2p","3p","1s","2s","3s","5m","5m","6m","7m"],["1m","1m","2m","2m","3m","3m","4m","4m","5m","5m","6m","6m","7m"],["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"],["1p","1p","3p","3p","5p","5p","7p","7p","9p","9p","E","E","S"]],"scores":[25000,25000,25000,25000]}
+{"type":"tsumo","actor":0,"pai":"8m"}
+{"type":"dahai","actor":0,"pai":"8m","tsumogiri":true}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+	path := writeTempFile(t, "omniscient.mjson", log)
+	archive := NewArchive(Omniscient())
+
+	var truths [4]PlayerTruth
+	err := archive.PlayPaths([]string{path}, Handlers{
+		OnEvent: func(ev event.Event, archive *Archive) error {
+			if _, ok := ev.(*event.Discard); !ok {
+				return nil
+			}
+			for i := range truths {
+				truth, ok := archive.Truth(seat.MustSeat(i))
+				if !ok {
+					t.Fatalf("Truth(%d) missing after discard", i)
+				}
+				truths[i] = truth
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Archive.PlayPaths() error = %v", err)
+	}
+
+	if got := truths[0]; !got.IsTenpai() || !got.Furiten || !slices.Equal(got.Waits, mustTiles("5m", "8m")) {
+		t.Errorf("truth of player 0 = %+v, want furiten tenpai on 5m and 8m", got)
+	}
+	if got := truths[2]; got.IsTenpai() || got.Furiten || len(got.Waits) != 0 {
+		t.Errorf("truth of player 2 = %+v, want not tenpai", got)
+	}
+	if got := truths[3]; got.Shanten != 0 || got.RegularShanten <= 0 {
+		t.Errorf("truth of player 3 = %+v, want Chiitoitsu tenpai only", got)
+	}
+}
+
+func TestArchiveOmniscientRejectsHiddenHand(t *testing.T) {
+	const log = `{"type":"start_game","names":["a","b","c","d"]}
+{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","1p","2p","3p","1s","2s","3s","5m","5m","6m","7m"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"],["1p","1p","2p","2p","3p","3p","4p","4p","5p","5p","6p","6p","7p"]],"scores":[25000,25000,25000,25000]}
+`
+	path := writeTempFile(t, "hidden.mjson", log)
+
+	err := NewArchive(Omniscient()).PlayPaths([]string{path}, Handlers{})
+	if err == nil || !strings.Contains(err.Error(), "hand of player 1 is hidden") {
+		t.Errorf("Archive.PlayPaths() error = %v, want hidden hand error", err)
+	}
+	if err := NewArchive().PlayPaths([]string{path}, Handlers{}); err != nil {
+		t.Errorf("Archive.PlayPaths() without Omniscient error = %v", err)
+	}
+}
+
+func TestArchiveTruthRequiresOmniscient(t *testing.T) {
+	path := writeTempFile(t, "sample.mjson", sampleLog)
+	err := NewArchive().PlayPaths([]string{path}, Handlers{
+		OnEvent: func(_ event.Event, archive *Archive) error {
+			if _, ok := archive.Truth(seat.MustSeat(0)); ok {
+				t.Error("Truth() exists without Omniscient")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Archive.PlayPaths() error = %v", err)
+	}
+}
+
+func mustTiles(codes ...string) tile.Tiles {
+	tiles := make(tile.Tiles, len(codes))
+	for i, code := range codes {
+		tiles[i] = tile.MustTileFromCode(code)
+	}
+	return tiles
+}
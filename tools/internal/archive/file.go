@@ -8,7 +8,8 @@ import (
 	"path/filepath"
 )
 
-func openMaybeGzip(path string) (io.ReadCloser, error) {
+// Open opens the log at path, decompressing it when the name ends in .gz.
+func Open(path string) (io.ReadCloser, error) {
 	file, err := os.Open(filepath.Clean(path))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open %s: %w", path, err)
@@ -40,3 +41,31 @@ func (g *gzipReadCloser) Close() error {
 	}
 	return err2
 }
+
+// Create creates the log at path, compressing it when the name ends in .gz.
+func Create(path string) (io.WriteCloser, error) {
+	file, err := os.Create(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if filepath.Ext(path) != ".gz" {
+		return file, nil
+	}
+
+	return &gzipWriteCloser{Writer: gzip.NewWriter(file), file: file}, nil
+}
+
+type gzipWriteCloser struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipWriteCloser) Close() error {
+	err1 := g.Writer.Close()
+	err2 := g.file.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
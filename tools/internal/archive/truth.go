@@ -0,0 +1,48 @@
+package archive
+
+import (
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/player"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round/service"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+// PlayerTruth is the true state of a player's hand, which only a log with
+// every hand reveals. It describes the hand without the drawn tile.
+type PlayerTruth struct {
+	// Shanten is the minimum shanten number over all winning forms.
+	Shanten int
+	// RegularShanten is the shanten number of the regular form only, which
+	// ignores Chiitoitsu and Kokushi Musou.
+	RegularShanten int
+	// Waits lists the winning tile kinds in tile ID order. It is empty unless
+	// the hand is tenpai.
+	Waits tile.Tiles
+	// Furiten reports whether the player cannot ron.
+	Furiten bool
+}
+
+func (t PlayerTruth) IsTenpai() bool {
+	return t.Shanten <= 0
+}
+
+func newPlayerTruth(p player.PlayerViewer) (PlayerTruth, bool) {
+	h, ok := p.Hand()
+	if !ok {
+		return PlayerTruth{}, false
+	}
+
+	regular, _ := service.AnalyzeShanten(h)
+	waitSet := service.WaitsFor(h)
+	var waits tile.Tiles
+	for id := range tile.NumTileType34 {
+		if t := tile.MustTileFromID(id); waitSet.Has(t) {
+			waits = append(waits, t)
+		}
+	}
+	return PlayerTruth{
+		Shanten:        min(regular, service.AnalyzeShantenChiitoitsu(h), service.AnalyzeShantenKokushimusou(h)),
+		RegularShanten: regular,
+		Waits:          waits,
+		Furiten:        p.IsFuriten(),
+	}, true
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/tile"
+)
+
+const (
+	numCopies    = 4
+	riichiPoints = 1000
+)
+
+// checkAction reports ev when it is an action that is not among the legal
+// actions of its actor. Actions of hidden hands are not checked.
+func (l *fileLinter) checkAction(ev event.Event) {
+	actor, ok := actionActor(ev)
+	if !ok {
+		return
+	}
+	legal := l.legal[actor.Index()]
+	if legal == nil {
+		return
+	}
+	if !slices.ContainsFunc(legal, func(a action.Action) bool { return matchesAction(ev, a) }) {
+		l.report(KindIllegalAction, fmt.Sprintf("player %d cannot take this action", actor.Index()))
+	}
+}
+
+func actionActor(ev event.Event) (seat.Seat, bool) {
+	switch ev := ev.(type) {
+	case *event.Discard:
+		return ev.Actor(), true
+	case *event.Chii:
+		return ev.Actor(), true
+	case *event.Pon:
+		return ev.Actor(), true
+	case *event.CalledKan:
+		return ev.Actor(), true
+	case *event.ConcealedKan:
+		return ev.Actor(), true
+	case *event.PromotedKan:
+		return ev.Actor(), true
+	case *event.Riichi:
+		return ev.Actor(), true
+	case *event.Win:
+		return ev.Actor(), true
+	default:
+		return seat.Seat{}, false
+	}
+}
+
+// matchesAction reports whether ev takes the action a. Discards match by
+// tile alone, since the tsumogiri flag is checked when the event is applied.
+func matchesAction(ev event.Event, a action.Action) bool {
+	switch ev := ev.(type) {
+	case *event.Discard:
+		d, ok := a.(*action.Discard)
+		return ok && d.Tile() == ev.Tile()
+	case *event.Chii:
+		c, ok := a.(*action.Chii)
+		if !ok {
+			return false
+		}
+		want, got := c.Consumed(), ev.Consumed()
+		return c.Target() == ev.Target() && c.Taken() == ev.Taken() && sameTiles(want[:], got[:])
+	case *event.Pon:
+		p, ok := a.(*action.Pon)
+		if !ok {
+			return false
+		}
+		want, got := p.Consumed(), ev.Consumed()
+		return p.Target() == ev.Target() && p.Taken() == ev.Taken() && sameTiles(want[:], got[:])
+	case *event.CalledKan:
+		k, ok := a.(*action.CalledKan)
+		if !ok {
+			return false
+		}
+		want, got := k.Consumed(), ev.Consumed()
+		return k.Target() == ev.Target() && k.Taken() == ev.Taken() && sameTiles(want[:], got[:])
+	case *event.ConcealedKan:
+		k, ok := a.(*action.ConcealedKan)
+		if !ok {
+			return false
+		}
+		want, got := k.Consumed(), ev.Consumed()
+		return sameTiles(want[:], got[:])
+	case *event.PromotedKan:
+		k, ok := a.(*action.PromotedKan)
+		if !ok {
+			return false
+		}
+		want, got := k.Consumed(), ev.Consumed()
+		return k.Added() == ev.Added() && sameTiles(want[:], got[:])
+	case *event.Riichi:
+		_, ok := a.(*action.Riichi)
+		return ok
+	case *event.Win:
+		w, ok := a.(*action.Win)
+		return ok && w.Target() == ev.Target() && (ev.WinningTile() == nil || w.WinningTile() == *ev.WinningTile())
+	default:
+		return false
+	}
+}
+
+// sameTiles reports whether a and b hold the same tiles in any order.
+func sameTiles(a, b []tile.Tile) bool {
+	sortedA, sortedB := tile.Tiles(slices.Clone(a)), tile.Tiles(slices.Clone(b))
+	sortedA.Sort()
+	sortedB.Sort()
+	return slices.Equal(sortedA, sortedB)
+}
+
+// checkScoreUpdate reports a win, draw or accepted riichi whose score
+// changes do not add up: the scores must equal the scores before plus the
+// deltas, and the deltas must sum to the riichi deposits the first winner
+// collects, to zero on a draw, and to the deposit of the riichi.
+func (l *fileLinter) checkScoreUpdate(ev event.Event) {
+	var deltas, scores *[common.NumPlayers]int
+	var want int
+	switch ev := ev.(type) {
+	case *event.Win:
+		deltas, scores = ev.Deltas(), ev.Scores()
+		if !l.depositCollected {
+			want = l.state.RiichiDeposit() * riichiPoints
+		}
+	case *event.DrawRound:
+		deltas, scores = ev.Deltas(), ev.Scores()
+	case *event.RiichiAccepted:
+		deltas, scores = ev.Deltas(), ev.Scores()
+		want = -riichiPoints
+	default:
+		return
+	}
+
+	before := l.state.Scores()
+	if deltas != nil && scores != nil {
+		for i := range common.NumPlayers {
+			if before[i]+deltas[i] != scores[i] {
+				l.report(KindScore, fmt.Sprintf("scores %v are not the scores %v plus the deltas %v", *scores, before, *deltas))
+				return
+			}
+		}
+	}
+	if deltas == nil {
+		if scores == nil {
+			return
+		}
+		deltas = new([common.NumPlayers]int)
+		for i := range common.NumPlayers {
+			deltas[i] = scores[i] - before[i]
+		}
+	}
+	sum := 0
+	for _, delta := range deltas {
+		sum += delta
+	}
+	if sum != want {
+		l.report(KindScore, fmt.Sprintf("deltas %v sum to %d, want %d", *deltas, sum, want))
+	}
+}
+
+// checkTileCounts reports the first point in a round where more than four
+// copies of a tile, or more than one copy of a red five, are known.
+func (l *fileLinter) checkTileCounts() {
+	if l.tileCountReported {
+		return
+	}
+	var counts [tile.NumTileType34]int
+	var redCounts [tile.NumTileType37 - tile.NumTileType34]int
+	count := func(ts []tile.Tile) {
+		for _, t := range ts {
+			if t.IsUnknown() {
+				continue
+			}
+			counts[t.RemoveRed().ID()]++
+			if t.IsRed() {
+				redCounts[t.ID()-tile.NumTileType34]++
+			}
+		}
+	}
+
+	count(l.state.DoraIndicators())
+	for i := range common.NumPlayers {
+		p := l.state.Player(seat.MustSeat(i))
+		count(p.HandTiles())
+		if drawn := p.DrawnTile(); drawn != nil {
+			count([]tile.Tile{*drawn})
+		}
+		for _, m := range p.Melds() {
+			count(m.ToTiles())
+		}
+		count(p.River())
+	}
+
+	for id, n := range counts {
+		if n > numCopies {
+			l.report(KindTileCount, fmt.Sprintf("%d copies of %s", n, tile.MustTileFromID(id)))
+			l.tileCountReported = true
+			return
+		}
+	}
+	for i, n := range redCounts {
+		if n > 1 {
+			l.report(KindTileCount, fmt.Sprintf("%d copies of %s", n, tile.MustTileFromID(tile.NumTileType34+i)))
+			l.tileCountReported = true
+			return
+		}
+	}
+}
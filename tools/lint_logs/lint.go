@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/action"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/round"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+type ProblemKind string
+
+const (
+	// KindUnreadable is a file that cannot be opened or read to the end.
+	KindUnreadable ProblemKind = "unreadable"
+	// KindUnknownType is a message whose type is not an mjai message type.
+	KindUnknownType ProblemKind = "unknown_type"
+	// KindInvalidMessage is a line that is not a well-formed mjai message.
+	KindInvalidMessage ProblemKind = "invalid_message"
+	// KindServerError is an error message the server sent.
+	KindServerError ProblemKind = "server_error"
+	// KindInconsistent is an event that contradicts the game as replayed.
+	KindInconsistent ProblemKind = "inconsistent"
+	// KindIllegalAction is a discard, call, riichi or win that the rules do
+	// not allow the actor.
+	KindIllegalAction ProblemKind = "illegal_action"
+	// KindTileCount is a round that shows more copies of a tile than exist.
+	KindTileCount ProblemKind = "tile_count"
+	// KindKanDora is a dora indicator revealed without a kan or at the wrong
+	// point after one.
+	KindKanDora ProblemKind = "kan_dora"
+	// KindScore is a score update that does not add up.
+	KindScore ProblemKind = "score"
+	// KindIncomplete is a game that the file does not finish.
+	KindIncomplete ProblemKind = "incomplete"
+)
+
+// Problem is a violation found in a log.
+type Problem struct {
+	Path    string      `json:"path"`
+	Line    int         `json:"line"`
+	Kind    ProblemKind `json:"kind"`
+	Message string      `json:"message"`
+	// Event is the line of the problem, empty for problems of the whole file.
+	Event string `json:"event,omitzero"`
+	// Board is the round as replayed when the problem was found, empty
+	// outside a round.
+	Board string `json:"-"`
+}
+
+func (p Problem) String() string {
+	var b bytes.Buffer
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "%s:%d: %s: %s\n", p.Path, p.Line, p.Kind, p.Message)
+	} else {
+		fmt.Fprintf(&b, "%s: %s: %s\n", p.Path, p.Kind, p.Message)
+	}
+	if p.Event != "" {
+		fmt.Fprintf(&b, "  event: %s\n", p.Event)
+	}
+	b.WriteString(p.Board)
+	return b.String()
+}
+
+// lintFile replays the log at path and returns every problem in it, along
+// with the number of lines read.
+func lintFile(path string) ([]Problem, int) {
+	l := &fileLinter{path: path}
+	reader, err := archive.Open(path)
+	if err != nil {
+		l.report(KindUnreadable, err.Error())
+		return l.problems, 0
+	}
+	defer reader.Close()
+
+	l.lint(reader)
+	return l.problems, l.line
+}
+
+// fileLinter replays a log through round.State. After an event that cannot
+// be applied it skips the rest of the round and resyncs at the next
+// start_kyoku.
+type fileLinter struct {
+	path     string
+	problems []Problem
+	line     int
+	raw      []byte
+
+	inGame bool
+	state  *round.State
+	// skipping is set while the rest of a broken round is skipped.
+	skipping bool
+	scores   [common.NumPlayers]int
+	// scoresKnown is set when scores hold the scores after the previous
+	// round, rather than the initial ones or the ones of a broken round.
+	scoresKnown bool
+
+	// legal holds the legal actions of each seat offered by the last event
+	// that offers any, nil for a seat whose hand is hidden.
+	legal [common.NumPlayers][]action.Action
+	// pendingDoras is the number of kans whose dora indicator is not
+	// revealed yet.
+	pendingDoras      int
+	depositCollected  bool
+	tileCountReported bool
+}
+
+func (l *fileLinter) lint(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		l.line++
+		l.raw = bytes.TrimSpace(scanner.Bytes())
+		l.lintLine()
+	}
+	l.raw = nil
+	if err := scanner.Err(); err != nil {
+		l.report(KindUnreadable, err.Error())
+		return
+	}
+	if l.inGame {
+		l.report(KindIncomplete, "the file ends before end_game")
+	}
+}
+
+func (l *fileLinter) lintLine() {
+	if len(l.raw) == 0 {
+		l.report(KindInvalidMessage, "empty line")
+		return
+	}
+	msg, err := inbound.ParseMessage(l.raw)
+	if err != nil {
+		if _, ok := errors.AsType[*inbound.UnsupportedTypeError](err); ok {
+			l.report(KindUnknownType, err.Error())
+		} else {
+			l.report(KindInvalidMessage, err.Error())
+		}
+		return
+	}
+
+	switch msg := msg.(type) {
+	case *inbound.Hello:
+	case *inbound.Error:
+		l.report(KindServerError, "the server sent an error")
+	case *inbound.StartGame:
+		if l.inGame {
+			l.report(KindInconsistent, "start_game before end_game of the previous game")
+		}
+		l.inGame = true
+		l.state = nil
+		l.skipping = false
+		for i := range l.scores {
+			l.scores[i] = archive.InitialScore
+		}
+		l.scoresKnown = false
+	case *inbound.EndGame:
+		if !l.inGame {
+			l.report(KindInconsistent, "end_game without start_game")
+		} else if l.state != nil {
+			l.report(KindInconsistent, "end_game before end_kyoku")
+		}
+		l.inGame = false
+		l.state = nil
+		l.skipping = false
+	default:
+		ev, err := inbound.ParseEvent(msg)
+		if err != nil {
+			l.report(KindInvalidMessage, err.Error())
+			return
+		}
+		if !l.inGame {
+			l.report(KindInconsistent, "event before start_game")
+			l.inGame = true
+		}
+		l.lintEvent(ev)
+	}
+}
+
+func (l *fileLinter) lintEvent(ev event.Event) {
+	switch ev := ev.(type) {
+	case *event.StartRound:
+		l.startRound(ev)
+		return
+	case *event.EndRound:
+		if l.state == nil && !l.skipping {
+			l.report(KindInconsistent, "end_kyoku outside a round")
+		}
+		l.state = nil
+		l.skipping = false
+		return
+	}
+
+	if l.state == nil {
+		if !l.skipping {
+			l.report(KindInconsistent, "event before start_kyoku")
+			l.skipping = true
+		}
+		return
+	}
+
+	l.checkAction(ev)
+	l.checkScoreUpdate(ev)
+	if err := l.state.Apply(ev); err != nil {
+		kind := KindInconsistent
+		switch ev.(type) {
+		case *event.Dora:
+			kind = KindKanDora
+		case *event.Draw, *event.Discard:
+			// The round holds draws and discards until a kan's indicator is
+			// revealed.
+			if l.pendingDoras > 0 {
+				kind = KindKanDora
+			}
+		}
+		l.report(kind, err.Error())
+		l.state = nil
+		l.skipping = true
+		l.scoresKnown = false
+		return
+	}
+
+	switch ev.(type) {
+	case *event.CalledKan, *event.ConcealedKan, *event.PromotedKan:
+		l.pendingDoras++
+	case *event.Dora:
+		l.pendingDoras--
+	case *event.Win:
+		l.depositCollected = true
+	}
+	if offersActions(ev) {
+		l.updateLegalActions()
+	}
+	l.checkTileCounts()
+	l.scores = l.state.Scores()
+	l.scoresKnown = true
+}
+
+func (l *fileLinter) startRound(ev *event.StartRound) {
+	if l.state != nil {
+		l.report(KindInconsistent, "start_kyoku before end_kyoku")
+	}
+	if l.scoresKnown && ev.Scores() != nil && *ev.Scores() != l.scores {
+		l.report(KindScore, fmt.Sprintf("scores %v differ from the scores %v after the previous round", *ev.Scores(), l.scores))
+	}
+
+	state, err := round.NewState(ev, l.scores)
+	if err != nil {
+		l.report(KindInconsistent, err.Error())
+		l.state = nil
+		l.skipping = true
+		l.scoresKnown = false
+		return
+	}
+	l.state = state
+	l.skipping = false
+	l.pendingDoras = 0
+	l.depositCollected = false
+	l.tileCountReported = false
+	l.scores = state.Scores()
+	l.updateLegalActions()
+	l.checkTileCounts()
+}
+
+// offersActions reports whether the legal actions after ev are the ones
+// offered to the players. Dora indicators and accepted riichi do not offer
+// new actions, and a win keeps offering the other ron on the same discard.
+func offersActions(ev event.Event) bool {
+	switch ev.(type) {
+	case *event.Dora, *event.RiichiAccepted, *event.Win:
+		return false
+	default:
+		return true
+	}
+}
+
+func (l *fileLinter) updateLegalActions() {
+	for i := range common.NumPlayers {
+		actions, err := l.state.LegalActions(seat.MustSeat(i))
+		if err != nil {
+			// The hand is hidden.
+			actions = nil
+		}
+		l.legal[i] = actions
+	}
+}
+
+func (l *fileLinter) report(kind ProblemKind, message string) {
+	p := Problem{
+		Path:    l.path,
+		Line:    l.line,
+		Kind:    kind,
+		Message: message,
+		Event:   string(l.raw),
+	}
+	if l.state != nil {
+		p.Board = l.state.RenderBoard()
+	}
+	l.problems = append(l.problems, p)
+}
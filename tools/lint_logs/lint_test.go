@@ -0,0 +1,192 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const startGame = `{"type":"start_game","names":["a","b","c","d"]}
+`
+
+// startKyoku deals player 0 a hand waiting on 5m and 8m with sanshoku, and
+// shows all four copies of 1m.
+const startKyoku = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","1p","2p","3p","1s","2s","3s","5m","5m","6m","7m"],["1m","1m","2m","2m","3m","3m","4m","4m","5m","5m","6m","6m","7m"],["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"],["1p","1p","2p","2p","3p","3p","4p","4p","5p","5p","6p","6p","7p"]],"scores":[25000,25000,25000,25000]}
+`
+
+// ronRound has player 1 deal 2000 points into player 0.
+const ronRound = startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"8m"}
+{"type":"dahai","actor":1,"pai":"8m","tsumogiri":true}
+{"type":"hora","actor":0,"target":1,"pai":"8m","hora_points":2000,"deltas":[2000,-2000,0,0],"scores":[27000,23000,25000,25000]}
+{"type":"end_kyoku"}
+`
+
+// daiminkan has player 1 call an open kan on 4m, whose dora indicator is
+// revealed after the discard.
+const daiminkan = startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"4m"}
+{"type":"dahai","actor":1,"pai":"7m","tsumogiri":false}
+{"type":"tsumo","actor":2,"pai":"4m"}
+{"type":"dahai","actor":2,"pai":"4m","tsumogiri":true}
+{"type":"daiminkan","actor":1,"target":2,"pai":"4m","consumed":["4m","4m","4m"]}
+`
+
+const endGame = `{"type":"end_game","scores":[27000,23000,25000,25000]}
+`
+
+func writeLogFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	return path
+}
+
+type wantProblem struct {
+	line int
+	kind ProblemKind
+}
+
+func TestLintFile(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want []wantProblem
+	}{
+		{
+			name: "clean",
+			log:  startGame + ronRound + endGame,
+		},
+		{
+			name: "unknown type",
+			log:  startGame + `{"type":"chat","text":"hi"}` + "\n" + ronRound + endGame,
+			want: []wantProblem{{2, KindUnknownType}},
+		},
+		{
+			name: "invalid message",
+			log:  startGame + `{"type":"tsumo","actor":9,"pai":"1m"}` + "\n" + ronRound + endGame,
+			want: []wantProblem{{2, KindInvalidMessage}},
+		},
+		{
+			name: "chi from a player who is not on the left",
+			log: startGame + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"4p"}
+{"type":"dahai","actor":1,"pai":"4p","tsumogiri":true}
+{"type":"chi","actor":3,"target":1,"pai":"4p","consumed":["2p","3p"]}
+`,
+			want: []wantProblem{{7, KindIllegalAction}, {7, KindInconsistent}, {7, KindIncomplete}},
+		},
+		{
+			name: "fifth copy",
+			log: startGame + startKyoku + `{"type":"tsumo","actor":0,"pai":"1m"}
+{"type":"dahai","actor":0,"pai":"1m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"1m"}
+{"type":"ryukyoku","reason":"fanpai","deltas":[0,0,0,0]}
+{"type":"end_kyoku"}
+` + endGame,
+			want: []wantProblem{{3, KindTileCount}},
+		},
+		{
+			name: "deltas that do not sum to zero",
+			log:  startGame + strings.Replace(ronRound, `"deltas":[2000,-2000,0,0]`, `"deltas":[2000,-1000,0,0]`, 1) + endGame,
+			want: []wantProblem{{7, KindScore}},
+		},
+		{
+			name: "scores that do not follow the deltas",
+			log:  startGame + strings.Replace(ronRound, `"scores":[27000,23000,25000,25000]`, `"scores":[27000,23000,25000,26000]`, 1) + endGame,
+			want: []wantProblem{{7, KindScore}},
+		},
+		{
+			name: "scores that change between rounds",
+			log: startGame + ronRound + strings.Replace(ronRound,
+				`"scores":[25000,25000,25000,25000]`, `"scores":[27000,23000,24000,26000]`, 1) + endGame,
+			want: []wantProblem{{9, KindScore}, {14, KindScore}},
+		},
+		{
+			name: "dora without kan resyncs at the next round",
+			log: startGame + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dora","dora_marker":"2p"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"end_kyoku"}
+` + strings.Replace(ronRound, `"deltas":[2000,-2000,0,0]`, `"deltas":[2000,0,0,0]`, 1) + endGame,
+			want: []wantProblem{{4, KindKanDora}, {12, KindScore}},
+		},
+		{
+			name: "discard before the kan dora",
+			log: startGame + daiminkan + `{"type":"tsumo","actor":1,"pai":"5p"}
+{"type":"dahai","actor":1,"pai":"5p","tsumogiri":true}
+{"type":"end_kyoku"}
+` + endGame,
+			want: []wantProblem{{11, KindKanDora}},
+		},
+		{
+			name: "error other than dora after a kan",
+			log: startGame + daiminkan + `{"type":"tsumo","actor":1,"pai":"5p"}
+{"type":"pon","actor":2,"target":0,"pai":"E","consumed":["E","E"]}
+{"type":"end_kyoku"}
+` + endGame,
+			want: []wantProblem{{11, KindIllegalAction}, {11, KindInconsistent}},
+		},
+		{
+			name: "file without end_game",
+			log:  startGame + ronRound,
+			want: []wantProblem{{8, KindIncomplete}},
+		},
+		{
+			name: "server error",
+			log:  startGame + `{"type":"error","message":"bad"}` + "\n" + ronRound + endGame,
+			want: []wantProblem{{2, KindServerError}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, numLines := lintFile(writeLogFile(t, "game.mjson", tt.log))
+
+			if want := strings.Count(tt.log, "\n"); numLines != want {
+				t.Errorf("numLines = %d, want %d", numLines, want)
+			}
+			got := make([]wantProblem, len(problems))
+			for i, p := range problems {
+				got[i] = wantProblem{p.Line, p.Kind}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("problems = %v, want %v\n%v", got, tt.want, problems)
+			}
+		})
+	}
+}
+
+func TestLintFile_ReportsEventAndBoard(t *testing.T) {
+	log := startGame + strings.Replace(ronRound,
+		`"deltas":[2000,-2000,0,0],"scores":[27000,23000,25000,25000]`,
+		`"deltas":[2000,-1000,0,0],"scores":[27000,24000,25000,25000]`, 1) + endGame
+	path := writeLogFile(t, "game.mjson", log)
+
+	problems, _ := lintFile(path)
+	if len(problems) != 1 {
+		t.Fatalf("problems = %v, want one", problems)
+	}
+	got := problems[0].String()
+	if !strings.HasPrefix(got, path+":7: score: deltas [2000 -1000 0 0] sum to 1000, want 0\n  event: {\"type\":\"hora\"") {
+		t.Errorf("String() = %q, want the location, kind, message and event", got)
+	}
+	if !strings.Contains(got, "tehai: 1m 2m 3m 5m 5m 6m 7m 1p 2p 3p 1s 2s 3s") {
+		t.Errorf("String() = %q, want the board", got)
+	}
+}
+
+func TestLintFile_Unreadable(t *testing.T) {
+	problems, numLines := lintFile(filepath.Join(t.TempDir(), "missing.mjson"))
+
+	if numLines != 0 || len(problems) != 1 || problems[0].Kind != KindUnreadable {
+		t.Errorf("lintFile() = %v, %d, want one unreadable problem", problems, numLines)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json/v2"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+// Summary is the machine-readable result of a lint run.
+type Summary struct {
+	NumFiles       int                 `json:"numFiles"`
+	NumCleanFiles  int                 `json:"numCleanFiles"`
+	NumLines       int                 `json:"numLines"`
+	NumProblems    int                 `json:"numProblems"`
+	ProblemsByKind map[ProblemKind]int `json:"problemsByKind"`
+	// Files lists the files with problems.
+	Files []FileSummary `json:"files"`
+}
+
+type FileSummary struct {
+	Path     string    `json:"path"`
+	NumLines int       `json:"numLines"`
+	Problems []Problem `json:"problems"`
+}
+
+// run lints the logs matching patterns and writes every problem to out.
+func run(patterns []string, out io.Writer) (Summary, error) {
+	paths, err := archive.GlobAll(patterns)
+	if err != nil {
+		return Summary{}, err
+	}
+	if len(paths) == 0 {
+		return Summary{}, fmt.Errorf("no input files matched")
+	}
+
+	summary := Summary{ProblemsByKind: map[ProblemKind]int{}, Files: []FileSummary{}}
+	for _, path := range paths {
+		problems, numLines := lintFile(path)
+		summary.NumFiles++
+		summary.NumLines += numLines
+		if len(problems) == 0 {
+			summary.NumCleanFiles++
+			continue
+		}
+		summary.NumProblems += len(problems)
+		for _, p := range problems {
+			summary.ProblemsByKind[p.Kind]++
+			if _, err := io.WriteString(out, p.String()); err != nil {
+				return Summary{}, err
+			}
+		}
+		summary.Files = append(summary.Files, FileSummary{Path: path, NumLines: numLines, Problems: problems})
+	}
+	return summary, nil
+}
+
+func writeSummary(path string, summary Summary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := json.MarshalWrite(f, summary, json.Deterministic(true)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func main() {
+	fs := flag.NewFlagSet("lint_logs", flag.ExitOnError)
+	summaryPath := fs.String("summary", "", "write a JSON summary to `FILE`")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [-summary FILE] <LOG_GLOB_PATTERNS>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	summary, err := run(fs.Args(), os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *summaryPath != "" {
+		if err := writeSummary(*summaryPath, summary); err != nil {
+			log.Fatalf("failed to write summary: %v", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d problems in %d of %d files\n", summary.NumProblems, summary.NumFiles-summary.NumCleanFiles, summary.NumFiles)
+	if summary.NumProblems > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSummarizesProblems(t *testing.T) {
+	dir := t.TempDir()
+	clean := filepath.Join(dir, "clean.mjson")
+	broken := filepath.Join(dir, "broken.mjson")
+	for path, log := range map[string]string{
+		clean:  startGame + ronRound + endGame,
+		broken: startGame + `{"type":"chat"}` + "\n" + startGame + ronRound,
+	} {
+		if err := os.WriteFile(path, []byte(log), 0o600); err != nil {
+			t.Fatalf("failed to write log: %v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	summary, err := run([]string{filepath.Join(dir, "*.mjson")}, &out)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if summary.NumFiles != 2 || summary.NumCleanFiles != 1 || summary.NumLines != 19 || summary.NumProblems != 3 {
+		t.Errorf("summary = %+v, want 2 files, 1 clean, 19 lines and 3 problems", summary)
+	}
+	wantByKind := map[ProblemKind]int{KindUnknownType: 1, KindInconsistent: 1, KindIncomplete: 1}
+	if len(summary.ProblemsByKind) != len(wantByKind) {
+		t.Errorf("ProblemsByKind = %v, want %v", summary.ProblemsByKind, wantByKind)
+	}
+	for kind, n := range wantByKind {
+		if summary.ProblemsByKind[kind] != n {
+			t.Errorf("ProblemsByKind = %v, want %v", summary.ProblemsByKind, wantByKind)
+		}
+	}
+	if len(summary.Files) != 1 || summary.Files[0].Path != broken || len(summary.Files[0].Problems) != 3 {
+		t.Errorf("Files = %+v, want the broken file with 3 problems", summary.Files)
+	}
+	for _, want := range []string{
+		broken + ":2: unknown_type: ",
+		broken + ":3: inconsistent: start_game before end_game of the previous game",
+		broken + ":10: incomplete: the file ends before end_game",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunRejectsNoMatches(t *testing.T) {
+	if _, err := run([]string{filepath.Join(t.TempDir(), "*.mjson")}, &bytes.Buffer{}); err == nil {
+		t.Fatal("run() succeeded without input files")
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	summary := Summary{
+		NumFiles:       1,
+		NumLines:       3,
+		NumProblems:    1,
+		ProblemsByKind: map[ProblemKind]int{KindScore: 1},
+		Files: []FileSummary{{
+			Path:     "game.mjson",
+			NumLines: 3,
+			Problems: []Problem{{Path: "game.mjson", Line: 2, Kind: KindScore, Message: "bad", Event: "{}", Board: "board"}},
+		}},
+	}
+	if err := writeSummary(path, summary); err != nil {
+		t.Fatalf("writeSummary() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read summary: %v", err)
+	}
+	want := `{"numFiles":1,"numCleanFiles":0,"numLines":3,"numProblems":1,"problemsByKind":{"score":1},` +
+		`"files":[{"path":"game.mjson","numLines":3,"problems":[{"path":"game.mjson","line":2,"kind":"score","message":"bad","event":"{}"}]}]}`
+	if string(data) != want {
+		t.Errorf("summary = %s, want %s", data, want)
+	}
+	var decoded Summary
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+}
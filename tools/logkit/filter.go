@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// datePattern finds the date in log names such as 2026-07-01-130909.mjson,
+// the names mjai servers give their logs.
+var datePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// gameFilter keeps the games that meet every condition set.
+type gameFilter struct {
+	players        []string
+	excludePlayers []string
+	minRounds      int
+	maxRounds      int
+	gameType       string
+	since          time.Time
+	until          time.Time
+}
+
+func newGameFilter(opts *Options) (*gameFilter, error) {
+	f := &gameFilter{
+		players:        opts.Players,
+		excludePlayers: opts.ExcludePlayers,
+		minRounds:      opts.MinRounds,
+		maxRounds:      opts.MaxRounds,
+		gameType:       opts.GameType,
+	}
+	var err error
+	if opts.Since != "" {
+		if f.since, err = time.Parse(dateLayout, opts.Since); err != nil {
+			return nil, fmt.Errorf("invalid -since: %w", err)
+		}
+	}
+	if opts.Until != "" {
+		if f.until, err = time.Parse(dateLayout, opts.Until); err != nil {
+			return nil, fmt.Errorf("invalid -until: %w", err)
+		}
+	}
+	return f, nil
+}
+
+// keepsFile reports whether games in the log at path can be kept at all,
+// which depends on the date in its name when -since or -until is set.
+func (f *gameFilter) keepsFile(path string) bool {
+	if f.since.IsZero() && f.until.IsZero() {
+		return true
+	}
+	date, ok := fileDate(path)
+	if !ok {
+		return false
+	}
+	return (f.since.IsZero() || !date.Before(f.since)) && (f.until.IsZero() || !date.After(f.until))
+}
+
+func (f *gameFilter) keeps(g game) bool {
+	if len(f.players) > 0 && !slices.ContainsFunc(g.names, func(name string) bool { return slices.Contains(f.players, name) }) {
+		return false
+	}
+	if slices.ContainsFunc(g.names, func(name string) bool { return slices.Contains(f.excludePlayers, name) }) {
+		return false
+	}
+	if g.numRounds < f.minRounds || (f.maxRounds > 0 && g.numRounds > f.maxRounds) {
+		return false
+	}
+	if f.gameType != "" && g.gameType != f.gameType {
+		return false
+	}
+	return true
+}
+
+// fileDate returns the date in the name of the log at path.
+func fileDate(path string) (time.Time, bool) {
+	for _, match := range datePattern.FindAllString(filepath.Base(path), -1) {
+		if date, err := time.Parse(dateLayout, match); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// filterGames keeps the games f keeps in a file of the same name.
+func filterGames(f *gameFilter) transform {
+	return func(path string, games []game) ([]output, error) {
+		if !f.keepsFile(path) {
+			return nil, nil
+		}
+		var kept []game
+		for _, g := range games {
+			if f.keeps(g) {
+				kept = append(kept, g)
+			}
+		}
+		return []output{{name: filepath.Base(path), games: kept}}, nil
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+// game is the lines of one game in a log, from start_game to end_game.
+type game struct {
+	lines [][]byte
+	names []string
+	// gameType is the gametype hint of start_game, such as "tonpu", or empty
+	// when the server does not send one.
+	gameType  string
+	numRounds int
+}
+
+// header holds the members of a message that logkit looks at.
+type header struct {
+	Type     string   `json:"type"`
+	Names    []string `json:"names"`
+	GameType string   `json:"gametype"`
+}
+
+// readGames reads the games of the log at path. Lines outside a game, such
+// as hello, are dropped. A game that the file does not finish is kept.
+func readGames(path string) ([]game, error) {
+	reader, err := archive.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var games []game
+	var current *game
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var h header
+		if err := json.Unmarshal(line, &h); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+
+		switch h.Type {
+		case "start_game":
+			if current != nil {
+				games = append(games, *current)
+			}
+			current = &game{names: h.Names, gameType: h.GameType}
+		case "start_kyoku":
+			if current != nil {
+				current.numRounds++
+			}
+		}
+		if current == nil {
+			continue
+		}
+		current.lines = append(current.lines, bytes.Clone(line))
+		if h.Type == "end_game" {
+			games = append(games, *current)
+			current = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if current != nil {
+		games = append(games, *current)
+	}
+	return games, nil
+}
+
+func writeGames(w io.Writer, games []game) error {
+	bw := bufio.NewWriter(w)
+	for _, g := range games {
+		for _, line := range g.lines {
+			if _, err := bw.Write(line); err != nil {
+				return err
+			}
+			if err := bw.WriteByte('\n'); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// splitName splits the base name of path into a stem and the log
+// extension, keeping .gz with the extension before it.
+func splitName(path string) (stem, ext string) {
+	base := filepath.Base(path)
+	if strings.HasSuffix(base, ".gz") {
+		ext = ".gz"
+		base = strings.TrimSuffix(base, ext)
+	}
+	inner := filepath.Ext(base)
+	return strings.TrimSuffix(base, inner), inner + ext
+}
+
+// splitGames writes each game to a file of its own, numbered after the
+// file it comes from.
+func splitGames(path string, games []game) ([]output, error) {
+	stem, ext := splitName(path)
+	outputs := make([]output, len(games))
+	for i, g := range games {
+		outputs[i] = output{name: fmt.Sprintf("%s_%d%s", stem, i+1, ext), games: []game{g}}
+	}
+	return outputs, nil
+}
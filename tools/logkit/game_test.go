@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+const (
+	hello = `{"type":"hello","protocol":"mjsonp","protocol_version":3}
+`
+	startKyoku = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"],["?","?","?","?","?","?","?","?","?","?","?","?","?"]]}
+{"type":"ryukyoku","reason":"fanpai","deltas":[0,0,0,0]}
+{"type":"end_kyoku"}
+`
+	endGame = `{"type":"end_game"}
+`
+)
+
+// gameLog returns a game of the players names with numRounds rounds.
+func gameLog(names string, gameType string, numRounds int) string {
+	log := `{"type":"start_game"`
+	if gameType != "" {
+		log += `,"gametype":"` + gameType + `"`
+	}
+	log += `,"names":` + names + "}\n"
+	for range numRounds {
+		log += startKyoku
+	}
+	return log + endGame
+}
+
+func writeLogFile(t *testing.T, name, content string) string {
+	t.Helper()
+	return writeTestFile(t, filepath.Join(t.TempDir(), name), content)
+}
+
+func writeTestFile(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	return path
+}
+
+func writeTestGzip(t *testing.T, path, content string) string {
+	t.Helper()
+	w, err := archive.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create log: %v", err)
+	}
+	if _, err := io.WriteString(w, content); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close log: %v", err)
+	}
+	return path
+}
+
+func TestReadGames(t *testing.T) {
+	path := writeLogFile(t, "games.mjson", hello+
+		gameLog(`["a","b","c","d"]`, "tonpu", 2)+
+		gameLog(`["e","f","g","h"]`, "", 1)+
+		`{"type":"start_game","names":["i","j","k","l"]}`+"\n"+startKyoku)
+
+	games, err := readGames(path)
+	if err != nil {
+		t.Fatalf("readGames() error = %v", err)
+	}
+
+	if len(games) != 3 {
+		t.Fatalf("len(games) = %d, want 3", len(games))
+	}
+	wants := []struct {
+		firstName string
+		gameType  string
+		numRounds int
+		numLines  int
+	}{
+		{"a", "tonpu", 2, 8},
+		{"e", "", 1, 5},
+		{"i", "", 1, 4},
+	}
+	for i, want := range wants {
+		g := games[i]
+		if g.names[0] != want.firstName || g.gameType != want.gameType || g.numRounds != want.numRounds || len(g.lines) != want.numLines {
+			t.Errorf("games[%d] = %v %q %d rounds %d lines, want %+v", i, g.names, g.gameType, g.numRounds, len(g.lines), want)
+		}
+	}
+}
+
+func TestReadGamesRejectsInvalidJSON(t *testing.T) {
+	path := writeLogFile(t, "games.mjson", gameLog(`["a","b","c","d"]`, "", 1)+"{\n")
+
+	if _, err := readGames(path); err == nil {
+		t.Fatal("readGames() succeeded on a broken line")
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		path string
+		stem string
+		ext  string
+	}{
+		{"logs/2026-07-01-130909.mjson", "2026-07-01-130909", ".mjson"},
+		{"logs/2026-07-01-130909.mjson.gz", "2026-07-01-130909", ".mjson.gz"},
+		{"game", "game", ""},
+	}
+	for _, tt := range tests {
+		if stem, ext := splitName(tt.path); stem != tt.stem || ext != tt.ext {
+			t.Errorf("splitName(%q) = %q, %q, want %q, %q", tt.path, stem, ext, tt.stem, tt.ext)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+type Options struct {
+	Output string
+
+	Players        stringListFlag
+	ExcludePlayers stringListFlag
+	MinRounds      int
+	MaxRounds      int
+	GameType       string
+	Since          string
+	Until          string
+
+	Renames   stringListFlag
+	Anonymize bool
+	Salt      string
+	Mapping   string
+
+	Seed     string
+	Ratios   string
+	Fraction float64
+	Num      int
+}
+
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return fmt.Sprint([]string(*f))
+}
+
+func (f *stringListFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func parseOptions(action string, args []string) (*Options, []string, error) {
+	opts := Options{}
+
+	name := fmt.Sprintf("logkit %s", action)
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&opts.Output, "o", "", "output directory")
+	switch action {
+	case "filter":
+		fs.Var(&opts.Players, "player", "keep games with this player; may be specified multiple times")
+		fs.Var(&opts.ExcludePlayers, "exclude_player", "drop games with this player; may be specified multiple times")
+		fs.IntVar(&opts.MinRounds, "min_rounds", 0, "keep games of at least this many rounds")
+		fs.IntVar(&opts.MaxRounds, "max_rounds", 0, "keep games of at most this many rounds")
+		fs.StringVar(&opts.GameType, "gametype", "", "keep games whose start_game has this gametype, such as tonpu")
+		fs.StringVar(&opts.Since, "since", "", "keep logs whose name has this date (YYYY-MM-DD) or a later one")
+		fs.StringVar(&opts.Until, "until", "", "keep logs whose name has this date (YYYY-MM-DD) or an earlier one")
+	case "split":
+		// no options
+	case "rename":
+		fs.Var(&opts.Renames, "map", "rename OLD=NEW; may be specified multiple times")
+		fs.BoolVar(&opts.Anonymize, "anonymize", false, "replace every name without -map with a pseudonym")
+		fs.StringVar(&opts.Salt, "salt", "", "salt of the pseudonyms")
+		fs.StringVar(&opts.Mapping, "mapping", "", "write the old and new names to this JSON file")
+	case "partition":
+		fs.StringVar(&opts.Seed, "seed", "", "seed of the assignment")
+		fs.StringVar(&opts.Ratios, "ratios", "8,1,1", "ratios of train, validation and test")
+	case "sample":
+		fs.StringVar(&opts.Seed, "seed", "", "seed of the sample")
+		fs.Float64Var(&opts.Fraction, "fraction", 0, "keep this fraction of the games")
+		fs.IntVar(&opts.Num, "n", 0, "keep this many games")
+	default:
+		return nil, nil, fmt.Errorf("unknown action: %s", action)
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse flags: %w", err)
+	}
+	if opts.Output == "" {
+		return nil, nil, fmt.Errorf("output directory is not specified")
+	}
+	if action == "sample" {
+		if (opts.Fraction == 0) == (opts.Num == 0) {
+			return nil, nil, fmt.Errorf("specify exactly one of -fraction and -n")
+		}
+		if opts.Fraction < 0 || opts.Fraction > 1 || opts.Num < 0 {
+			return nil, nil, fmt.Errorf("-fraction must be in (0, 1] and -n positive")
+		}
+	}
+
+	return &opts, fs.Args(), nil
+}
+
+func run(action string, paths []string, opts *Options) (Stats, error) {
+	switch action {
+	case "filter":
+		f, err := newGameFilter(opts)
+		if err != nil {
+			return Stats{}, err
+		}
+		return process(paths, opts.Output, filterGames(f))
+	case "split":
+		return process(paths, opts.Output, splitGames)
+	case "rename":
+		r, err := newRenamer(opts)
+		if err != nil {
+			return Stats{}, err
+		}
+		stats, err := process(paths, opts.Output, r.renameGames)
+		if err != nil {
+			return stats, err
+		}
+		if opts.Mapping != "" {
+			if err := r.writeMapping(opts.Mapping); err != nil {
+				return stats, fmt.Errorf("failed to write mapping: %w", err)
+			}
+		}
+		return stats, nil
+	case "partition":
+		bounds, err := parseRatios(opts.Ratios)
+		if err != nil {
+			return Stats{}, err
+		}
+		return process(paths, opts.Output, partitionGames(opts.Seed, bounds))
+	case "sample":
+		threshold, err := sampleThreshold(paths, opts.Seed, opts.Fraction, opts.Num)
+		if err != nil {
+			return Stats{}, err
+		}
+		return process(paths, opts.Output, sampleGames(opts.Seed, threshold))
+	default:
+		return Stats{}, fmt.Errorf("unknown action: %s", action)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "missing action argument")
+		os.Exit(2)
+	}
+	action := os.Args[1]
+
+	opts, patterns, err := parseOptions(action, os.Args[2:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	paths, err := archive.GlobAll(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(paths) == 0 {
+		log.Fatal("no input files matched")
+	}
+
+	stats, err := run(action, paths, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintln(os.Stderr, stats)
+}
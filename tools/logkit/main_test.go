@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// readOutput returns the first player of every game in each file under dir,
+// keyed by the path relative to dir.
+func readOutput(t *testing.T, dir string) map[string][]string {
+	t.Helper()
+	got := map[string][]string{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		games, err := readGames(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		for _, g := range games {
+			got[filepath.ToSlash(rel)] = append(got[filepath.ToSlash(rel)], g.names[0])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	return got
+}
+
+func TestParseOptionsFilter(t *testing.T) {
+	opts, paths, err := parseOptions("filter", []string{
+		"-o", "out",
+		"-player", "a",
+		"-player", "（≧▽≦）",
+		"-exclude_player", "b",
+		"-min_rounds", "4",
+		"-since", "2026-07-01",
+		"logs/*.mjson",
+	})
+	if err != nil {
+		t.Fatalf("parseOptions() error = %v", err)
+	}
+
+	if got := []string(opts.Players); !reflect.DeepEqual(got, []string{"a", "（≧▽≦）"}) {
+		t.Errorf("Players = %v", got)
+	}
+	if opts.Output != "out" || opts.MinRounds != 4 || opts.Since != "2026-07-01" || len(opts.ExcludePlayers) != 1 {
+		t.Errorf("opts = %+v", opts)
+	}
+	if !reflect.DeepEqual(paths, []string{"logs/*.mjson"}) {
+		t.Errorf("paths = %v", paths)
+	}
+}
+
+func TestParseOptionsRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		action string
+		args   []string
+	}{
+		{"unknown", []string{"-o", "out"}},
+		{"split", nil},
+		{"sample", []string{"-o", "out"}},
+		{"sample", []string{"-o", "out", "-n", "2", "-fraction", "0.5"}},
+		{"sample", []string{"-o", "out", "-fraction", "2"}},
+	}
+	for _, tt := range tests {
+		if _, _, err := parseOptions(tt.action, tt.args); err == nil {
+			t.Errorf("parseOptions(%q, %v) succeeded", tt.action, tt.args)
+		}
+	}
+}
+
+func TestRunFilter(t *testing.T) {
+	in := t.TempDir()
+	for name, content := range map[string]string{
+		"2026-07-01-120000.mjson": gameLog(`["a","b","c","d"]`, "tonpu", 4) + gameLog(`["e","b","c","d"]`, "tonpu", 4),
+		"2026-07-02-120000.mjson": gameLog(`["a","x","c","d"]`, "tonnan", 8) + gameLog(`["a","b","c","d"]`, "tonpu", 2),
+		"2026-06-30-120000.mjson": gameLog(`["a","b","c","d"]`, "tonpu", 4),
+	} {
+		writeTestFile(t, filepath.Join(in, name), content)
+	}
+	out := t.TempDir()
+	paths, _ := filepath.Glob(filepath.Join(in, "*.mjson"))
+
+	stats, err := run("filter", paths, &Options{
+		Output:         out,
+		Players:        stringListFlag{"a"},
+		ExcludePlayers: stringListFlag{"x"},
+		MinRounds:      3,
+		GameType:       "tonpu",
+		Since:          "2026-07-01",
+	})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	want := map[string][]string{"2026-07-01-120000.mjson": {"a"}}
+	if got := readOutput(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("output = %v, want %v", got, want)
+	}
+	if want := (Stats{NumFiles: 3, NumGames: 5, NumFilesWritten: 1, NumGamesWritten: 1}); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestRunSplit(t *testing.T) {
+	log := hello + gameLog(`["a","b","c","d"]`, "", 1) + gameLog(`["e","f","g","h"]`, "", 1)
+	in := t.TempDir()
+	writeTestFile(t, filepath.Join(in, "games.mjson"), log)
+	writeTestGzip(t, filepath.Join(in, "more.mjson.gz"), log)
+	out := t.TempDir()
+
+	if _, err := run("split", []string{filepath.Join(in, "games.mjson"), filepath.Join(in, "more.mjson.gz")}, &Options{Output: out}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	want := map[string][]string{
+		"games_1.mjson":   {"a"},
+		"games_2.mjson":   {"e"},
+		"more_1.mjson.gz": {"a"},
+		"more_2.mjson.gz": {"e"},
+	}
+	if got := readOutput(t, out); !reflect.DeepEqual(got, want) {
+		t.Errorf("output = %v, want %v", got, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(out, "more_1.mjson.gz")); strings.HasPrefix(string(data), "{") {
+		t.Error("more_1.mjson.gz is not compressed")
+	}
+}
+
+func TestRunRename(t *testing.T) {
+	in := t.TempDir()
+	writeTestFile(t, filepath.Join(in, "1.mjson"), gameLog(`["a","b","c","d"]`, "", 1))
+	writeTestFile(t, filepath.Join(in, "2.mjson"), gameLog(`["d","c","b","a"]`, "", 1))
+	out := t.TempDir()
+	mapping := filepath.Join(t.TempDir(), "mapping.json")
+	paths := []string{filepath.Join(in, "1.mjson"), filepath.Join(in, "2.mjson")}
+
+	if _, err := run("rename", paths, &Options{Output: out, Renames: stringListFlag{"a=alice"}, Anonymize: true, Mapping: mapping}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	first, _ := readGames(filepath.Join(out, "1.mjson"))
+	second, _ := readGames(filepath.Join(out, "2.mjson"))
+	if first[0].names[0] != "alice" || second[0].names[3] != "alice" {
+		t.Errorf("names = %v and %v, want a renamed to alice", first[0].names, second[0].names)
+	}
+	for i := 1; i < 4; i++ {
+		if first[0].names[i] != second[0].names[3-i] || first[0].names[i] == "bcd"[i-1:i] {
+			t.Errorf("names = %v and %v, want the same pseudonyms in both files", first[0].names, second[0].names)
+		}
+	}
+	data, err := os.ReadFile(mapping)
+	if err != nil {
+		t.Fatalf("failed to read mapping: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"a":"alice","b":"player-`) {
+		t.Errorf("mapping = %s", data)
+	}
+}
+
+func TestRunPartitionAndSampleAreDeterministic(t *testing.T) {
+	in := t.TempDir()
+	var log string
+	for i := range 40 {
+		log += gameLog(fmt.Sprintf(`["p%d","b","c","d"]`, i), "", 1)
+	}
+	writeTestFile(t, filepath.Join(in, "games.mjson"), log)
+	paths := []string{filepath.Join(in, "games.mjson")}
+
+	partition := func(seed string) map[string][]string {
+		out := t.TempDir()
+		if _, err := run("partition", paths, &Options{Output: out, Seed: seed, Ratios: "2,1,1"}); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		return readOutput(t, out)
+	}
+	got := partition("s")
+	if !reflect.DeepEqual(got, partition("s")) {
+		t.Error("partition differs between runs with the same seed")
+	}
+	if reflect.DeepEqual(got, partition("t")) {
+		t.Error("partition is the same with another seed")
+	}
+	total := 0
+	for _, name := range partitionNames {
+		games := got[name+"/games.mjson"]
+		if len(games) == 0 {
+			t.Errorf("%s is empty: %v", name, got)
+		}
+		total += len(games)
+	}
+	if total != 40 {
+		t.Errorf("partition has %d games, want 40", total)
+	}
+
+	sample := func(num int) []string {
+		out := t.TempDir()
+		stats, err := run("sample", paths, &Options{Output: out, Seed: "s", Num: num})
+		if err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if stats.NumGamesWritten != num {
+			t.Errorf("sample of %d has %d games", num, stats.NumGamesWritten)
+		}
+		return readOutput(t, out)["games.mjson"]
+	}
+	small, large := sample(5), sample(10)
+	for _, name := range small {
+		if !slices.Contains(large, name) {
+			t.Errorf("sample of 5 %v is not in the sample of 10 %v", small, large)
+		}
+	}
+}
+
+func TestRunRefusesToOverwriteInput(t *testing.T) {
+	path := writeLogFile(t, "games.mjson", gameLog(`["a","b","c","d"]`, "", 1))
+
+	if _, err := run("filter", []string{path}, &Options{Output: filepath.Dir(path)}); err == nil {
+		t.Fatal("run() overwrote its input")
+	}
+}
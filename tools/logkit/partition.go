@@ -0,0 +1,122 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// partitionNames are the directories partition writes the games to, in the
+// order of -ratios.
+var partitionNames = [...]string{"train", "validation", "test"}
+
+// gameHash hashes the seed and the lines of g. Games are assigned by the
+// hash of their content, so a game lands in the same partition or sample
+// whatever file it is in and whatever other games are read with it.
+func gameHash(seed string, g game) uint64 {
+	h := sha256.New()
+	h.Write([]byte(seed))
+	h.Write([]byte{0})
+	for _, line := range g.lines {
+		h.Write(line)
+		h.Write([]byte{'\n'})
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil))
+}
+
+// unitInterval maps a hash to [0, 1).
+func unitInterval(hash uint64) float64 {
+	return float64(hash>>11) / (1 << 53)
+}
+
+// parseRatios parses -ratios, such as "8,1,1", into the cumulative shares
+// of the partitions.
+func parseRatios(spec string) ([len(partitionNames)]float64, error) {
+	var bounds [len(partitionNames)]float64
+	fields := strings.Split(spec, ",")
+	if len(fields) != len(partitionNames) {
+		return bounds, fmt.Errorf("invalid -ratios %q: want %d comma-separated numbers", spec, len(partitionNames))
+	}
+	total := 0.0
+	for i, field := range fields {
+		ratio, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || ratio < 0 {
+			return bounds, fmt.Errorf("invalid -ratios %q: %q is not a non-negative number", spec, field)
+		}
+		total += ratio
+		bounds[i] = total
+	}
+	if total == 0 {
+		return bounds, fmt.Errorf("invalid -ratios %q: the ratios sum to zero", spec)
+	}
+	for i := range bounds {
+		bounds[i] /= total
+	}
+	return bounds, nil
+}
+
+// partitionGames writes the games of a file to files of the same name under
+// the train, validation and test directories.
+func partitionGames(seed string, bounds [len(partitionNames)]float64) transform {
+	return func(path string, games []game) ([]output, error) {
+		outputs := make([]output, len(partitionNames))
+		for i, name := range partitionNames {
+			outputs[i].name = filepath.Join(name, filepath.Base(path))
+		}
+		for _, g := range games {
+			x := unitInterval(gameHash(seed, g))
+			i := 0
+			for i < len(bounds)-1 && x >= bounds[i] {
+				i++
+			}
+			outputs[i].games = append(outputs[i].games, g)
+		}
+		return outputs, nil
+	}
+}
+
+// sampleGames keeps the games whose hash is at most threshold in a file of
+// the same name.
+func sampleGames(seed string, threshold uint64) transform {
+	return func(path string, games []game) ([]output, error) {
+		var kept []game
+		for _, g := range games {
+			if gameHash(seed, g) <= threshold {
+				kept = append(kept, g)
+			}
+		}
+		return []output{{name: filepath.Base(path), games: kept}}, nil
+	}
+}
+
+// sampleThreshold returns the hash threshold of a sample. With fraction,
+// each game is kept with that probability. With num, the num games of the
+// smallest hashes are kept, which takes a first pass over paths.
+func sampleThreshold(paths []string, seed string, fraction float64, num int) (uint64, error) {
+	if num == 0 {
+		if fraction >= 1 {
+			return ^uint64(0), nil
+		}
+		return uint64(fraction * (1 << 64)), nil
+	}
+
+	var hashes []uint64
+	for _, path := range paths {
+		games, err := readGames(path)
+		if err != nil {
+			return 0, err
+		}
+		for _, g := range games {
+			hashes = append(hashes, gameHash(seed, g))
+		}
+	}
+	if num >= len(hashes) {
+		return ^uint64(0), nil
+	}
+	slices.Sort(hashes)
+	return hashes[num-1], nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRatios(t *testing.T) {
+	got, err := parseRatios("8, 1,1")
+	if err != nil {
+		t.Fatalf("parseRatios() error = %v", err)
+	}
+	want := [...]float64{0.8, 0.9, 1}
+	for i := range want {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Errorf("parseRatios() = %v, want %v", got, want)
+		}
+	}
+
+	for _, spec := range []string{"8,1", "8,1,x", "8,-1,1", "0,0,0"} {
+		if _, err := parseRatios(spec); err == nil {
+			t.Errorf("parseRatios(%q) succeeded", spec)
+		}
+	}
+}
+
+func TestGameHash(t *testing.T) {
+	g := game{lines: [][]byte{[]byte(`{"type":"start_game"}`), []byte(`{"type":"end_game"}`)}}
+	moved := game{lines: [][]byte{[]byte(`{"type":"start_game"}`), []byte(`{"type":"end_game"}`)}, numRounds: 3}
+	other := game{lines: [][]byte{[]byte(`{"type":"start_game"}`)}}
+
+	if gameHash("s", g) != gameHash("s", moved) {
+		t.Error("gameHash() depends on more than the lines")
+	}
+	if gameHash("s", g) == gameHash("s", other) {
+		t.Error("gameHash() is the same for different games")
+	}
+	if gameHash("s", g) == gameHash("t", g) {
+		t.Error("gameHash() does not depend on the seed")
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+// output is a file to write under the output directory and its games.
+type output struct {
+	// name is the path of the file relative to the output directory.
+	name  string
+	games []game
+}
+
+// transform turns the games of the log at path into the files to write.
+type transform func(path string, games []game) ([]output, error)
+
+// Stats counts what a subcommand read and wrote.
+type Stats struct {
+	NumFiles        int
+	NumGames        int
+	NumFilesWritten int
+	NumGamesWritten int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("wrote %d of %d games to %d files from %d files", s.NumGamesWritten, s.NumGames, s.NumFilesWritten, s.NumFiles)
+}
+
+// process reads the logs at paths one at a time and writes the files fn
+// makes of each under dir. Files without games are not written. It refuses
+// to write a file twice or over one of its inputs.
+func process(paths []string, dir string, fn transform) (Stats, error) {
+	inputs := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return Stats{}, err
+		}
+		inputs[abs] = true
+	}
+	written := make(map[string]bool)
+
+	var stats Stats
+	for _, path := range paths {
+		games, err := readGames(path)
+		if err != nil {
+			return stats, err
+		}
+		stats.NumFiles++
+		stats.NumGames += len(games)
+
+		outputs, err := fn(path, games)
+		if err != nil {
+			return stats, err
+		}
+		for _, out := range outputs {
+			if len(out.games) == 0 {
+				continue
+			}
+			dest := filepath.Join(dir, out.name)
+			abs, err := filepath.Abs(dest)
+			if err != nil {
+				return stats, err
+			}
+			if inputs[abs] {
+				return stats, fmt.Errorf("refusing to overwrite input %s", dest)
+			}
+			if written[abs] {
+				return stats, fmt.Errorf("more than one input writes %s", dest)
+			}
+			written[abs] = true
+
+			if err := writeFile(dest, out.games); err != nil {
+				return stats, err
+			}
+			stats.NumFilesWritten++
+			stats.NumGamesWritten += len(out.games)
+		}
+	}
+	return stats, nil
+}
+
+func writeFile(path string, games []game) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	w, err := archive.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeGames(w, games); err != nil {
+		w.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return w.Close()
+}
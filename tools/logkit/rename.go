@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// renamer replaces player names consistently across a corpus.
+type renamer struct {
+	renames   map[string]string
+	anonymize bool
+	salt      string
+	// seen maps every name renamed so far to its new name.
+	seen map[string]string
+}
+
+func newRenamer(opts *Options) (*renamer, error) {
+	r := &renamer{
+		renames:   make(map[string]string, len(opts.Renames)),
+		anonymize: opts.Anonymize,
+		salt:      opts.Salt,
+		seen:      make(map[string]string),
+	}
+	for _, spec := range opts.Renames {
+		from, to, ok := strings.Cut(spec, "=")
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid -map %q: want OLD=NEW", spec)
+		}
+		r.renames[from] = to
+	}
+	if len(r.renames) == 0 && !r.anonymize {
+		return nil, fmt.Errorf("nothing to rename: set -map or -anonymize")
+	}
+	return r, nil
+}
+
+// name returns the new name of name. With -anonymize, a name without a -map
+// entry becomes a pseudonym derived from the salt and the name, so it is the
+// same in every file and every run with the same salt.
+func (r *renamer) name(name string) string {
+	renamed, ok := r.renames[name]
+	if !ok {
+		if !r.anonymize || name == "" {
+			return name
+		}
+		sum := sha256.Sum256([]byte(r.salt + "\x00" + name))
+		renamed = "player-" + hex.EncodeToString(sum[:6])
+	}
+	r.seen[name] = renamed
+	return renamed
+}
+
+// renameGames renames the players of every game in a file of the same name.
+func (r *renamer) renameGames(path string, games []game) ([]output, error) {
+	renamed := make([]game, len(games))
+	for i, g := range games {
+		names := make([]string, len(g.names))
+		for j, name := range g.names {
+			names[j] = r.name(name)
+		}
+		line, err := replaceNames(g.lines[0], names)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		g.lines = append([][]byte{line}, g.lines[1:]...)
+		g.names = names
+		renamed[i] = g
+	}
+	return []output{{name: filepath.Base(path), games: renamed}}, nil
+}
+
+// writeMapping writes the names renamed so far and their new names as a
+// JSON object.
+func (r *renamer) writeMapping(path string) error {
+	data, err := json.Marshal(r.seen, json.Deterministic(true))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// replaceNames returns the start_game message line with its names member
+// replaced, keeping the other members as they are and in their order.
+func replaceNames(line []byte, names []string) ([]byte, error) {
+	dec := jsontext.NewDecoder(bytes.NewReader(line))
+	var buf bytes.Buffer
+	enc := jsontext.NewEncoder(&buf)
+
+	copyToken := func() (jsontext.Token, error) {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return jsontext.Token{}, err
+		}
+		return tok, enc.WriteToken(tok)
+	}
+	if tok, err := copyToken(); err != nil {
+		return nil, err
+	} else if tok.Kind() != '{' {
+		return nil, fmt.Errorf("start_game is not an object")
+	}
+	for dec.PeekKind() != '}' {
+		tok, err := copyToken()
+		if err != nil {
+			return nil, err
+		}
+		if tok.String() != "names" {
+			value, err := dec.ReadValue()
+			if err != nil {
+				return nil, err
+			}
+			if err := enc.WriteValue(value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := dec.SkipValue(); err != nil {
+			return nil, err
+		}
+		if err := json.MarshalEncode(enc, names); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := copyToken(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
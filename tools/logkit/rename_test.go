@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReplaceNames(t *testing.T) {
+	line := []byte(`{"type":"start_game","names":["a","b","c","d"],"logs":[null,null,null,null]}`)
+
+	got, err := replaceNames(line, []string{"w", "x", "a", "名前"})
+	if err != nil {
+		t.Fatalf("replaceNames() error = %v", err)
+	}
+
+	want := `{"type":"start_game","names":["w","x","a","名前"],"logs":[null,null,null,null]}`
+	if string(got) != want {
+		t.Errorf("replaceNames() = %s, want %s", got, want)
+	}
+}
+
+func TestRenamerName(t *testing.T) {
+	r, err := newRenamer(&Options{Renames: stringListFlag{"a=alice"}, Anonymize: true, Salt: "s"})
+	if err != nil {
+		t.Fatalf("newRenamer() error = %v", err)
+	}
+
+	if got := r.name("a"); got != "alice" {
+		t.Errorf("name(a) = %q, want alice", got)
+	}
+	b := r.name("b")
+	if !strings.HasPrefix(b, "player-") || len(b) != len("player-")+12 {
+		t.Errorf("name(b) = %q, want a pseudonym", b)
+	}
+	if r.name("b") != b {
+		t.Error("name(b) changed between calls")
+	}
+	if r.name("c") == b {
+		t.Error("name(c) = name(b)")
+	}
+
+	other, _ := newRenamer(&Options{Anonymize: true, Salt: "t"})
+	if other.name("b") == b {
+		t.Error("name(b) does not depend on the salt")
+	}
+}
+
+func TestNewRenamerRejectsInvalidOptions(t *testing.T) {
+	for _, opts := range []*Options{
+		{},
+		{Renames: stringListFlag{"a"}},
+		{Renames: stringListFlag{"=a"}},
+	} {
+		if _, err := newRenamer(opts); err == nil {
+			t.Errorf("newRenamer(%+v) succeeded", opts)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import "math"
+
+// z95 is the standard normal quantile of a two-sided 95% interval.
+const z95 = 1.959963984540054
+
+// Estimate is a point estimate with its 95% confidence interval.
+type Estimate struct {
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// estimateRate estimates a proportion with the Wilson score interval, which
+// stays inside [0, 1] even for small samples or rates near the edges. It
+// returns nil when there are no trials.
+func estimateRate(successes, trials int) *Estimate {
+	if trials == 0 {
+		return nil
+	}
+
+	n := float64(trials)
+	p := float64(successes) / n
+	z2 := z95 * z95
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := z95 / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+	return &Estimate{
+		Value: p,
+		Lower: max(center-margin, 0),
+		Upper: min(center+margin, 1),
+	}
+}
+
+// estimateMean estimates a mean with the normal approximation. The interval
+// collapses to the mean when there is a single sample. It returns nil when
+// there are no samples.
+func estimateMean(samples []float64) *Estimate {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	n := float64(len(samples))
+	sum := 0.0
+	for _, s := range samples {
+		sum += s
+	}
+	mean := sum / n
+	if len(samples) == 1 {
+		return &Estimate{Value: mean, Lower: mean, Upper: mean}
+	}
+
+	sqSum := 0.0
+	for _, s := range samples {
+		sqSum += (s - mean) * (s - mean)
+	}
+	margin := z95 * math.Sqrt(sqSum/(n-1)/n)
+	return &Estimate{Value: mean, Lower: mean - margin, Upper: mean + margin}
+}
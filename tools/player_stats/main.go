@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json/v2"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+	"github.com/schollz/progressbar/v3"
+)
+
+func run(patterns []string) ([]PlayerStats, error) {
+	paths, err := archive.GlobAll(patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no input files matched")
+	}
+
+	a := archive.NewArchive()
+	counter := newStatsCounter()
+
+	bar := progressbar.Default(int64(len(paths)))
+	err = a.PlayPaths(paths, archive.Handlers{
+		OnMessage: func(msg inbound.Message) error {
+			switch msg := msg.(type) {
+			case *inbound.Error:
+				return fmt.Errorf("error in the log")
+			case *inbound.StartGame:
+				return counter.onStartGame(msg.Names)
+			case *inbound.EndGame:
+				scores := a.Scores()
+				if len(msg.Scores) == common.NumPlayers {
+					copy(scores[:], msg.Scores)
+				}
+				return counter.onEndGame(scores)
+			}
+			return nil
+		},
+		OnEvent: func(ev event.Event, _ *archive.Archive) error {
+			return counter.onEvent(ev)
+		},
+		OnFileDone: func(string) error {
+			return bar.Add(1)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return counter.result(), nil
+}
+
+func writeTable(w io.Writer, stats []PlayerStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "name\tgames\trounds\twin%\tdeal-in%\triichi%\tcall%\twin pts\tdeal-in pts\ttsumo%\tryukyoku tenpai%\t1st\t2nd\t3rd\t4th\tavg rank\t")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			s.Name, s.NumGames, s.NumRounds,
+			formatPercent(s.WinRate),
+			formatPercent(s.DealInRate),
+			formatPercent(s.RiichiRate),
+			formatPercent(s.CallRate),
+			formatEstimate(s.AverageWinPoints, "%.0f"),
+			formatEstimate(s.AverageDealInPoints, "%.0f"),
+			formatPercent(s.TsumoRate),
+			formatPercent(s.RyukyokuTenpaiRate),
+			formatRatio(s.PlacementDistribution[0]),
+			formatRatio(s.PlacementDistribution[1]),
+			formatRatio(s.PlacementDistribution[2]),
+			formatRatio(s.PlacementDistribution[3]),
+			formatEstimate(s.AverageRank, "%.2f"),
+		)
+	}
+	return tw.Flush()
+}
+
+func formatPercent(e *Estimate) string {
+	if e == nil {
+		return "-"
+	}
+	return formatEstimate(&Estimate{Value: e.Value * 100, Lower: e.Lower * 100, Upper: e.Upper * 100}, "%.1f")
+}
+
+// formatEstimate prints the value followed by its confidence interval.
+func formatEstimate(e *Estimate, verb string) string {
+	if e == nil {
+		return "-"
+	}
+	return fmt.Sprintf(verb+" ["+verb+", "+verb+"]", e.Value, e.Lower, e.Upper)
+}
+
+func formatRatio(ratio float64) string {
+	return fmt.Sprintf("%.3f", ratio)
+}
+
+func main() {
+	fs := flag.NewFlagSet("player_stats", flag.ExitOnError)
+	format := fs.String("format", "json", "output format: json or table")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [-format json|table] <LOG_GLOB_PATTERNS>...\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() < 1 || (*format != "json" && *format != "table") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	stats, err := run(fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *format {
+	case "json":
+		if err := json.MarshalWrite(os.Stdout, stats, json.Deterministic(true)); err != nil {
+			log.Fatalf("failed to output result: %v", err)
+		}
+		fmt.Println()
+	case "table":
+		if err := writeTable(os.Stdout, stats); err != nil {
+			log.Fatalf("failed to output result: %v", err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json/v2"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const startKyoku = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","1p","2p","3p","1s","2s","3s","5m","5m","6m","7m"],["1m","1m","2m","2m","3m","3m","4m","4m","5m","5m","6m","6m","7m"],["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","S","W","N"],["1p","1p","2p","2p","3p","3p","4p","4p","5p","5p","6p","6p","7p"]],"scores":[25000,25000,25000,25000]}
+`
+
+// ronLog has player "b" deal 2000 points into "a".
+const ronLog = `{"type":"start_game","names":["a","b","c","d"]}
+` + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"8m"}
+{"type":"dahai","actor":1,"pai":"8m","tsumogiri":true}
+{"type":"hora","actor":0,"target":1,"pai":"8m","hora_points":2000,"scores":[27000,23000,25000,25000]}
+{"type":"end_kyoku"}
+{"type":"end_game","scores":[27000,23000,25000,25000]}
+`
+
+// ryukyokuLog has "a" declare riichi and "c", "b" and "d" tie at 24000.
+const ryukyokuLog = `{"type":"start_game","names":["c","a","b","d"]}
+` + startKyoku + `{"type":"tsumo","actor":0,"pai":"9m"}
+{"type":"dahai","actor":0,"pai":"9m","tsumogiri":true}
+{"type":"tsumo","actor":1,"pai":"7m"}
+{"type":"reach","actor":1}
+{"type":"dahai","actor":1,"pai":"1m","tsumogiri":false}
+{"type":"reach_accepted","actor":1,"scores":[25000,24000,25000,25000]}
+{"type":"ryukyoku","tenpais":[false,true,false,false],"scores":[24000,27000,24000,24000]}
+{"type":"end_kyoku"}
+{"type":"end_game"}
+`
+
+func TestRunAggregatesByName(t *testing.T) {
+	got, err := run([]string{writeLogFile(t, "ron.mjson", ronLog), writeLogFile(t, "ryukyoku.mjson", ryukyokuLog)})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	names := make([]string, len(got))
+	for i, s := range got {
+		names[i] = s.Name
+	}
+	if strings.Join(names, ",") != "a,b,c,d" {
+		t.Fatalf("names = %v, want [a b c d]", names)
+	}
+
+	a, b, d := got[0], got[1], got[3]
+	if a.NumGames != 2 || a.NumRounds != 2 {
+		t.Errorf("a games/rounds = %d/%d, want 2/2", a.NumGames, a.NumRounds)
+	}
+	if a.WinRate.Value != 0.5 || a.RiichiRate.Value != 0.5 || a.RyukyokuTenpaiRate.Value != 1 {
+		t.Errorf("a win/riichi/tenpai = %v/%v/%v, want 0.5/0.5/1", a.WinRate.Value, a.RiichiRate.Value, a.RyukyokuTenpaiRate.Value)
+	}
+	if a.AverageWinPoints.Value != 2000 || a.TsumoRate.Value != 0 {
+		t.Errorf("a win points/tsumo = %v/%v, want 2000/0", a.AverageWinPoints.Value, a.TsumoRate.Value)
+	}
+	if a.PlacementCounts != [4]int{2, 0, 0, 0} || a.AverageRank.Value != 1 {
+		t.Errorf("a placements = %v avg %v, want [2 0 0 0] avg 1", a.PlacementCounts, a.AverageRank.Value)
+	}
+	if b.DealInRate.Value != 0.5 || b.AverageDealInPoints.Value != 2000 {
+		t.Errorf("b deal-in rate/points = %v/%v, want 0.5/2000", b.DealInRate.Value, b.AverageDealInPoints.Value)
+	}
+	if b.WinRate.Value != 0 || b.TsumoRate != nil {
+		t.Errorf("b win rate/tsumo = %v/%v, want 0/nil", b.WinRate.Value, b.TsumoRate)
+	}
+	// "d" ties with "c" and then "b" but sits last in both games.
+	if d.PlacementCounts != [4]int{0, 0, 1, 1} {
+		t.Errorf("d placements = %v, want [0 0 1 1]", d.PlacementCounts)
+	}
+}
+
+func TestRunRejectsMissingNames(t *testing.T) {
+	log := strings.Replace(ronLog, `"names":["a","b","c","d"]`, `"names":["a"]`, 1)
+	if _, err := run([]string{writeLogFile(t, "game.mjson", log)}); err == nil {
+		t.Fatal("run() succeeded unexpectedly")
+	}
+}
+
+func TestRunRejectsNoMatches(t *testing.T) {
+	if _, err := run([]string{filepath.Join(t.TempDir(), "*.mjson")}); err == nil {
+		t.Fatal("run() succeeded unexpectedly")
+	}
+}
+
+func TestEstimateRate(t *testing.T) {
+	if got := estimateRate(0, 0); got != nil {
+		t.Errorf("estimateRate(0, 0) = %v, want nil", got)
+	}
+	got := estimateRate(0, 10)
+	if got.Value != 0 || got.Lower != 0 || got.Upper <= 0 {
+		t.Errorf("estimateRate(0, 10) = %+v, want value and lower 0 with positive upper", got)
+	}
+	got = estimateRate(50, 100)
+	if got.Lower >= 0.5 || got.Upper <= 0.5 || math.Abs((got.Upper-0.5)-(0.5-got.Lower)) > 1e-12 {
+		t.Errorf("estimateRate(50, 100) = %+v, want an interval symmetric around 0.5", got)
+	}
+}
+
+func TestEstimateMean(t *testing.T) {
+	if got := estimateMean(nil); got != nil {
+		t.Errorf("estimateMean(nil) = %v, want nil", got)
+	}
+	got := estimateMean([]float64{1, 2, 3})
+	if got.Value != 2 || got.Lower >= 2 || got.Upper <= 2 {
+		t.Errorf("estimateMean = %+v, want an interval around 2", got)
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	stats, err := run([]string{writeLogFile(t, "game.mjson", ronLog)})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	data, err := json.Marshal(stats, json.Deterministic(true))
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"name":"a"`)) || !bytes.Contains(data, []byte(`"averageDealInPoints"`)) {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTable(&buf, stats); err != nil {
+		t.Fatalf("writeTable() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("table has %d lines, want header and 4 players:\n%s", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[1], "100.0 [") {
+		t.Errorf("row of a = %q, want a 100%% win rate", lines[1])
+	}
+}
+
+func writeLogFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+)
+
+// PlayerStats is the stat sheet of one player name.
+type PlayerStats struct {
+	Name      string `json:"name"`
+	NumGames  int    `json:"numGames"`
+	NumRounds int    `json:"numRounds"`
+
+	WinRate             *Estimate `json:"winRate,omitzero"`
+	DealInRate          *Estimate `json:"dealInRate,omitzero"`
+	RiichiRate          *Estimate `json:"riichiRate,omitzero"`
+	CallRate            *Estimate `json:"callRate,omitzero"`
+	AverageWinPoints    *Estimate `json:"averageWinPoints,omitzero"`
+	AverageDealInPoints *Estimate `json:"averageDealInPoints,omitzero"`
+	// TsumoRate is the share of self-draw wins among the wins.
+	TsumoRate *Estimate `json:"tsumoRate,omitzero"`
+	// RyukyokuTenpaiRate is the share of exhaustive draws the player ended
+	// in tenpai.
+	RyukyokuTenpaiRate *Estimate `json:"ryukyokuTenpaiRate,omitzero"`
+
+	// PlacementCounts counts the games finished in 1st to 4th place.
+	PlacementCounts       [common.NumPlayers]int     `json:"placementCounts"`
+	PlacementDistribution [common.NumPlayers]float64 `json:"placementDistribution"`
+	AverageRank           *Estimate                  `json:"averageRank,omitzero"`
+}
+
+// roundRecord is what one seat did in the current round. It is folded into
+// the player's totals at end_kyoku so that a double ron counts as one round
+// with a deal-in.
+type roundRecord struct {
+	won          bool
+	dealtIn      bool
+	riichi       bool
+	called       bool
+	dealInPoints int
+}
+
+type playerCounter struct {
+	numGames          int
+	numRounds         int
+	numWins           int
+	numTsumoWins      int
+	numDealIns        int
+	numRiichis        int
+	numCalls          int
+	numRyukyokus      int
+	numRyukyokuTenpai int
+	winPoints         []float64
+	dealInPoints      []float64
+	placementCounts   [common.NumPlayers]int
+	ranks             []float64
+}
+
+// statsCounter groups the stats of each seat by the player names of the
+// current game.
+type statsCounter struct {
+	players map[string]*playerCounter
+	names   [common.NumPlayers]string
+	inGame  bool
+	rounds  [common.NumPlayers]roundRecord
+}
+
+func newStatsCounter() *statsCounter {
+	return &statsCounter{players: make(map[string]*playerCounter)}
+}
+
+func (c *statsCounter) player(playerID int) *playerCounter {
+	name := c.names[playerID]
+	p, ok := c.players[name]
+	if !ok {
+		p = &playerCounter{}
+		c.players[name] = p
+	}
+	return p
+}
+
+func (c *statsCounter) onStartGame(names []string) error {
+	if len(names) != common.NumPlayers {
+		return fmt.Errorf("start_game must have %d player names, got %d", common.NumPlayers, len(names))
+	}
+	copy(c.names[:], names)
+	c.inGame = true
+	for playerID := range common.NumPlayers {
+		c.player(playerID).numGames++
+	}
+	return nil
+}
+
+// onEndGame records the placements from the final scores. Ties go to the
+// player nearer to the first dealer, as in the mjai server.
+func (c *statsCounter) onEndGame(scores [common.NumPlayers]int) error {
+	if !c.inGame {
+		return fmt.Errorf("end_game without start_game")
+	}
+	c.inGame = false
+
+	order := []int{0, 1, 2, 3}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return scores[b] - scores[a]
+	})
+	for rank, playerID := range order {
+		p := c.player(playerID)
+		p.placementCounts[rank]++
+		p.ranks = append(p.ranks, float64(rank+1))
+	}
+	return nil
+}
+
+func (c *statsCounter) onEvent(ev event.Event) error {
+	if !c.inGame {
+		return fmt.Errorf("%T outside a game", ev)
+	}
+
+	switch ev := ev.(type) {
+	case *event.StartRound:
+		c.rounds = [common.NumPlayers]roundRecord{}
+	case *event.RiichiAccepted:
+		c.rounds[ev.Actor().Index()].riichi = true
+	case *event.Chii:
+		c.rounds[ev.Actor().Index()].called = true
+	case *event.Pon:
+		c.rounds[ev.Actor().Index()].called = true
+	case *event.CalledKan:
+		c.rounds[ev.Actor().Index()].called = true
+	case *event.Win:
+		p := c.player(ev.Actor().Index())
+		c.rounds[ev.Actor().Index()].won = true
+		p.winPoints = append(p.winPoints, float64(ev.WinningPoints()))
+		if ev.Actor() == ev.Target() {
+			p.numTsumoWins++
+		} else {
+			target := &c.rounds[ev.Target().Index()]
+			target.dealtIn = true
+			target.dealInPoints += ev.WinningPoints()
+		}
+	case *event.DrawRound:
+		// Abortive draws carry no tenpai flags and are not counted.
+		tenpais := ev.Tenpais()
+		if tenpais == nil {
+			break
+		}
+		for playerID := range common.NumPlayers {
+			p := c.player(playerID)
+			p.numRyukyokus++
+			if tenpais[playerID] {
+				p.numRyukyokuTenpai++
+			}
+		}
+	case *event.EndRound:
+		for playerID, r := range c.rounds {
+			c.player(playerID).addRound(r)
+		}
+	}
+	return nil
+}
+
+func (p *playerCounter) addRound(r roundRecord) {
+	p.numRounds++
+	if r.won {
+		p.numWins++
+	}
+	if r.dealtIn {
+		p.numDealIns++
+		p.dealInPoints = append(p.dealInPoints, float64(r.dealInPoints))
+	}
+	if r.riichi {
+		p.numRiichis++
+	}
+	if r.called {
+		p.numCalls++
+	}
+}
+
+func (p *playerCounter) stats(name string) PlayerStats {
+	s := PlayerStats{
+		Name:                name,
+		NumGames:            p.numGames,
+		NumRounds:           p.numRounds,
+		WinRate:             estimateRate(p.numWins, p.numRounds),
+		DealInRate:          estimateRate(p.numDealIns, p.numRounds),
+		RiichiRate:          estimateRate(p.numRiichis, p.numRounds),
+		CallRate:            estimateRate(p.numCalls, p.numRounds),
+		AverageWinPoints:    estimateMean(p.winPoints),
+		AverageDealInPoints: estimateMean(p.dealInPoints),
+		TsumoRate:           estimateRate(p.numTsumoWins, p.numWins),
+		RyukyokuTenpaiRate:  estimateRate(p.numRyukyokuTenpai, p.numRyukyokus),
+		PlacementCounts:     p.placementCounts,
+		AverageRank:         estimateMean(p.ranks),
+	}
+	if numFinished := len(p.ranks); numFinished > 0 {
+		for rank, count := range p.placementCounts {
+			s.PlacementDistribution[rank] = float64(count) / float64(numFinished)
+		}
+	}
+	return s
+}
+
+// result returns the stat sheets sorted by player name.
+func (c *statsCounter) result() []PlayerStats {
+	result := make([]PlayerStats, 0, len(c.players))
+	for _, name := range slices.Sorted(maps.Keys(c.players)) {
+		result = append(result, c.players[name].stats(name))
+	}
+	return result
+}
@@ -0,0 +1,255 @@
+"use strict";
+
+// The page defines replay as {title, frames, images}. Each frame is the game
+// after a line of the log; its board is an mjai snapshot.
+
+const honorImages = {E: "ji_e", S: "ji_s", W: "ji_w", N: "ji_n", P: "no", F: "ji_h", C: "ji_c"};
+const suitImages = {m: "ms", p: "ps", s: "ss"};
+const windNames = {E: "East", S: "South", W: "West", N: "North"};
+const seatWinds = ["E", "S", "W", "N"];
+
+let current = 0;
+
+function tileImage(code, side) {
+  let name = "bk";
+  if (honorImages[code]) {
+    name = honorImages[code];
+  } else if (code && code !== "?") {
+    name = suitImages[code[1]] + code[0] + (code.endsWith("r") ? "r" : "");
+  }
+  const img = document.createElement("img");
+  img.src = replay.images[name + (side ? "_3" : "_1")];
+  img.alt = code || "?";
+  img.title = code || "?";
+  if (side) {
+    img.classList.add("side");
+  }
+  return img;
+}
+
+function element(tag, className, text) {
+  const e = document.createElement(tag);
+  if (className) {
+    e.className = className;
+  }
+  if (text !== undefined) {
+    e.textContent = text;
+  }
+  return e;
+}
+
+function tiles(codes, className) {
+  const div = element("div", "tiles" + (className ? " " + className : ""));
+  for (const code of codes) {
+    div.appendChild(tileImage(code, false));
+  }
+  return div;
+}
+
+// furoTiles lays the called tile sideways on the side of the player it was
+// taken from, as on a table.
+function furoTiles(furo, seat) {
+  const div = element("div", "tiles");
+  if (furo.type === "ankan") {
+    furo.consumed.forEach((code, i) => {
+      div.appendChild(tileImage(i === 0 || i === 3 ? "?" : code, false));
+    });
+    return div;
+  }
+  const codes = furo.consumed.map((code) => ({code, side: false}));
+  const relative = (furo.target - seat + 4) % 4;
+  const position = relative === 3 ? 0 : relative === 2 ? 1 : codes.length;
+  codes.splice(position, 0, {code: furo.pai, side: true});
+  if (furo.added) {
+    codes.splice(position + 1, 0, {code: furo.added, side: true});
+  }
+  for (const {code, side} of codes) {
+    div.appendChild(tileImage(code, side));
+  }
+  return div;
+}
+
+// river returns the visible discards. Tiles taken by calls are not in kawa,
+// so the tsumogiri flags of sutehai are matched to kawa in order.
+function river(player) {
+  const div = element("div", "tiles river");
+  const sutehai = player.sutehai || [];
+  const flags = player.tsumogiri || [];
+  let j = 0;
+  (player.kawa || []).forEach((code, i) => {
+    while (j < sutehai.length && sutehai[j] !== code) {
+      j++;
+    }
+    const img = tileImage(code, i === player.reach_kawa_index);
+    if (flags[j]) {
+      img.classList.add("tsumogiri");
+    }
+    j++;
+    div.appendChild(img);
+  });
+  return div;
+}
+
+function describeRound(board) {
+  return windNames[board.bakaze] + " " + board.kyoku + ", " + board.honba + " honba, " +
+    board.kyotaku + " riichi sticks, " + board.num_left_tiles + " tiles left";
+}
+
+function renderPlayer(frame, event, seat) {
+  const board = frame.board;
+  const player = board.players[seat];
+  const div = element("div", "player" + (event.actor === seat ? " actor" : ""));
+  const wind = seatWinds[(seat - board.oya + 4) % 4];
+  const head = element("div");
+  head.appendChild(element("span", "name", windNames[wind] + " " + (frame.names[seat] || "player " + seat)));
+  head.appendChild(document.createTextNode(" " + board.scores[seat]));
+  if (player.reach) {
+    head.appendChild(element("span", "reach", " riichi"));
+  }
+  div.appendChild(head);
+
+  const hand = element("div", "row");
+  hand.appendChild(tiles(player.tehai));
+  if (player.tsumo) {
+    hand.appendChild(tiles([player.tsumo]));
+  }
+  for (const furo of player.furos || []) {
+    hand.appendChild(furoTiles(furo, seat));
+  }
+  div.appendChild(hand);
+  if (frame.ukeire && frame.ukeire[seat]) {
+    div.appendChild(element("div", "ukeire", frame.ukeire[seat]));
+  }
+
+  const discards = element("div", "row");
+  discards.appendChild(river(player));
+  div.appendChild(discards);
+  return div;
+}
+
+function renderBoard(frame) {
+  const section = document.getElementById("board");
+  section.replaceChildren();
+  const event = Object.assign({}, frame.event);
+  delete event.logs;
+
+  if (frame.board) {
+    const info = element("div", "info", describeRound(frame.board) + ", dora indicators ");
+    info.appendChild(tiles(frame.board.dora_markers));
+    info.lastChild.style.display = "inline-flex";
+    section.appendChild(info);
+  } else {
+    section.appendChild(element("div", "info", frame.names.map((name, i) => name + " " + frame.scores[i]).join(", ")));
+  }
+  section.appendChild(element("div", "event", "line " + frame.line + ": " + JSON.stringify(event)));
+  if (frame.board) {
+    for (let seat = 0; seat < 4; seat++) {
+      section.appendChild(renderPlayer(frame, event, seat));
+    }
+  }
+}
+
+function percent(p) {
+  return (100 * p).toFixed(1) + "%";
+}
+
+function renderDecisions(frame) {
+  const aside = document.getElementById("decisions");
+  aside.replaceChildren();
+  for (const d of frame.decisions || []) {
+    const div = element("div", "decision");
+    let title = (frame.names[d.seat] || "player " + d.seat);
+    if (d.action) {
+      title += ": " + JSON.stringify(d.action);
+    }
+    div.appendChild(element("h2", "", title));
+    if (d.candidates) {
+      const table = element("table");
+      const head = element("tr");
+      for (const name of ["candidate", "exp_pt", "my_hora_prob", "hoju_prob", "shanten"]) {
+        head.appendChild(element("th", "", name));
+      }
+      table.appendChild(head);
+      for (const c of d.candidates) {
+        const row = element("tr");
+        row.appendChild(element("td", "", c.key));
+        row.appendChild(element("td", "", Math.round(c.exp_pt)));
+        row.appendChild(element("td", "", percent(c.my_hora_prob)));
+        row.appendChild(element("td", "", percent(c.hoju_prob)));
+        row.appendChild(element("td", "", c.shanten === null ? "-" : c.shanten));
+        table.appendChild(row);
+      }
+      div.appendChild(table);
+    }
+    if (d.log) {
+      div.appendChild(element("pre", "", d.log));
+    }
+    aside.appendChild(div);
+  }
+}
+
+function isRoundStart(frame) {
+  return frame.event.type === "start_kyoku" || frame.event.type === "start_game";
+}
+
+function roundLabel(frame) {
+  if (frame.event.type === "start_game") {
+    return "game " + (frame.game + 1);
+  }
+  return windNames[frame.event.bakaze] + " " + frame.event.kyoku + "-" + frame.event.honba;
+}
+
+function show(index) {
+  current = Math.max(0, Math.min(replay.frames.length - 1, index));
+  const frame = replay.frames[current];
+  renderBoard(frame);
+  renderDecisions(frame);
+  document.getElementById("slider").value = current;
+  document.getElementById("position").textContent = (current + 1) + " / " + replay.frames.length;
+  let start = current;
+  while (start > 0 && !isRoundStart(replay.frames[start])) {
+    start--;
+  }
+  document.getElementById("rounds").value = start;
+  if (location.hash !== "#" + current) {
+    history.replaceState(null, "", "#" + current);
+  }
+}
+
+function jumpRound(step) {
+  let i = current;
+  do {
+    i += step;
+  } while (i > 0 && i < replay.frames.length - 1 && !isRoundStart(replay.frames[i]));
+  show(i);
+}
+
+function init() {
+  const rounds = document.getElementById("rounds");
+  replay.frames.forEach((frame, i) => {
+    if (isRoundStart(frame)) {
+      const option = element("option", "", roundLabel(frame));
+      option.value = i;
+      rounds.appendChild(option);
+    }
+  });
+  rounds.addEventListener("change", () => show(Number(rounds.value)));
+  const slider = document.getElementById("slider");
+  slider.max = replay.frames.length - 1;
+  slider.addEventListener("input", () => show(Number(slider.value)));
+  document.getElementById("prev").addEventListener("click", () => show(current - 1));
+  document.getElementById("next").addEventListener("click", () => show(current + 1));
+  document.getElementById("prev-round").addEventListener("click", () => jumpRound(-1));
+  document.getElementById("next-round").addEventListener("click", () => jumpRound(1));
+  document.addEventListener("keydown", (e) => {
+    const actions = {ArrowLeft: () => show(current - 1), ArrowRight: () => show(current + 1),
+      ArrowUp: () => jumpRound(-1), ArrowDown: () => jumpRound(1)};
+    if (actions[e.key] && e.target.tagName !== "SELECT") {
+      e.preventDefault();
+      actions[e.key]();
+    }
+  });
+  show(Number(location.hash.slice(1)) || 0);
+}
+
+init();
@@ -0,0 +1,95 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/base64"
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	//go:embed assets/viewer.html
+	viewerHTML string
+	//go:embed assets/viewer.css
+	viewerCSS string
+	//go:embed assets/viewer.js
+	viewerJS string
+)
+
+var viewerTemplate = template.Must(template.New("viewer").Parse(viewerHTML))
+
+// tileImageNames are the names of the tile images without the pose, as in
+// scripts/self-match/images.
+var tileImageNames = func() []string {
+	var names []string
+	for _, suit := range []string{"ms", "ps", "ss"} {
+		for n := 1; n <= 9; n++ {
+			names = append(names, fmt.Sprintf("%s%d", suit, n))
+		}
+		names = append(names, suit+"5r")
+	}
+	return append(names, "ji_e", "ji_s", "ji_w", "ji_n", "no", "ji_h", "ji_c", "bk")
+}()
+
+// loadTileImages reads the tile images in dir as data URLs keyed by the
+// image name without the extension, such as "ms1_1". Red fives are PNG and
+// the other tiles are GIF.
+func loadTileImages(dir string) (map[string]string, error) {
+	images := map[string]string{}
+	var missing []string
+	for _, name := range tileImageNames {
+		ext, mime := ".gif", "image/gif"
+		if strings.HasSuffix(name, "5r") {
+			ext, mime = ".png", "image/png"
+		}
+		for _, pose := range []string{"1", "3"} {
+			key := name + "_" + pose
+			data, err := os.ReadFile(filepath.Join(dir, "p_"+key+ext))
+			if os.IsNotExist(err) {
+				missing = append(missing, "p_"+key+ext)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			images[key] = "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing tile images in %s: %s", dir, strings.Join(missing, ", "))
+	}
+	return images, nil
+}
+
+// page is the data of a replay page.
+type page struct {
+	Title  string            `json:"title"`
+	Frames []frame           `json:"frames"`
+	Images map[string]string `json:"images"`
+}
+
+// writeHTML writes a page that replays the frames without other files.
+func writeHTML(w io.Writer, p page) error {
+	// Escaping < and > keeps the data from closing the script element.
+	data, err := json.Marshal(p, jsontext.EscapeForHTML(true), json.Deterministic(true))
+	if err != nil {
+		return err
+	}
+	return viewerTemplate.Execute(w, struct {
+		Title  string
+		Style  template.CSS
+		Script template.JS
+		Data   template.JS
+	}{
+		Title:  p.Title,
+		Style:  template.CSS(viewerCSS),
+		Script: template.JS(viewerJS),
+		Data:   template.JS(data),
+	})
+}
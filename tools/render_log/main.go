@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultImageDir = "scripts/self-match/images"
+
+type stringListFlag []string
+
+func (s *stringListFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringListFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+type Options struct {
+	Output   string
+	Traces   stringListFlag
+	ImageDir string
+}
+
+// outputPath returns the page of the log at path, next to the log unless
+// the options name a file.
+func (o *Options) outputPath(path string) string {
+	if o.Output != "" {
+		return o.Output
+	}
+	return path + ".html"
+}
+
+// run writes the replay page of the log at path and returns its path and the
+// number of frames.
+func run(path string, opts *Options) (string, int, error) {
+	frames, err := replayLog(path)
+	if err != nil {
+		return "", 0, err
+	}
+	if len(frames) == 0 {
+		return "", 0, fmt.Errorf("%s: no games", path)
+	}
+	for _, trace := range opts.Traces {
+		games, err := readTrace(trace)
+		if err != nil {
+			return "", 0, err
+		}
+		if err := attachTrace(frames, games); err != nil {
+			return "", 0, fmt.Errorf("%s: %w", trace, err)
+		}
+	}
+	images, err := loadTileImages(opts.ImageDir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	out := opts.outputPath(path)
+	f, err := os.Create(out)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := writeHTML(f, page{Title: filepath.Base(path), Frames: frames, Images: images}); err != nil {
+		f.Close()
+		return "", 0, err
+	}
+	return out, len(frames), f.Close()
+}
+
+func main() {
+	opts := &Options{}
+	fs := flag.NewFlagSet("render_log", flag.ExitOnError)
+	fs.StringVar(&opts.Output, "o", "", "write the page to `FILE` instead of <LOG>.html")
+	fs.Var(&opts.Traces, "trace", "overlay the decisions in the session recording `FILE` of mjai-manue --record (repeatable)")
+	fs.StringVar(&opts.ImageDir, "images", defaultImageDir, "read the tile images from `DIR`")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [-o FILE] [-trace FILE]... [-images DIR] <LOG>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	out, numFrames, err := run(fs.Arg(0), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d events to %s\n", numFrames, out)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTileImages writes placeholder tile images and returns their directory.
+func writeTileImages(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range tileImageNames {
+		ext := ".gif"
+		if strings.HasSuffix(name, "5r") {
+			ext = ".png"
+		}
+		for _, pose := range []string{"1", "3"} {
+			if err := os.WriteFile(filepath.Join(dir, "p_"+name+"_"+pose+ext), []byte(name), 0o600); err != nil {
+				t.Fatalf("failed to write image: %v", err)
+			}
+		}
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	path := writeLogFile(t, "game.mjson", startGame+round+endGame)
+	trace := writeLogFile(t, "trace.jsonl", playerTrace(t, "tsumo"))
+
+	out, numFrames, err := run(path, &Options{Traces: stringListFlag{trace}, ImageDir: writeTileImages(t)})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	if out != path+".html" || numFrames != 7 {
+		t.Errorf("run() = %q, %d, want %q, 7", out, numFrames, path+".html")
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read page: %v", err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"<title>game.mjson</title>",
+		`"ms1_1":"data:image/gif;base64,`,
+		`"my_hora_prob":0.25`,
+		`decided \u003c/script\u003e N`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page does not contain %q", want)
+		}
+	}
+	if n := strings.Count(html, "</script>"); n != 2 {
+		t.Errorf("page has %d </script>, want 2", n)
+	}
+}
+
+func TestLoadTileImagesReportsMissingImages(t *testing.T) {
+	dir := writeTileImages(t)
+	if err := os.Remove(filepath.Join(dir, "p_ms5r_3.png")); err != nil {
+		t.Fatalf("failed to remove image: %v", err)
+	}
+
+	if _, err := loadTileImages(dir); err == nil || !strings.Contains(err.Error(), "p_ms5r_3.png") {
+		t.Errorf("loadTileImages() error = %v, want p_ms5r_3.png missing", err)
+	}
+}
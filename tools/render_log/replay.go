@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/inbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/snapshot"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/common"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/event"
+	"github.com/Apricot-S/mjai-manue-go/internal/domain/game/seat"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+// frame is the game after a line of the log, from start_game to end_game.
+type frame struct {
+	// Game is the 0-based index of the game in the log.
+	Game int `json:"game"`
+	// Line is the 1-based line number in the log.
+	Line   int                    `json:"line"`
+	Event  jsontext.Value         `json:"event"`
+	Names  []string               `json:"names"`
+	Scores [common.NumPlayers]int `json:"scores"`
+	// Board is the round after the event, nil outside a round.
+	Board *snapshot.Snapshot `json:"board,omitzero"`
+	// Ukeire holds the ukeire line of each player with a visible hand.
+	Ukeire    [common.NumPlayers]string `json:"ukeire,omitzero"`
+	Decisions []decision                `json:"decisions,omitzero"`
+}
+
+// decision is the response of a player to the event of a frame.
+type decision struct {
+	Seat int `json:"seat"`
+	// Action is the message sent, without log and meta.
+	Action     jsontext.Value           `json:"action,omitzero"`
+	Log        string                   `json:"log,omitzero"`
+	Candidates []outbound.MetaCandidate `json:"candidates,omitzero"`
+}
+
+// loggedDecisions holds the decision logs that the Mjai server writes into
+// each line of its logs.
+type loggedDecisions struct {
+	Logs []*string `json:"logs"`
+}
+
+// replayLog replays the log at path through round.State and returns a frame
+// for every line of its games.
+func replayLog(path string) ([]frame, error) {
+	var (
+		frames []frame
+		raw    []byte
+		line   int
+		game   = -1
+		inGame bool
+		names  []string
+	)
+	err := archive.NewArchive().PlayPaths([]string{path}, archive.Handlers{
+		OnRaw: func(b []byte) error {
+			raw = b
+			line++
+			return nil
+		},
+		OnMessage: func(msg inbound.Message) error {
+			switch msg := msg.(type) {
+			case *inbound.Hello:
+				return nil
+			case *inbound.StartGame:
+				game++
+				inGame = true
+				names = msg.Names
+			}
+			if !inGame {
+				return nil
+			}
+			f := frame{Game: game, Line: line, Event: jsontext.Value(raw), Names: names}
+			for i := range common.NumPlayers {
+				f.Scores[i] = archive.InitialScore
+			}
+			if len(frames) > 0 && frames[len(frames)-1].Game == game {
+				f.Scores = frames[len(frames)-1].Scores
+			}
+			var logged loggedDecisions
+			if err := json.Unmarshal(raw, &logged); err == nil {
+				for seat, log := range logged.Logs {
+					if log != nil && *log != "" {
+						f.Decisions = append(f.Decisions, decision{Seat: seat, Log: *log})
+					}
+				}
+			}
+			frames = append(frames, f)
+			if _, ok := msg.(*inbound.EndGame); ok {
+				inGame = false
+			}
+			return nil
+		},
+		OnEvent: func(_ event.Event, a *archive.Archive) error {
+			if !inGame {
+				return nil
+			}
+			f := &frames[len(frames)-1]
+			f.Scores = a.Scores()
+			if state, ok := a.State(); ok {
+				f.Board = snapshot.FromState(state)
+				for i := range common.NumPlayers {
+					f.Ukeire[i] = strings.TrimSuffix(state.RenderUkeire(seat.MustSeat(i)), "\n")
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	hello     = `{"type":"hello","protocol":"mjsonp","protocol_version":3}` + "\n"
+	startGame = `{"type":"start_game","names":["A","B","C","D"],"logs":[null,null,null,null]}` + "\n"
+	round     = `{"type":"start_kyoku","bakaze":"E","kyoku":1,"honba":0,"kyotaku":0,"oya":0,"dora_marker":"1m","tehais":[["1m","2m","3m","4m","5m","6m","7m","8m","1p","2p","3p","5p","5p"],["1s","2s","3s","4s","5s","6s","7s","8s","9s","E","E","S","S"],["1p","1p","2p","2p","3p","3p","4p","4p","6p","6p","7p","7p","W"],["9m","9m","9m","9p","9p","9p","1s","1s","P","P","F","F","C"]]}` + "\n" +
+		`{"type":"tsumo","actor":0,"pai":"N","logs":[null,null,null,null]}` + "\n" +
+		`{"type":"dahai","actor":0,"pai":"N","tsumogiri":true,"logs":["decided </script> N",null,null,null]}` + "\n" +
+		`{"type":"tsumo","actor":1,"pai":"W"}` + "\n" +
+		`{"type":"end_kyoku"}` + "\n"
+	endGame = `{"type":"end_game"}` + "\n"
+)
+
+func writeLogFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+	return path
+}
+
+func TestReplayLog(t *testing.T) {
+	path := writeLogFile(t, "game.mjson", hello+startGame+round+endGame)
+
+	frames, err := replayLog(path)
+	if err != nil {
+		t.Fatalf("replayLog() error = %v", err)
+	}
+
+	if len(frames) != 7 {
+		t.Fatalf("len(frames) = %d, want 7", len(frames))
+	}
+	if f := frames[0]; f.Line != 2 || f.Board != nil || f.Names[3] != "D" || f.Scores[0] != 25000 {
+		t.Errorf("frames[0] = %+v, want start_game on line 2", f)
+	}
+	if f := frames[2]; f.Board == nil || f.Board.Players[0].Tsumo != "N" {
+		t.Errorf("frames[2].Board = %+v, want N drawn by player 0", f.Board)
+	}
+	if f := frames[2]; f.Ukeire[0] != "ukeire: dahai N  shanten 0  10 tiles: 3m 6m 9m" {
+		t.Errorf("frames[2].Ukeire[0] = %q, want the ukeire after discarding N", f.Ukeire[0])
+	}
+	if f := frames[3]; len(f.Decisions) != 1 || f.Decisions[0].Seat != 0 || f.Decisions[0].Log != "decided </script> N" {
+		t.Errorf("frames[3].Decisions = %+v, want the log of player 0", f.Decisions)
+	}
+	if f := frames[6]; f.Board != nil || f.Game != 0 {
+		t.Errorf("frames[6] = %+v, want end_game without a board", f)
+	}
+}
+
+func TestReplayLogRejectsInconsistentLog(t *testing.T) {
+	path := writeLogFile(t, "game.mjson", startGame+`{"type":"tsumo","actor":0,"pai":"N"}`+"\n")
+
+	if _, err := replayLog(path); err == nil {
+		t.Fatal("replayLog() succeeded on an event before start_kyoku")
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"strings"
+
+	"github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/outbound"
+	mjairuntime "github.com/Apricot-S/mjai-manue-go/internal/adapter/mjai/runtime"
+	"github.com/Apricot-S/mjai-manue-go/tools/internal/archive"
+)
+
+// tracedStep is an inbound line of a session recording with the decision the
+// player made on it, if any.
+type tracedStep struct {
+	inbound  string
+	decision *decision
+}
+
+// readTrace reads a session recording written by mjai-manue --record and
+// returns its steps by game, from start_game on.
+func readTrace(path string) ([][]tracedStep, error) {
+	reader, err := archive.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	rec, err := mjairuntime.ReadRecording(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var games [][]tracedStep
+	seat := rec.Header.FallbackID
+	for _, step := range rec.Steps() {
+		var msg struct {
+			Type string `json:"type"`
+			ID   *int   `json:"id"`
+		}
+		if err := json.Unmarshal([]byte(step.Inbound), &msg); err != nil {
+			return nil, fmt.Errorf("%s: invalid inbound line: %w", path, err)
+		}
+		if msg.Type == "start_game" {
+			games = append(games, nil)
+			seat = rec.Header.FallbackID
+			if msg.ID != nil {
+				seat = *msg.ID
+			}
+		}
+		if len(games) == 0 {
+			continue
+		}
+		d, err := parseDecision(seat, step)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		games[len(games)-1] = append(games[len(games)-1], tracedStep{inbound: step.Inbound, decision: d})
+	}
+	return games, nil
+}
+
+// parseDecision returns the decision of seat in step, or nil when the player
+// did not decide anything: it sent nothing, or none without a trace as an
+// mjsonp client does for every message.
+func parseDecision(seat int, step mjairuntime.Step) (*decision, error) {
+	d := &decision{Seat: seat, Log: strings.Join(step.Traces, "\n")}
+	for _, line := range step.Outbound {
+		var members map[string]jsontext.Value
+		if err := json.Unmarshal([]byte(line), &members); err != nil {
+			return nil, fmt.Errorf("invalid outbound line: %w", err)
+		}
+		var typ string
+		if err := json.Unmarshal(members["type"], &typ); err != nil {
+			return nil, fmt.Errorf("invalid outbound line: %w", err)
+		}
+		if typ == "join" || (typ == "none" && d.Log == "") {
+			continue
+		}
+		if meta, ok := members["meta"]; ok {
+			var m outbound.Meta
+			if err := json.Unmarshal(meta, &m); err != nil {
+				return nil, fmt.Errorf("invalid meta: %w", err)
+			}
+			d.Candidates = m.Candidates
+		}
+		if log, ok := members["log"]; ok && d.Log == "" {
+			if err := json.Unmarshal(log, &d.Log); err != nil {
+				return nil, fmt.Errorf("invalid log: %w", err)
+			}
+		}
+		delete(members, "meta")
+		delete(members, "log")
+		action, err := json.Marshal(members, json.Deterministic(true))
+		if err != nil {
+			return nil, err
+		}
+		d.Action = action
+	}
+	if d.Action == nil && d.Log == "" {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// attachTrace adds the decisions of a trace to the frames of the games in
+// the same order. The lines of a game in the trace must be the lines of the
+// game in the log as the player saw them.
+func attachTrace(frames []frame, games [][]tracedStep) error {
+	start := 0
+	for g, steps := range games {
+		for start < len(frames) && frames[start].Game < g {
+			start++
+		}
+		if start == len(frames) || frames[start].Game != g {
+			return fmt.Errorf("the trace has %d games, but the log has %d", len(games), g)
+		}
+		for i, step := range steps {
+			j := start + i
+			if j == len(frames) || frames[j].Game != g {
+				return fmt.Errorf("game %d of the trace is longer than the log", g+1)
+			}
+			if !sameMessageType(frames[j].Event, step.inbound) {
+				return fmt.Errorf("line %d of the log %s does not match %s in the trace", frames[j].Line, frames[j].Event, step.inbound)
+			}
+			if step.decision == nil {
+				continue
+			}
+			// The trace replaces the log the server wrote for the player.
+			decisions := frames[j].Decisions[:0:0]
+			for _, d := range frames[j].Decisions {
+				if d.Seat != step.decision.Seat {
+					decisions = append(decisions, d)
+				}
+			}
+			frames[j].Decisions = append(decisions, *step.decision)
+		}
+	}
+	return nil
+}
+
+// sameMessageType reports whether two lines are messages of the same type
+// and actor, which is as far as a line of the log and the line the player
+// saw agree.
+func sameMessageType(a []byte, b string) bool {
+	type header struct {
+		Type  string `json:"type"`
+		Actor *int   `json:"actor"`
+	}
+	var ha, hb header
+	if json.Unmarshal(a, &ha) != nil || json.Unmarshal([]byte(b), &hb) != nil {
+		return false
+	}
+	if ha.Type != hb.Type {
+		return false
+	}
+	if ha.Actor == nil || hb.Actor == nil {
+		return ha.Actor == hb.Actor
+	}
+	return *ha.Actor == *hb.Actor
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json/v2"
+	"strings"
+	"testing"
+)
+
+const traceHeader = `{"type":"session","version":1,"transport":"stdio","name":"B","room":"default","fallback_id":0,"seed":0,"agent":"manue"}` + "\n"
+
+// recordingEntry returns a line of a session recording.
+func recordingEntry(t *testing.T, typ string, member string, value string) string {
+	t.Helper()
+	b, err := json.Marshal(map[string]string{"type": typ, member: value})
+	if err != nil {
+		t.Fatalf("failed to marshal entry: %v", err)
+	}
+	return string(b) + "\n"
+}
+
+// playerTrace returns a recording of player 1 in the game of replay_test.go,
+// deciding on its draw of W.
+func playerTrace(t *testing.T, lastType string) string {
+	t.Helper()
+	lines := []string{
+		hello,
+		`{"type":"start_game","id":1,"names":["A","B","C","D"]}`,
+		strings.SplitN(round, "\n", 2)[0],
+		`{"type":"tsumo","actor":0,"pai":"?"}`,
+		`{"type":"dahai","actor":0,"pai":"N","tsumogiri":true}`,
+		`{"type":"` + lastType + `","actor":1,"pai":"W"}`,
+	}
+	trace := traceHeader
+	for _, line := range lines {
+		trace += recordingEntry(t, "in", "line", strings.TrimSuffix(line, "\n"))
+		if strings.HasPrefix(line, `{"type":"hello"`) {
+			trace += recordingEntry(t, "out", "line", `{"type":"join","name":"B","room":"default"}`)
+		}
+	}
+	trace += recordingEntry(t, "trace", "text", "| action | expPt |")
+	trace += recordingEntry(t, "out", "line", `{"type":"dahai","actor":1,"pai":"W","tsumogiri":true,"log":"| action | expPt |","meta":{"eval_time_ms":1,"candidates":[{"key":"-1.W","action":{"type":"dahai","actor":1,"pai":"W","tsumogiri":true},"exp_pt":100,"my_hora_prob":0.25,"hoju_prob":0,"shanten":1}]}}`)
+	return trace
+}
+
+func TestAttachTrace(t *testing.T) {
+	frames, err := replayLog(writeLogFile(t, "game.mjson", startGame+round+endGame))
+	if err != nil {
+		t.Fatalf("replayLog() error = %v", err)
+	}
+	games, err := readTrace(writeLogFile(t, "trace.jsonl", playerTrace(t, "tsumo")))
+	if err != nil {
+		t.Fatalf("readTrace() error = %v", err)
+	}
+
+	if err := attachTrace(frames, games); err != nil {
+		t.Fatalf("attachTrace() error = %v", err)
+	}
+
+	decisions := frames[4].Decisions
+	if len(decisions) != 1 {
+		t.Fatalf("frames[4].Decisions = %+v, want the decision of player 1", decisions)
+	}
+	d := decisions[0]
+	if d.Seat != 1 || d.Log != "| action | expPt |" || len(d.Candidates) != 1 || d.Candidates[0].MyHoraProb != 0.25 {
+		t.Errorf("decision = %+v", d)
+	}
+	if want := `{"actor":1,"pai":"W","tsumogiri":true,"type":"dahai"}`; string(d.Action) != want {
+		t.Errorf("Action = %s, want %s", d.Action, want)
+	}
+	// The log of player 0 on its discard stays.
+	if len(frames[3].Decisions) != 1 || frames[3].Decisions[0].Seat != 0 {
+		t.Errorf("frames[3].Decisions = %+v, want the log of player 0", frames[3].Decisions)
+	}
+}
+
+func TestAttachTraceRejectsAnotherGame(t *testing.T) {
+	frames, err := replayLog(writeLogFile(t, "game.mjson", startGame+round+endGame))
+	if err != nil {
+		t.Fatalf("replayLog() error = %v", err)
+	}
+	games, err := readTrace(writeLogFile(t, "trace.jsonl", playerTrace(t, "dahai")))
+	if err != nil {
+		t.Fatalf("readTrace() error = %v", err)
+	}
+
+	if err := attachTrace(frames, games); err == nil || !strings.Contains(err.Error(), "line 5 of the log") {
+		t.Errorf("attachTrace() error = %v, want a mismatch on line 5", err)
+	}
+}